@@ -2,6 +2,7 @@ package apiclient
 
 import (
 	"bytes"
+	"cqrseventsourcingbar/shared"
 	"cqrseventsourcingbar/writeservice/model"
 	"encoding/json"
 	"errors"
@@ -9,6 +10,8 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+
+	"github.com/segmentio/ksuid"
 )
 
 type WriteClient struct {
@@ -37,6 +40,10 @@ func (w *WriteClient) ExecuteCommand(commandRequest interface{}) error {
 		return err
 	}
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(model.CorrelationIDHeader, ksuid.New().String())
+	if actor := actorOf(commandRequest); actor != "" {
+		req.Header.Set(model.ActorHeader, actor)
+	}
 
 	commandResponse := model.CommandReponse{}
 
@@ -63,9 +70,27 @@ func (w *WriteClient) ExecuteCommand(commandRequest interface{}) error {
 		return nil
 	}
 
+	if len(commandResponse.PriceMismatches) > 0 {
+		return &shared.PriceMismatchError{Mismatches: commandResponse.PriceMismatches}
+	}
+
+	if len(commandResponse.StockShortages) > 0 {
+		return &shared.InsufficientStockError{Shortages: commandResponse.StockShortages}
+	}
+
 	return errors.New(commandResponse.Error)
 }
 
+// actorOf is the waiter a request names, the write service takes the actor of the other tab commands from the tab.
+func actorOf(request interface{}) string {
+	switch r := request.(type) {
+	case model.OpenTabRequest:
+		return r.Waiter
+	default:
+		return ""
+	}
+}
+
 func resolveUri(request interface{}) (string, error) {
 	switch request.(type) {
 	case model.OpenTabRequest:
@@ -74,6 +99,18 @@ func resolveUri(request interface{}) (string, error) {
 		return "placeOrder", nil
 	case model.MarkDrinksServedRequest:
 		return "markDrinksServed", nil
+	case model.MarkFoodPreparedRequest:
+		return "markFoodPrepared", nil
+	case model.MarkFoodServedRequest:
+		return "markFoodServed", nil
+	case model.CancelItemsRequest:
+		return "cancelItems", nil
+	case model.ApplyDiscountRequest:
+		return "applyDiscount", nil
+	case model.CompItemRequest:
+		return "compItem", nil
+	case model.RecordPaymentRequest:
+		return "recordPayment", nil
 	case model.CloseTabRequest:
 		return "closeTab", nil
 	default:
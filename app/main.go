@@ -27,7 +27,7 @@ func main() {
 	mainContainerStage := ui.CreateMainContentScreen(tableControl, waiterControl)
 	openTabStage := ui.CreateOpenTabScreen(waiters, writeApiClient, &stageManager)
 	invoiceStage := ui.CreateInvoiceScreen(readApiClient, writeApiClient, &stageManager, w)
-	placeOrderStage := ui.CreatePlaceOrderScreen(writeApiClient, readApiClient, &stageManager)
+	placeOrderStage := ui.CreatePlaceOrderScreen(writeApiClient, readApiClient, &stageManager, w)
 	tabStatusStage := ui.CreateTabStatusScreen(&stageManager)
 
 	stageManager.RegisterStager(mainContainerStage)
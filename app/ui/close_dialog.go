@@ -2,11 +2,10 @@ package ui
 
 import (
 	"cqrseventsourcingbar/app/apiclient"
+	"cqrseventsourcingbar/shared"
 	"cqrseventsourcingbar/writeservice/model"
 	"errors"
-	"fmt"
 	"log/slog"
-	"strconv"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/dialog"
@@ -15,21 +14,25 @@ import (
 
 func createCoseTabFormDialog(w fyne.Window, payingWithEntry *widget.Entry, invoiceScreen *invoiceScreen, writeApiClient *apiclient.WriteClient) *dialog.FormDialog {
 	formItems := []*widget.FormItem{}
-	formItems = append(formItems, widget.NewFormItem("Total", widget.NewLabel(fmt.Sprintf("%.2f", invoiceScreen.currentTotal))))
+	balanceDue := invoiceScreen.currentInvoiceData.BalanceDue
+	formItems = append(formItems, widget.NewFormItem("Total", widget.NewLabel(invoiceScreen.currentTotal.Decimal())))
+	formItems = append(formItems, widget.NewFormItem("Balance due", widget.NewLabel(balanceDue.Decimal())))
 
 	payingWithFormItem := widget.NewFormItem("Paying with", payingWithEntry)
 	payingWithFormItem.HintText = "Amount"
 
 	formItems = append(formItems, payingWithFormItem)
 	payingWithEntry.SetValidationError(errors.New("must set paying with"))
+	stationEntry := newStationEntry()
+	formItems = append(formItems, widget.NewFormItem("Station", stationEntry))
 
 	payingWithEntry.Validator = func(s string) error {
-		amount, err := strconv.ParseFloat(s, 64)
+		amount, err := shared.ParseMoney(s)
 		if err != nil {
 			return err
 		}
-		if amount < invoiceScreen.currentTotal {
-			return errors.New("need to pay with an amount higher than total")
+		if amount.LessThan(balanceDue) {
+			return errors.New("need to pay with an amount higher than the balance due")
 		}
 
 		return nil
@@ -37,20 +40,21 @@ func createCoseTabFormDialog(w fyne.Window, payingWithEntry *widget.Entry, invoi
 	closeTabDialog := dialog.NewForm("Close Tab", "Close", "Cancel", formItems, func(hitCloseButton bool) {
 		if hitCloseButton {
 
-			amount, err := strconv.ParseFloat(payingWithEntry.Text, 64)
+			amount, err := shared.ParseMoney(payingWithEntry.Text)
 			if err != nil {
 				slog.Error("error converting paying with before closing tab", slog.Any("error", err))
 			}
 			err = writeApiClient.ExecuteCommand(model.CloseTabRequest{
 				TabId:      invoiceScreen.currentInvoiceData.TabID,
 				AmountPaid: amount,
+				Station:    stationEntry.Text,
 			})
 			if err != nil {
 				slog.Error("error calling write api", slog.Any("error", err))
 			}
 			// If no error, we asume the close tab command worked and refresh the Tip field
-			invoiceScreen.currentTip = amount - invoiceScreen.currentTotal
-			invoiceScreen.tipLabel.Text = fmt.Sprintf("%.2f", invoiceScreen.currentTip)
+			invoiceScreen.currentTip = amount.Sub(balanceDue)
+			invoiceScreen.tipLabel.Text = invoiceScreen.currentTip.Decimal()
 			invoiceScreen.closeTabButton.Disable()
 			invoiceScreen.tipLabel.Refresh()
 			invoiceScreen.containerInCard.Refresh()
@@ -59,3 +63,10 @@ func createCoseTabFormDialog(w fyne.Window, payingWithEntry *widget.Entry, invoi
 
 	return closeTabDialog
 }
+
+// newStationEntry asks for the station the money is taken at, the shift open there gets the takings.
+func newStationEntry() *widget.Entry {
+	stationEntry := widget.NewEntry()
+	stationEntry.SetPlaceHolder("Station")
+	return stationEntry
+}
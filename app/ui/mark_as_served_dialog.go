@@ -29,33 +29,76 @@ func createMarkAsServedDialog(tableNumberAndTabId tableNumberAndTabId, w fyne.Wi
 	group.Required = true
 	group.SetSelected(options)
 
+	cancelCheck := widget.NewCheck("Cancel the selected drinks instead of serving them", func(bool) {})
+	reasonEntry := widget.NewEntry()
+	reasonEntry.SetPlaceHolder("Reason for cancelling")
+
 	item := widget.NewFormItem("", group)
-	formItems := []*widget.FormItem{item}
+	formItems := []*widget.FormItem{item, widget.NewFormItem("", cancelCheck), widget.NewFormItem("Reason", reasonEntry)}
 
 	return dialog.NewForm(fmt.Sprintf("Mark as served items for table %d", tableNumberAndTabId.tableNumber), "Confirm", "Cancel", formItems, func(confirm bool) {
 		if confirm {
 			checkGroup := formItems[0].Widget.(*widget.CheckGroup)
 			selectedOptions := checkGroup.Selected
-			selectedMenuNumbers := []int{}
+			selectedDrinks := []int{}
+			selectedFood := []int{}
 
 			for _, selectedOption := range selectedOptions {
 				metadata := metadataByOptions[selectedOption]
 				for i := 0; i < metadata.amount; i++ {
-					selectedMenuNumbers = append(selectedMenuNumbers, metadata.tabItem.MenuNumber)
+					if metadata.tabItem.IsDrink {
+						selectedDrinks = append(selectedDrinks, metadata.tabItem.MenuNumber)
+					} else {
+						selectedFood = append(selectedFood, metadata.tabItem.MenuNumber)
+					}
 				}
 			}
 
-			err := writeApiClient.ExecuteCommand(model.MarkDrinksServedRequest{
-				TabId:       tableNumberAndTabId.tabId,
-				MenuNumbers: selectedMenuNumbers,
-			})
+			if cancelCheck.Checked {
+				// Only drinks that were not served yet can be cancelled, food already left the kitchen.
+				err := writeApiClient.ExecuteCommand(model.CancelItemsRequest{
+					TabId:       tableNumberAndTabId.tabId,
+					MenuNumbers: selectedDrinks,
+					Reason:      reasonEntry.Text,
+				})
 
-			if err != nil {
-				slog.Error("error calling writeApi with MarkDrinksServedRequest", slog.Any("error", err))
+				if err != nil {
+					slog.Error("error calling writeApi with CancelItemsRequest", slog.Any("error", err))
+					return
+				}
+
+				err = stageManager.TakeOver(MainContentStage, nil)
+				if err != nil {
+					slog.Error("error launching main content screen", slog.Any("error", err))
+				}
 				return
 			}
 
-			err = stageManager.TakeOver(MainContentStage, nil)
+			if len(selectedDrinks) > 0 {
+				err := writeApiClient.ExecuteCommand(model.MarkDrinksServedRequest{
+					TabId:       tableNumberAndTabId.tabId,
+					MenuNumbers: selectedDrinks,
+				})
+
+				if err != nil {
+					slog.Error("error calling writeApi with MarkDrinksServedRequest", slog.Any("error", err))
+					return
+				}
+			}
+
+			if len(selectedFood) > 0 {
+				err := writeApiClient.ExecuteCommand(model.MarkFoodServedRequest{
+					TabId:       tableNumberAndTabId.tabId,
+					MenuNumbers: selectedFood,
+				})
+
+				if err != nil {
+					slog.Error("error calling writeApi with MarkFoodServedRequest", slog.Any("error", err))
+					return
+				}
+			}
+
+			err := stageManager.TakeOver(MainContentStage, nil)
 			if err != nil {
 				slog.Error("error launching main content screen", slog.Any("error", err))
 			}
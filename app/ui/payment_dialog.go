@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"cqrseventsourcingbar/app/apiclient"
+	"cqrseventsourcingbar/shared"
+	"cqrseventsourcingbar/writeservice/model"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	splitWholeBalance = "Whole balance"
+	splitEvenly       = "Split evenly"
+	splitByItems      = "By selected items"
+)
+
+// createRecordPaymentDialog records the share of the bill paid by one guest, suggesting an amount for an even split
+// between a number of guests or for the items the guest had.
+func createRecordPaymentDialog(w fyne.Window, invoiceScreen *invoiceScreen, writeApiClient *apiclient.WriteClient) *dialog.FormDialog {
+	balanceDue := invoiceScreen.currentInvoiceData.BalanceDue
+
+	amountEntry := widget.NewEntry()
+	amountEntry.SetText(balanceDue.Decimal())
+	amountEntry.Validator = func(s string) error {
+		amount, err := shared.ParseMoney(s)
+		if err != nil {
+			return err
+		}
+		if !amount.IsPositive() {
+			return errors.New("need to pay a positive amount")
+		}
+		return nil
+	}
+
+	guestsEntry := widget.NewEntry()
+	guestsEntry.SetText("2")
+
+	metadataByOptions := make(map[string]tabItemWithAmount)
+	var options []string
+	for _, tabItemWithAmount := range getTabItemsWithAmount(invoiceScreen.currentInvoiceData.Items) {
+		option := fmt.Sprintf("%d x %s - %s", tabItemWithAmount.amount, tabItemWithAmount.tabItem.Description, tabItemWithAmount.subTotal.Decimal())
+		options = append(options, option)
+		metadataByOptions[option] = tabItemWithAmount
+	}
+	itemsGroup := widget.NewCheckGroup(options, func([]string) {})
+
+	splitRadio := widget.NewRadioGroup([]string{splitWholeBalance, splitEvenly, splitByItems}, func(string) {})
+	splitRadio.SetSelected(splitWholeBalance)
+
+	suggestAmount := func() {
+		switch splitRadio.Selected {
+		case splitEvenly:
+			guests, err := strconv.Atoi(guestsEntry.Text)
+			if err != nil || guests < 1 {
+				return
+			}
+			amountEntry.SetText(balanceDue.Split(guests)[0].Decimal())
+		case splitByItems:
+			selectedAmount := shared.Cents(0)
+			for _, selectedOption := range itemsGroup.Selected {
+				selectedAmount = selectedAmount.Add(metadataByOptions[selectedOption].subTotal)
+			}
+			if balanceDue.LessThan(selectedAmount) {
+				selectedAmount = balanceDue
+			}
+			amountEntry.SetText(selectedAmount.Decimal())
+		default:
+			amountEntry.SetText(balanceDue.Decimal())
+		}
+	}
+	splitRadio.OnChanged = func(string) { suggestAmount() }
+	guestsEntry.OnChanged = func(string) { suggestAmount() }
+	itemsGroup.OnChanged = func([]string) { suggestAmount() }
+
+	methodSelect := widget.NewSelect([]string{"card", "cash"}, func(string) {})
+	methodSelect.SetSelected("card")
+	payerEntry := widget.NewEntry()
+	payerEntry.SetPlaceHolder("Guest name")
+	stationEntry := newStationEntry()
+
+	formItems := []*widget.FormItem{
+		widget.NewFormItem("Balance due", widget.NewLabel(balanceDue.Decimal())),
+		widget.NewFormItem("Split", splitRadio),
+		widget.NewFormItem("Guests", guestsEntry),
+		widget.NewFormItem("Items", itemsGroup),
+		widget.NewFormItem("Amount", amountEntry),
+		widget.NewFormItem("Method", methodSelect),
+		widget.NewFormItem("Payer", payerEntry),
+		widget.NewFormItem("Station", stationEntry),
+	}
+
+	return dialog.NewForm("Record Payment", "Record", "Cancel", formItems, func(confirm bool) {
+		if !confirm {
+			return
+		}
+		amount, err := shared.ParseMoney(amountEntry.Text)
+		if err != nil {
+			slog.Error("error converting the amount before recording a payment", slog.Any("error", err))
+			return
+		}
+		err = writeApiClient.ExecuteCommand(model.RecordPaymentRequest{
+			TabId:   invoiceScreen.currentInvoiceData.TabID,
+			Method:  methodSelect.Selected,
+			Amount:  amount,
+			Payer:   payerEntry.Text,
+			Station: stationEntry.Text,
+		})
+		if err != nil {
+			slog.Error("error calling writeApi with RecordPaymentRequest", slog.Any("error", err))
+			return
+		}
+		// Reload the invoice to show the new balance due
+		invoiceScreen.ExecuteOnTakeOver(invoiceScreen.table)
+	}, w)
+}
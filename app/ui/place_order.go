@@ -4,12 +4,16 @@ import (
 	"cqrseventsourcingbar/app/apiclient"
 	"cqrseventsourcingbar/shared"
 	"cqrseventsourcingbar/writeservice/model"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strconv"
+	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 )
 
@@ -25,6 +29,7 @@ type placeOrderScreen struct {
 	form                 *widget.Form
 	allMenuItems         []shared.MenuItem
 	tabId                *string
+	window               fyne.Window
 }
 
 func (p *placeOrderScreen) ExecuteOnTakeOver(param interface{}) {
@@ -47,7 +52,7 @@ func (p *placeOrderScreen) GetStageName() string {
 	return PlaceOrderStage
 }
 
-func CreatePlaceOrderScreen(writeApiClient *apiclient.WriteClient, readApiClient *apiclient.ReadClient, stageManager *StageManager) *placeOrderScreen {
+func CreatePlaceOrderScreen(writeApiClient *apiclient.WriteClient, readApiClient *apiclient.ReadClient, stageManager *StageManager, w fyne.Window) *placeOrderScreen {
 	container := container.NewStack()
 
 	allMenuItemsResponse, err := readApiClient.GetAllMenuItems()
@@ -64,7 +69,7 @@ func CreatePlaceOrderScreen(writeApiClient *apiclient.WriteClient, readApiClient
 	for _, menuItem := range allMenuItems {
 		selectWidget := widget.NewSelect([]string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9"}, func(s string) {})
 		selectWidget.SetSelected("0")
-		formItem := widget.NewFormItem(fmt.Sprintf("%s: %.2f", menuItem.Description, menuItem.Price), selectWidget)
+		formItem := widget.NewFormItem(menuItemLabel(menuItem), selectWidget)
 		menuFormItems = append(menuFormItems, formItem)
 	}
 
@@ -79,6 +84,7 @@ func CreatePlaceOrderScreen(writeApiClient *apiclient.WriteClient, readApiClient
 		stageManager:         stageManager,
 		form:                 form,
 		allMenuItems:         allMenuItems,
+		window:               w,
 	}
 
 	form.SubmitText = "OK"
@@ -104,26 +110,74 @@ func CreatePlaceOrderScreen(writeApiClient *apiclient.WriteClient, readApiClient
 			}
 
 			for j := 0; j < amount; j++ {
-				orderedItems = append(orderedItems, i+1)
+				orderedItems = append(orderedItems, placeOrderScreen.allMenuItems[i].ID)
 			}
 		}
 
-		err = writeApiClient.ExecuteCommand(model.PlaceOrderRequest{
-			TabId:     *placeOrderScreen.tabId,
-			MenuItems: orderedItems,
-		})
+		placeOrderScreen.placeOrder(orderedItems)
+	}
 
-		if err != nil {
-			slog.Error("client error calling writeapi", slog.Any("error", err))
-			return
+	container.Add(placeOrderScreenCard)
+	return placeOrderScreen
+}
+
+func (p *placeOrderScreen) placeOrder(orderedItems []int) {
+	err := p.writeApiClient.ExecuteCommand(model.PlaceOrderRequest{
+		TabId:          *p.tabId,
+		MenuItems:      orderedItems,
+		ExpectedPrices: p.expectedPrices(orderedItems),
+	})
+
+	var priceMismatch *shared.PriceMismatchError
+	if errors.As(err, &priceMismatch) {
+		p.confirmNewPrices(orderedItems, priceMismatch.Mismatches)
+		return
+	}
+
+	if err != nil {
+		slog.Error("client error calling writeapi", slog.Any("error", err))
+		return
+	}
+
+	err = p.stageManager.TakeOver(MainContentStage, nil)
+	if err != nil {
+		slog.Error("error opening main content screen", slog.Any("error", err))
+	}
+}
+
+// expectedPrices sends back the price and version shown for every ordered item, so the order is refused if they changed.
+func (p *placeOrderScreen) expectedPrices(orderedItems []int) []shared.ExpectedPrice {
+	expectedPrices := []shared.ExpectedPrice{}
+	for _, menuItem := range p.allMenuItems {
+		if slices.Contains(orderedItems, menuItem.ID) {
+			expectedPrices = append(expectedPrices, shared.ExpectedPrice{MenuNumber: menuItem.ID, Price: &menuItem.Price, Version: menuItem.Version})
 		}
+	}
+	return expectedPrices
+}
 
-		err := stageManager.TakeOver(MainContentStage, nil)
-		if err != nil {
-			slog.Error("error opening main content screen", slog.Any("error", err))
+// confirmNewPrices shows the current prices on the form and places the order again once the waiter confirms them.
+func (p *placeOrderScreen) confirmNewPrices(orderedItems []int, mismatches []shared.PriceMismatch) {
+	lines := []string{}
+	for _, mismatch := range mismatches {
+		index := slices.IndexFunc(p.allMenuItems, func(item shared.MenuItem) bool { return item.ID == mismatch.MenuNumber })
+		if index < 0 {
+			continue
 		}
+		p.allMenuItems[index].Price = mismatch.CurrentPrice
+		p.allMenuItems[index].Version = mismatch.CurrentVersion
+		p.form.Items[index].Text = menuItemLabel(p.allMenuItems[index])
+		lines = append(lines, fmt.Sprintf("%s: %s", mismatch.Description, mismatch.CurrentPrice.Decimal()))
 	}
+	p.form.Refresh()
 
-	container.Add(placeOrderScreenCard)
-	return placeOrderScreen
+	dialog.ShowConfirm("Prices changed", fmt.Sprintf("The prices are now:\n%s\nPlace the order anyway?", strings.Join(lines, "\n")), func(confirm bool) {
+		if confirm {
+			p.placeOrder(orderedItems)
+		}
+	}, p.window)
+}
+
+func menuItemLabel(menuItem shared.MenuItem) string {
+	return fmt.Sprintf("%s: %s", menuItem.Description, menuItem.Price.Decimal())
 }
@@ -3,9 +3,9 @@ package ui
 import (
 	"cqrseventsourcingbar/app/apiclient"
 	"cqrseventsourcingbar/queries"
+	"cqrseventsourcingbar/shared"
 	"fmt"
 	"log/slog"
-	"strconv"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -19,6 +19,8 @@ type invoiceScreen struct {
 	containerInCard       *fyne.Container
 	invoiceScreenCard     *widget.Card
 	totalLabel            *widget.Label
+	paymentsLabel         *widget.Label
+	balanceDueLabel       *widget.Label
 	hasUnservedItemsLabel *widget.Label
 	tipLabel              *widget.Label
 	itemsList             *widget.List
@@ -28,15 +30,16 @@ type invoiceScreen struct {
 	stageManager          *StageManager
 	tabItemsWithAmount    *[]tabItemWithAmount
 	closeTabButton        *widget.Button
+	recordPaymentButton   *widget.Button
 	payingWithEntry       *widget.Entry
-	currentTotal          float64
-	currentTip            float64
+	currentTotal          shared.Money
+	currentTip            shared.Money
 	currentInvoiceData    *queries.TabInvoice
 }
 
 func (i *invoiceScreen) ExecuteOnTakeOver(param interface{}) {
-	i.currentTotal = -1
-	i.currentTip = 0
+	i.currentTotal = shared.Money{}
+	i.currentTip = shared.Money{}
 	i.tipLabel.Text = ""
 	tableNumber := param.(int)
 	i.table = tableNumber
@@ -63,18 +66,37 @@ func (i *invoiceScreen) ExecuteOnTakeOver(param interface{}) {
 	i.containerInCard.Refresh()
 	i.container.Refresh()
 	i.invoiceScreenCard.SetTitle(fmt.Sprintf("Invoice for table %d", i.table))
-	i.totalLabel.Text = fmt.Sprintf("%.2f", invoice.Total)
-	i.currentTotal, err = strconv.ParseFloat(fmt.Sprintf("%.2f", invoice.Total), 64)
-	if err != nil {
-		slog.Error("could not convert current total to float", slog.Any("error", err))
-	}
+	i.totalLabel.Text = invoice.Total.Decimal()
+	i.currentTotal = invoice.Total
 
+	i.paymentsLabel.Text = paymentsText(invoice.Payments)
+	i.balanceDueLabel.Text = invoice.BalanceDue.Decimal()
 	i.hasUnservedItemsLabel.Text = fmt.Sprintf("%t", invoice.HasUnservedItems)
 	if invoice.HasUnservedItems {
 		i.closeTabButton.Disable()
 	} else {
 		i.closeTabButton.Enable()
 	}
+	if invoice.BalanceDue.IsPositive() {
+		i.recordPaymentButton.Enable()
+	} else {
+		i.recordPaymentButton.Disable()
+	}
+	i.containerInCard.Refresh()
+}
+
+func paymentsText(payments []queries.TabPayment) string {
+	if len(payments) == 0 {
+		return "-"
+	}
+	text := ""
+	for index, payment := range payments {
+		if index > 0 {
+			text += "\n"
+		}
+		text += fmt.Sprintf("%s %s %s", payment.Amount.Decimal(), payment.Method, payment.Payer)
+	}
+	return text
 }
 
 func (i *invoiceScreen) GetPaintedContainer() *fyne.Container {
@@ -90,6 +112,8 @@ func CreateInvoiceScreen(readApiClient *apiclient.ReadClient, writeApiClient *ap
 	tabItemsWithAmount := &[]tabItemWithAmount{}
 	itemsList := CreateTabItemList(tabItemsWithAmount)
 	totalLabel := widget.NewLabel("")
+	paymentsLabel := widget.NewLabel("")
+	balanceDueLabel := widget.NewLabel("")
 	hasUnservedItemsLabel := widget.NewLabel("")
 	payingWithEntry := widget.NewEntry()
 	payingWithEntry.Text = "0"
@@ -97,6 +121,8 @@ func CreateInvoiceScreen(readApiClient *apiclient.ReadClient, writeApiClient *ap
 	invoiceScreen := &invoiceScreen{
 		table:                 0,
 		totalLabel:            totalLabel,
+		paymentsLabel:         paymentsLabel,
+		balanceDueLabel:       balanceDueLabel,
 		tipLabel:              tipLabel,
 		hasUnservedItemsLabel: hasUnservedItemsLabel,
 		readApiClient:         readApiClient,
@@ -105,8 +131,8 @@ func CreateInvoiceScreen(readApiClient *apiclient.ReadClient, writeApiClient *ap
 		itemsList:             itemsList,
 		tabItemsWithAmount:    tabItemsWithAmount,
 		payingWithEntry:       payingWithEntry,
-		currentTotal:          -1,
-		currentTip:            0,
+		currentTotal:          shared.Money{},
+		currentTip:            shared.Money{},
 	}
 
 	closeTabButton := widget.NewButton("Close Tab", func() {
@@ -114,6 +140,11 @@ func CreateInvoiceScreen(readApiClient *apiclient.ReadClient, writeApiClient *ap
 		closeTabDialog.Show()
 	})
 
+	recordPaymentButton := widget.NewButton("Record Payment", func() {
+		recordPaymentDialog := createRecordPaymentDialog(w, invoiceScreen, writeApiClient)
+		recordPaymentDialog.Show()
+	})
+
 	containerInCard := container.NewBorder(nil, container.NewGridWithRows(1,
 		widget.NewButton("Back", func() {
 			err := stageManager.TakeOver(MainContentStage, nil)
@@ -121,11 +152,14 @@ func CreateInvoiceScreen(readApiClient *apiclient.ReadClient, writeApiClient *ap
 				slog.Error("error launching main content screen", slog.Any("error", err))
 			}
 		}),
+		recordPaymentButton,
 		closeTabButton),
 		nil, nil,
 		container.NewGridWithColumns(2,
 			widget.NewLabel("Items"), itemsList,
 			widget.NewLabel("Total"), totalLabel,
+			widget.NewLabel("Payments"), paymentsLabel,
+			widget.NewLabel("Balance due"), balanceDueLabel,
 			widget.NewLabel("Has UnservedItems"), hasUnservedItemsLabel,
 			widget.NewLabel("Tip"), tipLabel,
 		))
@@ -137,6 +171,7 @@ func CreateInvoiceScreen(readApiClient *apiclient.ReadClient, writeApiClient *ap
 	invoiceScreen.invoiceScreenCard = invoiceScreenCard
 	invoiceScreen.container = container
 	invoiceScreen.closeTabButton = closeTabButton
+	invoiceScreen.recordPaymentButton = recordPaymentButton
 
 	return invoiceScreen
 }
@@ -20,6 +20,6 @@ func CreateTabItemList(tabItemsWithAmount *[]tabItemWithAmount) *widget.List {
 		rightLabel := objects[1].(*widget.Label)
 		borderContainer.Refresh()
 		leftLabel.Text = fmt.Sprintf("%d x %s", tabItemWithAmount.amount, tabItemWithAmount.tabItem.Description)
-		rightLabel.Text = fmt.Sprintf("%.2f", tabItemWithAmount.subTotal)
+		rightLabel.Text = tabItemWithAmount.subTotal.Decimal()
 	})
 }
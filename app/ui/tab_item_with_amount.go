@@ -2,13 +2,14 @@ package ui
 
 import (
 	"cqrseventsourcingbar/queries"
+	"cqrseventsourcingbar/shared"
 	"slices"
 )
 
 type tabItemWithAmount struct {
 	tabItem  queries.TabItem
 	amount   int
-	subTotal float64
+	subTotal shared.Money
 }
 
 func getTabItemsWithAmount(tabItems []queries.TabItem) []tabItemWithAmount {
@@ -40,7 +41,7 @@ func getTabItemsWithAmount(tabItems []queries.TabItem) []tabItemWithAmount {
 		tabItemWithAmounts = append(tabItemWithAmounts, tabItemWithAmount{
 			amount:   amount,
 			tabItem:  tabItemsByMenuNumbers[menuNumber],
-			subTotal: float64(amount) * tabItemsByMenuNumbers[menuNumber].Price,
+			subTotal: tabItemsByMenuNumbers[menuNumber].Price.Times(amount),
 		})
 	}
 
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"context"
+	"cqrseventsourcingbar/events"
+	"fmt"
+)
+
+// CashDrawerDispatcher attributes the payments and tab closes to the shift open at their station.
+type CashDrawerDispatcher struct {
+	eventStore      events.EventStore
+	tabDispatcher   CommandDispatcher
+	shiftDispatcher CommandDispatcher
+	defaultStation  string
+}
+
+// CreateCashDrawerDispatcher takes the payments and closes that name no station at the defaultStation.
+func CreateCashDrawerDispatcher(eventStore events.EventStore, tabDispatcher CommandDispatcher, shiftDispatcher CommandDispatcher, defaultStation string) *CashDrawerDispatcher {
+	return &CashDrawerDispatcher{eventStore: eventStore, tabDispatcher: tabDispatcher, shiftDispatcher: shiftDispatcher, defaultStation: defaultStation}
+}
+
+func (d *CashDrawerDispatcher) DispatchCommand(ctx context.Context, command Command) error {
+	switch c := command.(type) {
+	case OpenShift, CloseShift:
+		return d.shiftDispatcher.DispatchCommand(ctx, command)
+	case RecordPayment:
+		return d.recordPayment(ctx, c)
+	case CloseTab:
+		return d.closeTab(ctx, c)
+	default:
+		return d.tabDispatcher.DispatchCommand(ctx, command)
+	}
+}
+
+func (d *CashDrawerDispatcher) recordPayment(ctx context.Context, c RecordPayment) error {
+	station, shift, err := d.shiftAt(ctx, c.Station)
+	if err != nil {
+		return fmt.Errorf("cannot record the payment, %w", err)
+	}
+	c.Station, c.Shift = station, shift
+	return d.tabDispatcher.DispatchCommand(ctx, c)
+}
+
+func (d *CashDrawerDispatcher) closeTab(ctx context.Context, c CloseTab) error {
+	station, shift, err := d.shiftAt(ctx, c.Station)
+	if err != nil {
+		return fmt.Errorf("cannot close the tab, %w", err)
+	}
+	c.Station, c.Shift = station, shift
+	return d.tabDispatcher.DispatchCommand(ctx, c)
+}
+
+// shiftAt returns the station and its open shift, the default station needs none until it has had one.
+func (d *CashDrawerDispatcher) shiftAt(ctx context.Context, station string) (string, int, error) {
+	if station == "" {
+		station = d.defaultStation
+	}
+	stationEvents, err := d.eventStore.LoadEvents(ctx, StationID(station))
+	if err != nil {
+		return "", 0, err
+	}
+	if len(stationEvents) == 0 && station == d.defaultStation {
+		return station, 0, nil
+	}
+	shift := 0
+	for _, event := range stationEvents {
+		switch e := event.(type) {
+		case events.ShiftOpened:
+			shift = e.Shift
+		case events.ShiftClosed:
+			shift = 0
+		}
+	}
+	if shift == 0 {
+		return "", 0, fmt.Errorf("%w at station %s", ErrNoShiftOpen, station)
+	}
+	return station, shift, nil
+}
@@ -0,0 +1,135 @@
+package commands_test
+
+import (
+	"context"
+	"cqrseventsourcingbar/commands"
+	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/shared"
+	"testing"
+
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CashDrawerDispatcherTestSuite struct {
+	suite.Suite
+	eventStore *events.InMemoryEventStore
+	dispatcher *commands.CashDrawerDispatcher
+	tabID      ksuid.KSUID
+	ctx        context.Context
+}
+
+func (suite *CashDrawerDispatcherTestSuite) SetupTest() {
+	suite.eventStore = events.CreateInMemoryEventStore()
+	tabDispatcher := commands.CreateCommandDispatcher(suite.eventStore, events.NoopEventEmitter{}, commands.TabAggregateFactory{})
+	shiftDispatcher := commands.CreateCommandDispatcher(suite.eventStore, events.NoopEventEmitter{}, commands.ShiftAggregateFactory{})
+	suite.dispatcher = commands.CreateCashDrawerDispatcher(suite.eventStore, tabDispatcher, shiftDispatcher, "bar")
+	suite.ctx = context.TODO()
+	suite.tabID = ksuid.New()
+	err := suite.dispatcher.DispatchCommand(suite.ctx, commands.OpenTab{BaseCommand: commands.BaseCommand{ID: suite.tabID}, TableNumber: 3, Waiter: "Charles"})
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *CashDrawerDispatcherTestSuite) lastTabEvent() events.Event {
+	tabEvents, err := suite.eventStore.LoadEvents(suite.ctx, suite.tabID)
+	assert.NoError(suite.T(), err)
+	return tabEvents[len(tabEvents)-1]
+}
+
+func (suite *CashDrawerDispatcherTestSuite) TestTabsPaidAtAStationGoToItsOpenShift() {
+	// Given
+	for _, command := range []commands.Command{
+		commands.OpenShift{BaseCommand: commands.BaseCommand{ID: commands.StationID("terrace")}, Station: "terrace", OpeningFloat: shared.Cents(10000)},
+		commands.CloseShift{BaseCommand: commands.BaseCommand{ID: commands.StationID("terrace")}, Station: "terrace", CountedCash: shared.Cents(10000)},
+		commands.OpenShift{BaseCommand: commands.BaseCommand{ID: commands.StationID("terrace")}, Station: "terrace", OpeningFloat: shared.Cents(10000)},
+	} {
+		assert.NoError(suite.T(), suite.dispatcher.DispatchCommand(suite.ctx, command))
+	}
+
+	// When
+	err := suite.dispatcher.DispatchCommand(suite.ctx, commands.CloseTab{BaseCommand: commands.BaseCommand{ID: suite.tabID}, AmountPaid: shared.Cents(500), Station: "terrace"})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	tabClosed := suite.lastTabEvent().(events.TabClosed)
+	assert.Equal(suite.T(), "terrace", tabClosed.Station)
+	assert.Equal(suite.T(), 2, tabClosed.Shift)
+}
+
+func (suite *CashDrawerDispatcherTestSuite) TestTabsCanNotBePaidAtAStationWithoutAnOpenShift() {
+	// When
+	err := suite.dispatcher.DispatchCommand(suite.ctx, commands.CloseTab{BaseCommand: commands.BaseCommand{ID: suite.tabID}, AmountPaid: shared.Cents(500), Station: "terrace"})
+
+	// Then
+	assert.ErrorIs(suite.T(), err, commands.ErrNoShiftOpen)
+	assert.EqualError(suite.T(), err, "cannot close the tab, no shift is open at station terrace")
+	assert.IsType(suite.T(), events.TabOpened{}, suite.lastTabEvent())
+}
+
+func (suite *CashDrawerDispatcherTestSuite) TestPaymentsTakenAtAStationGoToItsOpenShift() {
+	// Given
+	assert.NoError(suite.T(), suite.dispatcher.DispatchCommand(suite.ctx, commands.OpenShift{BaseCommand: commands.BaseCommand{ID: commands.StationID("bar")}, Station: "bar", OpeningFloat: shared.Cents(10000)}))
+
+	// When
+	err := suite.dispatcher.DispatchCommand(suite.ctx, commands.RecordPayment{BaseCommand: commands.BaseCommand{ID: suite.tabID}, Method: "card", Amount: shared.Cents(500), Payer: "Pete", Station: "bar"})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	paymentRecorded := suite.lastTabEvent().(events.PaymentRecorded)
+	assert.Equal(suite.T(), "bar", paymentRecorded.Station)
+	assert.Equal(suite.T(), 1, paymentRecorded.Shift)
+}
+
+func (suite *CashDrawerDispatcherTestSuite) TestPaymentsCanNotBeTakenAtAStationWithoutAnOpenShift() {
+	// When
+	err := suite.dispatcher.DispatchCommand(suite.ctx, commands.RecordPayment{BaseCommand: commands.BaseCommand{ID: suite.tabID}, Method: "cash", Amount: shared.Cents(500), Station: "terrace"})
+
+	// Then
+	assert.ErrorIs(suite.T(), err, commands.ErrNoShiftOpen)
+	assert.EqualError(suite.T(), err, "cannot record the payment, no shift is open at station terrace")
+	assert.IsType(suite.T(), events.TabOpened{}, suite.lastTabEvent())
+}
+
+func (suite *CashDrawerDispatcherTestSuite) TestTabsClosedWithoutAStationBelongToNoShiftUntilTheDefaultStationHasOne() {
+	// When
+	err := suite.dispatcher.DispatchCommand(suite.ctx, commands.CloseTab{BaseCommand: commands.BaseCommand{ID: suite.tabID}, AmountPaid: shared.Cents(500)})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	tabClosed := suite.lastTabEvent().(events.TabClosed)
+	assert.Equal(suite.T(), "bar", tabClosed.Station)
+	assert.Equal(suite.T(), 0, tabClosed.Shift)
+}
+
+func (suite *CashDrawerDispatcherTestSuite) TestPaymentsWithoutAStationGoToTheShiftOpenAtTheDefaultStation() {
+	// Given
+	assert.NoError(suite.T(), suite.dispatcher.DispatchCommand(suite.ctx, commands.OpenShift{BaseCommand: commands.BaseCommand{ID: commands.StationID("bar")}, Station: "bar", OpeningFloat: shared.Cents(10000)}))
+
+	// When
+	err := suite.dispatcher.DispatchCommand(suite.ctx, commands.RecordPayment{BaseCommand: commands.BaseCommand{ID: suite.tabID}, Method: "cash", Amount: shared.Cents(500)})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	paymentRecorded := suite.lastTabEvent().(events.PaymentRecorded)
+	assert.Equal(suite.T(), "bar", paymentRecorded.Station)
+	assert.Equal(suite.T(), 1, paymentRecorded.Shift)
+}
+
+func (suite *CashDrawerDispatcherTestSuite) TestTabsCanNotBeClosedWithoutAStationOnceTheDefaultStationWorksInShifts() {
+	// Given
+	assert.NoError(suite.T(), suite.dispatcher.DispatchCommand(suite.ctx, commands.OpenShift{BaseCommand: commands.BaseCommand{ID: commands.StationID("bar")}, Station: "bar", OpeningFloat: shared.Cents(10000)}))
+	assert.NoError(suite.T(), suite.dispatcher.DispatchCommand(suite.ctx, commands.CloseShift{BaseCommand: commands.BaseCommand{ID: commands.StationID("bar")}, Station: "bar", CountedCash: shared.Cents(10000)}))
+
+	// When
+	err := suite.dispatcher.DispatchCommand(suite.ctx, commands.CloseTab{BaseCommand: commands.BaseCommand{ID: suite.tabID}, AmountPaid: shared.Cents(500)})
+
+	// Then
+	assert.ErrorIs(suite.T(), err, commands.ErrNoShiftOpen)
+	assert.EqualError(suite.T(), err, "cannot close the tab, no shift is open at station bar")
+	assert.IsType(suite.T(), events.TabOpened{}, suite.lastTabEvent())
+}
+
+func TestCashDrawerDispatcherTestSuite(t *testing.T) {
+	suite.Run(t, new(CashDrawerDispatcherTestSuite))
+}
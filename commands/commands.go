@@ -34,7 +34,194 @@ type MarkDrinksServed struct {
 	MenuNumbers []int
 }
 
+type MarkFoodPrepared struct {
+	BaseCommand
+	MenuNumbers []int
+}
+
+type MarkFoodServed struct {
+	BaseCommand
+	MenuNumbers []int
+}
+
+type CancelItems struct {
+	BaseCommand
+	MenuNumbers []int
+	Reason      string
+}
+
+// ApplyDiscount takes either a Percentage or a fixed Amount off the whole tab.
+type ApplyDiscount struct {
+	BaseCommand
+	Percentage   float64
+	Amount       shared.Money
+	Reason       string
+	AuthorisedBy string
+}
+
+type CompItem struct {
+	BaseCommand
+	MenuNumber   int
+	Reason       string
+	AuthorisedBy string
+}
+
+// RecordPayment records part of the bill paid by one of the guests, a tab can take many payments before it is closed.
+// RecordPayment records a payment towards a tab. When it is taken at a Station, the cash drawer dispatcher fills in
+// the Shift open there.
+type RecordPayment struct {
+	BaseCommand
+	Method  string
+	Amount  shared.Money
+	Payer   string
+	Station string
+	Shift   int
+}
+
+// CloseTab closes a tab once the recorded payments plus AmountPaid cover what is due. When the tab is paid at a
+// Station, the cash drawer dispatcher fills in the Shift open there.
 type CloseTab struct {
 	BaseCommand
-	AmountPaid float64
+	AmountPaid shared.Money
+	Station    string
+	Shift      int
+}
+
+type ClaimTable struct {
+	BaseCommand
+	TableNumber int
+	TabID       ksuid.KSUID
+}
+
+type ReleaseTable struct {
+	BaseCommand
+	TableNumber int
+	TabID       ksuid.KSUID
+}
+
+type AddMenuItem struct {
+	BaseCommand
+	MenuNumber  int
+	Description string
+	Price       shared.Money
+	IsDrink     bool
+	Category    string
+}
+
+type ChangeMenuItemPrice struct {
+	BaseCommand
+	MenuNumber int
+	Price      shared.Money
+}
+
+type RenameMenuItem struct {
+	BaseCommand
+	MenuNumber  int
+	Description string
+}
+
+type CategoriseMenuItem struct {
+	BaseCommand
+	MenuNumber int
+	Category   string
+}
+
+type SetMenuItemRecipe struct {
+	BaseCommand
+	MenuNumber int
+	Recipe     []shared.RecipeIngredient
+}
+
+type RetireMenuItem struct {
+	BaseCommand
+	MenuNumber int
+}
+
+type Restock struct {
+	BaseCommand
+	MenuNumber int
+	Quantity   int
+}
+
+// CountStock records the stock on hand found by counting it, whatever the events said there should be.
+type CountStock struct {
+	BaseCommand
+	MenuNumber int
+	Quantity   int
+}
+
+type SetReorderLevel struct {
+	BaseCommand
+	MenuNumber int
+	Level      int
+}
+
+// ReserveStock, ReleaseStock and ConsumeStock follow the drinks on a tab. TabPosition is the position of the tab event
+// in the event store, it makes handling the same tab event twice harmless.
+type ReserveStock struct {
+	BaseCommand
+	MenuNumber        int
+	Quantity          int
+	TabID             ksuid.KSUID
+	TabSequenceNumber int
+	TabPosition       int64
+}
+
+type ReleaseStock struct {
+	BaseCommand
+	MenuNumber        int
+	Quantity          int
+	TabID             ksuid.KSUID
+	TabSequenceNumber int
+	TabPosition       int64
+}
+
+type ConsumeStock struct {
+	BaseCommand
+	MenuNumber        int
+	Quantity          int
+	TabID             ksuid.KSUID
+	TabSequenceNumber int
+	TabPosition       int64
+}
+
+// RestockIngredient adds Quantity of an ingredient, in the unit its recipes use. ContainerSize is how much comes in
+// one bottle or keg, zero keeps the size given before.
+type RestockIngredient struct {
+	BaseCommand
+	Ingredient    string
+	Quantity      int
+	Unit          string
+	ContainerSize int
+}
+
+type CountIngredient struct {
+	BaseCommand
+	Ingredient string
+	Quantity   int
+}
+
+type ConsumeIngredient struct {
+	BaseCommand
+	Ingredient        string
+	Quantity          int
+	MenuNumbers       []int
+	TabID             ksuid.KSUID
+	TabSequenceNumber int
+	TabPosition       int64
+}
+
+type OpenShift struct {
+	BaseCommand
+	Station      string
+	OpeningFloat shared.Money
+}
+
+// CloseShift ends the shift open at a station with the cash counted in the drawer, float included, and what each
+// waiter who cashed up separately handed in.
+type CloseShift struct {
+	BaseCommand
+	Station      string
+	CountedCash  shared.Money
+	WaiterCounts map[string]shared.Money
 }
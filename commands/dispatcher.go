@@ -3,8 +3,13 @@ package commands
 import (
 	"context"
 	"cqrseventsourcingbar/events"
+	"errors"
 	"fmt"
+	"log/slog"
 	"reflect"
+	"time"
+
+	"github.com/segmentio/ksuid"
 )
 
 //go:generate mockery --name CommandDispatcher
@@ -16,26 +21,69 @@ type Dispatcher struct {
 	eventStore       events.EventStore
 	eventEmitter     events.EventEmitter
 	aggregateFactory AggregateFactory
+	snapshotStore    events.SnapshotStore
+	snapshotEvery    int
+	conflictRetries  int
+	now              func() time.Time
 }
 
-func CreateCommandDispatcher(eventStore events.EventStore, eventEmitter events.EventEmitter, aggregateFactory AggregateFactory) *Dispatcher {
-	return &Dispatcher{eventStore: eventStore, eventEmitter: eventEmitter, aggregateFactory: aggregateFactory}
+const defaultConflictRetries = 3
+
+// defaultActor names who acts on the aggregate when a command doesn't, the waiter of a tab for instance.
+type defaultActor interface {
+	DefaultActor() string
 }
 
-func (d *Dispatcher) DispatchCommand(ctx context.Context, command Command) error {
-	aggregate := d.aggregateFactory.CreateAggregate()
+type DispatcherOption func(d *Dispatcher)
 
-	eventsLoaded, err := d.eventStore.LoadEvents(ctx, command.GetID())
+// WithSnapshots stores a snapshot of the aggregate every snapshotEvery events.
+func WithSnapshots(snapshotStore events.SnapshotStore, snapshotEvery int) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.snapshotStore = snapshotStore
+		d.snapshotEvery = snapshotEvery
+	}
+}
 
-	if err != nil {
-		return fmt.Errorf("error loading events for aggregate: %s, reason: %w", command.GetID().String(), err)
+func WithClock(now func() time.Time) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.now = now
 	}
+}
 
-	for i, event := range eventsLoaded {
-		err = aggregate.ApplyEvent(event)
-		if err != nil {
-			return fmt.Errorf("error applying past event [%s-#%d] for aggregate: %s, reason: %w", events.GetEventTypeAsString(event), i, command.GetID().String(), err)
+// WithConflictRetries sets how many times a command is retried after a concurrency conflict.
+func WithConflictRetries(conflictRetries int) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.conflictRetries = conflictRetries
+	}
+}
+
+func CreateCommandDispatcher(eventStore events.EventStore, eventEmitter events.EventEmitter, aggregateFactory AggregateFactory, options ...DispatcherOption) *Dispatcher {
+	d := &Dispatcher{eventStore: eventStore, eventEmitter: eventEmitter, aggregateFactory: aggregateFactory, conflictRetries: defaultConflictRetries, now: time.Now}
+	for _, option := range options {
+		option(d)
+	}
+	return d
+}
+
+func (d *Dispatcher) DispatchCommand(ctx context.Context, command Command) error {
+	// Every dispatched command gets an ID of its own, which the events it causes keep as their causation ID.
+	metadata := events.MetadataFromContext(ctx)
+	metadata.CausationID = ksuid.New()
+	for attempt := 0; ; attempt++ {
+		err := d.dispatchCommand(ctx, command, metadata)
+		if !errors.Is(err, events.ErrConcurrencyConflict) || attempt >= d.conflictRetries {
+			return err
 		}
+		slog.Info("retrying command after concurrency conflict", slog.String("aggregate", command.GetID().String()), slog.Int("attempt", attempt+1))
+	}
+}
+
+func (d *Dispatcher) dispatchCommand(ctx context.Context, command Command, metadata events.Metadata) error {
+	aggregate := d.aggregateFactory.CreateAggregate()
+
+	previousEventCount, err := loadAggregate(ctx, d.eventStore, d.snapshotStore, aggregate, command.GetID())
+	if err != nil {
+		return err
 	}
 
 	newEvents, err := aggregate.HandleCommand(command)
@@ -44,12 +92,24 @@ func (d *Dispatcher) DispatchCommand(ctx context.Context, command Command) error
 		return fmt.Errorf("error handling command [%s] for aggregate: %s, reason: %w", reflect.TypeOf(command).Name(), command.GetID().String(), err)
 	}
 
-	err = d.eventStore.SaveEvents(ctx, command.GetID(), len(eventsLoaded), newEvents)
+	if len(newEvents) == 0 {
+		return nil
+	}
+
+	if actor, ok := aggregate.(defaultActor); ok && metadata.Actor == "" {
+		metadata.Actor = actor.DefaultActor()
+	}
+	// Postgres keeps timestamps to the microsecond, dropping the rest keeps the events the same once loaded again.
+	metadata.OccurredAt = d.now().UTC().Truncate(time.Microsecond)
+	newEvents = numberEvents(newEvents, previousEventCount, metadata)
+	err = d.eventStore.SaveEvents(ctx, command.GetID(), previousEventCount, newEvents)
 
 	if err != nil {
 		return fmt.Errorf("error when saving events for aggregate: %s, reason: %w", command.GetID(), err)
 	}
 
+	d.takeSnapshotIfDue(ctx, aggregate, command.GetID(), previousEventCount, newEvents)
+
 	for _, event := range newEvents {
 		err = d.eventEmitter.EmitEvent(event)
 		if err != nil {
@@ -59,3 +119,89 @@ func (d *Dispatcher) DispatchCommand(ctx context.Context, command Command) error
 
 	return nil
 }
+
+func numberEvents(newEvents []events.Event, previousEventCount int, metadata events.Metadata) []events.Event {
+	numbered := make([]events.Event, 0, len(newEvents))
+	for i, event := range newEvents {
+		event = events.WithMetadata(events.WithSequenceNumber(event, previousEventCount+i+1), metadata)
+		numbered = append(numbered, events.WithEventID(event, ksuid.New()))
+	}
+	return numbered
+}
+
+// loadAggregate returns the number of events the aggregate is made of.
+func loadAggregate(ctx context.Context, eventStore events.EventStore, snapshotStore events.SnapshotStore, aggregate Aggregate, aggregateID ksuid.KSUID) (int, error) {
+	version, err := restoreSnapshot(ctx, snapshotStore, aggregate, aggregateID)
+	if err != nil {
+		return 0, fmt.Errorf("error loading snapshot for aggregate: %s, reason: %w", aggregateID.String(), err)
+	}
+
+	var eventsLoaded []events.Event
+	if version > 0 {
+		eventsLoaded, err = eventStore.LoadEventsAfter(ctx, aggregateID, version)
+	} else {
+		eventsLoaded, err = eventStore.LoadEvents(ctx, aggregateID)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error loading events for aggregate: %s, reason: %w", aggregateID.String(), err)
+	}
+
+	for i, event := range eventsLoaded {
+		err = aggregate.ApplyEvent(event)
+		if err != nil {
+			return 0, fmt.Errorf("error applying past event [%s-#%d] for aggregate: %s, reason: %w", events.GetEventTypeAsString(event), version+i, aggregateID.String(), err)
+		}
+	}
+	return version + len(eventsLoaded), nil
+}
+
+func restoreSnapshot(ctx context.Context, snapshotStore events.SnapshotStore, aggregate Aggregate, aggregateID ksuid.KSUID) (int, error) {
+	if snapshotStore == nil {
+		return 0, nil
+	}
+
+	snapshot, err := snapshotStore.LoadSnapshot(ctx, aggregateID)
+	if err != nil {
+		return 0, err
+	}
+	if snapshot == nil {
+		return 0, nil
+	}
+
+	err = aggregate.RestoreSnapshot(snapshot.State)
+	if err != nil {
+		slog.Warn("ignoring snapshot, replaying all events", slog.String("aggregate", aggregateID.String()), slog.String("error", err.Error()))
+		return 0, nil
+	}
+
+	return snapshot.Version, nil
+}
+
+func (d *Dispatcher) takeSnapshotIfDue(ctx context.Context, aggregate Aggregate, aggregateID ksuid.KSUID, previousEventCount int, newEvents []events.Event) {
+	if d.snapshotStore == nil || d.snapshotEvery <= 0 {
+		return
+	}
+
+	version := previousEventCount + len(newEvents)
+	if version/d.snapshotEvery == previousEventCount/d.snapshotEvery {
+		return
+	}
+
+	for _, event := range newEvents {
+		if err := aggregate.ApplyEvent(event); err != nil {
+			slog.Warn("could not take snapshot", slog.String("aggregate", aggregateID.String()), slog.String("error", err.Error()))
+			return
+		}
+	}
+
+	state, err := aggregate.Snapshot()
+	if err != nil {
+		slog.Warn("could not take snapshot", slog.String("aggregate", aggregateID.String()), slog.String("error", err.Error()))
+		return
+	}
+
+	err = d.snapshotStore.SaveSnapshot(ctx, events.Snapshot{AggregateID: aggregateID, Version: version, State: state})
+	if err != nil {
+		slog.Warn("could not save snapshot", slog.String("aggregate", aggregateID.String()), slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,60 @@
+package commands_test
+
+import (
+	"context"
+	"cqrseventsourcingbar/commands"
+	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/queries"
+	"cqrseventsourcingbar/shared"
+	"testing"
+
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDispatcherWithInMemoryEventStoreUpdatesOpenTabs(t *testing.T) {
+	// Given
+	ctx := context.TODO()
+	eventStore := events.CreateInMemoryEventStore()
+	openTabs := queries.CreateOpenTabs()
+	eventStore.Subscribe(openTabs)
+	dispatcher := commands.CreateCommandDispatcher(eventStore, events.NoopEventEmitter{}, commands.TabAggregateFactory{})
+	tabId := ksuid.New()
+	water := shared.MenuItem{ID: 1, Description: "Water", Price: shared.Cents(150), IsDrink: true}
+
+	// When
+	assert.NoError(t, dispatcher.DispatchCommand(ctx, commands.OpenTab{BaseCommand: commands.BaseCommand{ID: tabId}, TableNumber: 3, Waiter: "Charles"}))
+	assert.NoError(t, dispatcher.DispatchCommand(ctx, commands.PlaceOrder{BaseCommand: commands.BaseCommand{ID: tabId}, Items: []shared.MenuItem{water, water}}))
+	assert.NoError(t, dispatcher.DispatchCommand(ctx, commands.MarkDrinksServed{BaseCommand: commands.BaseCommand{ID: tabId}, MenuNumbers: []int{1}}))
+
+	// Then
+	tabStatus, err := openTabs.TabForTable(3)
+	assert.NoError(t, err)
+	assert.Equal(t, tabId.String(), tabStatus.TabID)
+	assert.Equal(t, []queries.TabItem{{MenuNumber: 1, Description: "Water", Price: shared.Cents(150), IsDrink: true}}, tabStatus.ToServe)
+	assert.Equal(t, []queries.TabItem{{MenuNumber: 1, Description: "Water", Price: shared.Cents(150), IsDrink: true}}, tabStatus.Served)
+
+	storedEvents, err := eventStore.LoadEvents(ctx, tabId)
+	assert.NoError(t, err)
+	assert.Len(t, storedEvents, 3)
+}
+
+func TestDispatcherSavesTabEventsWithTheWaiterAsActorWhenTheRequestNamesNone(t *testing.T) {
+	// Given
+	eventStore := events.CreateInMemoryEventStore()
+	dispatcher := commands.CreateCommandDispatcher(eventStore, events.NoopEventEmitter{}, commands.TabAggregateFactory{})
+	tabId := ksuid.New()
+	assert.NoError(t, dispatcher.DispatchCommand(context.TODO(), commands.OpenTab{BaseCommand: commands.BaseCommand{ID: tabId}, TableNumber: 3, Waiter: "Charles"}))
+
+	// When
+	assert.NoError(t, dispatcher.DispatchCommand(context.TODO(), commands.PlaceOrder{BaseCommand: commands.BaseCommand{ID: tabId}, Items: []shared.MenuItem{water}}))
+	managerCtx := events.ContextWithMetadata(context.TODO(), events.Metadata{Actor: "manager"})
+	assert.NoError(t, dispatcher.DispatchCommand(managerCtx, commands.CancelItems{BaseCommand: commands.BaseCommand{ID: tabId}, MenuNumbers: []int{1}, Reason: "spilled"}))
+
+	// Then
+	storedEvents, err := eventStore.LoadEvents(context.TODO(), tabId)
+	assert.NoError(t, err)
+	assert.Len(t, storedEvents, 3)
+	assert.Equal(t, "Charles", storedEvents[1].GetMetadata().Actor)
+	assert.Equal(t, "manager", storedEvents[2].GetMetadata().Actor)
+}
@@ -9,9 +9,11 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/segmentio/ksuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -21,6 +23,7 @@ type DispatcherTestSuite struct {
 	eventEmitter *mock_events.EventEmitter
 	eventStore   *mock_events.EventStore
 	aggregate    *mock_commands.Aggregate
+	factory      *mock_commands.AggregateFactory
 	ctx          context.Context
 }
 
@@ -34,6 +37,7 @@ func (suite *DispatcherTestSuite) SetupTest() {
 	suite.eventEmitter = eventEmitter
 	suite.eventStore = eventStore
 	suite.aggregate = aggregate
+	suite.factory = aggregateFactory
 	suite.ctx = context.TODO()
 }
 
@@ -98,7 +102,7 @@ func (suite *DispatcherTestSuite) TestDispatcherReturnsErrorWhenFailingToSaveEve
 	suite.eventStore.On("LoadEvents", suite.ctx, aggregateId).Return([]events.Event{events.BaseEvent{ID: aggregateId}}, nil)
 	suite.aggregate.On("ApplyEvent", events.BaseEvent{ID: aggregateId}).Return(nil)
 	suite.aggregate.On("HandleCommand", commands.BaseCommand{ID: aggregateId}).Return([]events.Event{events.BaseEvent{ID: aggregateId}}, nil)
-	suite.eventStore.On("SaveEvents", suite.ctx, aggregateId, 1, []events.Event{events.BaseEvent{ID: aggregateId}}).Return(errors.New("all broken"))
+	suite.eventStore.On("SaveEvents", suite.ctx, aggregateId, 1, stampedEvents(aggregateId, 2)).Return(errors.New("all broken"))
 
 	// When
 	err := suite.dispatcher.DispatchCommand(
@@ -118,8 +122,8 @@ func (suite *DispatcherTestSuite) TestDispatcherReturnsErrorWhenFailingToEmitEve
 	suite.eventStore.On("LoadEvents", suite.ctx, aggregateId).Return([]events.Event{events.BaseEvent{ID: aggregateId}}, nil)
 	suite.aggregate.On("ApplyEvent", events.BaseEvent{ID: aggregateId}).Return(nil)
 	suite.aggregate.On("HandleCommand", commands.BaseCommand{ID: aggregateId}).Return([]events.Event{events.BaseEvent{ID: aggregateId}}, nil)
-	suite.eventStore.On("SaveEvents", suite.ctx, aggregateId, 1, []events.Event{events.BaseEvent{ID: aggregateId}}).Return(nil)
-	suite.eventEmitter.On("EmitEvent", events.BaseEvent{ID: aggregateId}).Return(errors.New("all broken"))
+	suite.eventStore.On("SaveEvents", suite.ctx, aggregateId, 1, stampedEvents(aggregateId, 2)).Return(nil)
+	suite.eventEmitter.On("EmitEvent", stampedEvent(aggregateId, 2)).Return(errors.New("all broken"))
 
 	// When
 	err := suite.dispatcher.DispatchCommand(
@@ -139,8 +143,8 @@ func (suite *DispatcherTestSuite) TestDispatcher() {
 	suite.eventStore.On("LoadEvents", suite.ctx, aggregateId).Return([]events.Event{events.BaseEvent{ID: aggregateId}}, nil)
 	suite.aggregate.On("ApplyEvent", events.BaseEvent{ID: aggregateId}).Return(nil)
 	suite.aggregate.On("HandleCommand", commands.BaseCommand{ID: aggregateId}).Return([]events.Event{events.BaseEvent{ID: aggregateId}}, nil)
-	suite.eventStore.On("SaveEvents", suite.ctx, aggregateId, 1, []events.Event{events.BaseEvent{ID: aggregateId}}).Return(nil)
-	suite.eventEmitter.On("EmitEvent", events.BaseEvent{ID: aggregateId}).Return(nil)
+	suite.eventStore.On("SaveEvents", suite.ctx, aggregateId, 1, stampedEvents(aggregateId, 2)).Return(nil)
+	suite.eventEmitter.On("EmitEvent", stampedEvent(aggregateId, 2)).Return(nil)
 
 	// When
 	err := suite.dispatcher.DispatchCommand(
@@ -152,6 +156,236 @@ func (suite *DispatcherTestSuite) TestDispatcher() {
 	assert.NoError(suite.T(), err)
 }
 
+func (suite *DispatcherTestSuite) TestDispatcherSavesEventsWithTheirMetadata() {
+	// Given
+	aggregateId := ksuid.New()
+	occurredAt := time.Date(2024, 3, 1, 1, 13, 0, 0, time.UTC)
+	dispatcher := commands.CreateCommandDispatcher(suite.eventStore, suite.eventEmitter, suite.factory, commands.WithClock(func() time.Time { return occurredAt }))
+	ctx := events.ContextWithMetadata(suite.ctx, events.Metadata{CorrelationID: "request-1", Actor: "Charles"})
+	var savedEvents []events.Event
+	suite.eventStore.On("LoadEvents", ctx, aggregateId).Return([]events.Event{}, nil)
+	suite.aggregate.On("HandleCommand", commands.BaseCommand{ID: aggregateId}).Return([]events.Event{events.BaseEvent{ID: aggregateId}, events.BaseEvent{ID: aggregateId}}, nil)
+	suite.eventStore.On("SaveEvents", ctx, aggregateId, 0, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		savedEvents = args.Get(3).([]events.Event)
+	})
+	suite.eventEmitter.On("EmitEvent", mock.Anything).Return(nil)
+
+	// When
+	err := dispatcher.DispatchCommand(ctx, commands.BaseCommand{ID: aggregateId})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), savedEvents, 2) {
+		metadata := savedEvents[0].GetMetadata()
+		assert.Equal(suite.T(), occurredAt, metadata.OccurredAt)
+		assert.NotEqual(suite.T(), ksuid.Nil, metadata.CausationID)
+		assert.Equal(suite.T(), "request-1", metadata.CorrelationID)
+		assert.Equal(suite.T(), "Charles", metadata.Actor)
+		assert.Equal(suite.T(), metadata, savedEvents[1].GetMetadata())
+	}
+}
+
+func (suite *DispatcherTestSuite) TestDispatcherReplaysOnlyEventsAfterSnapshot() {
+	// Given
+	aggregateId := ksuid.New()
+	snapshotStore := mock_events.NewSnapshotStore(suite.T())
+	dispatcher := commands.CreateCommandDispatcher(suite.eventStore, suite.eventEmitter, suite.factory, commands.WithSnapshots(snapshotStore, 10))
+	snapshotStore.On("LoadSnapshot", suite.ctx, aggregateId).Return(&events.Snapshot{AggregateID: aggregateId, Version: 5, State: []byte("{}")}, nil)
+	suite.aggregate.On("RestoreSnapshot", []byte("{}")).Return(nil)
+	suite.eventStore.On("LoadEventsAfter", suite.ctx, aggregateId, 5).Return([]events.Event{events.BaseEvent{ID: aggregateId}}, nil)
+	suite.aggregate.On("ApplyEvent", events.BaseEvent{ID: aggregateId}).Return(nil)
+	suite.aggregate.On("HandleCommand", commands.BaseCommand{ID: aggregateId}).Return([]events.Event{events.BaseEvent{ID: aggregateId}}, nil)
+	suite.eventStore.On("SaveEvents", suite.ctx, aggregateId, 6, stampedEvents(aggregateId, 7)).Return(nil)
+	suite.eventEmitter.On("EmitEvent", stampedEvent(aggregateId, 7)).Return(nil)
+
+	// When
+	err := dispatcher.DispatchCommand(
+		context.TODO(),
+		commands.BaseCommand{aggregateId},
+	)
+
+	// Then
+	assert.NoError(suite.T(), err)
+	suite.eventStore.AssertNotCalled(suite.T(), "LoadEvents", suite.ctx, aggregateId)
+	snapshotStore.AssertNotCalled(suite.T(), "SaveSnapshot", mock.Anything, mock.Anything)
+}
+
+func (suite *DispatcherTestSuite) TestDispatcherTakesSnapshotWhenCrossingInterval() {
+	// Given
+	aggregateId := ksuid.New()
+	snapshotStore := mock_events.NewSnapshotStore(suite.T())
+	dispatcher := commands.CreateCommandDispatcher(suite.eventStore, suite.eventEmitter, suite.factory, commands.WithSnapshots(snapshotStore, 2))
+	snapshotStore.On("LoadSnapshot", suite.ctx, aggregateId).Return(nil, nil)
+	suite.eventStore.On("LoadEvents", suite.ctx, aggregateId).Return([]events.Event{events.BaseEvent{ID: aggregateId}}, nil)
+	suite.aggregate.On("ApplyEvent", events.BaseEvent{ID: aggregateId}).Return(nil)
+	suite.aggregate.On("HandleCommand", commands.BaseCommand{ID: aggregateId}).Return([]events.Event{events.BaseEvent{ID: aggregateId}}, nil)
+	suite.eventStore.On("SaveEvents", suite.ctx, aggregateId, 1, stampedEvents(aggregateId, 2)).Return(nil)
+	suite.aggregate.On("ApplyEvent", stampedEvent(aggregateId, 2)).Return(nil)
+	suite.aggregate.On("Snapshot").Return([]byte("{}"), nil)
+	snapshotStore.On("SaveSnapshot", suite.ctx, events.Snapshot{AggregateID: aggregateId, Version: 2, State: []byte("{}")}).Return(nil)
+	suite.eventEmitter.On("EmitEvent", stampedEvent(aggregateId, 2)).Return(nil)
+
+	// When
+	err := dispatcher.DispatchCommand(
+		context.TODO(),
+		commands.BaseCommand{aggregateId},
+	)
+
+	// Then
+	assert.NoError(suite.T(), err)
+	suite.aggregate.AssertNumberOfCalls(suite.T(), "ApplyEvent", 2)
+}
+
+func (suite *DispatcherTestSuite) TestDispatcherIgnoresSnapshotThatCannotBeRestored() {
+	// Given
+	aggregateId := ksuid.New()
+	snapshotStore := mock_events.NewSnapshotStore(suite.T())
+	dispatcher := commands.CreateCommandDispatcher(suite.eventStore, suite.eventEmitter, suite.factory, commands.WithSnapshots(snapshotStore, 10))
+	snapshotStore.On("LoadSnapshot", suite.ctx, aggregateId).Return(&events.Snapshot{AggregateID: aggregateId, Version: 5, State: []byte("broken")}, nil)
+	suite.aggregate.On("RestoreSnapshot", []byte("broken")).Return(errors.New("all broken"))
+	suite.eventStore.On("LoadEvents", suite.ctx, aggregateId).Return([]events.Event{events.BaseEvent{ID: aggregateId}}, nil)
+	suite.aggregate.On("ApplyEvent", events.BaseEvent{ID: aggregateId}).Return(nil)
+	suite.aggregate.On("HandleCommand", commands.BaseCommand{ID: aggregateId}).Return([]events.Event{events.BaseEvent{ID: aggregateId}}, nil)
+	suite.eventStore.On("SaveEvents", suite.ctx, aggregateId, 1, stampedEvents(aggregateId, 2)).Return(nil)
+	suite.eventEmitter.On("EmitEvent", stampedEvent(aggregateId, 2)).Return(nil)
+
+	// When
+	err := dispatcher.DispatchCommand(
+		context.TODO(),
+		commands.BaseCommand{aggregateId},
+	)
+
+	// Then
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *DispatcherTestSuite) TestDispatcherReturnsErrorWhenFailingToLoadSnapshot() {
+	// Given
+	aggregateId := ksuid.New()
+	snapshotStore := mock_events.NewSnapshotStore(suite.T())
+	dispatcher := commands.CreateCommandDispatcher(suite.eventStore, suite.eventEmitter, suite.factory, commands.WithSnapshots(snapshotStore, 10))
+	snapshotStore.On("LoadSnapshot", suite.ctx, aggregateId).Return(nil, errors.New("all broken"))
+
+	// When
+	err := dispatcher.DispatchCommand(
+		context.TODO(),
+		commands.BaseCommand{aggregateId},
+	)
+
+	// Then
+	if assert.Error(suite.T(), err) {
+		assert.Equal(suite.T(), fmt.Sprintf("error loading snapshot for aggregate: %s, reason: all broken", aggregateId), err.Error())
+	}
+}
+
+func (suite *DispatcherTestSuite) TestDispatcherGivesEachNewEventItsOwnID() {
+	// Given
+	aggregateId := ksuid.New()
+	var savedEvents []events.Event
+	suite.eventStore.On("LoadEvents", suite.ctx, aggregateId).Return([]events.Event{}, nil)
+	suite.aggregate.On("HandleCommand", commands.BaseCommand{ID: aggregateId}).Return([]events.Event{events.BaseEvent{ID: aggregateId}, events.BaseEvent{ID: aggregateId}}, nil)
+	suite.eventStore.On("SaveEvents", suite.ctx, aggregateId, 0, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		savedEvents = args.Get(3).([]events.Event)
+	})
+	suite.eventEmitter.On("EmitEvent", mock.Anything).Return(nil)
+
+	// When
+	err := suite.dispatcher.DispatchCommand(
+		context.TODO(),
+		commands.BaseCommand{aggregateId},
+	)
+
+	// Then
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), savedEvents, 2) {
+		assert.Equal(suite.T(), 1, savedEvents[0].GetSequenceNumber())
+		assert.Equal(suite.T(), 2, savedEvents[1].GetSequenceNumber())
+		assert.NotEqual(suite.T(), ksuid.Nil, savedEvents[0].GetEventID())
+		assert.NotEqual(suite.T(), savedEvents[0].GetEventID(), savedEvents[1].GetEventID())
+	}
+}
+
+func (suite *DispatcherTestSuite) TestDispatcherRetriesCommandAfterConcurrencyConflict() {
+	// Given
+	aggregateId := ksuid.New()
+	firstEvent := events.BaseEvent{ID: aggregateId, SequenceNumber: 1}
+	concurrentEvent := events.BaseEvent{ID: aggregateId, SequenceNumber: 2}
+	suite.eventStore.On("LoadEvents", suite.ctx, aggregateId).Return([]events.Event{firstEvent}, nil).Once()
+	suite.eventStore.On("LoadEvents", suite.ctx, aggregateId).Return([]events.Event{firstEvent, concurrentEvent}, nil).Once()
+	suite.aggregate.On("ApplyEvent", firstEvent).Return(nil)
+	suite.aggregate.On("ApplyEvent", concurrentEvent).Return(nil)
+	suite.aggregate.On("HandleCommand", commands.BaseCommand{ID: aggregateId}).Return([]events.Event{events.BaseEvent{ID: aggregateId}}, nil)
+	suite.eventStore.On("SaveEvents", suite.ctx, aggregateId, 1, stampedEvents(aggregateId, 2)).Return(fmt.Errorf("%w: lost the race", events.ErrConcurrencyConflict))
+	suite.eventStore.On("SaveEvents", suite.ctx, aggregateId, 2, stampedEvents(aggregateId, 3)).Return(nil)
+	suite.eventEmitter.On("EmitEvent", stampedEvent(aggregateId, 3)).Return(nil)
+
+	// When
+	err := suite.dispatcher.DispatchCommand(
+		context.TODO(),
+		commands.BaseCommand{aggregateId},
+	)
+
+	// Then
+	assert.NoError(suite.T(), err)
+	suite.aggregate.AssertNumberOfCalls(suite.T(), "HandleCommand", 2)
+}
+
+func (suite *DispatcherTestSuite) TestDispatcherReturnsConflictWhenRetriesAreExhausted() {
+	// Given
+	dispatcher := commands.CreateCommandDispatcher(suite.eventStore, suite.eventEmitter, suite.factory, commands.WithConflictRetries(1))
+	aggregateId := ksuid.New()
+	suite.eventStore.On("LoadEvents", suite.ctx, aggregateId).Return([]events.Event{}, nil)
+	suite.aggregate.On("HandleCommand", commands.BaseCommand{ID: aggregateId}).Return([]events.Event{events.BaseEvent{ID: aggregateId}}, nil)
+	suite.eventStore.On("SaveEvents", suite.ctx, aggregateId, 0, stampedEvents(aggregateId, 1)).Return(events.ErrConcurrencyConflict)
+
+	// When
+	err := dispatcher.DispatchCommand(
+		context.TODO(),
+		commands.BaseCommand{aggregateId},
+	)
+
+	// Then
+	assert.ErrorIs(suite.T(), err, events.ErrConcurrencyConflict)
+	suite.eventStore.AssertNumberOfCalls(suite.T(), "SaveEvents", 2)
+}
+
+func stampedEvent(aggregateId ksuid.KSUID, sequenceNumber int) interface{} {
+	return mock.MatchedBy(func(event events.Event) bool {
+		return isStamped(event, aggregateId, sequenceNumber)
+	})
+}
+
+func stampedEvents(aggregateId ksuid.KSUID, sequenceNumber int) interface{} {
+	return mock.MatchedBy(func(savedEvents []events.Event) bool {
+		return len(savedEvents) == 1 && isStamped(savedEvents[0], aggregateId, sequenceNumber)
+	})
+}
+
+func isStamped(event events.Event, aggregateId ksuid.KSUID, sequenceNumber int) bool {
+	return event.GetID() == aggregateId && event.GetSequenceNumber() == sequenceNumber && event.GetEventID() != ksuid.Nil
+}
+
 func TestDispatcherTestSuite(t *testing.T) {
 	suite.Run(t, new(DispatcherTestSuite))
 }
+
+func TestEventsOfUnknownTypesAreSkippedButKeepTheirPlaceInTheStream(t *testing.T) {
+	// Given
+	ctx := context.TODO()
+	eventStore := events.CreateInMemoryEventStore()
+	tabDispatcher := commands.CreateCommandDispatcher(eventStore, events.NoopEventEmitter{}, commands.TabAggregateFactory{})
+	tabId := ksuid.New()
+	assert.NoError(t, tabDispatcher.DispatchCommand(ctx, commands.OpenTab{BaseCommand: commands.BaseCommand{ID: tabId}, TableNumber: 3, Waiter: "Charles"}))
+	assert.NoError(t, eventStore.SaveEvents(ctx, tabId, 1, []events.Event{events.UnknownEvent{BaseEvent: events.BaseEvent{ID: tabId}, EventType: "TabRenamed", SchemaVersion: 1, Payload: []byte(`{}`)}}))
+
+	// When
+	err := tabDispatcher.DispatchCommand(ctx, commands.CloseTab{BaseCommand: commands.BaseCommand{ID: tabId}})
+
+	// Then
+	assert.NoError(t, err)
+	tabEvents, err := eventStore.LoadEvents(ctx, tabId)
+	assert.NoError(t, err)
+	assert.Len(t, tabEvents, 3)
+	assert.IsType(t, events.TabClosed{}, tabEvents[2])
+	assert.Equal(t, 3, tabEvents[2].GetSequenceNumber())
+}
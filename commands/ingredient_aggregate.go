@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"cqrseventsourcingbar/events"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/ksuid"
+)
+
+// ingredientAggregate is the stock of one ingredient, a spirit, mixer or garnish that recipes use. Like menu items,
+// ingredients are only tracked once they have been restocked or counted.
+type ingredientAggregate struct {
+	tracked         bool
+	onHand          int
+	unit            string
+	containerSize   int
+	lastTabPosition int64
+}
+
+type ingredientSnapshot struct {
+	Tracked         bool   `json:"tracked"`
+	OnHand          int    `json:"on_hand"`
+	Unit            string `json:"unit"`
+	ContainerSize   int    `json:"container_size"`
+	LastTabPosition int64  `json:"last_tab_position"`
+}
+
+var ingredientIDTimestamp = time.Unix(1400000003, 0)
+
+// IngredientID returns the aggregate ID of the stock of an ingredient, the same for every call with the same name.
+func IngredientID(ingredient string) ksuid.KSUID {
+	hash := sha256.Sum256([]byte(ingredient))
+	payload := make([]byte, 16)
+	copy(payload, "ingr")
+	copy(payload[4:], hash[:12])
+	id, _ := ksuid.FromParts(ingredientIDTimestamp, payload)
+	return id
+}
+
+func (i ingredientAggregate) HandleCommand(c Command) ([]events.Event, error) {
+	switch command := c.(type) {
+	case RestockIngredient:
+		return i.handleCommandRestockIngredient(command)
+	case CountIngredient:
+		return i.handleCommandCountIngredient(command)
+	case ConsumeIngredient:
+		return i.handleCommandConsumeIngredient(command)
+	default:
+		return nil, fmt.Errorf("unexpected Command: %#v", c)
+	}
+}
+
+func (i *ingredientAggregate) ApplyEvent(e events.Event) error {
+	switch event := e.(type) {
+	case events.IngredientRestocked:
+		i.tracked = true
+		i.onHand += event.Quantity
+		i.unit = event.Unit
+		i.containerSize = event.ContainerSize
+	case events.IngredientCounted:
+		i.tracked = true
+		i.onHand = event.Quantity
+	case events.IngredientConsumed:
+		i.onHand -= event.Quantity
+		i.lastTabPosition = max(i.lastTabPosition, event.TabPosition)
+	case events.UnknownEvent:
+		return nil
+	default:
+		return fmt.Errorf("unexpected events.Event: %#v", e)
+	}
+	return nil
+}
+
+func (i *ingredientAggregate) Snapshot() ([]byte, error) {
+	return json.Marshal(ingredientSnapshot{Tracked: i.tracked, OnHand: i.onHand, Unit: i.unit, ContainerSize: i.containerSize, LastTabPosition: i.lastTabPosition})
+}
+
+func (i *ingredientAggregate) RestoreSnapshot(state []byte) error {
+	var snapshot ingredientSnapshot
+	if err := json.Unmarshal(state, &snapshot); err != nil {
+		return fmt.Errorf("could not restore ingredient aggregate from snapshot: %s", state)
+	}
+	i.tracked = snapshot.Tracked
+	i.onHand = snapshot.OnHand
+	i.unit = snapshot.Unit
+	i.containerSize = snapshot.ContainerSize
+	i.lastTabPosition = snapshot.LastTabPosition
+	return nil
+}
+
+// handleCommandRestockIngredient keeps the unit and container size of earlier deliveries unless new ones are given.
+func (i ingredientAggregate) handleCommandRestockIngredient(c RestockIngredient) ([]events.Event, error) {
+	if c.Ingredient == "" {
+		return nil, errors.New("an ingredient needs a name")
+	}
+	if c.Quantity <= 0 {
+		return nil, fmt.Errorf("the quantity restocked must be positive, got: %d", c.Quantity)
+	}
+	if c.ContainerSize < 0 {
+		return nil, fmt.Errorf("the container size can't be negative, got: %d", c.ContainerSize)
+	}
+	unit := c.Unit
+	if unit == "" {
+		unit = i.unit
+	}
+	containerSize := c.ContainerSize
+	if containerSize == 0 {
+		containerSize = i.containerSize
+	}
+	return []events.Event{events.IngredientRestocked{BaseEvent: events.BaseEvent{ID: c.ID}, Ingredient: c.Ingredient, Quantity: c.Quantity, Unit: unit, ContainerSize: containerSize}}, nil
+}
+
+func (i ingredientAggregate) handleCommandCountIngredient(c CountIngredient) ([]events.Event, error) {
+	if c.Ingredient == "" {
+		return nil, errors.New("an ingredient needs a name")
+	}
+	if c.Quantity < 0 {
+		return nil, fmt.Errorf("the quantity counted can't be negative, got: %d", c.Quantity)
+	}
+	return []events.Event{events.IngredientCounted{BaseEvent: events.BaseEvent{ID: c.ID}, Ingredient: c.Ingredient, Quantity: c.Quantity, Difference: c.Quantity - i.onHand}}, nil
+}
+
+func (i ingredientAggregate) handleCommandConsumeIngredient(c ConsumeIngredient) ([]events.Event, error) {
+	if !i.tracked || c.TabPosition <= i.lastTabPosition {
+		return nil, nil
+	}
+	return []events.Event{events.IngredientConsumed{BaseEvent: events.BaseEvent{ID: c.ID}, Ingredient: c.Ingredient, Quantity: c.Quantity, MenuNumbers: c.MenuNumbers, TabID: c.TabID, TabSequenceNumber: c.TabSequenceNumber, TabPosition: c.TabPosition}}, nil
+}
+
+type IngredientAggregateFactory struct {
+}
+
+func (i IngredientAggregateFactory) CreateAggregate() Aggregate {
+	return &ingredientAggregate{}
+}
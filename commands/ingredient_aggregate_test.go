@@ -0,0 +1,82 @@
+package commands_test
+
+import (
+	"cqrseventsourcingbar/commands"
+	"cqrseventsourcingbar/events"
+	"testing"
+
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type IngredientAggregateTestSuite struct {
+	suite.Suite
+	ingredientAggregate commands.Aggregate
+	ingredientID        ksuid.KSUID
+}
+
+func (suite *IngredientAggregateTestSuite) SetupTest() {
+	suite.ingredientAggregate = commands.IngredientAggregateFactory{}.CreateAggregate()
+	suite.ingredientID = commands.IngredientID("gin")
+}
+
+func (suite *IngredientAggregateTestSuite) TestIngredientIDIsStablePerName() {
+	assert.Equal(suite.T(), commands.IngredientID("gin"), commands.IngredientID("gin"))
+	assert.NotEqual(suite.T(), commands.IngredientID("gin"), commands.IngredientID("rum"))
+}
+
+func (suite *IngredientAggregateTestSuite) TestRestockingKeepsTheContainerSizeOfEarlierDeliveries() {
+	// Given
+	assert.NoError(suite.T(), suite.ingredientAggregate.ApplyEvent(events.IngredientRestocked{BaseEvent: events.BaseEvent{ID: suite.ingredientID}, Ingredient: "gin", Quantity: 1400, Unit: "ml", ContainerSize: 700}))
+
+	// When
+	newEvents, err := suite.ingredientAggregate.HandleCommand(commands.RestockIngredient{BaseCommand: commands.BaseCommand{ID: suite.ingredientID}, Ingredient: "gin", Quantity: 700})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []events.Event{events.IngredientRestocked{BaseEvent: events.BaseEvent{ID: suite.ingredientID}, Ingredient: "gin", Quantity: 700, Unit: "ml", ContainerSize: 700}}, newEvents)
+}
+
+func (suite *IngredientAggregateTestSuite) TestCountingRecordsTheDifference() {
+	// Given
+	assert.NoError(suite.T(), suite.ingredientAggregate.ApplyEvent(events.IngredientRestocked{BaseEvent: events.BaseEvent{ID: suite.ingredientID}, Ingredient: "gin", Quantity: 1400, Unit: "ml", ContainerSize: 700}))
+
+	// When
+	newEvents, err := suite.ingredientAggregate.HandleCommand(commands.CountIngredient{BaseCommand: commands.BaseCommand{ID: suite.ingredientID}, Ingredient: "gin", Quantity: 1250})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []events.Event{events.IngredientCounted{BaseEvent: events.BaseEvent{ID: suite.ingredientID}, Ingredient: "gin", Quantity: 1250, Difference: -150}}, newEvents)
+}
+
+func (suite *IngredientAggregateTestSuite) TestTabEventsAreOnlyConsumedOnce() {
+	// Given
+	tabId := ksuid.New()
+	consume := commands.ConsumeIngredient{BaseCommand: commands.BaseCommand{ID: suite.ingredientID}, Ingredient: "gin", Quantity: 50, MenuNumbers: []int{6}, TabID: tabId, TabSequenceNumber: 3, TabPosition: 13}
+	assert.NoError(suite.T(), suite.ingredientAggregate.ApplyEvent(events.IngredientRestocked{BaseEvent: events.BaseEvent{ID: suite.ingredientID}, Ingredient: "gin", Quantity: 1400, Unit: "ml", ContainerSize: 700}))
+	newEvents, err := suite.ingredientAggregate.HandleCommand(consume)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), newEvents, 1)
+	assert.NoError(suite.T(), suite.ingredientAggregate.ApplyEvent(newEvents[0]))
+
+	// When
+	newEvents, err = suite.ingredientAggregate.HandleCommand(consume)
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), newEvents)
+}
+
+func (suite *IngredientAggregateTestSuite) TestUntrackedIngredientsRecordNothing() {
+	// When
+	newEvents, err := suite.ingredientAggregate.HandleCommand(commands.ConsumeIngredient{BaseCommand: commands.BaseCommand{ID: suite.ingredientID}, Ingredient: "gin", Quantity: 50, MenuNumbers: []int{6}, TabID: ksuid.New(), TabSequenceNumber: 3, TabPosition: 13})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), newEvents)
+}
+
+func TestIngredientAggregateTestSuite(t *testing.T) {
+	suite.Run(t, new(IngredientAggregateTestSuite))
+}
@@ -0,0 +1,23 @@
+package commands
+
+import "context"
+
+// InventoryDispatcher sends the ingredient commands to the ingredient dispatcher and the other stock commands to the
+// stock dispatcher, so the inventory can be kept through a single CommandDispatcher.
+type InventoryDispatcher struct {
+	stockDispatcher      CommandDispatcher
+	ingredientDispatcher CommandDispatcher
+}
+
+func CreateInventoryDispatcher(stockDispatcher CommandDispatcher, ingredientDispatcher CommandDispatcher) *InventoryDispatcher {
+	return &InventoryDispatcher{stockDispatcher: stockDispatcher, ingredientDispatcher: ingredientDispatcher}
+}
+
+func (d *InventoryDispatcher) DispatchCommand(ctx context.Context, command Command) error {
+	switch command.(type) {
+	case RestockIngredient, CountIngredient, ConsumeIngredient:
+		return d.ingredientDispatcher.DispatchCommand(ctx, command)
+	default:
+		return d.stockDispatcher.DispatchCommand(ctx, command)
+	}
+}
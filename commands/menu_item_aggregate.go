@@ -0,0 +1,205 @@
+package commands
+
+import (
+	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/shared"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/segmentio/ksuid"
+)
+
+// menuItemAggregate is one item of the menu, from the moment it is added until it is retired.
+type menuItemAggregate struct {
+	added   bool
+	retired bool
+	item    shared.MenuItem
+}
+
+type menuItemSnapshot struct {
+	Added   bool            `json:"added"`
+	Retired bool            `json:"retired"`
+	Item    shared.MenuItem `json:"item"`
+}
+
+var (
+	ErrMenuItemNotFound      = errors.New("menu item not found")
+	ErrMenuItemAlreadyExists = errors.New("menu item already exists")
+	ErrMenuItemRetired       = errors.New("is retired")
+)
+
+var menuItemIDTimestamp = time.Unix(1400000001, 0)
+
+// MenuItemID returns the aggregate ID of a menu item, the same for every call with the same menu number.
+func MenuItemID(menuNumber int) ksuid.KSUID {
+	payload := make([]byte, 16)
+	copy(payload, "menu")
+	binary.BigEndian.PutUint64(payload[8:], uint64(menuNumber))
+	id, _ := ksuid.FromParts(menuItemIDTimestamp, payload)
+	return id
+}
+
+func (m menuItemAggregate) HandleCommand(c Command) ([]events.Event, error) {
+	switch command := c.(type) {
+	case AddMenuItem:
+		return m.handleCommandAddMenuItem(command)
+	case ChangeMenuItemPrice:
+		return m.handleCommandChangeMenuItemPrice(command)
+	case RenameMenuItem:
+		return m.handleCommandRenameMenuItem(command)
+	case CategoriseMenuItem:
+		return m.handleCommandCategoriseMenuItem(command)
+	case SetMenuItemRecipe:
+		return m.handleCommandSetMenuItemRecipe(command)
+	case RetireMenuItem:
+		return m.handleCommandRetireMenuItem(command)
+	default:
+		return nil, fmt.Errorf("unexpected Command: %#v", c)
+	}
+}
+
+func (m *menuItemAggregate) ApplyEvent(e events.Event) error {
+	switch event := e.(type) {
+	case events.MenuItemAdded:
+		m.added = true
+		m.item = shared.MenuItem{ID: event.MenuNumber, Description: event.Description, Price: event.Price, IsDrink: event.IsDrink, Category: event.Category}
+	case events.MenuItemPriceChanged:
+		m.item.Price = event.Price
+	case events.MenuItemRenamed:
+		m.item.Description = event.Description
+	case events.MenuItemCategorised:
+		m.item.Category = event.Category
+	case events.MenuItemRecipeSet:
+		m.item.Recipe = event.Recipe
+	case events.MenuItemRetired:
+		m.retired = true
+	case events.UnknownEvent:
+		return nil
+	default:
+		return fmt.Errorf("unexpected events.Event: %#v", e)
+	}
+	m.item.Version = e.GetSequenceNumber()
+	return nil
+}
+
+func (m *menuItemAggregate) Snapshot() ([]byte, error) {
+	return json.Marshal(menuItemSnapshot{Added: m.added, Retired: m.retired, Item: m.item})
+}
+
+func (m *menuItemAggregate) RestoreSnapshot(state []byte) error {
+	var snapshot menuItemSnapshot
+	if err := json.Unmarshal(state, &snapshot); err != nil {
+		return fmt.Errorf("could not restore menu item aggregate from snapshot: %s", state)
+	}
+	m.added = snapshot.Added
+	m.retired = snapshot.Retired
+	m.item = snapshot.Item
+	return nil
+}
+
+func (m menuItemAggregate) handleCommandAddMenuItem(c AddMenuItem) ([]events.Event, error) {
+	if m.added {
+		return nil, fmt.Errorf("%w: %d", ErrMenuItemAlreadyExists, c.MenuNumber)
+	}
+	if c.Description == "" {
+		return nil, errors.New("a menu item needs a description")
+	}
+	if !c.Price.IsPositive() {
+		return nil, fmt.Errorf("the price of a menu item must be positive, got: %s", c.Price)
+	}
+	return []events.Event{events.MenuItemAdded{BaseEvent: events.BaseEvent{ID: c.ID}, MenuNumber: c.MenuNumber, Description: c.Description, Price: c.Price, IsDrink: c.IsDrink, Category: c.Category}}, nil
+}
+
+func (m menuItemAggregate) handleCommandChangeMenuItemPrice(c ChangeMenuItemPrice) ([]events.Event, error) {
+	if err := m.mustBeOnTheMenu(c.MenuNumber); err != nil {
+		return nil, err
+	}
+	if !c.Price.IsPositive() {
+		return nil, fmt.Errorf("the price of a menu item must be positive, got: %s", c.Price)
+	}
+	if c.Price.Currency != m.item.Price.Currency {
+		return nil, fmt.Errorf("menu item %d is priced in %s, not %s", c.MenuNumber, m.item.Price.Currency, c.Price.Currency)
+	}
+	if c.Price == m.item.Price {
+		return nil, nil
+	}
+	return []events.Event{events.MenuItemPriceChanged{BaseEvent: events.BaseEvent{ID: c.ID}, MenuNumber: c.MenuNumber, Price: c.Price, PreviousPrice: m.item.Price}}, nil
+}
+
+func (m menuItemAggregate) handleCommandRenameMenuItem(c RenameMenuItem) ([]events.Event, error) {
+	if err := m.mustBeOnTheMenu(c.MenuNumber); err != nil {
+		return nil, err
+	}
+	if c.Description == "" {
+		return nil, errors.New("a menu item needs a description")
+	}
+	if c.Description == m.item.Description {
+		return nil, nil
+	}
+	return []events.Event{events.MenuItemRenamed{BaseEvent: events.BaseEvent{ID: c.ID}, MenuNumber: c.MenuNumber, Description: c.Description}}, nil
+}
+
+func (m menuItemAggregate) handleCommandCategoriseMenuItem(c CategoriseMenuItem) ([]events.Event, error) {
+	if err := m.mustBeOnTheMenu(c.MenuNumber); err != nil {
+		return nil, err
+	}
+	if c.Category == m.item.Category {
+		return nil, nil
+	}
+	return []events.Event{events.MenuItemCategorised{BaseEvent: events.BaseEvent{ID: c.ID}, MenuNumber: c.MenuNumber, Category: c.Category}}, nil
+}
+
+func (m menuItemAggregate) handleCommandSetMenuItemRecipe(c SetMenuItemRecipe) ([]events.Event, error) {
+	if err := m.mustBeOnTheMenu(c.MenuNumber); err != nil {
+		return nil, err
+	}
+	ingredients := make(map[string]bool)
+	for _, ingredient := range c.Recipe {
+		if ingredient.Ingredient == "" {
+			return nil, errors.New("a recipe ingredient needs a name")
+		}
+		if ingredient.Quantity <= 0 {
+			return nil, fmt.Errorf("the quantity of %s in a recipe must be positive, got: %d", ingredient.Ingredient, ingredient.Quantity)
+		}
+		if ingredients[ingredient.Ingredient] {
+			return nil, fmt.Errorf("%s is listed twice in the recipe", ingredient.Ingredient)
+		}
+		ingredients[ingredient.Ingredient] = true
+	}
+	if slices.Equal(c.Recipe, m.item.Recipe) {
+		return nil, nil
+	}
+	return []events.Event{events.MenuItemRecipeSet{BaseEvent: events.BaseEvent{ID: c.ID}, MenuNumber: c.MenuNumber, Recipe: c.Recipe}}, nil
+}
+
+// handleCommandRetireMenuItem does nothing for an item that is already retired, so retiring twice is harmless.
+func (m menuItemAggregate) handleCommandRetireMenuItem(c RetireMenuItem) ([]events.Event, error) {
+	if !m.added {
+		return nil, fmt.Errorf("%w: %d", ErrMenuItemNotFound, c.MenuNumber)
+	}
+	if m.retired {
+		return nil, nil
+	}
+	return []events.Event{events.MenuItemRetired{BaseEvent: events.BaseEvent{ID: c.ID}, MenuNumber: c.MenuNumber}}, nil
+}
+
+func (m menuItemAggregate) mustBeOnTheMenu(menuNumber int) error {
+	if !m.added {
+		return fmt.Errorf("%w: %d", ErrMenuItemNotFound, menuNumber)
+	}
+	if m.retired {
+		return fmt.Errorf("menu item %d %w", menuNumber, ErrMenuItemRetired)
+	}
+	return nil
+}
+
+type MenuItemAggregateFactory struct {
+}
+
+func (m MenuItemAggregateFactory) CreateAggregate() Aggregate {
+	return &menuItemAggregate{}
+}
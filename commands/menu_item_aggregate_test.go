@@ -0,0 +1,189 @@
+package commands_test
+
+import (
+	"cqrseventsourcingbar/commands"
+	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/shared"
+	"testing"
+
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type MenuItemAggregateTestSuite struct {
+	suite.Suite
+	menuItemAggregate commands.Aggregate
+	menuItemID        ksuid.KSUID
+}
+
+func (suite *MenuItemAggregateTestSuite) SetupTest() {
+	suite.menuItemAggregate = commands.MenuItemAggregateFactory{}.CreateAggregate()
+	suite.menuItemID = commands.MenuItemID(6)
+}
+
+func (suite *MenuItemAggregateTestSuite) addPie() {
+	err := suite.menuItemAggregate.ApplyEvent(events.MenuItemAdded{BaseEvent: events.BaseEvent{ID: suite.menuItemID}, MenuNumber: 6, Description: "pie", Price: shared.Cents(450), Category: "mains"})
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *MenuItemAggregateTestSuite) TestMenuItemIDIsStablePerMenuNumber() {
+	assert.Equal(suite.T(), commands.MenuItemID(6), commands.MenuItemID(6))
+	assert.NotEqual(suite.T(), commands.MenuItemID(6), commands.MenuItemID(7))
+	assert.NotEqual(suite.T(), commands.TableID(6), commands.MenuItemID(6))
+}
+
+func (suite *MenuItemAggregateTestSuite) TestCanAddAMenuItem() {
+	// When
+	newEvents, err := suite.menuItemAggregate.HandleCommand(commands.AddMenuItem{BaseCommand: commands.BaseCommand{ID: suite.menuItemID}, MenuNumber: 6, Description: "pie", Price: shared.Cents(450), Category: "mains"})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []events.Event{events.MenuItemAdded{BaseEvent: events.BaseEvent{ID: suite.menuItemID}, MenuNumber: 6, Description: "pie", Price: shared.Cents(450), Category: "mains"}}, newEvents)
+}
+
+func (suite *MenuItemAggregateTestSuite) TestCanNotAddAMenuItemTwice() {
+	// Given
+	suite.addPie()
+
+	// When
+	newEvents, err := suite.menuItemAggregate.HandleCommand(commands.AddMenuItem{BaseCommand: commands.BaseCommand{ID: suite.menuItemID}, MenuNumber: 6, Description: "pie", Price: shared.Cents(450)})
+
+	// Then
+	assert.Empty(suite.T(), newEvents)
+	assert.ErrorIs(suite.T(), err, commands.ErrMenuItemAlreadyExists)
+}
+
+func (suite *MenuItemAggregateTestSuite) TestMenuItemsNeedAPositivePrice() {
+	// When
+	newEvents, err := suite.menuItemAggregate.HandleCommand(commands.AddMenuItem{BaseCommand: commands.BaseCommand{ID: suite.menuItemID}, MenuNumber: 6, Description: "pie", Price: shared.Cents(0)})
+
+	// Then
+	assert.Empty(suite.T(), newEvents)
+	assert.EqualError(suite.T(), err, "the price of a menu item must be positive, got: 0.00 EUR")
+}
+
+func (suite *MenuItemAggregateTestSuite) TestChangingThePriceKeepsThePreviousOne() {
+	// Given
+	suite.addPie()
+
+	// When
+	newEvents, err := suite.menuItemAggregate.HandleCommand(commands.ChangeMenuItemPrice{BaseCommand: commands.BaseCommand{ID: suite.menuItemID}, MenuNumber: 6, Price: shared.Cents(500)})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []events.Event{events.MenuItemPriceChanged{BaseEvent: events.BaseEvent{ID: suite.menuItemID}, MenuNumber: 6, Price: shared.Cents(500), PreviousPrice: shared.Cents(450)}}, newEvents)
+}
+
+func (suite *MenuItemAggregateTestSuite) TestChangesToTheSameValueDoNothing() {
+	// Given
+	suite.addPie()
+
+	// When
+	priceEvents, priceErr := suite.menuItemAggregate.HandleCommand(commands.ChangeMenuItemPrice{BaseCommand: commands.BaseCommand{ID: suite.menuItemID}, MenuNumber: 6, Price: shared.Cents(450)})
+	renameEvents, renameErr := suite.menuItemAggregate.HandleCommand(commands.RenameMenuItem{BaseCommand: commands.BaseCommand{ID: suite.menuItemID}, MenuNumber: 6, Description: "pie"})
+	categoryEvents, categoryErr := suite.menuItemAggregate.HandleCommand(commands.CategoriseMenuItem{BaseCommand: commands.BaseCommand{ID: suite.menuItemID}, MenuNumber: 6, Category: "mains"})
+
+	// Then
+	assert.NoError(suite.T(), priceErr)
+	assert.NoError(suite.T(), renameErr)
+	assert.NoError(suite.T(), categoryErr)
+	assert.Empty(suite.T(), priceEvents)
+	assert.Empty(suite.T(), renameEvents)
+	assert.Empty(suite.T(), categoryEvents)
+}
+
+func (suite *MenuItemAggregateTestSuite) TestCanRenameAndCategoriseAMenuItem() {
+	// Given
+	suite.addPie()
+
+	// When
+	renameEvents, renameErr := suite.menuItemAggregate.HandleCommand(commands.RenameMenuItem{BaseCommand: commands.BaseCommand{ID: suite.menuItemID}, MenuNumber: 6, Description: "pork pie"})
+	categoryEvents, categoryErr := suite.menuItemAggregate.HandleCommand(commands.CategoriseMenuItem{BaseCommand: commands.BaseCommand{ID: suite.menuItemID}, MenuNumber: 6, Category: "pies"})
+
+	// Then
+	assert.NoError(suite.T(), renameErr)
+	assert.NoError(suite.T(), categoryErr)
+	assert.Equal(suite.T(), []events.Event{events.MenuItemRenamed{BaseEvent: events.BaseEvent{ID: suite.menuItemID}, MenuNumber: 6, Description: "pork pie"}}, renameEvents)
+	assert.Equal(suite.T(), []events.Event{events.MenuItemCategorised{BaseEvent: events.BaseEvent{ID: suite.menuItemID}, MenuNumber: 6, Category: "pies"}}, categoryEvents)
+}
+
+func (suite *MenuItemAggregateTestSuite) TestCanSetTheRecipeOfAMenuItem() {
+	// Given
+	suite.addPie()
+	recipe := []shared.RecipeIngredient{{Ingredient: "pastry", Quantity: 1}, {Ingredient: "pork", Quantity: 150}}
+
+	// When
+	newEvents, err := suite.menuItemAggregate.HandleCommand(commands.SetMenuItemRecipe{BaseCommand: commands.BaseCommand{ID: suite.menuItemID}, MenuNumber: 6, Recipe: recipe})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []events.Event{events.MenuItemRecipeSet{BaseEvent: events.BaseEvent{ID: suite.menuItemID}, MenuNumber: 6, Recipe: recipe}}, newEvents)
+	assert.NoError(suite.T(), suite.menuItemAggregate.ApplyEvent(newEvents[0]))
+	sameEvents, err := suite.menuItemAggregate.HandleCommand(commands.SetMenuItemRecipe{BaseCommand: commands.BaseCommand{ID: suite.menuItemID}, MenuNumber: 6, Recipe: recipe})
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), sameEvents)
+}
+
+func (suite *MenuItemAggregateTestSuite) TestRecipesNeedPositiveQuantities() {
+	// Given
+	suite.addPie()
+
+	// When
+	zeroEvents, zeroErr := suite.menuItemAggregate.HandleCommand(commands.SetMenuItemRecipe{BaseCommand: commands.BaseCommand{ID: suite.menuItemID}, MenuNumber: 6, Recipe: []shared.RecipeIngredient{{Ingredient: "pork", Quantity: 0}}})
+	twiceEvents, twiceErr := suite.menuItemAggregate.HandleCommand(commands.SetMenuItemRecipe{BaseCommand: commands.BaseCommand{ID: suite.menuItemID}, MenuNumber: 6, Recipe: []shared.RecipeIngredient{{Ingredient: "pork", Quantity: 100}, {Ingredient: "pork", Quantity: 50}}})
+
+	// Then
+	assert.Empty(suite.T(), zeroEvents)
+	assert.EqualError(suite.T(), zeroErr, "the quantity of pork in a recipe must be positive, got: 0")
+	assert.Empty(suite.T(), twiceEvents)
+	assert.EqualError(suite.T(), twiceErr, "pork is listed twice in the recipe")
+}
+
+func (suite *MenuItemAggregateTestSuite) TestRetiredItemsCanNotBeChanged() {
+	// Given
+	suite.addPie()
+	err := suite.menuItemAggregate.ApplyEvent(events.MenuItemRetired{BaseEvent: events.BaseEvent{ID: suite.menuItemID}, MenuNumber: 6})
+	assert.NoError(suite.T(), err)
+
+	// When
+	newEvents, err := suite.menuItemAggregate.HandleCommand(commands.ChangeMenuItemPrice{BaseCommand: commands.BaseCommand{ID: suite.menuItemID}, MenuNumber: 6, Price: shared.Cents(500)})
+	retireEvents, retireErr := suite.menuItemAggregate.HandleCommand(commands.RetireMenuItem{BaseCommand: commands.BaseCommand{ID: suite.menuItemID}, MenuNumber: 6})
+
+	// Then
+	assert.Empty(suite.T(), newEvents)
+	assert.EqualError(suite.T(), err, "menu item 6 is retired")
+	assert.ErrorIs(suite.T(), err, commands.ErrMenuItemRetired)
+	assert.NoError(suite.T(), retireErr)
+	assert.Empty(suite.T(), retireEvents)
+}
+
+func (suite *MenuItemAggregateTestSuite) TestUnknownItemsCanNotBeChanged() {
+	// When
+	newEvents, err := suite.menuItemAggregate.HandleCommand(commands.RenameMenuItem{BaseCommand: commands.BaseCommand{ID: suite.menuItemID}, MenuNumber: 6, Description: "pork pie"})
+
+	// Then
+	assert.Empty(suite.T(), newEvents)
+	assert.ErrorIs(suite.T(), err, commands.ErrMenuItemNotFound)
+}
+
+func (suite *MenuItemAggregateTestSuite) TestSnapshotRestoresTheMenuItem() {
+	// Given
+	suite.addPie()
+	state, err := suite.menuItemAggregate.Snapshot()
+	assert.NoError(suite.T(), err)
+	restored := commands.MenuItemAggregateFactory{}.CreateAggregate()
+
+	// When
+	err = restored.RestoreSnapshot(state)
+
+	// Then
+	assert.NoError(suite.T(), err)
+	newEvents, err := restored.HandleCommand(commands.ChangeMenuItemPrice{BaseCommand: commands.BaseCommand{ID: suite.menuItemID}, MenuNumber: 6, Price: shared.Cents(500)})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []events.Event{events.MenuItemPriceChanged{BaseEvent: events.BaseEvent{ID: suite.menuItemID}, MenuNumber: 6, Price: shared.Cents(500), PreviousPrice: shared.Cents(450)}}, newEvents)
+}
+
+func TestMenuItemAggregateTestSuite(t *testing.T) {
+	suite.Run(t, new(MenuItemAggregateTestSuite))
+}
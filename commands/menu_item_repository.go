@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"cmp"
+	"context"
+	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/shared"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/segmentio/ksuid"
+)
+
+// eventSourcedMenuItemRepository reads the menu from the events of the menu item aggregates,
+// so orders are priced with the menu as it is right now.
+type eventSourcedMenuItemRepository struct {
+	eventStore events.EventStore
+}
+
+func (r *eventSourcedMenuItemRepository) ReadItems(ctx context.Context, menuItems []int) ([]shared.MenuItem, error) {
+	orderedItems := []shared.MenuItem{}
+	for _, id := range slices.Sorted(slices.Values(menuItems)) {
+		item, err := r.readItem(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		orderedItems = append(orderedItems, item)
+	}
+	return orderedItems, nil
+}
+
+func (r *eventSourcedMenuItemRepository) readItem(ctx context.Context, menuNumber int) (shared.MenuItem, error) {
+	menuItemEvents, err := r.eventStore.LoadEvents(ctx, MenuItemID(menuNumber))
+	if err != nil {
+		return shared.MenuItem{}, err
+	}
+	aggregate := &menuItemAggregate{}
+	for _, event := range menuItemEvents {
+		if err := aggregate.ApplyEvent(event); err != nil {
+			return shared.MenuItem{}, err
+		}
+	}
+	if err := aggregate.mustBeOnTheMenu(menuNumber); err != nil {
+		return shared.MenuItem{}, err
+	}
+	return aggregate.item, nil
+}
+
+func (r *eventSourcedMenuItemRepository) ReadAllItems(ctx context.Context) ([]shared.MenuItem, error) {
+	allEvents, err := r.eventStore.LoadAllEvents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	aggregates := make(map[ksuid.KSUID]*menuItemAggregate)
+	for _, event := range allEvents {
+		if !isMenuItemEvent(event) {
+			continue
+		}
+		aggregate, ok := aggregates[event.GetID()]
+		if !ok {
+			aggregate = &menuItemAggregate{}
+			aggregates[event.GetID()] = aggregate
+		}
+		if err := aggregate.ApplyEvent(event); err != nil {
+			return nil, err
+		}
+	}
+
+	items := []shared.MenuItem{}
+	for _, aggregate := range aggregates {
+		if aggregate.added && !aggregate.retired {
+			items = append(items, aggregate.item)
+		}
+	}
+	slices.SortFunc(items, func(a, b shared.MenuItem) int { return cmp.Compare(a.ID, b.ID) })
+	return items, nil
+}
+
+func isMenuItemEvent(e events.Event) bool {
+	switch e.(type) {
+	case events.MenuItemAdded, events.MenuItemPriceChanged, events.MenuItemRenamed, events.MenuItemCategorised, events.MenuItemRecipeSet, events.MenuItemRetired:
+		return true
+	default:
+		return false
+	}
+}
+
+func CreateEventSourcedMenuItemRepository(eventStore events.EventStore) shared.MenuItemRepository {
+	return &eventSourcedMenuItemRepository{eventStore: eventStore}
+}
+
+// ImportMenu adds the items that have no events yet, so a menu kept elsewhere can seed the event sourced one.
+// Items already added are left alone, whatever happened to them since.
+func ImportMenu(ctx context.Context, eventStore events.EventStore, dispatcher CommandDispatcher, items []shared.MenuItem) error {
+	for _, item := range items {
+		id := MenuItemID(item.ID)
+		menuItemEvents, err := eventStore.LoadEvents(ctx, id)
+		if err != nil {
+			return err
+		}
+		if len(menuItemEvents) > 0 {
+			continue
+		}
+		err = dispatcher.DispatchCommand(ctx, AddMenuItem{
+			BaseCommand: BaseCommand{ID: id},
+			MenuNumber:  item.ID,
+			Description: item.Description,
+			Price:       item.Price,
+			IsDrink:     item.IsDrink,
+			Category:    item.Category,
+		})
+		if errors.Is(err, ErrMenuItemAlreadyExists) {
+			slog.Info("menu item already imported by another service", slog.Int("menu_number", item.ID))
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not import menu item %d, reason: %w", item.ID, err)
+		}
+		if len(item.Recipe) > 0 {
+			err = dispatcher.DispatchCommand(ctx, SetMenuItemRecipe{BaseCommand: BaseCommand{ID: id}, MenuNumber: item.ID, Recipe: item.Recipe})
+			if err != nil {
+				return fmt.Errorf("could not import the recipe of menu item %d, reason: %w", item.ID, err)
+			}
+		}
+	}
+	return nil
+}
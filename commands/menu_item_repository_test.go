@@ -0,0 +1,67 @@
+package commands_test
+
+import (
+	"context"
+	"cqrseventsourcingbar/commands"
+	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/shared"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSourcedMenuReflectsManagerChanges(t *testing.T) {
+	// Given
+	ctx := context.TODO()
+	eventStore := events.CreateInMemoryEventStore()
+	dispatcher := commands.CreateCommandDispatcher(eventStore, events.NoopEventEmitter{}, commands.MenuItemAggregateFactory{})
+	menuItemRepository := commands.CreateEventSourcedMenuItemRepository(eventStore)
+	err := commands.ImportMenu(ctx, eventStore, dispatcher, []shared.MenuItem{
+		{ID: 1, Description: "blue water", Price: shared.Cents(100), IsDrink: true},
+		{ID: 4, Description: "burger", Price: shared.Cents(800)},
+		{ID: 5, Description: "fries", Price: shared.Cents(300)},
+	})
+	assert.NoError(t, err)
+
+	// When
+	assert.NoError(t, dispatcher.DispatchCommand(ctx, commands.ChangeMenuItemPrice{BaseCommand: commands.BaseCommand{ID: commands.MenuItemID(4)}, MenuNumber: 4, Price: shared.Cents(900)}))
+	assert.NoError(t, dispatcher.DispatchCommand(ctx, commands.CategoriseMenuItem{BaseCommand: commands.BaseCommand{ID: commands.MenuItemID(4)}, MenuNumber: 4, Category: "mains"}))
+	assert.NoError(t, dispatcher.DispatchCommand(ctx, commands.RetireMenuItem{BaseCommand: commands.BaseCommand{ID: commands.MenuItemID(5)}, MenuNumber: 5}))
+
+	// Then
+	allItems, err := menuItemRepository.ReadAllItems(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []shared.MenuItem{
+		{ID: 1, Description: "blue water", Price: shared.Cents(100), IsDrink: true, Version: 1},
+		{ID: 4, Description: "burger", Price: shared.Cents(900), Category: "mains", Version: 3},
+	}, allItems)
+	orderedItems, err := menuItemRepository.ReadItems(ctx, []int{4, 1, 4})
+	assert.NoError(t, err)
+	assert.Equal(t, []shared.MenuItem{allItems[0], allItems[1], allItems[1]}, orderedItems)
+	_, err = menuItemRepository.ReadItems(ctx, []int{5})
+	assert.EqualError(t, err, "menu item 5 is retired")
+	_, err = menuItemRepository.ReadItems(ctx, []int{9})
+	assert.ErrorIs(t, err, commands.ErrMenuItemNotFound)
+}
+
+func TestImportingTheMenuAgainKeepsTheChanges(t *testing.T) {
+	// Given
+	ctx := context.TODO()
+	eventStore := events.CreateInMemoryEventStore()
+	dispatcher := commands.CreateCommandDispatcher(eventStore, events.NoopEventEmitter{}, commands.MenuItemAggregateFactory{})
+	burger := shared.MenuItem{ID: 4, Description: "burger", Price: shared.Cents(800)}
+	assert.NoError(t, commands.ImportMenu(ctx, eventStore, dispatcher, []shared.MenuItem{burger}))
+	assert.NoError(t, dispatcher.DispatchCommand(ctx, commands.ChangeMenuItemPrice{BaseCommand: commands.BaseCommand{ID: commands.MenuItemID(4)}, MenuNumber: 4, Price: shared.Cents(900)}))
+
+	// When
+	err := commands.ImportMenu(ctx, eventStore, dispatcher, []shared.MenuItem{burger})
+
+	// Then
+	assert.NoError(t, err)
+	items, err := commands.CreateEventSourcedMenuItemRepository(eventStore).ReadItems(ctx, []int{4})
+	assert.NoError(t, err)
+	assert.Equal(t, shared.Cents(900), items[0].Price)
+	storedEvents, err := eventStore.LoadEvents(ctx, commands.MenuItemID(4))
+	assert.NoError(t, err)
+	assert.Len(t, storedEvents, 2)
+}
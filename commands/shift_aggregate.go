@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/shared"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/ksuid"
+)
+
+var (
+	ErrShiftAlreadyOpen = errors.New("a shift is already open")
+	ErrNoShiftOpen      = errors.New("no shift is open")
+)
+
+// shiftAggregate is the cash drawer of a bar station, one shift after the other is opened and closed on it.
+type shiftAggregate struct {
+	open  bool
+	shift int
+}
+
+type shiftSnapshot struct {
+	Open  bool `json:"open"`
+	Shift int  `json:"shift"`
+}
+
+var stationIDTimestamp = time.Unix(1400000004, 0)
+
+// StationID returns the aggregate ID of the shifts of a bar station, the same for every call with the same name.
+func StationID(station string) ksuid.KSUID {
+	hash := sha256.Sum256([]byte(station))
+	payload := make([]byte, 16)
+	copy(payload, "stat")
+	copy(payload[4:], hash[:12])
+	id, _ := ksuid.FromParts(stationIDTimestamp, payload)
+	return id
+}
+
+func (s shiftAggregate) HandleCommand(c Command) ([]events.Event, error) {
+	switch command := c.(type) {
+	case OpenShift:
+		return s.handleCommandOpenShift(command)
+	case CloseShift:
+		return s.handleCommandCloseShift(command)
+	default:
+		return nil, fmt.Errorf("unexpected Command: %#v", c)
+	}
+}
+
+func (s *shiftAggregate) ApplyEvent(e events.Event) error {
+	switch event := e.(type) {
+	case events.ShiftOpened:
+		s.open = true
+		s.shift = event.Shift
+	case events.ShiftClosed:
+		s.open = false
+	case events.UnknownEvent:
+		return nil
+	default:
+		return fmt.Errorf("unexpected events.Event: %#v", e)
+	}
+	return nil
+}
+
+func (s *shiftAggregate) Snapshot() ([]byte, error) {
+	return json.Marshal(shiftSnapshot{Open: s.open, Shift: s.shift})
+}
+
+func (s *shiftAggregate) RestoreSnapshot(state []byte) error {
+	var snapshot shiftSnapshot
+	if err := json.Unmarshal(state, &snapshot); err != nil {
+		return fmt.Errorf("could not restore shift aggregate from snapshot: %s", state)
+	}
+	s.open = snapshot.Open
+	s.shift = snapshot.Shift
+	return nil
+}
+
+func (s shiftAggregate) handleCommandOpenShift(c OpenShift) ([]events.Event, error) {
+	if c.Station == "" {
+		return nil, errors.New("a shift needs a station")
+	}
+	if s.open {
+		return nil, fmt.Errorf("%w at station %s", ErrShiftAlreadyOpen, c.Station)
+	}
+	if c.OpeningFloat.Amount < 0 {
+		return nil, fmt.Errorf("the opening float can't be negative, got: %v", c.OpeningFloat)
+	}
+	if err := shared.Cents(0).CheckCurrency(c.OpeningFloat); err != nil {
+		return nil, fmt.Errorf("cannot open the shift, %w", err)
+	}
+	return []events.Event{events.ShiftOpened{BaseEvent: events.BaseEvent{ID: c.ID}, Station: c.Station, Shift: s.shift + 1, OpeningFloat: c.OpeningFloat}}, nil
+}
+
+func (s shiftAggregate) handleCommandCloseShift(c CloseShift) ([]events.Event, error) {
+	if !s.open {
+		return nil, fmt.Errorf("%w at station %s", ErrNoShiftOpen, c.Station)
+	}
+	if c.CountedCash.Amount < 0 {
+		return nil, fmt.Errorf("the cash counted can't be negative, got: %v", c.CountedCash)
+	}
+	if err := shared.Cents(0).CheckCurrency(c.CountedCash); err != nil {
+		return nil, fmt.Errorf("cannot close the shift, %w", err)
+	}
+	for waiter, counted := range c.WaiterCounts {
+		if counted.Amount < 0 {
+			return nil, fmt.Errorf("the cash counted for %s can't be negative, got: %v", waiter, counted)
+		}
+		if err := shared.Cents(0).CheckCurrency(counted); err != nil {
+			return nil, fmt.Errorf("cannot close the shift, %w", err)
+		}
+	}
+	return []events.Event{events.ShiftClosed{BaseEvent: events.BaseEvent{ID: c.ID}, Station: c.Station, Shift: s.shift, CountedCash: c.CountedCash, WaiterCounts: c.WaiterCounts}}, nil
+}
+
+type ShiftAggregateFactory struct {
+}
+
+func (s ShiftAggregateFactory) CreateAggregate() Aggregate {
+	return &shiftAggregate{}
+}
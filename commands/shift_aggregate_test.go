@@ -0,0 +1,132 @@
+package commands_test
+
+import (
+	"cqrseventsourcingbar/commands"
+	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/shared"
+	"testing"
+
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ShiftAggregateTestSuite struct {
+	suite.Suite
+	shiftAggregate commands.Aggregate
+	stationID      ksuid.KSUID
+}
+
+func (suite *ShiftAggregateTestSuite) SetupTest() {
+	suite.shiftAggregate = commands.ShiftAggregateFactory{}.CreateAggregate()
+	suite.stationID = commands.StationID("terrace")
+}
+
+func (suite *ShiftAggregateTestSuite) applyEvents(shiftEvents ...events.Event) {
+	for _, event := range shiftEvents {
+		assert.NoError(suite.T(), suite.shiftAggregate.ApplyEvent(event))
+	}
+}
+
+func (suite *ShiftAggregateTestSuite) TestStationIDIsStablePerStation() {
+	assert.Equal(suite.T(), commands.StationID("terrace"), commands.StationID("terrace"))
+	assert.NotEqual(suite.T(), commands.StationID("terrace"), commands.StationID("main bar"))
+	assert.NotEqual(suite.T(), commands.IngredientID("terrace"), commands.StationID("terrace"))
+}
+
+func (suite *ShiftAggregateTestSuite) TestShiftsAreNumberedPerStation() {
+	// Given
+	suite.applyEvents(
+		events.ShiftOpened{BaseEvent: events.BaseEvent{ID: suite.stationID}, Station: "terrace", Shift: 1, OpeningFloat: shared.Cents(10000)},
+		events.ShiftClosed{BaseEvent: events.BaseEvent{ID: suite.stationID}, Station: "terrace", Shift: 1, CountedCash: shared.Cents(25000)},
+	)
+
+	// When
+	newEvents, err := suite.shiftAggregate.HandleCommand(commands.OpenShift{BaseCommand: commands.BaseCommand{ID: suite.stationID}, Station: "terrace", OpeningFloat: shared.Cents(15000)})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []events.Event{events.ShiftOpened{BaseEvent: events.BaseEvent{ID: suite.stationID}, Station: "terrace", Shift: 2, OpeningFloat: shared.Cents(15000)}}, newEvents)
+}
+
+func (suite *ShiftAggregateTestSuite) TestCanNotOpenTwoShiftsAtOnce() {
+	// Given
+	suite.applyEvents(events.ShiftOpened{BaseEvent: events.BaseEvent{ID: suite.stationID}, Station: "terrace", Shift: 1, OpeningFloat: shared.Cents(10000)})
+
+	// When
+	newEvents, err := suite.shiftAggregate.HandleCommand(commands.OpenShift{BaseCommand: commands.BaseCommand{ID: suite.stationID}, Station: "terrace", OpeningFloat: shared.Cents(10000)})
+
+	// Then
+	assert.Empty(suite.T(), newEvents)
+	assert.ErrorIs(suite.T(), err, commands.ErrShiftAlreadyOpen)
+	assert.EqualError(suite.T(), err, "a shift is already open at station terrace")
+}
+
+func (suite *ShiftAggregateTestSuite) TestTheOpeningFloatCanNotBeNegative() {
+	// When
+	newEvents, err := suite.shiftAggregate.HandleCommand(commands.OpenShift{BaseCommand: commands.BaseCommand{ID: suite.stationID}, Station: "terrace", OpeningFloat: shared.Cents(-100)})
+
+	// Then
+	assert.Empty(suite.T(), newEvents)
+	assert.EqualError(suite.T(), err, "the opening float can't be negative, got: -1.00 EUR")
+}
+
+func (suite *ShiftAggregateTestSuite) TestTheCashCountedMustBeInTheDefaultCurrency() {
+	// Given
+	suite.applyEvents(events.ShiftOpened{BaseEvent: events.BaseEvent{ID: suite.stationID}, Station: "terrace", Shift: 1, OpeningFloat: shared.Cents(10000)})
+
+	// When
+	newEvents, err := suite.shiftAggregate.HandleCommand(commands.CloseShift{BaseCommand: commands.BaseCommand{ID: suite.stationID}, Station: "terrace", CountedCash: shared.Cents(25000), WaiterCounts: map[string]shared.Money{"Charles": shared.NewMoney(9000, "GBP")}})
+
+	// Then
+	assert.Empty(suite.T(), newEvents)
+	var currencyMismatch *shared.CurrencyMismatchError
+	assert.ErrorAs(suite.T(), err, &currencyMismatch)
+	assert.EqualError(suite.T(), err, "cannot close the shift, cannot combine amounts in EUR and GBP")
+}
+
+func (suite *ShiftAggregateTestSuite) TestClosingRecordsTheCashCounted() {
+	// Given
+	suite.applyEvents(events.ShiftOpened{BaseEvent: events.BaseEvent{ID: suite.stationID}, Station: "terrace", Shift: 1, OpeningFloat: shared.Cents(10000)})
+	waiterCounts := map[string]shared.Money{"Charles": shared.Cents(9000), "Jenkins": shared.Cents(6000)}
+
+	// When
+	newEvents, err := suite.shiftAggregate.HandleCommand(commands.CloseShift{BaseCommand: commands.BaseCommand{ID: suite.stationID}, Station: "terrace", CountedCash: shared.Cents(25000), WaiterCounts: waiterCounts})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []events.Event{events.ShiftClosed{BaseEvent: events.BaseEvent{ID: suite.stationID}, Station: "terrace", Shift: 1, CountedCash: shared.Cents(25000), WaiterCounts: waiterCounts}}, newEvents)
+}
+
+func (suite *ShiftAggregateTestSuite) TestCanNotCloseAShiftThatIsNotOpen() {
+	// When
+	newEvents, err := suite.shiftAggregate.HandleCommand(commands.CloseShift{BaseCommand: commands.BaseCommand{ID: suite.stationID}, Station: "terrace", CountedCash: shared.Cents(0)})
+
+	// Then
+	assert.Empty(suite.T(), newEvents)
+	assert.ErrorIs(suite.T(), err, commands.ErrNoShiftOpen)
+}
+
+func (suite *ShiftAggregateTestSuite) TestSnapshotRestoresTheOpenShift() {
+	// Given
+	suite.applyEvents(
+		events.ShiftOpened{BaseEvent: events.BaseEvent{ID: suite.stationID}, Station: "terrace", Shift: 1, OpeningFloat: shared.Cents(10000)},
+		events.ShiftClosed{BaseEvent: events.BaseEvent{ID: suite.stationID}, Station: "terrace", Shift: 1, CountedCash: shared.Cents(10000)},
+		events.ShiftOpened{BaseEvent: events.BaseEvent{ID: suite.stationID}, Station: "terrace", Shift: 2, OpeningFloat: shared.Cents(10000)},
+	)
+	state, err := suite.shiftAggregate.Snapshot()
+	assert.NoError(suite.T(), err)
+
+	// When
+	restored := commands.ShiftAggregateFactory{}.CreateAggregate()
+	assert.NoError(suite.T(), restored.RestoreSnapshot(state))
+
+	// Then
+	newEvents, err := restored.HandleCommand(commands.CloseShift{BaseCommand: commands.BaseCommand{ID: suite.stationID}, Station: "terrace", CountedCash: shared.Cents(10000)})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []events.Event{events.ShiftClosed{BaseEvent: events.BaseEvent{ID: suite.stationID}, Station: "terrace", Shift: 2, CountedCash: shared.Cents(10000)}}, newEvents)
+}
+
+func TestShiftAggregateTestSuite(t *testing.T) {
+	suite.Run(t, new(ShiftAggregateTestSuite))
+}
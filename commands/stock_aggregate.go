@@ -0,0 +1,186 @@
+package commands
+
+import (
+	"cqrseventsourcingbar/events"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/ksuid"
+)
+
+// stockAggregate is the stock of one menu item. Items are only tracked once they have been restocked or counted,
+// until then reserving, releasing and consuming them records nothing. The stock keeper handles the tab events in the
+// order of the event store, so the position of the last one handled is enough to ignore them when they come again.
+type stockAggregate struct {
+	tracked         bool
+	onHand          int
+	reserved        int
+	reorderLevel    int
+	lastTabPosition int64
+}
+
+type stockSnapshot struct {
+	Tracked         bool  `json:"tracked"`
+	OnHand          int   `json:"on_hand"`
+	Reserved        int   `json:"reserved"`
+	ReorderLevel    int   `json:"reorder_level"`
+	LastTabPosition int64 `json:"last_tab_position"`
+}
+
+var stockIDTimestamp = time.Unix(1400000002, 0)
+
+// StockID returns the aggregate ID of the stock of a menu item, the same for every call with the same menu number.
+func StockID(menuNumber int) ksuid.KSUID {
+	payload := make([]byte, 16)
+	copy(payload, "stock")
+	binary.BigEndian.PutUint64(payload[8:], uint64(menuNumber))
+	id, _ := ksuid.FromParts(stockIDTimestamp, payload)
+	return id
+}
+
+func (s stockAggregate) HandleCommand(c Command) ([]events.Event, error) {
+	switch command := c.(type) {
+	case Restock:
+		return s.handleCommandRestock(command)
+	case CountStock:
+		return s.handleCommandCountStock(command)
+	case SetReorderLevel:
+		return s.handleCommandSetReorderLevel(command)
+	case ReserveStock:
+		return s.handleCommandReserveStock(command)
+	case ReleaseStock:
+		return s.handleCommandReleaseStock(command)
+	case ConsumeStock:
+		return s.handleCommandConsumeStock(command)
+	default:
+		return nil, fmt.Errorf("unexpected Command: %#v", c)
+	}
+}
+
+func (s *stockAggregate) ApplyEvent(e events.Event) error {
+	switch event := e.(type) {
+	case events.StockRestocked:
+		s.tracked = true
+		s.onHand += event.Quantity
+	case events.StockCounted:
+		s.tracked = true
+		s.onHand = event.Quantity
+	case events.ReorderLevelSet:
+		s.reorderLevel = event.Level
+	case events.StockReserved:
+		s.reserved += event.Quantity
+		s.lastTabPosition = max(s.lastTabPosition, event.TabPosition)
+	case events.StockReleased:
+		s.reserved -= event.Quantity
+		s.lastTabPosition = max(s.lastTabPosition, event.TabPosition)
+	case events.StockConsumed:
+		s.onHand -= event.Quantity
+		s.reserved -= min(event.Quantity, s.reserved)
+		s.lastTabPosition = max(s.lastTabPosition, event.TabPosition)
+	case events.StockRanLow:
+	case events.UnknownEvent:
+		return nil
+	default:
+		return fmt.Errorf("unexpected events.Event: %#v", e)
+	}
+	return nil
+}
+
+func (s *stockAggregate) Snapshot() ([]byte, error) {
+	return json.Marshal(stockSnapshot{Tracked: s.tracked, OnHand: s.onHand, Reserved: s.reserved, ReorderLevel: s.reorderLevel, LastTabPosition: s.lastTabPosition})
+}
+
+func (s *stockAggregate) RestoreSnapshot(state []byte) error {
+	var snapshot stockSnapshot
+	if err := json.Unmarshal(state, &snapshot); err != nil {
+		return fmt.Errorf("could not restore stock aggregate from snapshot: %s", state)
+	}
+	s.tracked = snapshot.Tracked
+	s.onHand = snapshot.OnHand
+	s.reserved = snapshot.Reserved
+	s.reorderLevel = snapshot.ReorderLevel
+	s.lastTabPosition = snapshot.LastTabPosition
+	return nil
+}
+
+func (s stockAggregate) available() int {
+	return s.onHand - s.reserved
+}
+
+// isLow tells whether the given availability is at the reorder level or below it, nothing is low until it is tracked.
+func (s stockAggregate) isLow(available int, reorderLevel int) bool {
+	return s.tracked && available <= reorderLevel
+}
+
+// withRanLow adds StockRanLow when the command takes the stock available to the reorder level or below it.
+func (s stockAggregate) withRanLow(c Command, menuNumber int, newEvents []events.Event, available int, reorderLevel int) []events.Event {
+	if s.isLow(s.available(), s.reorderLevel) || available > reorderLevel {
+		return newEvents
+	}
+	return append(newEvents, events.StockRanLow{BaseEvent: events.BaseEvent{ID: c.GetID()}, MenuNumber: menuNumber, Available: available, ReorderLevel: reorderLevel})
+}
+
+func (s stockAggregate) handleCommandRestock(c Restock) ([]events.Event, error) {
+	if c.Quantity <= 0 {
+		return nil, fmt.Errorf("the quantity restocked must be positive, got: %d", c.Quantity)
+	}
+	return []events.Event{events.StockRestocked{BaseEvent: events.BaseEvent{ID: c.ID}, MenuNumber: c.MenuNumber, Quantity: c.Quantity}}, nil
+}
+
+func (s stockAggregate) handleCommandCountStock(c CountStock) ([]events.Event, error) {
+	if c.Quantity < 0 {
+		return nil, fmt.Errorf("the quantity counted can't be negative, got: %d", c.Quantity)
+	}
+	counted := []events.Event{events.StockCounted{BaseEvent: events.BaseEvent{ID: c.ID}, MenuNumber: c.MenuNumber, Quantity: c.Quantity, Difference: c.Quantity - s.onHand}}
+	return s.withRanLow(c, c.MenuNumber, counted, c.Quantity-s.reserved, s.reorderLevel), nil
+}
+
+func (s stockAggregate) handleCommandSetReorderLevel(c SetReorderLevel) ([]events.Event, error) {
+	if c.Level < 0 {
+		return nil, fmt.Errorf("the reorder level can't be negative, got: %d", c.Level)
+	}
+	if c.Level == s.reorderLevel {
+		return nil, nil
+	}
+	levelSet := []events.Event{events.ReorderLevelSet{BaseEvent: events.BaseEvent{ID: c.ID}, MenuNumber: c.MenuNumber, Level: c.Level}}
+	return s.withRanLow(c, c.MenuNumber, levelSet, s.available(), c.Level), nil
+}
+
+func (s stockAggregate) handleCommandReserveStock(c ReserveStock) ([]events.Event, error) {
+	if !s.tracked || s.alreadyHandled(c.TabPosition) {
+		return nil, nil
+	}
+	reserved := []events.Event{events.StockReserved{BaseEvent: events.BaseEvent{ID: c.ID}, MenuNumber: c.MenuNumber, Quantity: c.Quantity, TabID: c.TabID, TabSequenceNumber: c.TabSequenceNumber, TabPosition: c.TabPosition}}
+	return s.withRanLow(c, c.MenuNumber, reserved, s.available()-c.Quantity, s.reorderLevel), nil
+}
+
+// handleCommandReleaseStock never releases more than is reserved, the drinks may have been ordered before the item was tracked.
+func (s stockAggregate) handleCommandReleaseStock(c ReleaseStock) ([]events.Event, error) {
+	quantity := min(c.Quantity, s.reserved)
+	if !s.tracked || quantity <= 0 || s.alreadyHandled(c.TabPosition) {
+		return nil, nil
+	}
+	return []events.Event{events.StockReleased{BaseEvent: events.BaseEvent{ID: c.ID}, MenuNumber: c.MenuNumber, Quantity: quantity, TabID: c.TabID, TabSequenceNumber: c.TabSequenceNumber, TabPosition: c.TabPosition}}, nil
+}
+
+func (s stockAggregate) handleCommandConsumeStock(c ConsumeStock) ([]events.Event, error) {
+	if !s.tracked || s.alreadyHandled(c.TabPosition) {
+		return nil, nil
+	}
+	consumed := []events.Event{events.StockConsumed{BaseEvent: events.BaseEvent{ID: c.ID}, MenuNumber: c.MenuNumber, Quantity: c.Quantity, TabID: c.TabID, TabSequenceNumber: c.TabSequenceNumber, TabPosition: c.TabPosition}}
+	unreserved := c.Quantity - min(c.Quantity, s.reserved)
+	return s.withRanLow(c, c.MenuNumber, consumed, s.available()-unreserved, s.reorderLevel), nil
+}
+
+func (s stockAggregate) alreadyHandled(tabPosition int64) bool {
+	return tabPosition <= s.lastTabPosition
+}
+
+type StockAggregateFactory struct {
+}
+
+func (s StockAggregateFactory) CreateAggregate() Aggregate {
+	return &stockAggregate{}
+}
@@ -0,0 +1,163 @@
+package commands_test
+
+import (
+	"cqrseventsourcingbar/commands"
+	"cqrseventsourcingbar/events"
+	"testing"
+
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type StockAggregateTestSuite struct {
+	suite.Suite
+	stockAggregate commands.Aggregate
+	stockID        ksuid.KSUID
+	tabID          ksuid.KSUID
+}
+
+func (suite *StockAggregateTestSuite) SetupTest() {
+	suite.stockAggregate = commands.StockAggregateFactory{}.CreateAggregate()
+	suite.stockID = commands.StockID(1)
+	suite.tabID = ksuid.New()
+}
+
+func (suite *StockAggregateTestSuite) applyEvents(stockEvents ...events.Event) {
+	for _, event := range stockEvents {
+		assert.NoError(suite.T(), suite.stockAggregate.ApplyEvent(event))
+	}
+}
+
+func (suite *StockAggregateTestSuite) TestStockIDIsStablePerMenuNumber() {
+	assert.Equal(suite.T(), commands.StockID(1), commands.StockID(1))
+	assert.NotEqual(suite.T(), commands.StockID(1), commands.StockID(2))
+	assert.NotEqual(suite.T(), commands.MenuItemID(1), commands.StockID(1))
+}
+
+func (suite *StockAggregateTestSuite) TestRestockingNeedsAPositiveQuantity() {
+	// When
+	newEvents, err := suite.stockAggregate.HandleCommand(commands.Restock{BaseCommand: commands.BaseCommand{ID: suite.stockID}, MenuNumber: 1, Quantity: 0})
+
+	// Then
+	assert.Empty(suite.T(), newEvents)
+	assert.EqualError(suite.T(), err, "the quantity restocked must be positive, got: 0")
+}
+
+func (suite *StockAggregateTestSuite) TestCountingRecordsTheDifference() {
+	// Given
+	suite.applyEvents(events.StockRestocked{BaseEvent: events.BaseEvent{ID: suite.stockID}, MenuNumber: 1, Quantity: 24})
+
+	// When
+	newEvents, err := suite.stockAggregate.HandleCommand(commands.CountStock{BaseCommand: commands.BaseCommand{ID: suite.stockID}, MenuNumber: 1, Quantity: 20})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []events.Event{events.StockCounted{BaseEvent: events.BaseEvent{ID: suite.stockID}, MenuNumber: 1, Quantity: 20, Difference: -4}}, newEvents)
+}
+
+func (suite *StockAggregateTestSuite) TestUntrackedItemsRecordNothing() {
+	// When
+	newEvents, err := suite.stockAggregate.HandleCommand(commands.ReserveStock{BaseCommand: commands.BaseCommand{ID: suite.stockID}, MenuNumber: 1, Quantity: 2, TabID: suite.tabID, TabSequenceNumber: 2, TabPosition: 12})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), newEvents)
+}
+
+func (suite *StockAggregateTestSuite) TestReservingDownToTheReorderLevelRaisesAnAlert() {
+	// Given
+	suite.applyEvents(
+		events.StockRestocked{BaseEvent: events.BaseEvent{ID: suite.stockID}, MenuNumber: 1, Quantity: 6},
+		events.ReorderLevelSet{BaseEvent: events.BaseEvent{ID: suite.stockID}, MenuNumber: 1, Level: 2},
+	)
+
+	// When
+	newEvents, err := suite.stockAggregate.HandleCommand(commands.ReserveStock{BaseCommand: commands.BaseCommand{ID: suite.stockID}, MenuNumber: 1, Quantity: 4, TabID: suite.tabID, TabSequenceNumber: 2, TabPosition: 12})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []events.Event{
+		events.StockReserved{BaseEvent: events.BaseEvent{ID: suite.stockID}, MenuNumber: 1, Quantity: 4, TabID: suite.tabID, TabSequenceNumber: 2, TabPosition: 12},
+		events.StockRanLow{BaseEvent: events.BaseEvent{ID: suite.stockID}, MenuNumber: 1, Available: 2, ReorderLevel: 2},
+	}, newEvents)
+}
+
+func (suite *StockAggregateTestSuite) TestStockAlreadyLowRaisesNoNewAlert() {
+	// Given
+	suite.applyEvents(
+		events.StockRestocked{BaseEvent: events.BaseEvent{ID: suite.stockID}, MenuNumber: 1, Quantity: 2},
+		events.ReorderLevelSet{BaseEvent: events.BaseEvent{ID: suite.stockID}, MenuNumber: 1, Level: 2},
+		events.StockRanLow{BaseEvent: events.BaseEvent{ID: suite.stockID}, MenuNumber: 1, Available: 2, ReorderLevel: 2},
+	)
+
+	// When
+	newEvents, err := suite.stockAggregate.HandleCommand(commands.ReserveStock{BaseCommand: commands.BaseCommand{ID: suite.stockID}, MenuNumber: 1, Quantity: 1, TabID: suite.tabID, TabSequenceNumber: 2, TabPosition: 12})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []events.Event{events.StockReserved{BaseEvent: events.BaseEvent{ID: suite.stockID}, MenuNumber: 1, Quantity: 1, TabID: suite.tabID, TabSequenceNumber: 2, TabPosition: 12}}, newEvents)
+}
+
+func (suite *StockAggregateTestSuite) TestTabEventsAreOnlyHandledOnce() {
+	// Given
+	suite.applyEvents(
+		events.StockRestocked{BaseEvent: events.BaseEvent{ID: suite.stockID}, MenuNumber: 1, Quantity: 6},
+		events.StockReserved{BaseEvent: events.BaseEvent{ID: suite.stockID}, MenuNumber: 1, Quantity: 2, TabID: suite.tabID, TabSequenceNumber: 2, TabPosition: 12},
+		events.StockConsumed{BaseEvent: events.BaseEvent{ID: suite.stockID}, MenuNumber: 1, Quantity: 2, TabID: suite.tabID, TabSequenceNumber: 3, TabPosition: 13},
+	)
+
+	// When
+	reserved, reserveErr := suite.stockAggregate.HandleCommand(commands.ReserveStock{BaseCommand: commands.BaseCommand{ID: suite.stockID}, MenuNumber: 1, Quantity: 2, TabID: suite.tabID, TabSequenceNumber: 2, TabPosition: 12})
+	consumed, consumeErr := suite.stockAggregate.HandleCommand(commands.ConsumeStock{BaseCommand: commands.BaseCommand{ID: suite.stockID}, MenuNumber: 1, Quantity: 2, TabID: suite.tabID, TabSequenceNumber: 3, TabPosition: 13})
+
+	// Then
+	assert.NoError(suite.T(), reserveErr)
+	assert.Empty(suite.T(), reserved)
+	assert.NoError(suite.T(), consumeErr)
+	assert.Empty(suite.T(), consumed)
+}
+
+func (suite *StockAggregateTestSuite) TestReleasingNeverGoesBelowWhatIsReserved() {
+	// Given
+	suite.applyEvents(
+		events.StockRestocked{BaseEvent: events.BaseEvent{ID: suite.stockID}, MenuNumber: 1, Quantity: 6},
+		events.StockReserved{BaseEvent: events.BaseEvent{ID: suite.stockID}, MenuNumber: 1, Quantity: 1, TabID: suite.tabID, TabSequenceNumber: 2, TabPosition: 12},
+	)
+
+	// When
+	newEvents, err := suite.stockAggregate.HandleCommand(commands.ReleaseStock{BaseCommand: commands.BaseCommand{ID: suite.stockID}, MenuNumber: 1, Quantity: 3, TabID: suite.tabID, TabSequenceNumber: 4, TabPosition: 14})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []events.Event{events.StockReleased{BaseEvent: events.BaseEvent{ID: suite.stockID}, MenuNumber: 1, Quantity: 1, TabID: suite.tabID, TabSequenceNumber: 4, TabPosition: 14}}, newEvents)
+}
+
+func (suite *StockAggregateTestSuite) TestSnapshotRestoresTheStock() {
+	// Given
+	suite.applyEvents(
+		events.StockRestocked{BaseEvent: events.BaseEvent{ID: suite.stockID}, MenuNumber: 1, Quantity: 6},
+		events.StockReserved{BaseEvent: events.BaseEvent{ID: suite.stockID}, MenuNumber: 1, Quantity: 2, TabID: suite.tabID, TabSequenceNumber: 2, TabPosition: 12},
+	)
+	state, err := suite.stockAggregate.Snapshot()
+	assert.NoError(suite.T(), err)
+
+	// When
+	restored := commands.StockAggregateFactory{}.CreateAggregate()
+	assert.NoError(suite.T(), restored.RestoreSnapshot(state))
+
+	// Then
+	newEvents, err := restored.HandleCommand(commands.ReserveStock{BaseCommand: commands.BaseCommand{ID: suite.stockID}, MenuNumber: 1, Quantity: 2, TabID: suite.tabID, TabSequenceNumber: 2, TabPosition: 12})
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), newEvents)
+	newEvents, err = restored.HandleCommand(commands.CountStock{BaseCommand: commands.BaseCommand{ID: suite.stockID}, MenuNumber: 1, Quantity: 2})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []events.Event{
+		events.StockCounted{BaseEvent: events.BaseEvent{ID: suite.stockID}, MenuNumber: 1, Quantity: 2, Difference: -4},
+		events.StockRanLow{BaseEvent: events.BaseEvent{ID: suite.stockID}, MenuNumber: 1, Available: 0, ReorderLevel: 0},
+	}, newEvents)
+}
+
+func TestStockAggregateTestSuite(t *testing.T) {
+	suite.Run(t, new(StockAggregateTestSuite))
+}
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"context"
+	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/shared"
+	"maps"
+	"slices"
+)
+
+// eventSourcedStockChecker checks orders against the stock aggregates, items that are not tracked are never short.
+type eventSourcedStockChecker struct {
+	eventStore    events.EventStore
+	snapshotStore events.SnapshotStore
+}
+
+func (c *eventSourcedStockChecker) CheckStock(ctx context.Context, items []shared.MenuItem) error {
+	requested := make(map[int]int)
+	descriptions := make(map[int]string)
+	for _, item := range items {
+		requested[item.ID]++
+		descriptions[item.ID] = item.Description
+	}
+
+	shortages := []shared.StockShortage{}
+	for _, menuNumber := range slices.Sorted(maps.Keys(requested)) {
+		stock, err := c.loadStock(ctx, menuNumber)
+		if err != nil {
+			return err
+		}
+		if stock.tracked && requested[menuNumber] > stock.available() {
+			shortages = append(shortages, shared.StockShortage{
+				MenuNumber:  menuNumber,
+				Description: descriptions[menuNumber],
+				Requested:   requested[menuNumber],
+				Available:   max(stock.available(), 0),
+			})
+		}
+	}
+	if len(shortages) > 0 {
+		return &shared.InsufficientStockError{Shortages: shortages}
+	}
+	return nil
+}
+
+func (c *eventSourcedStockChecker) loadStock(ctx context.Context, menuNumber int) (*stockAggregate, error) {
+	stock := &stockAggregate{}
+	if _, err := loadAggregate(ctx, c.eventStore, c.snapshotStore, stock, StockID(menuNumber)); err != nil {
+		return nil, err
+	}
+	return stock, nil
+}
+
+// CreateEventSourcedStockChecker loads the stock from the latest snapshot the stock dispatcher took in snapshotStore,
+// which can be nil when the dispatcher takes none.
+func CreateEventSourcedStockChecker(eventStore events.EventStore, snapshotStore events.SnapshotStore) shared.StockChecker {
+	return &eventSourcedStockChecker{eventStore: eventStore, snapshotStore: snapshotStore}
+}
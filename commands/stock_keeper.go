@@ -0,0 +1,195 @@
+package commands
+
+import (
+	"context"
+	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/shared"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/segmentio/ksuid"
+)
+
+// StockKeeper reserves, consumes and releases the stock of the drinks on the tabs, following the event log in order.
+type StockKeeper struct {
+	dispatcher         CommandDispatcher
+	position           int64
+	trackedStock       map[int]bool
+	trackedIngredients map[string]bool
+	outstandingDrinks  map[ksuid.KSUID][]shared.MenuItem
+	pending            []Command
+	wake               chan struct{}
+	lock               sync.Mutex
+}
+
+func CreateStockKeeper(dispatcher CommandDispatcher) *StockKeeper {
+	return &StockKeeper{
+		dispatcher:         dispatcher,
+		trackedStock:       make(map[int]bool),
+		trackedIngredients: make(map[string]bool),
+		outstandingDrinks:  make(map[ksuid.KSUID][]shared.MenuItem),
+		wake:               make(chan struct{}, 1),
+	}
+}
+
+func (k *StockKeeper) LastPosition(_ context.Context) (int64, error) {
+	defer k.lock.Unlock()
+	k.lock.Lock()
+	return k.position, nil
+}
+
+func (k *StockKeeper) HandleRecordedEvent(_ context.Context, recordedEvent events.RecordedEvent) error {
+	k.lock.Lock()
+	if recordedEvent.Position <= k.position {
+		k.lock.Unlock()
+		return nil
+	}
+	k.pending = append(k.pending, k.commandsFor(recordedEvent)...)
+	k.position = recordedEvent.Position
+	k.lock.Unlock()
+
+	select {
+	case k.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// DispatchPending dispatches the queued commands in order, a command that fails stays queued with the ones after it.
+func (k *StockKeeper) DispatchPending(ctx context.Context) error {
+	for {
+		k.lock.Lock()
+		if len(k.pending) == 0 {
+			k.lock.Unlock()
+			return nil
+		}
+		command := k.pending[0]
+		k.lock.Unlock()
+
+		if err := k.dispatcher.DispatchCommand(ctx, command); err != nil {
+			return err
+		}
+
+		k.lock.Lock()
+		k.pending = k.pending[1:]
+		k.lock.Unlock()
+	}
+}
+
+// Run dispatches the commands as they are queued, after a failure it tries again every retryInterval.
+func (k *StockKeeper) Run(ctx context.Context, retryInterval time.Duration) {
+	ticker := time.NewTicker(retryInterval)
+	defer ticker.Stop()
+	for {
+		if err := k.DispatchPending(ctx); err != nil {
+			slog.Error("error keeping stock", slog.String("error", err.Error()))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-k.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (k *StockKeeper) commandsFor(recordedEvent events.RecordedEvent) []Command {
+	position := recordedEvent.Position
+	switch event := recordedEvent.Event.(type) {
+	case events.StockRestocked:
+		k.trackedStock[event.MenuNumber] = true
+	case events.StockCounted:
+		k.trackedStock[event.MenuNumber] = true
+	case events.IngredientRestocked:
+		k.trackedIngredients[event.Ingredient] = true
+	case events.IngredientCounted:
+		k.trackedIngredients[event.Ingredient] = true
+	case events.DrinksOrdered:
+		k.outstandingDrinks[event.ID] = append(k.outstandingDrinks[event.ID], event.Items...)
+		menuNumbers := make([]int, 0, len(event.Items))
+		for _, item := range event.Items {
+			menuNumbers = append(menuNumbers, item.ID)
+		}
+		return k.perTrackedMenuNumber(menuNumbers, func(menuNumber int, quantity int) Command {
+			return ReserveStock{BaseCommand: BaseCommand{ID: StockID(menuNumber)}, MenuNumber: menuNumber, Quantity: quantity, TabID: event.ID, TabSequenceNumber: event.SequenceNumber, TabPosition: position}
+		})
+	case events.DrinksServed:
+		served := k.takeOutstandingDrinks(event.ID, event.MenuNumbers)
+		commands := k.perTrackedMenuNumber(event.MenuNumbers, func(menuNumber int, quantity int) Command {
+			return ConsumeStock{BaseCommand: BaseCommand{ID: StockID(menuNumber)}, MenuNumber: menuNumber, Quantity: quantity, TabID: event.ID, TabSequenceNumber: event.SequenceNumber, TabPosition: position}
+		})
+		return append(commands, k.consumeIngredients(event, position, served)...)
+	case events.ItemsCancelled:
+		k.takeOutstandingDrinks(event.ID, event.MenuNumbers)
+		return k.perTrackedMenuNumber(event.MenuNumbers, func(menuNumber int, quantity int) Command {
+			return ReleaseStock{BaseCommand: BaseCommand{ID: StockID(menuNumber)}, MenuNumber: menuNumber, Quantity: quantity, TabID: event.ID, TabSequenceNumber: event.SequenceNumber, TabPosition: position}
+		})
+	case events.TabClosed:
+		delete(k.outstandingDrinks, event.ID)
+	}
+	return nil
+}
+
+// takeOutstandingDrinks removes the drinks served or cancelled from the tab, oldest order first.
+func (k *StockKeeper) takeOutstandingDrinks(tabID ksuid.KSUID, menuNumbers []int) []shared.MenuItem {
+	taken := make([]shared.MenuItem, 0, len(menuNumbers))
+	for _, menuNumber := range menuNumbers {
+		outstanding := k.outstandingDrinks[tabID]
+		index := slices.IndexFunc(outstanding, func(item shared.MenuItem) bool { return item.ID == menuNumber })
+		if index < 0 {
+			continue
+		}
+		taken = append(taken, outstanding[index])
+		k.outstandingDrinks[tabID] = slices.Delete(outstanding, index, index+1)
+	}
+	return taken
+}
+
+func (k *StockKeeper) consumeIngredients(event events.DrinksServed, position int64, served []shared.MenuItem) []Command {
+	quantities := make(map[string]int)
+	menuNumbersByIngredient := make(map[string][]int)
+	for _, item := range served {
+		for _, ingredient := range item.Recipe {
+			if !k.trackedIngredients[ingredient.Ingredient] {
+				continue
+			}
+			quantities[ingredient.Ingredient] += ingredient.Quantity
+			if !slices.Contains(menuNumbersByIngredient[ingredient.Ingredient], item.ID) {
+				menuNumbersByIngredient[ingredient.Ingredient] = append(menuNumbersByIngredient[ingredient.Ingredient], item.ID)
+			}
+		}
+	}
+
+	commands := make([]Command, 0, len(quantities))
+	for _, ingredient := range slices.Sorted(maps.Keys(quantities)) {
+		menuNumbers := menuNumbersByIngredient[ingredient]
+		slices.Sort(menuNumbers)
+		commands = append(commands, ConsumeIngredient{
+			BaseCommand:       BaseCommand{ID: IngredientID(ingredient)},
+			Ingredient:        ingredient,
+			Quantity:          quantities[ingredient],
+			MenuNumbers:       menuNumbers,
+			TabID:             event.ID,
+			TabSequenceNumber: event.SequenceNumber,
+			TabPosition:       position,
+		})
+	}
+	return commands
+}
+
+func (k *StockKeeper) perTrackedMenuNumber(menuNumbers []int, command func(menuNumber int, quantity int) Command) []Command {
+	quantities := make(map[int]int)
+	for _, menuNumber := range menuNumbers {
+		if k.trackedStock[menuNumber] {
+			quantities[menuNumber]++
+		}
+	}
+	commands := make([]Command, 0, len(quantities))
+	for _, menuNumber := range slices.Sorted(maps.Keys(quantities)) {
+		commands = append(commands, command(menuNumber, quantities[menuNumber]))
+	}
+	return commands
+}
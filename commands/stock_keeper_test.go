@@ -0,0 +1,143 @@
+package commands_test
+
+import (
+	"context"
+	"cqrseventsourcingbar/commands"
+	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/queries"
+	"cqrseventsourcingbar/shared"
+	"testing"
+
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	water       = shared.MenuItem{ID: 1, Description: "Water", Price: shared.Cents(150), IsDrink: true}
+	beer        = shared.MenuItem{ID: 7, Description: "Beer", Price: shared.Cents(300), IsDrink: true}
+	ginAndTonic = shared.MenuItem{ID: 6, Description: "gin and tonic", Price: shared.Cents(900), IsDrink: true, Recipe: []shared.RecipeIngredient{{Ingredient: "gin", Quantity: 50}, {Ingredient: "tonic", Quantity: 150}, {Ingredient: "lime", Quantity: 1}}}
+	negroni     = shared.MenuItem{ID: 8, Description: "negroni", Price: shared.Cents(1000), IsDrink: true, Recipe: []shared.RecipeIngredient{{Ingredient: "gin", Quantity: 30}, {Ingredient: "campari", Quantity: 30}, {Ingredient: "vermouth", Quantity: 30}}}
+)
+
+// startStockKeeper follows the event store from its first event, the way the services start the keeper.
+func startStockKeeper(t *testing.T, ctx context.Context, eventStore *events.InMemoryEventStore, inventoryDispatcher commands.CommandDispatcher) *commands.StockKeeper {
+	stockKeeper := commands.CreateStockKeeper(inventoryDispatcher)
+	projector := queries.CreateProjector(eventStore, stockKeeper, 100)
+	eventStore.Subscribe(projector)
+	assert.NoError(t, projector.CatchUp(ctx))
+	return stockKeeper
+}
+
+func createInventoryDispatcher(eventStore events.EventStore) commands.CommandDispatcher {
+	return commands.CreateInventoryDispatcher(
+		commands.CreateCommandDispatcher(eventStore, events.NoopEventEmitter{}, commands.StockAggregateFactory{}),
+		commands.CreateCommandDispatcher(eventStore, events.NoopEventEmitter{}, commands.IngredientAggregateFactory{}),
+	)
+}
+
+func TestStockFollowsTheDrinksOnTabs(t *testing.T) {
+	// Given
+	ctx := context.TODO()
+	eventStore := events.CreateInMemoryEventStore()
+	tabDispatcher := commands.CreateCommandDispatcher(eventStore, events.NoopEventEmitter{}, commands.TabAggregateFactory{})
+	inventoryDispatcher := createInventoryDispatcher(eventStore)
+	stockKeeper := startStockKeeper(t, ctx, eventStore, inventoryDispatcher)
+	stockChecker := commands.CreateEventSourcedStockChecker(eventStore, nil)
+	assert.NoError(t, inventoryDispatcher.DispatchCommand(ctx, commands.Restock{BaseCommand: commands.BaseCommand{ID: commands.StockID(1)}, MenuNumber: 1, Quantity: 5}))
+	tabId := ksuid.New()
+
+	// When
+	assert.NoError(t, tabDispatcher.DispatchCommand(ctx, commands.OpenTab{BaseCommand: commands.BaseCommand{ID: tabId}, TableNumber: 3, Waiter: "Charles"}))
+	assert.NoError(t, tabDispatcher.DispatchCommand(ctx, commands.PlaceOrder{BaseCommand: commands.BaseCommand{ID: tabId}, Items: []shared.MenuItem{water, water, water, beer}}))
+	assert.NoError(t, tabDispatcher.DispatchCommand(ctx, commands.MarkDrinksServed{BaseCommand: commands.BaseCommand{ID: tabId}, MenuNumbers: []int{1, 7}}))
+	assert.NoError(t, tabDispatcher.DispatchCommand(ctx, commands.CancelItems{BaseCommand: commands.BaseCommand{ID: tabId}, MenuNumbers: []int{1}, Reason: "spilled"}))
+	assert.NoError(t, stockKeeper.DispatchPending(ctx))
+
+	// Then
+	stockEvents, err := eventStore.LoadEvents(ctx, commands.StockID(1))
+	assert.NoError(t, err)
+	assert.Len(t, stockEvents, 4)
+	assert.IsType(t, events.StockReserved{}, stockEvents[1])
+	assert.IsType(t, events.StockConsumed{}, stockEvents[2])
+	assert.IsType(t, events.StockReleased{}, stockEvents[3])
+	assert.NoError(t, stockChecker.CheckStock(ctx, []shared.MenuItem{water, water, water}))
+	err = stockChecker.CheckStock(ctx, []shared.MenuItem{beer, water, water, water, water, water})
+	assert.Equal(t, &shared.InsufficientStockError{Shortages: []shared.StockShortage{
+		{MenuNumber: 1, Description: "Water", Requested: 5, Available: 3},
+	}}, err)
+	assert.EqualError(t, err, "not enough stock for menu items: 1")
+	beerEvents, err := eventStore.LoadEvents(ctx, commands.StockID(7))
+	assert.NoError(t, err)
+	assert.Empty(t, beerEvents)
+}
+
+func TestARestartedKeeperDoesNotChangeTheStockAgain(t *testing.T) {
+	// Given
+	ctx := context.TODO()
+	eventStore := events.CreateInMemoryEventStore()
+	tabDispatcher := commands.CreateCommandDispatcher(eventStore, events.NoopEventEmitter{}, commands.TabAggregateFactory{})
+	inventoryDispatcher := createInventoryDispatcher(eventStore)
+	stockKeeper := startStockKeeper(t, ctx, eventStore, inventoryDispatcher)
+	tabId := ksuid.New()
+	assert.NoError(t, tabDispatcher.DispatchCommand(ctx, commands.OpenTab{BaseCommand: commands.BaseCommand{ID: tabId}, TableNumber: 3, Waiter: "Charles"}))
+	assert.NoError(t, tabDispatcher.DispatchCommand(ctx, commands.PlaceOrder{BaseCommand: commands.BaseCommand{ID: tabId}, Items: []shared.MenuItem{water, water, ginAndTonic}}))
+	assert.NoError(t, tabDispatcher.DispatchCommand(ctx, commands.MarkDrinksServed{BaseCommand: commands.BaseCommand{ID: tabId}, MenuNumbers: []int{1, 1, 6}}))
+	assert.NoError(t, inventoryDispatcher.DispatchCommand(ctx, commands.Restock{BaseCommand: commands.BaseCommand{ID: commands.StockID(1)}, MenuNumber: 1, Quantity: 5}))
+	assert.NoError(t, inventoryDispatcher.DispatchCommand(ctx, commands.RestockIngredient{BaseCommand: commands.BaseCommand{ID: commands.IngredientID("gin")}, Ingredient: "gin", Quantity: 700, Unit: "ml"}))
+	assert.NoError(t, tabDispatcher.DispatchCommand(ctx, commands.PlaceOrder{BaseCommand: commands.BaseCommand{ID: tabId}, Items: []shared.MenuItem{water}}))
+	assert.NoError(t, stockKeeper.DispatchPending(ctx))
+
+	// When
+	restartedKeeper := startStockKeeper(t, ctx, eventStore, inventoryDispatcher)
+	assert.NoError(t, restartedKeeper.DispatchPending(ctx))
+
+	// Then
+	stockEvents, err := eventStore.LoadEvents(ctx, commands.StockID(1))
+	assert.NoError(t, err)
+	assert.Len(t, stockEvents, 2)
+	assert.IsType(t, events.StockReserved{}, stockEvents[1])
+	stockChecker := commands.CreateEventSourcedStockChecker(eventStore, nil)
+	assert.NoError(t, stockChecker.CheckStock(ctx, []shared.MenuItem{water, water, water, water}))
+	ginEvents, err := eventStore.LoadEvents(ctx, commands.IngredientID("gin"))
+	assert.NoError(t, err)
+	assert.Len(t, ginEvents, 1)
+}
+
+func TestServingCocktailsConsumesTheIngredientsOfTheirRecipeWhenOrdered(t *testing.T) {
+	// Given
+	ctx := context.TODO()
+	eventStore := events.CreateInMemoryEventStore()
+	menuDispatcher := commands.CreateCommandDispatcher(eventStore, events.NoopEventEmitter{}, commands.MenuItemAggregateFactory{})
+	tabDispatcher := commands.CreateCommandDispatcher(eventStore, events.NoopEventEmitter{}, commands.TabAggregateFactory{})
+	inventoryDispatcher := createInventoryDispatcher(eventStore)
+	stockKeeper := startStockKeeper(t, ctx, eventStore, inventoryDispatcher)
+	menuItemRepository := commands.CreateEventSourcedMenuItemRepository(eventStore)
+	assert.NoError(t, commands.ImportMenu(ctx, eventStore, menuDispatcher, []shared.MenuItem{ginAndTonic, negroni}))
+	assert.NoError(t, inventoryDispatcher.DispatchCommand(ctx, commands.RestockIngredient{BaseCommand: commands.BaseCommand{ID: commands.IngredientID("gin")}, Ingredient: "gin", Quantity: 1400, Unit: "ml", ContainerSize: 700}))
+	assert.NoError(t, inventoryDispatcher.DispatchCommand(ctx, commands.RestockIngredient{BaseCommand: commands.BaseCommand{ID: commands.IngredientID("tonic")}, Ingredient: "tonic", Quantity: 6000, Unit: "ml", ContainerSize: 200}))
+	orderedItems, err := menuItemRepository.ReadItems(ctx, []int{6, 6, 8})
+	assert.NoError(t, err)
+	tabId := ksuid.New()
+	assert.NoError(t, tabDispatcher.DispatchCommand(ctx, commands.OpenTab{BaseCommand: commands.BaseCommand{ID: tabId}, TableNumber: 3, Waiter: "Charles"}))
+	assert.NoError(t, tabDispatcher.DispatchCommand(ctx, commands.PlaceOrder{BaseCommand: commands.BaseCommand{ID: tabId}, Items: orderedItems}))
+	assert.NoError(t, menuDispatcher.DispatchCommand(ctx, commands.SetMenuItemRecipe{BaseCommand: commands.BaseCommand{ID: commands.MenuItemID(6)}, MenuNumber: 6, Recipe: []shared.RecipeIngredient{{Ingredient: "gin", Quantity: 60}}}))
+	assert.NoError(t, menuDispatcher.DispatchCommand(ctx, commands.RetireMenuItem{BaseCommand: commands.BaseCommand{ID: commands.MenuItemID(8)}, MenuNumber: 8}))
+
+	// When
+	assert.NoError(t, tabDispatcher.DispatchCommand(ctx, commands.MarkDrinksServed{BaseCommand: commands.BaseCommand{ID: tabId}, MenuNumbers: []int{6, 6, 8}}))
+	assert.NoError(t, stockKeeper.DispatchPending(ctx))
+
+	// Then
+	ginEvents, err := eventStore.LoadEvents(ctx, commands.IngredientID("gin"))
+	assert.NoError(t, err)
+	assert.Len(t, ginEvents, 2)
+	ginConsumed := ginEvents[1].(events.IngredientConsumed)
+	assert.Equal(t, 130, ginConsumed.Quantity)
+	assert.Equal(t, []int{6, 8}, ginConsumed.MenuNumbers)
+	tonicEvents, err := eventStore.LoadEvents(ctx, commands.IngredientID("tonic"))
+	assert.NoError(t, err)
+	assert.Equal(t, 300, tonicEvents[1].(events.IngredientConsumed).Quantity)
+	limeEvents, err := eventStore.LoadEvents(ctx, commands.IngredientID("lime"))
+	assert.NoError(t, err)
+	assert.Empty(t, limeEvents)
+}
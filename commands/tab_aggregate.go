@@ -3,22 +3,47 @@ package commands
 import (
 	"cqrseventsourcingbar/events"
 	"cqrseventsourcingbar/shared"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/thoas/go-funk"
 )
 
 type tabAggregate struct {
 	tabOpen           bool
+	waiter            string
 	outstandingDrinks []shared.MenuItem
-	servedItemsAmount float64
+	outstandingFood   []shared.MenuItem
+	preparedFood      []shared.MenuItem
+	servedItemsAmount shared.Money
+	// servedItems are the served items that can still be comped.
+	servedItems  []shared.MenuItem
+	compedAmount shared.Money
+	discounts    []shared.Discount
+	paidAmount   shared.Money
 }
 
 //go:generate mockery --name Aggregate
 type Aggregate interface {
 	HandleCommand(c Command) ([]events.Event, error)
 	ApplyEvent(e events.Event) error
+	Snapshot() ([]byte, error)
+	RestoreSnapshot(state []byte) error
+}
+
+type tabSnapshot struct {
+	TabOpen           bool              `json:"tab_open"`
+	Waiter            string            `json:"waiter,omitempty"`
+	OutstandingDrinks []shared.MenuItem `json:"outstanding_drinks"`
+	OutstandingFood   []shared.MenuItem `json:"outstanding_food"`
+	PreparedFood      []shared.MenuItem `json:"prepared_food"`
+	ServedItemsAmount shared.Money      `json:"served_items_amount"`
+	ServedItems       []shared.MenuItem `json:"served_items"`
+	CompedAmount      shared.Money      `json:"comped_amount"`
+	Discounts         []shared.Discount `json:"discounts"`
+	PaidAmount        shared.Money      `json:"paid_amount"`
 }
 
 func (t tabAggregate) HandleCommand(c Command) ([]events.Event, error) {
@@ -29,6 +54,18 @@ func (t tabAggregate) HandleCommand(c Command) ([]events.Event, error) {
 		return t.handleCommandPlaceOrder(command)
 	case MarkDrinksServed:
 		return t.handleCommandMarkDrinksServed(command)
+	case MarkFoodPrepared:
+		return t.handleCommandMarkFoodPrepared(command)
+	case MarkFoodServed:
+		return t.handleCommandMarkFoodServed(command)
+	case CancelItems:
+		return t.handleCommandCancelItems(command)
+	case ApplyDiscount:
+		return t.handleCommandApplyDiscount(command)
+	case CompItem:
+		return t.handleCommandCompItem(command)
+	case RecordPayment:
+		return t.handleCommandRecordPayment(command)
 	case CloseTab:
 		return t.handleCommandCloseTab(command)
 	default:
@@ -44,22 +81,95 @@ func (t *tabAggregate) ApplyEvent(e events.Event) error {
 		return t.applyDrinksOrdered(event)
 	case events.DrinksServed:
 		return t.applyDrinksServed(event)
+	case events.FoodOrdered:
+		return t.applyFoodOrdered(event)
+	case events.FoodPrepared:
+		return t.applyFoodPrepared(event)
+	case events.FoodServed:
+		return t.applyFoodServed(event)
+	case events.ItemsCancelled:
+		return t.applyItemsCancelled(event)
+	case events.DiscountApplied:
+		return t.applyDiscountApplied(event)
+	case events.ItemComped:
+		return t.applyItemComped(event)
+	case events.PaymentRecorded:
+		return t.applyPaymentRecorded(event)
 	case events.TabClosed:
 		return t.applyTabClosed(event)
+	case events.UnknownEvent:
+		return nil
 	default:
 		return fmt.Errorf("unexpected events.Event: %#v", e)
 	}
 }
 
+// DefaultActor is the waiter of the tab, who acts on it when a request doesn't say.
+func (t tabAggregate) DefaultActor() string {
+	return t.waiter
+}
+
+func (t *tabAggregate) Snapshot() ([]byte, error) {
+	return json.Marshal(tabSnapshot{
+		TabOpen:           t.tabOpen,
+		Waiter:            t.waiter,
+		OutstandingDrinks: t.outstandingDrinks,
+		OutstandingFood:   t.outstandingFood,
+		PreparedFood:      t.preparedFood,
+		ServedItemsAmount: t.servedItemsAmount,
+		ServedItems:       t.servedItems,
+		CompedAmount:      t.compedAmount,
+		Discounts:         t.discounts,
+		PaidAmount:        t.paidAmount,
+	})
+}
+
+func (t *tabAggregate) RestoreSnapshot(state []byte) error {
+	var snapshot tabSnapshot
+	if err := json.Unmarshal(state, &snapshot); err != nil {
+		return fmt.Errorf("could not restore tab aggregate from snapshot: %s", state)
+	}
+	t.tabOpen = snapshot.TabOpen
+	t.waiter = snapshot.Waiter
+	t.outstandingDrinks = nonNilItems(snapshot.OutstandingDrinks)
+	t.outstandingFood = nonNilItems(snapshot.OutstandingFood)
+	t.preparedFood = nonNilItems(snapshot.PreparedFood)
+	t.servedItemsAmount = snapshot.ServedItemsAmount
+	t.servedItems = nonNilItems(snapshot.ServedItems)
+	t.compedAmount = snapshot.CompedAmount
+	t.discounts = snapshot.Discounts
+	if t.discounts == nil {
+		t.discounts = []shared.Discount{}
+	}
+	t.paidAmount = snapshot.PaidAmount
+	return nil
+}
+
 func (t *tabAggregate) handleCommandOpenTab(c OpenTab) ([]events.Event, error) {
 	return []events.Event{events.TabOpened{BaseEvent: events.BaseEvent{ID: c.ID}, TableNumber: c.TableNumber, Waiter: c.Waiter}}, nil
 }
 
 func (t *tabAggregate) handleCommandPlaceOrder(c PlaceOrder) ([]events.Event, error) {
-	if t.tabOpen {
-		return []events.Event{events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: c.ID}, Items: c.Items}}, nil
+	if !t.tabOpen {
+		return nil, errors.New("tab is not opened")
 	}
-	return nil, errors.New("tab is not opened")
+	for _, item := range c.Items {
+		if err := t.servedItemsAmount.CheckCurrency(item.Price); err != nil {
+			return nil, fmt.Errorf("cannot order menu item %d, %w", item.ID, err)
+		}
+	}
+
+	drinks := funk.Filter(c.Items, func(item shared.MenuItem) bool { return item.IsDrink }).([]shared.MenuItem)
+	food := funk.Filter(c.Items, func(item shared.MenuItem) bool { return !item.IsDrink }).([]shared.MenuItem)
+
+	newEvents := []events.Event{}
+	if len(drinks) > 0 {
+		newEvents = append(newEvents, events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: c.ID}, Items: drinks})
+	}
+	if len(food) > 0 {
+		newEvents = append(newEvents, events.FoodOrdered{BaseEvent: events.BaseEvent{ID: c.ID}, Items: food})
+	}
+	return newEvents, nil
 }
 
 func (t *tabAggregate) handleCommandMarkDrinksServed(c MarkDrinksServed) ([]events.Event, error) {
@@ -71,24 +181,128 @@ func (t *tabAggregate) handleCommandMarkDrinksServed(c MarkDrinksServed) ([]even
 	return []events.Event{events.DrinksServed{BaseEvent: events.BaseEvent{ID: c.ID}, MenuNumbers: c.MenuNumbers}}, nil
 }
 
+func (t *tabAggregate) handleCommandMarkFoodPrepared(c MarkFoodPrepared) ([]events.Event, error) {
+	menuItemsThatAreNotInOrderedItems := FindMenuItemsThatAreNotInOrderedItems(t.outstandingFood, c.MenuNumbers)
+	if len(menuItemsThatAreNotInOrderedItems) > 0 {
+		return nil, fmt.Errorf("cannot prepare food that was not ordered: %v", menuItemsThatAreNotInOrderedItems)
+	}
+
+	return []events.Event{events.FoodPrepared{BaseEvent: events.BaseEvent{ID: c.ID}, MenuNumbers: c.MenuNumbers}}, nil
+}
+
+func (t *tabAggregate) handleCommandMarkFoodServed(c MarkFoodServed) ([]events.Event, error) {
+	menuItemsThatAreNotInPreparedItems := FindMenuItemsThatAreNotInOrderedItems(t.preparedFood, c.MenuNumbers)
+	if len(menuItemsThatAreNotInPreparedItems) > 0 {
+		return nil, fmt.Errorf("cannot serve food that was not prepared: %v", menuItemsThatAreNotInPreparedItems)
+	}
+
+	return []events.Event{events.FoodServed{BaseEvent: events.BaseEvent{ID: c.ID}, MenuNumbers: c.MenuNumbers}}, nil
+}
+
+// handleCommandCancelItems voids drinks that were ordered by mistake, only drinks not yet served can be cancelled.
+func (t *tabAggregate) handleCommandCancelItems(c CancelItems) ([]events.Event, error) {
+	if c.Reason == "" {
+		return nil, errors.New("a reason is required to cancel items")
+	}
+	menuItemsThatAreNotInOrderedItems := FindMenuItemsThatAreNotInOrderedItems(t.outstandingDrinks, c.MenuNumbers)
+	if len(menuItemsThatAreNotInOrderedItems) > 0 {
+		return nil, fmt.Errorf("cannot cancel items that are not waiting to be served: %v", menuItemsThatAreNotInOrderedItems)
+	}
+
+	return []events.Event{events.ItemsCancelled{BaseEvent: events.BaseEvent{ID: c.ID}, MenuNumbers: c.MenuNumbers, Reason: c.Reason}}, nil
+}
+
+func (t *tabAggregate) handleCommandApplyDiscount(c ApplyDiscount) ([]events.Event, error) {
+	if !t.tabOpen {
+		return nil, errors.New("tab is not opened")
+	}
+	if c.Reason == "" || c.AuthorisedBy == "" {
+		return nil, errors.New("a reason and an authorising user are required to apply a discount")
+	}
+	if c.Percentage < 0 || c.Percentage > 100 {
+		return nil, fmt.Errorf("a discount percentage must be between 0 and 100, got: %v%%", c.Percentage)
+	}
+	if c.Amount.Amount < 0 {
+		return nil, fmt.Errorf("a discount amount can't be negative, got: %v", c.Amount)
+	}
+	if (c.Percentage > 0) == c.Amount.IsPositive() {
+		return nil, errors.New("a discount needs either a percentage or an amount")
+	}
+	if err := t.servedItemsAmount.CheckCurrency(c.Amount); err != nil {
+		return nil, fmt.Errorf("cannot apply the discount, %w", err)
+	}
+	percentage := c.Percentage
+	for _, discount := range t.discounts {
+		percentage += discount.Percentage
+	}
+	if percentage > 100 {
+		return nil, fmt.Errorf("discounts cannot add up to more than 100%%, asked for: %v%%", percentage)
+	}
+
+	return []events.Event{events.DiscountApplied{BaseEvent: events.BaseEvent{ID: c.ID}, Percentage: c.Percentage, Amount: c.Amount, Reason: c.Reason, AuthorisedBy: c.AuthorisedBy}}, nil
+}
+
+func (t *tabAggregate) handleCommandCompItem(c CompItem) ([]events.Event, error) {
+	if !t.tabOpen {
+		return nil, errors.New("tab is not opened")
+	}
+	if c.Reason == "" || c.AuthorisedBy == "" {
+		return nil, errors.New("a reason and an authorising user are required to comp an item")
+	}
+	index := slices.IndexFunc(t.servedItems, func(item shared.MenuItem) bool { return item.ID == c.MenuNumber })
+	if index < 0 {
+		return nil, fmt.Errorf("cannot comp an item that was not served: %d", c.MenuNumber)
+	}
+	compedItem := t.servedItems[index]
+
+	return []events.Event{events.ItemComped{BaseEvent: events.BaseEvent{ID: c.ID}, MenuNumber: compedItem.ID, Description: compedItem.Description, Amount: compedItem.Price, Reason: c.Reason, AuthorisedBy: c.AuthorisedBy}}, nil
+}
+
+func (t *tabAggregate) handleCommandRecordPayment(c RecordPayment) ([]events.Event, error) {
+	if !t.tabOpen {
+		return nil, errors.New("tab is not opened")
+	}
+	if c.Method == "" {
+		return nil, errors.New("a payment method is required")
+	}
+	if !c.Amount.IsPositive() {
+		return nil, fmt.Errorf("a payment must be for a positive amount, got: %v", c.Amount)
+	}
+	if err := t.servedItemsAmount.CheckCurrency(c.Amount); err != nil {
+		return nil, fmt.Errorf("cannot record the payment, %w", err)
+	}
+
+	return []events.Event{events.PaymentRecorded{BaseEvent: events.BaseEvent{ID: c.ID}, Method: c.Method, Amount: c.Amount, Payer: c.Payer, Station: c.Station, Shift: c.Shift}}, nil
+}
+
+// netAmount is what is left to pay for the served items once the comps and the discounts are taken off.
+func (t *tabAggregate) netAmount() shared.Money {
+	return shared.ApplyDiscounts(t.servedItemsAmount.Sub(t.compedAmount), t.discounts)
+}
+
 func (t *tabAggregate) handleCommandCloseTab(c CloseTab) ([]events.Event, error) {
-	servedItemsAmount := t.servedItemsAmount
+	servedItemsAmount := t.netAmount()
 	if !t.tabOpen {
 		return nil, errors.New("cannot close a tab that is not open")
 	}
-	if len(t.outstandingDrinks) > 0 {
+	if len(t.outstandingDrinks) > 0 || len(t.outstandingFood) > 0 || len(t.preparedFood) > 0 {
 		return nil, errors.New("cannot close a tab with unserved items")
 	}
-	if c.AmountPaid < servedItemsAmount {
-		return nil, fmt.Errorf("not enough to cover tab, total served cost is: %v, but paid: %v", servedItemsAmount, c.AmountPaid)
+	if err := t.servedItemsAmount.CheckCurrency(c.AmountPaid); err != nil {
+		return nil, fmt.Errorf("cannot close the tab, %w", err)
 	}
-	return []events.Event{events.TabClosed{BaseEvent: events.BaseEvent{ID: c.ID}, AmountPaid: c.AmountPaid, OrderAmount: servedItemsAmount, Tip: c.AmountPaid - servedItemsAmount}},
+	amountPaid := t.paidAmount.Add(c.AmountPaid)
+	if amountPaid.LessThan(servedItemsAmount) {
+		return nil, fmt.Errorf("not enough to cover tab, total served cost is: %v, but paid: %v", servedItemsAmount, amountPaid)
+	}
+	return []events.Event{events.TabClosed{BaseEvent: events.BaseEvent{ID: c.ID}, AmountPaid: amountPaid, OrderAmount: servedItemsAmount, Tip: amountPaid.Sub(servedItemsAmount), Station: c.Station, Shift: c.Shift}},
 		nil
 
 }
 
-func (t *tabAggregate) applyTabOpened(_ events.TabOpened) error {
+func (t *tabAggregate) applyTabOpened(e events.TabOpened) error {
 	t.tabOpen = true
+	t.waiter = e.Waiter
 	return nil
 }
 
@@ -103,7 +317,8 @@ func (t *tabAggregate) applyDrinksServed(e events.DrinksServed) error {
 		if found != nil {
 			if itemFound, ok := found.(shared.MenuItem); ok {
 				t.outstandingDrinks = deleteFirstMatch(t.outstandingDrinks, itemFound.ID)
-				t.servedItemsAmount += itemFound.Price
+				t.servedItemsAmount = t.servedItemsAmount.Add(itemFound.Price)
+				t.servedItems = append(t.servedItems, itemFound)
 			}
 
 		}
@@ -112,6 +327,64 @@ func (t *tabAggregate) applyDrinksServed(e events.DrinksServed) error {
 	return nil
 }
 
+func (t *tabAggregate) applyFoodOrdered(e events.FoodOrdered) error {
+	t.outstandingFood = append(t.outstandingFood, e.Items...)
+	return nil
+}
+
+func (t *tabAggregate) applyFoodPrepared(e events.FoodPrepared) error {
+	for _, menuNumber := range e.MenuNumbers {
+		found := funk.Find(t.outstandingFood, func(item shared.MenuItem) bool { return item.ID == menuNumber })
+		if itemFound, ok := found.(shared.MenuItem); ok {
+			t.outstandingFood = deleteFirstMatch(t.outstandingFood, itemFound.ID)
+			t.preparedFood = append(t.preparedFood, itemFound)
+		}
+	}
+	return nil
+}
+
+func (t *tabAggregate) applyFoodServed(e events.FoodServed) error {
+	for _, menuNumber := range e.MenuNumbers {
+		found := funk.Find(t.preparedFood, func(item shared.MenuItem) bool { return item.ID == menuNumber })
+		if itemFound, ok := found.(shared.MenuItem); ok {
+			t.preparedFood = deleteFirstMatch(t.preparedFood, itemFound.ID)
+			t.servedItemsAmount = t.servedItemsAmount.Add(itemFound.Price)
+			t.servedItems = append(t.servedItems, itemFound)
+		}
+	}
+	return nil
+}
+
+func (t *tabAggregate) applyItemsCancelled(e events.ItemsCancelled) error {
+	for _, menuNumber := range e.MenuNumbers {
+		t.outstandingDrinks = deleteFirstMatch(t.outstandingDrinks, menuNumber)
+	}
+	return nil
+}
+
+func (t *tabAggregate) applyDiscountApplied(e events.DiscountApplied) error {
+	t.discounts = append(t.discounts, shared.Discount{Percentage: e.Percentage, Amount: e.Amount})
+	return nil
+}
+
+func (t *tabAggregate) applyItemComped(e events.ItemComped) error {
+	t.servedItems = deleteFirstMatch(t.servedItems, e.MenuNumber)
+	t.compedAmount = t.compedAmount.Add(e.Amount)
+	return nil
+}
+
+func (t *tabAggregate) applyPaymentRecorded(e events.PaymentRecorded) error {
+	t.paidAmount = t.paidAmount.Add(e.Amount)
+	return nil
+}
+
+func nonNilItems(items []shared.MenuItem) []shared.MenuItem {
+	if items == nil {
+		return []shared.MenuItem{}
+	}
+	return items
+}
+
 func deleteFirstMatch(slice []shared.MenuItem, target int) []shared.MenuItem {
 	for i, val := range slice {
 		if val.ID == target {
@@ -138,6 +411,12 @@ func (t TabAggregateFactory) CreateAggregate() Aggregate {
 	return &tabAggregate{
 		tabOpen:           false,
 		outstandingDrinks: []shared.MenuItem{},
-		servedItemsAmount: 0,
+		outstandingFood:   []shared.MenuItem{},
+		preparedFood:      []shared.MenuItem{},
+		servedItemsAmount: shared.Cents(0),
+		servedItems:       []shared.MenuItem{},
+		compedAmount:      shared.Cents(0),
+		discounts:         []shared.Discount{},
+		paidAmount:        shared.Cents(0),
 	}
 }
@@ -52,7 +52,7 @@ func (suite *TabAggregateTestSuite) TestCanNotOrderWithUnOpenedTab() {
 	// When
 	newEvents, err := suite.tabAggregate.HandleCommand(commands.PlaceOrder{
 		BaseCommand: commands.BaseCommand{ID: commandID},
-		Items:       []shared.MenuItem{{ID: 11, Description: "beer", Price: 1.5}},
+		Items:       []shared.MenuItem{{ID: 11, Description: "beer", Price: shared.Cents(150), IsDrink: true}},
 	})
 
 	// Then
@@ -73,14 +73,14 @@ func (suite *TabAggregateTestSuite) TestCanOrderWhenTabIsOpen() {
 	// When
 	newEvents, err := suite.tabAggregate.HandleCommand(commands.PlaceOrder{
 		BaseCommand: commands.BaseCommand{ID: placeOrderCommandID},
-		Items:       []shared.MenuItem{{ID: 11, Description: "beer", Price: 1.5}},
+		Items:       []shared.MenuItem{{ID: 11, Description: "beer", Price: shared.Cents(150), IsDrink: true}},
 	})
 
 	// Then
 	assert.NoError(t, err)
 	assert.Equal(t, []events.Event{events.DrinksOrdered{
 		BaseEvent: events.BaseEvent{ID: placeOrderCommandID},
-		Items:     []shared.MenuItem{{ID: 11, Description: "beer", Price: 1.5}},
+		Items:     []shared.MenuItem{{ID: 11, Description: "beer", Price: shared.Cents(150), IsDrink: true}},
 	}}, newEvents)
 }
 
@@ -93,8 +93,8 @@ func (suite *TabAggregateTestSuite) TestOrderedDrinksCanBeServed() {
 	// Given
 	_ = suite.tabAggregate.ApplyEvent(events.TabOpened{BaseEvent: events.BaseEvent{ID: tabOpenedEventID}, Waiter: "waiter_1", TableNumber: 0})
 	_ = suite.tabAggregate.ApplyEvent(events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: drinksOrderedEventID}, Items: []shared.MenuItem{
-		{ID: 11, Description: "beer", Price: 1.5},
-		{ID: 12, Description: "water", Price: 1.0},
+		{ID: 11, Description: "beer", Price: shared.Cents(150)},
+		{ID: 12, Description: "water", Price: shared.Cents(100)},
 	}})
 
 	// When
@@ -120,8 +120,8 @@ func (suite *TabAggregateTestSuite) TestCannotServeUnorderedDrinks() {
 	// Given
 	_ = suite.tabAggregate.ApplyEvent(events.TabOpened{BaseEvent: events.BaseEvent{ID: tabOpenedEventID}, Waiter: "waiter_1", TableNumber: 0})
 	_ = suite.tabAggregate.ApplyEvent(events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: drinksOrderedEventID}, Items: []shared.MenuItem{
-		{ID: 11, Description: "beer", Price: 1.5},
-		{ID: 12, Description: "water", Price: 1.0},
+		{ID: 11, Description: "beer", Price: shared.Cents(150)},
+		{ID: 12, Description: "water", Price: shared.Cents(100)},
 	}})
 
 	// When
@@ -146,7 +146,7 @@ func (suite *TabAggregateTestSuite) TestCannotServeTheSameOrderedDrinkTwice() {
 	// Given
 	_ = suite.tabAggregate.ApplyEvent(events.TabOpened{BaseEvent: events.BaseEvent{ID: tabOpenedEventID}, Waiter: "waiter_1", TableNumber: 0})
 	_ = suite.tabAggregate.ApplyEvent(events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: drinksOrderedEventID}, Items: []shared.MenuItem{
-		{ID: 11, Description: "beer", Price: 1.5},
+		{ID: 11, Description: "beer", Price: shared.Cents(150)},
 	}})
 	_ = suite.tabAggregate.ApplyEvent(events.DrinksServed{BaseEvent: events.BaseEvent{ID: drinksServedID}, MenuNumbers: []int{11}})
 
@@ -162,6 +162,489 @@ func (suite *TabAggregateTestSuite) TestCannotServeTheSameOrderedDrinkTwice() {
 	assert.Empty(t, newEvents)
 }
 
+func (suite *TabAggregateTestSuite) TestOrderIsSplitIntoDrinksAndFood() {
+	tabOpenedEventID, _ := ksuid.NewRandom()
+	placeOrderCommandID, _ := ksuid.NewRandom()
+	t := suite.T()
+
+	// Given
+	_ = suite.tabAggregate.ApplyEvent(events.TabOpened{BaseEvent: events.BaseEvent{ID: tabOpenedEventID}, Waiter: "waiter_1", TableNumber: 0})
+
+	// When
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.PlaceOrder{
+		BaseCommand: commands.BaseCommand{ID: placeOrderCommandID},
+		Items: []shared.MenuItem{
+			{ID: 11, Description: "beer", Price: shared.Cents(150), IsDrink: true},
+			{ID: 21, Description: "burger", Price: shared.Cents(800)},
+		},
+	})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []events.Event{
+		events.DrinksOrdered{
+			BaseEvent: events.BaseEvent{ID: placeOrderCommandID},
+			Items:     []shared.MenuItem{{ID: 11, Description: "beer", Price: shared.Cents(150), IsDrink: true}},
+		},
+		events.FoodOrdered{
+			BaseEvent: events.BaseEvent{ID: placeOrderCommandID},
+			Items:     []shared.MenuItem{{ID: 21, Description: "burger", Price: shared.Cents(800)}},
+		},
+	}, newEvents)
+}
+
+func (suite *TabAggregateTestSuite) TestOrderedFoodCanBePrepared() {
+	tabOpenedEventID, _ := ksuid.NewRandom()
+	foodOrderedEventID, _ := ksuid.NewRandom()
+	markFoodPreparedID, _ := ksuid.NewRandom()
+	t := suite.T()
+
+	// Given
+	_ = suite.tabAggregate.ApplyEvent(events.TabOpened{BaseEvent: events.BaseEvent{ID: tabOpenedEventID}, Waiter: "waiter_1", TableNumber: 0})
+	_ = suite.tabAggregate.ApplyEvent(events.FoodOrdered{BaseEvent: events.BaseEvent{ID: foodOrderedEventID}, Items: []shared.MenuItem{
+		{ID: 21, Description: "burger", Price: shared.Cents(800)},
+	}})
+
+	// When
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.MarkFoodPrepared{
+		BaseCommand: commands.BaseCommand{ID: markFoodPreparedID},
+		MenuNumbers: []int{21},
+	})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []events.Event{events.FoodPrepared{
+		BaseEvent:   events.BaseEvent{ID: markFoodPreparedID},
+		MenuNumbers: []int{21},
+	}}, newEvents)
+}
+
+func (suite *TabAggregateTestSuite) TestCannotPrepareUnorderedFood() {
+	tabOpenedEventID, _ := ksuid.NewRandom()
+	markFoodPreparedID, _ := ksuid.NewRandom()
+	t := suite.T()
+
+	// Given
+	_ = suite.tabAggregate.ApplyEvent(events.TabOpened{BaseEvent: events.BaseEvent{ID: tabOpenedEventID}, Waiter: "waiter_1", TableNumber: 0})
+
+	// When
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.MarkFoodPrepared{
+		BaseCommand: commands.BaseCommand{ID: markFoodPreparedID},
+		MenuNumbers: []int{21},
+	})
+
+	// Then
+	assert.Error(t, err)
+	assert.Equal(t, "cannot prepare food that was not ordered: [21]", err.Error())
+	assert.Empty(t, newEvents)
+}
+
+func (suite *TabAggregateTestSuite) TestCannotServeFoodBeforeItIsPrepared() {
+	tabOpenedEventID, _ := ksuid.NewRandom()
+	foodOrderedEventID, _ := ksuid.NewRandom()
+	markFoodServedID, _ := ksuid.NewRandom()
+	t := suite.T()
+
+	// Given
+	_ = suite.tabAggregate.ApplyEvent(events.TabOpened{BaseEvent: events.BaseEvent{ID: tabOpenedEventID}, Waiter: "waiter_1", TableNumber: 0})
+	_ = suite.tabAggregate.ApplyEvent(events.FoodOrdered{BaseEvent: events.BaseEvent{ID: foodOrderedEventID}, Items: []shared.MenuItem{
+		{ID: 21, Description: "burger", Price: shared.Cents(800)},
+	}})
+
+	// When
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.MarkFoodServed{
+		BaseCommand: commands.BaseCommand{ID: markFoodServedID},
+		MenuNumbers: []int{21},
+	})
+
+	// Then
+	assert.Error(t, err)
+	assert.Equal(t, "cannot serve food that was not prepared: [21]", err.Error())
+	assert.Empty(t, newEvents)
+}
+
+func (suite *TabAggregateTestSuite) TestPreparedFoodCanBeServedAndPaidFor() {
+	tabOpenedEventID, _ := ksuid.NewRandom()
+	foodOrderedEventID, _ := ksuid.NewRandom()
+	foodPreparedEventID, _ := ksuid.NewRandom()
+	markFoodServedID, _ := ksuid.NewRandom()
+	closeTabID, _ := ksuid.NewRandom()
+	t := suite.T()
+
+	// Given
+	_ = suite.tabAggregate.ApplyEvent(events.TabOpened{BaseEvent: events.BaseEvent{ID: tabOpenedEventID}, Waiter: "waiter_1", TableNumber: 0})
+	_ = suite.tabAggregate.ApplyEvent(events.FoodOrdered{BaseEvent: events.BaseEvent{ID: foodOrderedEventID}, Items: []shared.MenuItem{
+		{ID: 21, Description: "burger", Price: shared.Cents(800)},
+	}})
+	_ = suite.tabAggregate.ApplyEvent(events.FoodPrepared{BaseEvent: events.BaseEvent{ID: foodPreparedEventID}, MenuNumbers: []int{21}})
+
+	// When
+	_, err := suite.tabAggregate.HandleCommand(commands.CloseTab{BaseCommand: commands.BaseCommand{ID: closeTabID}, AmountPaid: shared.Cents(800)})
+	assert.Equal(t, "cannot close a tab with unserved items", err.Error())
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.MarkFoodServed{
+		BaseCommand: commands.BaseCommand{ID: markFoodServedID},
+		MenuNumbers: []int{21},
+	})
+	assert.NoError(t, err)
+	_ = suite.tabAggregate.ApplyEvent(newEvents[0])
+	closeEvents, err := suite.tabAggregate.HandleCommand(commands.CloseTab{BaseCommand: commands.BaseCommand{ID: closeTabID}, AmountPaid: shared.Cents(800)})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []events.Event{events.FoodServed{
+		BaseEvent:   events.BaseEvent{ID: markFoodServedID},
+		MenuNumbers: []int{21},
+	}}, newEvents)
+	assert.Equal(t, []events.Event{events.TabClosed{
+		BaseEvent:   events.BaseEvent{ID: closeTabID},
+		AmountPaid:  shared.Cents(800),
+		OrderAmount: shared.Cents(800),
+		Tip:         shared.Cents(0),
+	}}, closeEvents)
+}
+
+func (suite *TabAggregateTestSuite) TestUnservedDrinksCanBeCancelled() {
+	tabID := ksuid.New()
+	t := suite.T()
+
+	// Given
+	_ = suite.tabAggregate.ApplyEvent(events.TabOpened{BaseEvent: events.BaseEvent{ID: tabID}, Waiter: "waiter_1", TableNumber: 0})
+	_ = suite.tabAggregate.ApplyEvent(events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: tabID}, Items: []shared.MenuItem{
+		{ID: 11, Description: "beer", Price: shared.Cents(150), IsDrink: true},
+		{ID: 12, Description: "water", Price: shared.Cents(100), IsDrink: true},
+	}})
+
+	// When
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.CancelItems{
+		BaseCommand: commands.BaseCommand{ID: tabID},
+		MenuNumbers: []int{12},
+		Reason:      "ordered by mistake",
+	})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []events.Event{events.ItemsCancelled{
+		BaseEvent:   events.BaseEvent{ID: tabID},
+		MenuNumbers: []int{12},
+		Reason:      "ordered by mistake",
+	}}, newEvents)
+}
+
+func (suite *TabAggregateTestSuite) TestCannotCancelServedDrinks() {
+	tabID := ksuid.New()
+	t := suite.T()
+
+	// Given
+	_ = suite.tabAggregate.ApplyEvent(events.TabOpened{BaseEvent: events.BaseEvent{ID: tabID}, Waiter: "waiter_1", TableNumber: 0})
+	_ = suite.tabAggregate.ApplyEvent(events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: tabID}, Items: []shared.MenuItem{
+		{ID: 11, Description: "beer", Price: shared.Cents(150), IsDrink: true},
+	}})
+	_ = suite.tabAggregate.ApplyEvent(events.DrinksServed{BaseEvent: events.BaseEvent{ID: tabID}, MenuNumbers: []int{11}})
+
+	// When
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.CancelItems{
+		BaseCommand: commands.BaseCommand{ID: tabID},
+		MenuNumbers: []int{11},
+		Reason:      "ordered by mistake",
+	})
+
+	// Then
+	assert.EqualError(t, err, "cannot cancel items that are not waiting to be served: [11]")
+	assert.Empty(t, newEvents)
+}
+
+func (suite *TabAggregateTestSuite) TestCannotCancelItemsWithoutAReason() {
+	tabID := ksuid.New()
+	t := suite.T()
+
+	// Given
+	_ = suite.tabAggregate.ApplyEvent(events.TabOpened{BaseEvent: events.BaseEvent{ID: tabID}, Waiter: "waiter_1", TableNumber: 0})
+	_ = suite.tabAggregate.ApplyEvent(events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: tabID}, Items: []shared.MenuItem{
+		{ID: 11, Description: "beer", Price: shared.Cents(150), IsDrink: true},
+	}})
+
+	// When
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.CancelItems{
+		BaseCommand: commands.BaseCommand{ID: tabID},
+		MenuNumbers: []int{11},
+	})
+
+	// Then
+	assert.EqualError(t, err, "a reason is required to cancel items")
+	assert.Empty(t, newEvents)
+}
+
+func (suite *TabAggregateTestSuite) TestTabCanBeClosedAfterCancellingUnservedDrinks() {
+	tabID := ksuid.New()
+	t := suite.T()
+
+	// Given
+	_ = suite.tabAggregate.ApplyEvent(events.TabOpened{BaseEvent: events.BaseEvent{ID: tabID}, Waiter: "waiter_1", TableNumber: 0})
+	_ = suite.tabAggregate.ApplyEvent(events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: tabID}, Items: []shared.MenuItem{
+		{ID: 11, Description: "beer", Price: shared.Cents(150), IsDrink: true},
+		{ID: 12, Description: "water", Price: shared.Cents(100), IsDrink: true},
+	}})
+	_ = suite.tabAggregate.ApplyEvent(events.DrinksServed{BaseEvent: events.BaseEvent{ID: tabID}, MenuNumbers: []int{11}})
+	_ = suite.tabAggregate.ApplyEvent(events.ItemsCancelled{BaseEvent: events.BaseEvent{ID: tabID}, MenuNumbers: []int{12}, Reason: "customer left"})
+
+	// When
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.CloseTab{BaseCommand: commands.BaseCommand{ID: tabID}, AmountPaid: shared.Cents(150)})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []events.Event{events.TabClosed{BaseEvent: events.BaseEvent{ID: tabID}, AmountPaid: shared.Cents(150), OrderAmount: shared.Cents(150), Tip: shared.Cents(0)}}, newEvents)
+}
+
+func (suite *TabAggregateTestSuite) givenServedDrinks(tabID ksuid.KSUID, items ...shared.MenuItem) {
+	menuNumbers := []int{}
+	for _, item := range items {
+		menuNumbers = append(menuNumbers, item.ID)
+	}
+	_ = suite.tabAggregate.ApplyEvent(events.TabOpened{BaseEvent: events.BaseEvent{ID: tabID}, Waiter: "waiter_1", TableNumber: 0})
+	_ = suite.tabAggregate.ApplyEvent(events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: tabID}, Items: items})
+	_ = suite.tabAggregate.ApplyEvent(events.DrinksServed{BaseEvent: events.BaseEvent{ID: tabID}, MenuNumbers: menuNumbers})
+}
+
+func (suite *TabAggregateTestSuite) TestServedItemCanBeComped() {
+	tabID := ksuid.New()
+	t := suite.T()
+
+	// Given
+	suite.givenServedDrinks(tabID, shared.MenuItem{ID: 11, Description: "beer", Price: shared.Cents(150), IsDrink: true})
+
+	// When
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.CompItem{BaseCommand: commands.BaseCommand{ID: tabID}, MenuNumber: 11, Reason: "spilled", AuthorisedBy: "manager"})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []events.Event{events.ItemComped{
+		BaseEvent:    events.BaseEvent{ID: tabID},
+		MenuNumber:   11,
+		Description:  "beer",
+		Amount:       shared.Cents(150),
+		Reason:       "spilled",
+		AuthorisedBy: "manager",
+	}}, newEvents)
+}
+
+func (suite *TabAggregateTestSuite) TestCannotCompTheSameServedItemTwice() {
+	tabID := ksuid.New()
+	t := suite.T()
+
+	// Given
+	suite.givenServedDrinks(tabID, shared.MenuItem{ID: 11, Description: "beer", Price: shared.Cents(150), IsDrink: true})
+	_ = suite.tabAggregate.ApplyEvent(events.ItemComped{BaseEvent: events.BaseEvent{ID: tabID}, MenuNumber: 11, Amount: shared.Cents(150), Reason: "spilled", AuthorisedBy: "manager"})
+
+	// When
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.CompItem{BaseCommand: commands.BaseCommand{ID: tabID}, MenuNumber: 11, Reason: "spilled", AuthorisedBy: "manager"})
+
+	// Then
+	assert.EqualError(t, err, "cannot comp an item that was not served: 11")
+	assert.Empty(t, newEvents)
+}
+
+func (suite *TabAggregateTestSuite) TestCannotApplyADiscountWithoutAnAuthorisingUser() {
+	tabID := ksuid.New()
+	t := suite.T()
+
+	// Given
+	suite.givenServedDrinks(tabID, shared.MenuItem{ID: 11, Description: "beer", Price: shared.Cents(150), IsDrink: true})
+
+	// When
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.ApplyDiscount{BaseCommand: commands.BaseCommand{ID: tabID}, Percentage: 10, Reason: "regular"})
+
+	// Then
+	assert.EqualError(t, err, "a reason and an authorising user are required to apply a discount")
+	assert.Empty(t, newEvents)
+}
+
+func (suite *TabAggregateTestSuite) TestCannotApplyADiscountWithBothAPercentageAndAnAmount() {
+	tabID := ksuid.New()
+	t := suite.T()
+
+	// Given
+	suite.givenServedDrinks(tabID, shared.MenuItem{ID: 11, Description: "beer", Price: shared.Cents(150), IsDrink: true})
+
+	// When
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.ApplyDiscount{BaseCommand: commands.BaseCommand{ID: tabID}, Percentage: 10, Amount: shared.Cents(100), Reason: "regular", AuthorisedBy: "manager"})
+
+	// Then
+	assert.EqualError(t, err, "a discount needs either a percentage or an amount")
+	assert.Empty(t, newEvents)
+}
+
+func (suite *TabAggregateTestSuite) TestCannotApplyADiscountWithAPercentageOutOfRange() {
+	tabID := ksuid.New()
+	t := suite.T()
+
+	// Given
+	suite.givenServedDrinks(tabID, shared.MenuItem{ID: 11, Description: "beer", Price: shared.Cents(150), IsDrink: true})
+
+	// When
+	negative, negativeErr := suite.tabAggregate.HandleCommand(commands.ApplyDiscount{BaseCommand: commands.BaseCommand{ID: tabID}, Percentage: -10, Amount: shared.Cents(100), Reason: "regular", AuthorisedBy: "manager"})
+	tooHigh, tooHighErr := suite.tabAggregate.HandleCommand(commands.ApplyDiscount{BaseCommand: commands.BaseCommand{ID: tabID}, Percentage: 120, Reason: "regular", AuthorisedBy: "manager"})
+
+	// Then
+	assert.EqualError(t, negativeErr, "a discount percentage must be between 0 and 100, got: -10%")
+	assert.Empty(t, negative)
+	assert.EqualError(t, tooHighErr, "a discount percentage must be between 0 and 100, got: 120%")
+	assert.Empty(t, tooHigh)
+}
+
+func (suite *TabAggregateTestSuite) TestCannotApplyADiscountWithANegativeAmount() {
+	tabID := ksuid.New()
+	t := suite.T()
+
+	// Given
+	suite.givenServedDrinks(tabID, shared.MenuItem{ID: 11, Description: "beer", Price: shared.Cents(150), IsDrink: true})
+
+	// When
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.ApplyDiscount{BaseCommand: commands.BaseCommand{ID: tabID}, Percentage: 10, Amount: shared.Cents(-100), Reason: "regular", AuthorisedBy: "manager"})
+
+	// Then
+	assert.EqualError(t, err, "a discount amount can't be negative, got: -1.00 EUR")
+	assert.Empty(t, newEvents)
+}
+
+func (suite *TabAggregateTestSuite) TestCannotDiscountMoreThanTheWholeTab() {
+	tabID := ksuid.New()
+	t := suite.T()
+
+	// Given
+	suite.givenServedDrinks(tabID, shared.MenuItem{ID: 11, Description: "beer", Price: shared.Cents(150), IsDrink: true})
+	_ = suite.tabAggregate.ApplyEvent(events.DiscountApplied{BaseEvent: events.BaseEvent{ID: tabID}, Percentage: 80, Reason: "regular", AuthorisedBy: "manager"})
+
+	// When
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.ApplyDiscount{BaseCommand: commands.BaseCommand{ID: tabID}, Percentage: 30, Reason: "birthday", AuthorisedBy: "manager"})
+
+	// Then
+	assert.EqualError(t, err, "discounts cannot add up to more than 100%, asked for: 110%")
+	assert.Empty(t, newEvents)
+}
+
+func (suite *TabAggregateTestSuite) TestClosingATabChargesTheNetAmount() {
+	tabID := ksuid.New()
+	t := suite.T()
+
+	// Given
+	suite.givenServedDrinks(tabID,
+		shared.MenuItem{ID: 11, Description: "beer", Price: shared.Cents(400), IsDrink: true},
+		shared.MenuItem{ID: 12, Description: "wine", Price: shared.Cents(800), IsDrink: true},
+		shared.MenuItem{ID: 13, Description: "water", Price: shared.Cents(200), IsDrink: true},
+	)
+	_ = suite.tabAggregate.ApplyEvent(events.ItemComped{BaseEvent: events.BaseEvent{ID: tabID}, MenuNumber: 13, Amount: shared.Cents(200), Reason: "spilled", AuthorisedBy: "manager"})
+	_ = suite.tabAggregate.ApplyEvent(events.DiscountApplied{BaseEvent: events.BaseEvent{ID: tabID}, Percentage: 25, Reason: "regular", AuthorisedBy: "manager"})
+	_ = suite.tabAggregate.ApplyEvent(events.DiscountApplied{BaseEvent: events.BaseEvent{ID: tabID}, Amount: shared.Cents(100), Reason: "birthday", AuthorisedBy: "manager"})
+
+	// When
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.CloseTab{BaseCommand: commands.BaseCommand{ID: tabID}, AmountPaid: shared.Cents(1000)})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []events.Event{events.TabClosed{BaseEvent: events.BaseEvent{ID: tabID}, AmountPaid: shared.Cents(1000), OrderAmount: shared.Cents(800), Tip: shared.Cents(200)}}, newEvents)
+}
+
+func (suite *TabAggregateTestSuite) TestPaymentsCanBeRecordedWhileTheTabIsOpen() {
+	tabID := ksuid.New()
+	t := suite.T()
+
+	// Given
+	suite.givenServedDrinks(tabID, shared.MenuItem{ID: 11, Description: "beer", Price: shared.Cents(400), IsDrink: true})
+
+	// When
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.RecordPayment{BaseCommand: commands.BaseCommand{ID: tabID}, Method: "card", Amount: shared.Cents(200), Payer: "Alice"})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []events.Event{events.PaymentRecorded{BaseEvent: events.BaseEvent{ID: tabID}, Method: "card", Amount: shared.Cents(200), Payer: "Alice"}}, newEvents)
+}
+
+func (suite *TabAggregateTestSuite) TestCannotRecordAPaymentWithoutAPositiveAmount() {
+	tabID := ksuid.New()
+	t := suite.T()
+
+	// Given
+	suite.givenServedDrinks(tabID, shared.MenuItem{ID: 11, Description: "beer", Price: shared.Cents(400), IsDrink: true})
+
+	// When
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.RecordPayment{BaseCommand: commands.BaseCommand{ID: tabID}, Method: "cash", Amount: shared.Cents(0), Payer: "Alice"})
+
+	// Then
+	assert.EqualError(t, err, "a payment must be for a positive amount, got: 0.00 EUR")
+	assert.Empty(t, newEvents)
+}
+
+func (suite *TabAggregateTestSuite) TestCannotRecordAPaymentInAnotherCurrency() {
+	tabID := ksuid.New()
+	t := suite.T()
+
+	// Given
+	suite.givenServedDrinks(tabID, shared.MenuItem{ID: 11, Description: "beer", Price: shared.Cents(400), IsDrink: true})
+
+	// When
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.RecordPayment{BaseCommand: commands.BaseCommand{ID: tabID}, Method: "cash", Amount: shared.NewMoney(400, "GBP"), Payer: "Alice"})
+
+	// Then
+	var currencyMismatch *shared.CurrencyMismatchError
+	assert.ErrorAs(t, err, &currencyMismatch)
+	assert.EqualError(t, err, "cannot record the payment, cannot combine amounts in EUR and GBP")
+	assert.Empty(t, newEvents)
+}
+
+func (suite *TabAggregateTestSuite) TestCannotOrderItemsPricedInAnotherCurrency() {
+	tabID := ksuid.New()
+	t := suite.T()
+
+	// Given
+	suite.givenServedDrinks(tabID, shared.MenuItem{ID: 11, Description: "beer", Price: shared.Cents(400), IsDrink: true})
+
+	// When
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.PlaceOrder{BaseCommand: commands.BaseCommand{ID: tabID}, Items: []shared.MenuItem{{ID: 12, Description: "ale", Price: shared.NewMoney(500, "GBP"), IsDrink: true}}})
+
+	// Then
+	assert.EqualError(t, err, "cannot order menu item 12, cannot combine amounts in EUR and GBP")
+	assert.Empty(t, newEvents)
+}
+
+func (suite *TabAggregateTestSuite) TestCannotCloseATabUntilPaymentsCoverIt() {
+	tabID := ksuid.New()
+	t := suite.T()
+
+	// Given
+	suite.givenServedDrinks(tabID,
+		shared.MenuItem{ID: 11, Description: "beer", Price: shared.Cents(400), IsDrink: true},
+		shared.MenuItem{ID: 12, Description: "wine", Price: shared.Cents(600), IsDrink: true},
+	)
+	_ = suite.tabAggregate.ApplyEvent(events.PaymentRecorded{BaseEvent: events.BaseEvent{ID: tabID}, Method: "card", Amount: shared.Cents(400), Payer: "Alice"})
+
+	// When
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.CloseTab{BaseCommand: commands.BaseCommand{ID: tabID}})
+
+	// Then
+	assert.EqualError(t, err, "not enough to cover tab, total served cost is: 10.00 EUR, but paid: 4.00 EUR")
+	assert.Empty(t, newEvents)
+}
+
+func (suite *TabAggregateTestSuite) TestSplitPaymentsCloseTheTab() {
+	tabID := ksuid.New()
+	t := suite.T()
+
+	// Given
+	suite.givenServedDrinks(tabID,
+		shared.MenuItem{ID: 11, Description: "beer", Price: shared.Cents(400), IsDrink: true},
+		shared.MenuItem{ID: 12, Description: "wine", Price: shared.Cents(600), IsDrink: true},
+	)
+	_ = suite.tabAggregate.ApplyEvent(events.PaymentRecorded{BaseEvent: events.BaseEvent{ID: tabID}, Method: "card", Amount: shared.Cents(400), Payer: "Alice"})
+	_ = suite.tabAggregate.ApplyEvent(events.PaymentRecorded{BaseEvent: events.BaseEvent{ID: tabID}, Method: "cash", Amount: shared.Cents(500), Payer: "Bob"})
+
+	// When
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.CloseTab{BaseCommand: commands.BaseCommand{ID: tabID}, AmountPaid: shared.Cents(200)})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []events.Event{events.TabClosed{BaseEvent: events.BaseEvent{ID: tabID}, AmountPaid: shared.Cents(1100), OrderAmount: shared.Cents(1000), Tip: shared.Cents(100)}}, newEvents)
+}
+
 func (suite *TabAggregateTestSuite) TestCanCloseTabWhenPayingExactAmount() {
 
 	tabOpenedEventID, _ := ksuid.NewRandom()
@@ -173,21 +656,21 @@ func (suite *TabAggregateTestSuite) TestCanCloseTabWhenPayingExactAmount() {
 	// Given
 	_ = suite.tabAggregate.ApplyEvent(events.TabOpened{BaseEvent: events.BaseEvent{ID: tabOpenedEventID}, Waiter: "waiter_1", TableNumber: 0})
 	_ = suite.tabAggregate.ApplyEvent(events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: drinksOrderedEventID}, Items: []shared.MenuItem{
-		{ID: 11, Description: "beer", Price: 1.5},
-		{ID: 12, Description: "water", Price: 1.0},
+		{ID: 11, Description: "beer", Price: shared.Cents(150)},
+		{ID: 12, Description: "water", Price: shared.Cents(100)},
 	}})
 	_ = suite.tabAggregate.ApplyEvent(events.DrinksServed{BaseEvent: events.BaseEvent{ID: drinksServedID}, MenuNumbers: []int{11, 12}})
 
 	// When
-	newEvents, err := suite.tabAggregate.HandleCommand(commands.CloseTab{BaseCommand: commands.BaseCommand{ID: closeTabID}, AmountPaid: 2.5})
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.CloseTab{BaseCommand: commands.BaseCommand{ID: closeTabID}, AmountPaid: shared.Cents(250)})
 
 	// Then
 	assert.NoError(t, err)
 	assert.Equal(t, []events.Event{events.TabClosed{
 		BaseEvent:   events.BaseEvent{ID: closeTabID},
-		AmountPaid:  2.5,
-		OrderAmount: 2.5,
-		Tip:         0,
+		AmountPaid:  shared.Cents(250),
+		OrderAmount: shared.Cents(250),
+		Tip:         shared.Cents(0),
 	}}, newEvents)
 }
 
@@ -202,20 +685,20 @@ func (suite *TabAggregateTestSuite) TestCanCloseTabWithTipWhenPayingMoreThanOrde
 	// Given
 	_ = suite.tabAggregate.ApplyEvent(events.TabOpened{BaseEvent: events.BaseEvent{ID: tabOpenedEventID}, Waiter: "waiter_1", TableNumber: 0})
 	_ = suite.tabAggregate.ApplyEvent(events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: drinksOrderedEventID}, Items: []shared.MenuItem{
-		{ID: 11, Description: "beer", Price: 1.5},
+		{ID: 11, Description: "beer", Price: shared.Cents(150)},
 	}})
 	_ = suite.tabAggregate.ApplyEvent(events.DrinksServed{BaseEvent: events.BaseEvent{ID: drinksServedID}, MenuNumbers: []int{11}})
 
 	// When
-	newEvents, err := suite.tabAggregate.HandleCommand(commands.CloseTab{BaseCommand: commands.BaseCommand{ID: closeTabID}, AmountPaid: 2.5})
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.CloseTab{BaseCommand: commands.BaseCommand{ID: closeTabID}, AmountPaid: shared.Cents(250)})
 
 	// Then
 	assert.NoError(t, err)
 	assert.Equal(t, []events.Event{events.TabClosed{
 		BaseEvent:   events.BaseEvent{ID: closeTabID},
-		AmountPaid:  2.5,
-		OrderAmount: 1.5,
-		Tip:         1,
+		AmountPaid:  shared.Cents(250),
+		OrderAmount: shared.Cents(150),
+		Tip:         shared.Cents(100),
 	}}, newEvents)
 }
 
@@ -230,19 +713,48 @@ func (suite *TabAggregateTestSuite) TestCanotCloseTabWhenPayingLessThanOrdered()
 	// Given
 	_ = suite.tabAggregate.ApplyEvent(events.TabOpened{BaseEvent: events.BaseEvent{ID: tabOpenedEventID}, Waiter: "waiter_1", TableNumber: 0})
 	_ = suite.tabAggregate.ApplyEvent(events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: drinksOrderedEventID}, Items: []shared.MenuItem{
-		{ID: 11, Description: "beer", Price: 1.5},
+		{ID: 11, Description: "beer", Price: shared.Cents(150)},
 	}})
 	_ = suite.tabAggregate.ApplyEvent(events.DrinksServed{BaseEvent: events.BaseEvent{ID: drinksServedID}, MenuNumbers: []int{11}})
 
 	// When
-	newEvents, err := suite.tabAggregate.HandleCommand(commands.CloseTab{BaseCommand: commands.BaseCommand{ID: closeTabID}, AmountPaid: 1.0})
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.CloseTab{BaseCommand: commands.BaseCommand{ID: closeTabID}, AmountPaid: shared.Cents(100)})
 
 	// Then
 	assert.Error(t, err)
-	assert.Equal(t, "not enough to cover tab, total served cost is: 1.5, but paid: 1", err.Error())
+	assert.Equal(t, "not enough to cover tab, total served cost is: 1.50 EUR, but paid: 1.00 EUR", err.Error())
 	assert.Empty(t, newEvents)
 }
 
+func (suite *TabAggregateTestSuite) TestCanCloseTabPayingExactlyTheSumOfCentPrices() {
+
+	tabOpenedEventID, _ := ksuid.NewRandom()
+	drinksOrderedEventID, _ := ksuid.NewRandom()
+	drinksServedID, _ := ksuid.NewRandom()
+	closeTabID, _ := ksuid.NewRandom()
+	t := suite.T()
+
+	// Given
+	_ = suite.tabAggregate.ApplyEvent(events.TabOpened{BaseEvent: events.BaseEvent{ID: tabOpenedEventID}, Waiter: "waiter_1", TableNumber: 0})
+	_ = suite.tabAggregate.ApplyEvent(events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: drinksOrderedEventID}, Items: []shared.MenuItem{
+		{ID: 11, Description: "shot", Price: shared.Cents(10)},
+		{ID: 12, Description: "chaser", Price: shared.Cents(20)},
+	}})
+	_ = suite.tabAggregate.ApplyEvent(events.DrinksServed{BaseEvent: events.BaseEvent{ID: drinksServedID}, MenuNumbers: []int{11, 12}})
+
+	// When
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.CloseTab{BaseCommand: commands.BaseCommand{ID: closeTabID}, AmountPaid: shared.Cents(30)})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []events.Event{events.TabClosed{
+		BaseEvent:   events.BaseEvent{ID: closeTabID},
+		AmountPaid:  shared.Cents(30),
+		OrderAmount: shared.Cents(30),
+		Tip:         shared.Cents(0),
+	}}, newEvents)
+}
+
 func (suite *TabAggregateTestSuite) TestCanotCloseTabWithUnservedItems() {
 
 	tabOpenedEventID, _ := ksuid.NewRandom()
@@ -254,13 +766,13 @@ func (suite *TabAggregateTestSuite) TestCanotCloseTabWithUnservedItems() {
 	// Given
 	_ = suite.tabAggregate.ApplyEvent(events.TabOpened{BaseEvent: events.BaseEvent{ID: tabOpenedEventID}, Waiter: "waiter_1", TableNumber: 0})
 	_ = suite.tabAggregate.ApplyEvent(events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: drinksOrderedEventID}, Items: []shared.MenuItem{
-		{ID: 11, Description: "beer", Price: 1.5},
-		{ID: 12, Description: "water", Price: 1.0},
+		{ID: 11, Description: "beer", Price: shared.Cents(150)},
+		{ID: 12, Description: "water", Price: shared.Cents(100)},
 	}})
 	_ = suite.tabAggregate.ApplyEvent(events.DrinksServed{BaseEvent: events.BaseEvent{ID: drinksServedID}, MenuNumbers: []int{11}})
 
 	// When
-	newEvents, err := suite.tabAggregate.HandleCommand(commands.CloseTab{BaseCommand: commands.BaseCommand{ID: closeTabID}, AmountPaid: 1.5})
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.CloseTab{BaseCommand: commands.BaseCommand{ID: closeTabID}, AmountPaid: shared.Cents(150)})
 
 	// Then
 	assert.Error(t, err)
@@ -279,18 +791,84 @@ func (suite *TabAggregateTestSuite) TestCanotCloseTabTwice() {
 	// Given
 	_ = suite.tabAggregate.ApplyEvent(events.TabOpened{BaseEvent: events.BaseEvent{ID: tabOpenedEventID}, Waiter: "waiter_1", TableNumber: 0})
 	_ = suite.tabAggregate.ApplyEvent(events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: drinksOrderedEventID}, Items: []shared.MenuItem{
-		{ID: 11, Description: "beer", Price: 1.5},
+		{ID: 11, Description: "beer", Price: shared.Cents(150)},
 	}})
 	_ = suite.tabAggregate.ApplyEvent(events.DrinksServed{BaseEvent: events.BaseEvent{ID: drinksServedID}, MenuNumbers: []int{11}})
-	_ = suite.tabAggregate.ApplyEvent(events.TabClosed{BaseEvent: events.BaseEvent{ID: drinksServedID}, AmountPaid: 1.5, OrderAmount: 1.5, Tip: 0})
+	_ = suite.tabAggregate.ApplyEvent(events.TabClosed{BaseEvent: events.BaseEvent{ID: drinksServedID}, AmountPaid: shared.Cents(150), OrderAmount: shared.Cents(150), Tip: shared.Cents(0)})
 
-	newEvents, err := suite.tabAggregate.HandleCommand(commands.CloseTab{BaseCommand: commands.BaseCommand{ID: closeTabID}, AmountPaid: 1.5})
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.CloseTab{BaseCommand: commands.BaseCommand{ID: closeTabID}, AmountPaid: shared.Cents(150)})
 
 	assert.Error(t, err)
 	assert.Equal(t, "cannot close a tab that is not open", err.Error())
 	assert.Empty(t, newEvents)
 }
 
+func (suite *TabAggregateTestSuite) TestRestoredSnapshotBehavesLikeReplayedEvents() {
+
+	tabOpenedEventID, _ := ksuid.NewRandom()
+	drinksOrderedEventID, _ := ksuid.NewRandom()
+	drinksServedID, _ := ksuid.NewRandom()
+	closeTabID, _ := ksuid.NewRandom()
+	t := suite.T()
+
+	// Given
+	_ = suite.tabAggregate.ApplyEvent(events.TabOpened{BaseEvent: events.BaseEvent{ID: tabOpenedEventID}, Waiter: "waiter_1", TableNumber: 0})
+	_ = suite.tabAggregate.ApplyEvent(events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: drinksOrderedEventID}, Items: []shared.MenuItem{
+		{ID: 11, Description: "beer", Price: shared.Cents(150)},
+		{ID: 12, Description: "water", Price: shared.Cents(100)},
+	}})
+	_ = suite.tabAggregate.ApplyEvent(events.DrinksServed{BaseEvent: events.BaseEvent{ID: drinksServedID}, MenuNumbers: []int{11}})
+	state, err := suite.tabAggregate.Snapshot()
+	assert.NoError(t, err)
+	restored := commands.TabAggregateFactory{}.CreateAggregate()
+
+	// When
+	err = restored.RestoreSnapshot(state)
+
+	// Then
+	assert.NoError(t, err)
+	_, err = restored.HandleCommand(commands.CloseTab{BaseCommand: commands.BaseCommand{ID: closeTabID}, AmountPaid: shared.Cents(150)})
+	assert.Equal(t, "cannot close a tab with unserved items", err.Error())
+	_ = restored.ApplyEvent(events.DrinksServed{BaseEvent: events.BaseEvent{ID: drinksServedID}, MenuNumbers: []int{12}})
+	newEvents, err := restored.HandleCommand(commands.CloseTab{BaseCommand: commands.BaseCommand{ID: closeTabID}, AmountPaid: shared.Cents(250)})
+	assert.NoError(t, err)
+	assert.Equal(t, []events.Event{events.TabClosed{
+		BaseEvent:   events.BaseEvent{ID: closeTabID},
+		AmountPaid:  shared.Cents(250),
+		OrderAmount: shared.Cents(250),
+		Tip:         shared.Cents(0),
+	}}, newEvents)
+}
+
+func (suite *TabAggregateTestSuite) TestRestoresSnapshotWithLegacyFloatAmounts() {
+	closeTabID, _ := ksuid.NewRandom()
+	t := suite.T()
+
+	// Given
+	state := []byte(`{"tab_open":true,"outstanding_drinks":[],"outstanding_food":[],"prepared_food":[],"served_items_amount":3.3,"served_items":[],"comped_amount":0.1,"discounts":[],"paid_amount":1.2}`)
+
+	// When
+	err := suite.tabAggregate.RestoreSnapshot(state)
+
+	// Then
+	assert.NoError(t, err)
+	newEvents, err := suite.tabAggregate.HandleCommand(commands.CloseTab{BaseCommand: commands.BaseCommand{ID: closeTabID}, AmountPaid: shared.Cents(200)})
+	assert.NoError(t, err)
+	assert.Equal(t, []events.Event{events.TabClosed{
+		BaseEvent:   events.BaseEvent{ID: closeTabID},
+		AmountPaid:  shared.Cents(320),
+		OrderAmount: shared.Cents(320),
+		Tip:         shared.Cents(0),
+	}}, newEvents)
+}
+
+func (suite *TabAggregateTestSuite) TestCannotRestoreInvalidSnapshot() {
+	err := suite.tabAggregate.RestoreSnapshot([]byte("not json"))
+
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), "could not restore tab aggregate from snapshot: not json", err.Error())
+}
+
 func TestSuite(t *testing.T) {
 	suite.Run(t, new(TabAggregateTestSuite))
 }
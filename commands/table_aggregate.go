@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"cqrseventsourcingbar/events"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/ksuid"
+)
+
+// tableAggregate tracks which tab, if any, is currently open on a table.
+type tableAggregate struct {
+	tabID ksuid.KSUID
+}
+
+type tableSnapshot struct {
+	TabID ksuid.KSUID `json:"tab_id"`
+}
+
+type TableOccupiedError struct {
+	TableNumber int
+	TabID       ksuid.KSUID
+}
+
+func (e *TableOccupiedError) Error() string {
+	return fmt.Sprintf("table %d already has an open tab: %s", e.TableNumber, e.TabID)
+}
+
+var tableIDTimestamp = time.Unix(1400000000, 0)
+
+// TableID returns the aggregate ID of a table, the same for every call with the same table number.
+func TableID(tableNumber int) ksuid.KSUID {
+	payload := make([]byte, 16)
+	copy(payload, "table")
+	binary.BigEndian.PutUint64(payload[8:], uint64(tableNumber))
+	id, _ := ksuid.FromParts(tableIDTimestamp, payload)
+	return id
+}
+
+func (t tableAggregate) HandleCommand(c Command) ([]events.Event, error) {
+	switch command := c.(type) {
+	case ClaimTable:
+		return t.handleCommandClaimTable(command)
+	case ReleaseTable:
+		return t.handleCommandReleaseTable(command)
+	default:
+		return nil, fmt.Errorf("unexpected Command: %#v", c)
+	}
+}
+
+func (t *tableAggregate) ApplyEvent(e events.Event) error {
+	switch event := e.(type) {
+	case events.TableClaimed:
+		t.tabID = event.TabID
+		return nil
+	case events.TableReleased:
+		t.tabID = ksuid.Nil
+		return nil
+	case events.UnknownEvent:
+		return nil
+	default:
+		return fmt.Errorf("unexpected events.Event: %#v", e)
+	}
+}
+
+func (t *tableAggregate) Snapshot() ([]byte, error) {
+	return json.Marshal(tableSnapshot{TabID: t.tabID})
+}
+
+func (t *tableAggregate) RestoreSnapshot(state []byte) error {
+	var snapshot tableSnapshot
+	if err := json.Unmarshal(state, &snapshot); err != nil {
+		return fmt.Errorf("could not restore table aggregate from snapshot: %s", state)
+	}
+	t.tabID = snapshot.TabID
+	return nil
+}
+
+func (t *tableAggregate) handleCommandClaimTable(c ClaimTable) ([]events.Event, error) {
+	if t.tabID == c.TabID {
+		return nil, nil
+	}
+	if !t.tabID.IsNil() {
+		return nil, &TableOccupiedError{TableNumber: c.TableNumber, TabID: t.tabID}
+	}
+	return []events.Event{events.TableClaimed{BaseEvent: events.BaseEvent{ID: c.ID}, TableNumber: c.TableNumber, TabID: c.TabID}}, nil
+}
+
+// handleCommandReleaseTable does nothing unless the table is held by the tab, so releasing twice is harmless.
+func (t *tableAggregate) handleCommandReleaseTable(c ReleaseTable) ([]events.Event, error) {
+	if t.tabID.IsNil() || t.tabID != c.TabID {
+		return nil, nil
+	}
+	return []events.Event{events.TableReleased{BaseEvent: events.BaseEvent{ID: c.ID}, TableNumber: c.TableNumber, TabID: c.TabID}}, nil
+}
+
+type TableAggregateFactory struct {
+}
+
+func (t TableAggregateFactory) CreateAggregate() Aggregate {
+	return &tableAggregate{}
+}
@@ -0,0 +1,107 @@
+package commands_test
+
+import (
+	"cqrseventsourcingbar/commands"
+	"cqrseventsourcingbar/events"
+	"testing"
+
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type TableAggregateTestSuite struct {
+	suite.Suite
+	tableAggregate commands.Aggregate
+	tableID        ksuid.KSUID
+}
+
+func (suite *TableAggregateTestSuite) SetupTest() {
+	suite.tableAggregate = commands.TableAggregateFactory{}.CreateAggregate()
+	suite.tableID = commands.TableID(3)
+}
+
+func (suite *TableAggregateTestSuite) TestTableIDIsStablePerTableNumber() {
+	assert.Equal(suite.T(), commands.TableID(3), commands.TableID(3))
+	assert.NotEqual(suite.T(), commands.TableID(3), commands.TableID(4))
+}
+
+func (suite *TableAggregateTestSuite) TestCanClaimAFreeTable() {
+	// Given
+	tabID := ksuid.New()
+
+	// When
+	newEvents, err := suite.tableAggregate.HandleCommand(commands.ClaimTable{BaseCommand: commands.BaseCommand{ID: suite.tableID}, TableNumber: 3, TabID: tabID})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []events.Event{events.TableClaimed{BaseEvent: events.BaseEvent{ID: suite.tableID}, TableNumber: 3, TabID: tabID}}, newEvents)
+}
+
+func (suite *TableAggregateTestSuite) TestCanNotClaimATableHeldByAnotherTab() {
+	// Given
+	holderID := ksuid.New()
+	err := suite.tableAggregate.ApplyEvent(events.TableClaimed{BaseEvent: events.BaseEvent{ID: suite.tableID}, TableNumber: 3, TabID: holderID})
+	assert.NoError(suite.T(), err)
+
+	// When
+	newEvents, err := suite.tableAggregate.HandleCommand(commands.ClaimTable{BaseCommand: commands.BaseCommand{ID: suite.tableID}, TableNumber: 3, TabID: ksuid.New()})
+
+	// Then
+	assert.Empty(suite.T(), newEvents)
+	assert.Equal(suite.T(), &commands.TableOccupiedError{TableNumber: 3, TabID: holderID}, err)
+	assert.Equal(suite.T(), "table 3 already has an open tab: "+holderID.String(), err.Error())
+}
+
+func (suite *TableAggregateTestSuite) TestClaimingTwiceForTheSameTabDoesNothing() {
+	// Given
+	tabID := ksuid.New()
+	err := suite.tableAggregate.ApplyEvent(events.TableClaimed{BaseEvent: events.BaseEvent{ID: suite.tableID}, TableNumber: 3, TabID: tabID})
+	assert.NoError(suite.T(), err)
+
+	// When
+	newEvents, err := suite.tableAggregate.HandleCommand(commands.ClaimTable{BaseCommand: commands.BaseCommand{ID: suite.tableID}, TableNumber: 3, TabID: tabID})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), newEvents)
+}
+
+func (suite *TableAggregateTestSuite) TestOnlyTheHoldingTabReleasesTheTable() {
+	// Given
+	tabID := ksuid.New()
+	err := suite.tableAggregate.ApplyEvent(events.TableClaimed{BaseEvent: events.BaseEvent{ID: suite.tableID}, TableNumber: 3, TabID: tabID})
+	assert.NoError(suite.T(), err)
+
+	// When
+	eventsForOtherTab, errForOtherTab := suite.tableAggregate.HandleCommand(commands.ReleaseTable{BaseCommand: commands.BaseCommand{ID: suite.tableID}, TableNumber: 3, TabID: ksuid.New()})
+	newEvents, err := suite.tableAggregate.HandleCommand(commands.ReleaseTable{BaseCommand: commands.BaseCommand{ID: suite.tableID}, TableNumber: 3, TabID: tabID})
+
+	// Then
+	assert.NoError(suite.T(), errForOtherTab)
+	assert.Empty(suite.T(), eventsForOtherTab)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []events.Event{events.TableReleased{BaseEvent: events.BaseEvent{ID: suite.tableID}, TableNumber: 3, TabID: tabID}}, newEvents)
+}
+
+func (suite *TableAggregateTestSuite) TestSnapshotRoundTrip() {
+	// Given
+	tabID := ksuid.New()
+	err := suite.tableAggregate.ApplyEvent(events.TableClaimed{BaseEvent: events.BaseEvent{ID: suite.tableID}, TableNumber: 3, TabID: tabID})
+	assert.NoError(suite.T(), err)
+	state, err := suite.tableAggregate.Snapshot()
+	assert.NoError(suite.T(), err)
+
+	// When
+	restored := commands.TableAggregateFactory{}.CreateAggregate()
+	err = restored.RestoreSnapshot(state)
+
+	// Then
+	assert.NoError(suite.T(), err)
+	_, err = restored.HandleCommand(commands.ClaimTable{BaseCommand: commands.BaseCommand{ID: suite.tableID}, TableNumber: 3, TabID: ksuid.New()})
+	assert.Equal(suite.T(), &commands.TableOccupiedError{TableNumber: 3, TabID: tabID}, err)
+}
+
+func TestTableAggregateTestSuite(t *testing.T) {
+	suite.Run(t, new(TableAggregateTestSuite))
+}
@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"context"
+	"cqrseventsourcingbar/events"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/segmentio/ksuid"
+)
+
+// TableOccupancyDispatcher claims the table before a tab is opened on it and releases it once the tab is closed,
+// so a table never has more than one open tab. Every other command goes straight to the tab dispatcher.
+type TableOccupancyDispatcher struct {
+	eventStore      events.EventStore
+	tabDispatcher   CommandDispatcher
+	tableDispatcher CommandDispatcher
+}
+
+type tabState struct {
+	tableNumber int
+	opened      bool
+	closed      bool
+}
+
+func CreateTableOccupancyDispatcher(eventStore events.EventStore, tabDispatcher CommandDispatcher, tableDispatcher CommandDispatcher) *TableOccupancyDispatcher {
+	return &TableOccupancyDispatcher{eventStore: eventStore, tabDispatcher: tabDispatcher, tableDispatcher: tableDispatcher}
+}
+
+func (d *TableOccupancyDispatcher) DispatchCommand(ctx context.Context, command Command) error {
+	switch c := command.(type) {
+	case OpenTab:
+		return d.openTab(ctx, c)
+	case CloseTab:
+		return d.closeTab(ctx, c)
+	default:
+		return d.tabDispatcher.DispatchCommand(ctx, command)
+	}
+}
+
+func (d *TableOccupancyDispatcher) openTab(ctx context.Context, c OpenTab) error {
+	err := d.claimTable(ctx, c.TableNumber, c.ID)
+	if err != nil {
+		return err
+	}
+
+	err = d.tabDispatcher.DispatchCommand(ctx, c)
+	if err != nil {
+		if releaseErr := d.releaseTable(ctx, c.TableNumber, c.ID); releaseErr != nil {
+			slog.Error("could not release table after failing to open tab", slog.Int("table", c.TableNumber), slog.String("tab", c.ID.String()), slog.String("error", releaseErr.Error()))
+		}
+		return err
+	}
+	return nil
+}
+
+func (d *TableOccupancyDispatcher) closeTab(ctx context.Context, c CloseTab) error {
+	tab, err := d.loadTab(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+
+	err = d.tabDispatcher.DispatchCommand(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	// The tab is closed either way, a table left claimed is released the next time a tab is opened on it.
+	if tab.opened {
+		if err := d.releaseTable(ctx, tab.tableNumber, c.ID); err != nil {
+			slog.Warn("could not release table after closing tab", slog.Int("table", tab.tableNumber), slog.String("tab", c.ID.String()), slog.String("error", err.Error()))
+		}
+	}
+	return nil
+}
+
+// ClaimOpenTables claims the tables of the tabs that are open without a claim, like the ones opened before tables
+// were claimed. Run on start, before any command is dispatched.
+func (d *TableOccupancyDispatcher) ClaimOpenTables(ctx context.Context, batchSize int) error {
+	openTabs := make(map[ksuid.KSUID]int)
+	var opened []ksuid.KSUID
+	_, err := events.ForEachEventFrom(ctx, d.eventStore, 0, batchSize, func(recordedEvent events.RecordedEvent) error {
+		switch e := recordedEvent.Event.(type) {
+		case events.TabOpened:
+			openTabs[e.ID] = e.TableNumber
+			opened = append(opened, e.ID)
+		case events.TabClosed:
+			delete(openTabs, e.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, tabID := range opened {
+		tableNumber, open := openTabs[tabID]
+		if !open {
+			continue
+		}
+		err := d.claimTable(ctx, tableNumber, tabID)
+		var occupied *TableOccupiedError
+		if errors.As(err, &occupied) {
+			slog.Warn("table already has another open tab", slog.Int("table", tableNumber), slog.String("tab", tabID.String()), slog.String("holder", occupied.TabID.String()))
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not claim table %d for tab %s, reason: %w", tableNumber, tabID, err)
+		}
+	}
+	return nil
+}
+
+func (d *TableOccupancyDispatcher) claimTable(ctx context.Context, tableNumber int, tabID ksuid.KSUID) error {
+	claim := ClaimTable{BaseCommand: BaseCommand{ID: TableID(tableNumber)}, TableNumber: tableNumber, TabID: tabID}
+	err := d.tableDispatcher.DispatchCommand(ctx, claim)
+
+	var occupied *TableOccupiedError
+	if !errors.As(err, &occupied) {
+		return err
+	}
+
+	holder, loadErr := d.loadTab(ctx, occupied.TabID)
+	if loadErr != nil {
+		return loadErr
+	}
+	if !holder.closed {
+		return err
+	}
+
+	// The tab holding the table was closed without releasing it, for example because the process stopped in between.
+	err = d.releaseTable(ctx, tableNumber, occupied.TabID)
+	if err != nil {
+		return err
+	}
+	return d.tableDispatcher.DispatchCommand(ctx, claim)
+}
+
+func (d *TableOccupancyDispatcher) releaseTable(ctx context.Context, tableNumber int, tabID ksuid.KSUID) error {
+	return d.tableDispatcher.DispatchCommand(ctx, ReleaseTable{BaseCommand: BaseCommand{ID: TableID(tableNumber)}, TableNumber: tableNumber, TabID: tabID})
+}
+
+func (d *TableOccupancyDispatcher) loadTab(ctx context.Context, tabID ksuid.KSUID) (tabState, error) {
+	var tab tabState
+	tabEvents, err := d.eventStore.LoadEvents(ctx, tabID)
+	if err != nil {
+		return tab, err
+	}
+	for _, event := range tabEvents {
+		switch e := event.(type) {
+		case events.TabOpened:
+			tab.opened = true
+			tab.tableNumber = e.TableNumber
+		case events.TabClosed:
+			tab.closed = true
+		}
+	}
+	return tab, nil
+}
@@ -0,0 +1,125 @@
+package commands_test
+
+import (
+	"context"
+	"cqrseventsourcingbar/commands"
+	mock_commands "cqrseventsourcingbar/commands/mocks"
+	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/shared"
+	"errors"
+	"testing"
+
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type TableOccupancyDispatcherTestSuite struct {
+	suite.Suite
+	eventStore      *events.InMemoryEventStore
+	tableDispatcher *commands.Dispatcher
+	dispatcher      *commands.TableOccupancyDispatcher
+	ctx             context.Context
+}
+
+func (suite *TableOccupancyDispatcherTestSuite) SetupTest() {
+	suite.eventStore = events.CreateInMemoryEventStore()
+	tabDispatcher := commands.CreateCommandDispatcher(suite.eventStore, events.NoopEventEmitter{}, commands.TabAggregateFactory{})
+	suite.tableDispatcher = commands.CreateCommandDispatcher(suite.eventStore, events.NoopEventEmitter{}, commands.TableAggregateFactory{})
+	suite.dispatcher = commands.CreateTableOccupancyDispatcher(suite.eventStore, tabDispatcher, suite.tableDispatcher)
+	suite.ctx = context.TODO()
+}
+
+func (suite *TableOccupancyDispatcherTestSuite) TestCanNotOpenASecondTabOnATable() {
+	// Given
+	firstTabID := ksuid.New()
+	err := suite.dispatcher.DispatchCommand(suite.ctx, commands.OpenTab{BaseCommand: commands.BaseCommand{ID: firstTabID}, TableNumber: 3, Waiter: "Charles"})
+	assert.NoError(suite.T(), err)
+	secondTabID := ksuid.New()
+
+	// When
+	err = suite.dispatcher.DispatchCommand(suite.ctx, commands.OpenTab{BaseCommand: commands.BaseCommand{ID: secondTabID}, TableNumber: 3, Waiter: "Jenkins"})
+
+	// Then
+	var occupied *commands.TableOccupiedError
+	if assert.ErrorAs(suite.T(), err, &occupied) {
+		assert.Equal(suite.T(), commands.TableOccupiedError{TableNumber: 3, TabID: firstTabID}, *occupied)
+	}
+	secondTabEvents, err := suite.eventStore.LoadEvents(suite.ctx, secondTabID)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), secondTabEvents)
+}
+
+func (suite *TableOccupancyDispatcherTestSuite) TestClosingATabFreesTheTable() {
+	// Given
+	firstTabID := ksuid.New()
+	err := suite.dispatcher.DispatchCommand(suite.ctx, commands.OpenTab{BaseCommand: commands.BaseCommand{ID: firstTabID}, TableNumber: 3, Waiter: "Charles"})
+	assert.NoError(suite.T(), err)
+	err = suite.dispatcher.DispatchCommand(suite.ctx, commands.CloseTab{BaseCommand: commands.BaseCommand{ID: firstTabID}, AmountPaid: shared.Cents(0)})
+	assert.NoError(suite.T(), err)
+
+	// When
+	err = suite.dispatcher.DispatchCommand(suite.ctx, commands.OpenTab{BaseCommand: commands.BaseCommand{ID: ksuid.New()}, TableNumber: 3, Waiter: "Jenkins"})
+
+	// Then
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *TableOccupancyDispatcherTestSuite) TestATableLeftClaimedByAClosedTabIsReclaimed() {
+	// Given
+	closedTabID := ksuid.New()
+	tabDispatcher := commands.CreateCommandDispatcher(suite.eventStore, events.NoopEventEmitter{}, commands.TabAggregateFactory{})
+	err := suite.dispatcher.DispatchCommand(suite.ctx, commands.OpenTab{BaseCommand: commands.BaseCommand{ID: closedTabID}, TableNumber: 3, Waiter: "Charles"})
+	assert.NoError(suite.T(), err)
+	err = tabDispatcher.DispatchCommand(suite.ctx, commands.CloseTab{BaseCommand: commands.BaseCommand{ID: closedTabID}, AmountPaid: shared.Cents(0)})
+	assert.NoError(suite.T(), err)
+
+	// When
+	err = suite.dispatcher.DispatchCommand(suite.ctx, commands.OpenTab{BaseCommand: commands.BaseCommand{ID: ksuid.New()}, TableNumber: 3, Waiter: "Jenkins"})
+
+	// Then
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *TableOccupancyDispatcherTestSuite) TestTheTableIsReleasedWhenOpeningTheTabFails() {
+	// Given
+	tabDispatcher := mock_commands.NewCommandDispatcher(suite.T())
+	tabDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(errors.New("all broken")).Once()
+	dispatcher := commands.CreateTableOccupancyDispatcher(suite.eventStore, tabDispatcher, suite.tableDispatcher)
+
+	// When
+	err := dispatcher.DispatchCommand(suite.ctx, commands.OpenTab{BaseCommand: commands.BaseCommand{ID: ksuid.New()}, TableNumber: 3, Waiter: "Charles"})
+
+	// Then
+	assert.EqualError(suite.T(), err, "all broken")
+	err = suite.dispatcher.DispatchCommand(suite.ctx, commands.OpenTab{BaseCommand: commands.BaseCommand{ID: ksuid.New()}, TableNumber: 3, Waiter: "Jenkins"})
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *TableOccupancyDispatcherTestSuite) TestTabsOpenedWithoutAClaimHoldTheirTableOnceClaimed() {
+	// Given
+	tabDispatcher := commands.CreateCommandDispatcher(suite.eventStore, events.NoopEventEmitter{}, commands.TabAggregateFactory{})
+	openTabID := ksuid.New()
+	closedTabID := ksuid.New()
+	assert.NoError(suite.T(), tabDispatcher.DispatchCommand(suite.ctx, commands.OpenTab{BaseCommand: commands.BaseCommand{ID: openTabID}, TableNumber: 3, Waiter: "Charles"}))
+	assert.NoError(suite.T(), tabDispatcher.DispatchCommand(suite.ctx, commands.OpenTab{BaseCommand: commands.BaseCommand{ID: closedTabID}, TableNumber: 4, Waiter: "Charles"}))
+	assert.NoError(suite.T(), tabDispatcher.DispatchCommand(suite.ctx, commands.CloseTab{BaseCommand: commands.BaseCommand{ID: closedTabID}, AmountPaid: shared.Cents(0)}))
+
+	// When
+	err := suite.dispatcher.ClaimOpenTables(suite.ctx, 2)
+
+	// Then
+	assert.NoError(suite.T(), err)
+	err = suite.dispatcher.DispatchCommand(suite.ctx, commands.OpenTab{BaseCommand: commands.BaseCommand{ID: ksuid.New()}, TableNumber: 3, Waiter: "Jenkins"})
+	var occupied *commands.TableOccupiedError
+	if assert.ErrorAs(suite.T(), err, &occupied) {
+		assert.Equal(suite.T(), openTabID, occupied.TabID)
+	}
+	assert.NoError(suite.T(), suite.dispatcher.DispatchCommand(suite.ctx, commands.OpenTab{BaseCommand: commands.BaseCommand{ID: ksuid.New()}, TableNumber: 4, Waiter: "Jenkins"}))
+	assert.NoError(suite.T(), suite.dispatcher.ClaimOpenTables(suite.ctx, 2))
+}
+
+func TestTableOccupancyDispatcherTestSuite(t *testing.T) {
+	suite.Run(t, new(TableOccupancyDispatcherTestSuite))
+}
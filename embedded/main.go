@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"cqrseventsourcingbar/commands"
+	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/queries"
+	readservice "cqrseventsourcingbar/readservice/service"
+	"cqrseventsourcingbar/shared"
+	writeservice "cqrseventsourcingbar/writeservice/service"
+	"flag"
+	"fmt"
+	"time"
+)
+
+const catchUpBatchSize = 500
+const stockRetryInterval = 5 * time.Second
+
+var menu = []shared.MenuItem{
+	{ID: 1, Description: "blue water", Price: shared.Cents(100), IsDrink: true},
+	{ID: 2, Description: "red water", Price: shared.Cents(200), IsDrink: true},
+	{ID: 3, Description: "green water", Price: shared.Cents(300), IsDrink: true},
+	{ID: 4, Description: "burger", Price: shared.Cents(800)},
+	{ID: 5, Description: "fries", Price: shared.Cents(300)},
+	{ID: 6, Description: "gin and tonic", Price: shared.Cents(900), IsDrink: true, Recipe: []shared.RecipeIngredient{
+		{Ingredient: "gin", Quantity: 50}, {Ingredient: "tonic", Quantity: 150}, {Ingredient: "lime", Quantity: 1},
+	}},
+}
+
+// The embedded bar runs the write service, the read service and the read models in one process,
+// with the events kept in a local file and delivered in-process, so it needs neither Postgres nor NATS.
+func main() {
+	eventsFile := flag.String("events-file", "bar-events.jsonl", "file the events are stored in")
+	writePort := flag.Int("write-port", 8080, "port of the write service")
+	readPort := flag.Int("read-port", 8081, "port of the read service")
+	defaultStation := flag.String("station", "bar", "station the payments and closes that name none are taken at, once it works in shifts")
+	flag.Parse()
+
+	ctx := context.Background()
+
+	eventStore, err := events.NewFileEventStore(*eventsFile)
+	panicIfErrors(err)
+
+	openTabQueries := queries.CreateOpenTabs()
+	chefTodoList := queries.CreateChefTodoList()
+	menuCatalogue := queries.CreateMenuCatalogue()
+	stockLevels := queries.CreateStockLevels()
+	shiftReconciliations := queries.CreateShiftReconciliations()
+
+	// The stock keeper only queues its commands while events are delivered, they are dispatched by its own goroutine.
+	stockDispatcher := commands.CreateCommandDispatcher(eventStore, events.NoopEventEmitter{}, commands.StockAggregateFactory{})
+	ingredientDispatcher := commands.CreateCommandDispatcher(eventStore, events.NoopEventEmitter{}, commands.IngredientAggregateFactory{})
+	inventoryDispatcher := commands.CreateInventoryDispatcher(stockDispatcher, ingredientDispatcher)
+	stockKeeper := commands.CreateStockKeeper(inventoryDispatcher)
+	stockProjector := queries.CreateProjector(eventStore, stockKeeper, catchUpBatchSize)
+	runner := queries.CreateCatchUpRunner(eventStore, events.EventListeners{openTabQueries, chefTodoList, menuCatalogue, stockLevels, shiftReconciliations}, catchUpBatchSize)
+
+	// Subscribe before replaying, the runner buffers live events until the history is applied.
+	eventStore.Subscribe(runner)
+	eventStore.Subscribe(stockProjector)
+
+	err = runner.CatchUp(ctx)
+	panicIfErrors(err)
+	err = stockProjector.CatchUp(ctx)
+	panicIfErrors(err)
+	go stockKeeper.Run(ctx, stockRetryInterval)
+
+	// The menu above only seeds the events file, later changes go through the menu commands.
+	menuDispatcher := commands.CreateCommandDispatcher(eventStore, events.NoopEventEmitter{}, commands.MenuItemAggregateFactory{})
+	err = commands.ImportMenu(ctx, eventStore, menuDispatcher, menu)
+	panicIfErrors(err)
+	menuItemRepository := shared.CreateHappyHourMenuItemRepository(commands.CreateEventSourcedMenuItemRepository(eventStore), shared.DefaultHappyHourRules, time.Now)
+
+	tabDispatcher := commands.CreateCommandDispatcher(eventStore, events.NoopEventEmitter{}, commands.TabAggregateFactory{})
+	tableDispatcher := commands.CreateCommandDispatcher(eventStore, events.NoopEventEmitter{}, commands.TableAggregateFactory{})
+	shiftDispatcher := commands.CreateCommandDispatcher(eventStore, events.NoopEventEmitter{}, commands.ShiftAggregateFactory{})
+	tableOccupancyDispatcher := commands.CreateTableOccupancyDispatcher(eventStore, tabDispatcher, tableDispatcher)
+	err = tableOccupancyDispatcher.ClaimOpenTables(ctx, catchUpBatchSize)
+	panicIfErrors(err)
+	dispatcher := commands.CreateCashDrawerDispatcher(eventStore, tableOccupancyDispatcher, shiftDispatcher, *defaultStation)
+
+	writeService := writeservice.CreateWriteService(*writePort, menuItemRepository, dispatcher, menuDispatcher, inventoryDispatcher, commands.CreateEventSourcedStockChecker(eventStore, nil))
+	readService := readservice.CreateReadService(*readPort, openTabQueries, chefTodoList, shared.CreateHappyHourMenuItemRepository(menuCatalogue, shared.DefaultHappyHourRules, time.Now), menuCatalogue, stockLevels, shiftReconciliations)
+
+	errs := make(chan error, 2)
+	go func() { errs <- writeService.Start() }()
+	go func() { errs <- readService.Start() }()
+
+	panicIfErrors(<-errs)
+}
+
+func panicIfErrors(err error) {
+	if err != nil {
+		panic(fmt.Sprintf("error: %s, not starting app", err.Error()))
+	}
+}
@@ -0,0 +1,37 @@
+package events
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+//go:generate mockery --name DeadLetterSink
+type DeadLetterSink interface {
+	DeadLetter(ctx context.Context, unknown UnknownEvent) error
+}
+
+// InMemoryDeadLetterSink keeps the dead letters for as long as the process runs.
+type InMemoryDeadLetterSink struct {
+	deadLetters []UnknownEvent
+	lock        sync.RWMutex
+}
+
+func (s *InMemoryDeadLetterSink) DeadLetter(_ context.Context, unknown UnknownEvent) error {
+	defer s.lock.Unlock()
+	s.lock.Lock()
+
+	s.deadLetters = append(s.deadLetters, unknown)
+	return nil
+}
+
+func (s *InMemoryDeadLetterSink) DeadLetters() []UnknownEvent {
+	defer s.lock.RUnlock()
+	s.lock.RLock()
+
+	return slices.Clone(s.deadLetters)
+}
+
+func CreateInMemoryDeadLetterSink() *InMemoryDeadLetterSink {
+	return &InMemoryDeadLetterSink{}
+}
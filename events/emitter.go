@@ -14,3 +14,11 @@ func (e PrintlnEventEmitter) EmitEvent(event Event) error {
 	fmt.Printf("emitted: %v\n", event)
 	return nil
 }
+
+// NoopEventEmitter is used by the dispatcher when an OutboxRelay publishes the saved events instead.
+type NoopEventEmitter struct {
+}
+
+func (e NoopEventEmitter) EmitEvent(_ Event) error {
+	return nil
+}
@@ -2,8 +2,6 @@ package events
 
 import (
 	"cqrseventsourcingbar/shared"
-	"encoding/json"
-	"fmt"
 	"reflect"
 
 	"github.com/segmentio/ksuid"
@@ -11,17 +9,65 @@ import (
 
 type Event interface {
 	GetID() ksuid.KSUID
+	GetEventID() ksuid.KSUID
+	GetSequenceNumber() int
+	GetMetadata() Metadata
 }
 
 type BaseEvent struct {
-	ID ksuid.KSUID `json:"id"`
+	ID             ksuid.KSUID `json:"id"`
+	EventID        ksuid.KSUID `json:"event_id"`
+	SequenceNumber int         `json:"sequence_number,omitempty"`
+	// Metadata is kept by the event stores next to the payload rather than inside it.
+	Metadata Metadata `json:"-"`
 }
 
 func (event BaseEvent) GetID() ksuid.KSUID {
 	return event.ID
 }
 
+func (event BaseEvent) GetEventID() ksuid.KSUID {
+	return event.EventID
+}
+
+func (event BaseEvent) GetSequenceNumber() int {
+	return event.SequenceNumber
+}
+
+func (event BaseEvent) GetMetadata() Metadata {
+	return event.Metadata
+}
+
+// WithSequenceNumber returns a copy of the event carrying its position in the aggregate stream.
+func WithSequenceNumber(event Event, sequenceNumber int) Event {
+	return withBaseEvent(event, func(base *BaseEvent) {
+		base.SequenceNumber = sequenceNumber
+	})
+}
+
+// WithEventID returns a copy of the event carrying an identity of its own, distinct from the aggregate ID.
+func WithEventID(event Event, eventID ksuid.KSUID) Event {
+	return withBaseEvent(event, func(base *BaseEvent) {
+		base.EventID = eventID
+	})
+}
+
+func withBaseEvent(event Event, update func(base *BaseEvent)) Event {
+	copied := reflect.New(reflect.TypeOf(event)).Elem()
+	copied.Set(reflect.ValueOf(event))
+	if base, ok := copied.Addr().Interface().(*BaseEvent); ok {
+		update(base)
+	} else {
+		update(copied.FieldByName("BaseEvent").Addr().Interface().(*BaseEvent))
+	}
+	return copied.Interface().(Event)
+}
+
+// GetEventTypeAsString returns the name the event is registered with, or the name of its Go type when it is not registered.
 func GetEventTypeAsString(event Event) string {
+	if typeName, err := DefaultRegistry.TypeName(event); err == nil {
+		return typeName
+	}
 	return reflect.TypeOf(event).Name()
 }
 
@@ -31,6 +77,8 @@ type TabOpened struct {
 	Waiter      string `json:"waiter"`
 }
 
+// DrinksOrdered keeps the items as they were on the menu when ordered, recipes included, so serving them later
+// uses what was ordered.
 type DrinksOrdered struct {
 	BaseEvent
 	Items []shared.MenuItem `json:"items"`
@@ -41,40 +89,217 @@ type DrinksServed struct {
 	MenuNumbers []int `json:"menu_numbers"`
 }
 
+type FoodOrdered struct {
+	BaseEvent
+	Items []shared.MenuItem `json:"items"`
+}
+
+type FoodPrepared struct {
+	BaseEvent
+	MenuNumbers []int `json:"menu_numbers"`
+}
+
+type FoodServed struct {
+	BaseEvent
+	MenuNumbers []int `json:"menu_numbers"`
+}
+
+type ItemsCancelled struct {
+	BaseEvent
+	MenuNumbers []int  `json:"menu_numbers"`
+	Reason      string `json:"reason"`
+}
+
+type DiscountApplied struct {
+	BaseEvent
+	Percentage   float64      `json:"percentage"`
+	Amount       shared.Money `json:"amount"`
+	Reason       string       `json:"reason"`
+	AuthorisedBy string       `json:"authorised_by"`
+}
+
+type ItemComped struct {
+	BaseEvent
+	MenuNumber   int          `json:"menu_number"`
+	Description  string       `json:"description"`
+	Amount       shared.Money `json:"amount"`
+	Reason       string       `json:"reason"`
+	AuthorisedBy string       `json:"authorised_by"`
+}
+
+// PaymentRecorded is attributed to the shift open at the station the payment was taken at, payments recorded
+// without naming a station go to the shift the tab is closed in.
+type PaymentRecorded struct {
+	BaseEvent
+	Method  string       `json:"method"`
+	Amount  shared.Money `json:"amount"`
+	Payer   string       `json:"payer"`
+	Station string       `json:"station,omitempty"`
+	Shift   int          `json:"shift,omitempty"`
+}
+
+// TabClosed is attributed to the shift open at the station the tab was paid at, tabs closed without naming a
+// station belong to no shift.
 type TabClosed struct {
 	BaseEvent
-	AmountPaid  float64 `json:"amount_paid"`
-	OrderAmount float64 `json:"order_amount"`
-	Tip         float64 `json:"tip"`
-}
-
-func UnmarshallPayload(typeName string, payload []byte) (Event, error) {
-	switch typeName {
-	case "TabOpened":
-		var event TabOpened
-		if err := json.Unmarshal(payload, &event); err != nil {
-			return TabOpened{}, fmt.Errorf("could not create TabOpened event from payload: %s", payload)
-		}
-		return event, nil
-	case "DrinksOrdered":
-		var event DrinksOrdered
-		if err := json.Unmarshal(payload, &event); err != nil {
-			return DrinksOrdered{}, fmt.Errorf("could not create DrinksOrdered event from payload: %s", payload)
-		}
-		return event, nil
-	case "DrinksServed":
-		var event DrinksServed
-		if err := json.Unmarshal(payload, &event); err != nil {
-			return DrinksServed{}, fmt.Errorf("could not create DrinkServed event from payload: %s", payload)
-		}
-		return event, nil
-	case "TabClosed":
-		var event TabClosed
-		if err := json.Unmarshal(payload, &event); err != nil {
-			return TabClosed{}, fmt.Errorf("could not create TabClosed event from payload: %s", payload)
-		}
-		return event, nil
-	default:
-		return nil, fmt.Errorf("unsupported type: %s", typeName)
-	}
+	AmountPaid  shared.Money `json:"amount_paid"`
+	OrderAmount shared.Money `json:"order_amount"`
+	Tip         shared.Money `json:"tip"`
+	Station     string       `json:"station,omitempty"`
+	Shift       int          `json:"shift,omitempty"`
+}
+
+type TableClaimed struct {
+	BaseEvent
+	TableNumber int         `json:"table_number"`
+	TabID       ksuid.KSUID `json:"tab_id"`
+}
+
+type TableReleased struct {
+	BaseEvent
+	TableNumber int         `json:"table_number"`
+	TabID       ksuid.KSUID `json:"tab_id"`
+}
+
+type MenuItemAdded struct {
+	BaseEvent
+	MenuNumber  int          `json:"menu_number"`
+	Description string       `json:"description"`
+	Price       shared.Money `json:"price"`
+	IsDrink     bool         `json:"is_drink"`
+	Category    string       `json:"category"`
+}
+
+type MenuItemPriceChanged struct {
+	BaseEvent
+	MenuNumber    int          `json:"menu_number"`
+	Price         shared.Money `json:"price"`
+	PreviousPrice shared.Money `json:"previous_price"`
+}
+
+type MenuItemRenamed struct {
+	BaseEvent
+	MenuNumber  int    `json:"menu_number"`
+	Description string `json:"description"`
+}
+
+type MenuItemCategorised struct {
+	BaseEvent
+	MenuNumber int    `json:"menu_number"`
+	Category   string `json:"category"`
+}
+
+// MenuItemRecipeSet replaces the ingredients an item uses, an empty recipe means the item uses none.
+type MenuItemRecipeSet struct {
+	BaseEvent
+	MenuNumber int                       `json:"menu_number"`
+	Recipe     []shared.RecipeIngredient `json:"recipe"`
+}
+
+// MenuItemRetired takes the item off the menu, orders already placed for it are not affected.
+type MenuItemRetired struct {
+	BaseEvent
+	MenuNumber int `json:"menu_number"`
+}
+
+type StockRestocked struct {
+	BaseEvent
+	MenuNumber int `json:"menu_number"`
+	Quantity   int `json:"quantity"`
+}
+
+// StockCounted replaces the stock on hand with the quantity counted, the difference is what had gone unrecorded.
+type StockCounted struct {
+	BaseEvent
+	MenuNumber int `json:"menu_number"`
+	Quantity   int `json:"quantity"`
+	Difference int `json:"difference"`
+}
+
+type ReorderLevelSet struct {
+	BaseEvent
+	MenuNumber int `json:"menu_number"`
+	Level      int `json:"level"`
+}
+
+// StockReserved holds stock for drinks ordered on a tab until they are served or cancelled.
+type StockReserved struct {
+	BaseEvent
+	MenuNumber        int         `json:"menu_number"`
+	Quantity          int         `json:"quantity"`
+	TabID             ksuid.KSUID `json:"tab_id"`
+	TabSequenceNumber int         `json:"tab_sequence_number"`
+	TabPosition       int64       `json:"tab_position"`
+}
+
+type StockReleased struct {
+	BaseEvent
+	MenuNumber        int         `json:"menu_number"`
+	Quantity          int         `json:"quantity"`
+	TabID             ksuid.KSUID `json:"tab_id"`
+	TabSequenceNumber int         `json:"tab_sequence_number"`
+	TabPosition       int64       `json:"tab_position"`
+}
+
+type StockConsumed struct {
+	BaseEvent
+	MenuNumber        int         `json:"menu_number"`
+	Quantity          int         `json:"quantity"`
+	TabID             ksuid.KSUID `json:"tab_id"`
+	TabSequenceNumber int         `json:"tab_sequence_number"`
+	TabPosition       int64       `json:"tab_position"`
+}
+
+// StockRanLow is raised when the stock available falls to the reorder level or below it.
+type StockRanLow struct {
+	BaseEvent
+	MenuNumber   int `json:"menu_number"`
+	Available    int `json:"available"`
+	ReorderLevel int `json:"reorder_level"`
+}
+
+// IngredientRestocked adds to the stock of an ingredient, in the unit its recipes use. The container size is how
+// much of it comes in a bottle or keg, so the stock can also be told in containers.
+type IngredientRestocked struct {
+	BaseEvent
+	Ingredient    string `json:"ingredient"`
+	Quantity      int    `json:"quantity"`
+	Unit          string `json:"unit"`
+	ContainerSize int    `json:"container_size"`
+}
+
+type IngredientCounted struct {
+	BaseEvent
+	Ingredient string `json:"ingredient"`
+	Quantity   int    `json:"quantity"`
+	Difference int    `json:"difference"`
+}
+
+// IngredientConsumed records what the drinks served in one tab event used of an ingredient.
+type IngredientConsumed struct {
+	BaseEvent
+	Ingredient        string      `json:"ingredient"`
+	Quantity          int         `json:"quantity"`
+	MenuNumbers       []int       `json:"menu_numbers"`
+	TabID             ksuid.KSUID `json:"tab_id"`
+	TabSequenceNumber int         `json:"tab_sequence_number"`
+	TabPosition       int64       `json:"tab_position"`
+}
+
+// ShiftOpened starts a shift at a bar station, the shifts of a station are numbered from 1.
+type ShiftOpened struct {
+	BaseEvent
+	Station      string       `json:"station"`
+	Shift        int          `json:"shift"`
+	OpeningFloat shared.Money `json:"opening_float"`
+}
+
+// ShiftClosed records the cash counted in the drawer, float included. Waiters who cash up their own takings have
+// them counted separately in WaiterCounts.
+type ShiftClosed struct {
+	BaseEvent
+	Station      string                  `json:"station"`
+	Shift        int                     `json:"shift"`
+	CountedCash  shared.Money            `json:"counted_cash"`
+	WaiterCounts map[string]shared.Money `json:"waiter_counts,omitempty"`
 }
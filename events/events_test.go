@@ -0,0 +1,60 @@
+package events_test
+
+import (
+	"context"
+	"cqrseventsourcingbar/events"
+	mock_events "cqrseventsourcingbar/events/mocks"
+	"cqrseventsourcingbar/shared"
+	"errors"
+	"testing"
+
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithSequenceNumberReturnsANumberedCopy(t *testing.T) {
+	// Given
+	tabOpened := events.TabOpened{BaseEvent: events.BaseEvent{ID: ksuid.New()}, TableNumber: 1, Waiter: "w1"}
+
+	// When
+	numbered := events.WithSequenceNumber(tabOpened, 3)
+
+	// Then
+	assert.Equal(t, 3, numbered.GetSequenceNumber())
+	assert.Equal(t, 0, tabOpened.GetSequenceNumber())
+	assert.Equal(t, tabOpened.TableNumber, numbered.(events.TabOpened).TableNumber)
+}
+
+func TestWithSequenceNumberOnBaseEvent(t *testing.T) {
+	numbered := events.WithSequenceNumber(events.BaseEvent{ID: ksuid.New()}, 2)
+
+	assert.Equal(t, 2, numbered.GetSequenceNumber())
+}
+
+func TestEventListenersHandEveryEventToEachListener(t *testing.T) {
+	// Given
+	event := events.BaseEvent{ID: ksuid.New()}
+	first := mock_events.NewEventListener(t)
+	second := mock_events.NewEventListener(t)
+	first.On("HandleEvent", event).Return(errors.New("all broken"))
+	second.On("HandleEvent", event).Return(nil)
+
+	// When
+	err := events.EventListeners{first, second}.HandleEvent(event)
+
+	// Then
+	assert.EqualError(t, err, "all broken")
+	second.AssertCalled(t, "HandleEvent", event)
+}
+
+func TestDefaultRegistryUpcastsLegacyFloatAmounts(t *testing.T) {
+	// When
+	event, err := events.DefaultRegistry.Unmarshal(context.TODO(), "TabClosed", 1, []byte(`{"id":"2qPTBJCN6ib7iJ6WaIVvoSmySSV","amount_paid":3.3,"order_amount":3.1,"tip":0.2}`))
+
+	// Then
+	assert.NoError(t, err)
+	tabClosed := event.(events.TabClosed)
+	assert.Equal(t, shared.Cents(330), tabClosed.AmountPaid)
+	assert.Equal(t, shared.Cents(310), tabClosed.OrderAmount)
+	assert.Equal(t, shared.Cents(20), tabClosed.Tip)
+}
@@ -2,13 +2,47 @@ package events
 
 import (
 	"context"
+	"errors"
 
 	"github.com/segmentio/ksuid"
 )
 
+type RecordedEvent struct {
+	Position       int64
+	SequenceNumber int
+	Event          Event
+}
+
+// ErrConcurrencyConflict is returned by SaveEvents when another writer appended to the aggregate since it was loaded.
+var ErrConcurrencyConflict = errors.New("concurrency conflict, the aggregate was changed by another writer")
+
 //go:generate mockery --name EventStore
 type EventStore interface {
 	LoadEvents(ctx context.Context, aggregateID ksuid.KSUID) ([]Event, error)
+	LoadEventsAfter(ctx context.Context, aggregateID ksuid.KSUID, sequenceNumber int) ([]Event, error)
 	LoadAllEvents(ctx context.Context) ([]Event, error)
+	// LoadEventsFrom returns at most batchSize events with a global position greater than position, in position order.
+	LoadEventsFrom(ctx context.Context, position int64, batchSize int) ([]RecordedEvent, error)
+	// LoadEventsByMetadata returns the events whose metadata matches the query, in position order.
+	LoadEventsByMetadata(ctx context.Context, query MetadataQuery) ([]RecordedEvent, error)
 	SaveEvents(ctx context.Context, aggregateID ksuid.KSUID, previousEventCount int, events []Event) error
 }
+
+// ForEachEventFrom pages through the global log starting after position and returns the position of the last event handled.
+func ForEachEventFrom(ctx context.Context, eventStore EventStore, position int64, batchSize int, handle func(RecordedEvent) error) (int64, error) {
+	for {
+		batch, err := eventStore.LoadEventsFrom(ctx, position, batchSize)
+		if err != nil {
+			return position, err
+		}
+		for _, recordedEvent := range batch {
+			if err := handle(recordedEvent); err != nil {
+				return position, err
+			}
+			position = recordedEvent.Position
+		}
+		if len(batch) < batchSize {
+			return position, nil
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package events_test
+
+import (
+	"context"
+	"cqrseventsourcingbar/events"
+	mock_events "cqrseventsourcingbar/events/mocks"
+	"errors"
+	"testing"
+
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForEachEventFromPagesUntilAShortBatch(t *testing.T) {
+	// Given
+	ctx := context.TODO()
+	eventStore := mock_events.NewEventStore(t)
+	aggregateId := ksuid.New()
+	eventStore.On("LoadEventsFrom", ctx, int64(0), 2).Return([]events.RecordedEvent{
+		{Position: 1, SequenceNumber: 1, Event: events.BaseEvent{ID: aggregateId}},
+		{Position: 2, SequenceNumber: 2, Event: events.BaseEvent{ID: aggregateId}},
+	}, nil)
+	eventStore.On("LoadEventsFrom", ctx, int64(2), 2).Return([]events.RecordedEvent{
+		{Position: 4, SequenceNumber: 3, Event: events.BaseEvent{ID: aggregateId}},
+	}, nil)
+	handled := []int64{}
+
+	// When
+	position, err := events.ForEachEventFrom(ctx, eventStore, 0, 2, func(recordedEvent events.RecordedEvent) error {
+		handled = append(handled, recordedEvent.Position)
+		return nil
+	})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), position)
+	assert.Equal(t, []int64{1, 2, 4}, handled)
+}
+
+func TestForEachEventFromStopsAtTheFirstFailure(t *testing.T) {
+	// Given
+	ctx := context.TODO()
+	eventStore := mock_events.NewEventStore(t)
+	aggregateId := ksuid.New()
+	eventStore.On("LoadEventsFrom", ctx, int64(5), 10).Return([]events.RecordedEvent{
+		{Position: 6, SequenceNumber: 1, Event: events.BaseEvent{ID: aggregateId}},
+		{Position: 7, SequenceNumber: 2, Event: events.BaseEvent{ID: aggregateId}},
+	}, nil)
+
+	// When
+	position, err := events.ForEachEventFrom(ctx, eventStore, 5, 10, func(recordedEvent events.RecordedEvent) error {
+		if recordedEvent.Position == 7 {
+			return errors.New("all broken")
+		}
+		return nil
+	})
+
+	// Then
+	assert.Error(t, err)
+	assert.Equal(t, int64(6), position)
+}
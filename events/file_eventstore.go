@@ -0,0 +1,94 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/segmentio/ksuid"
+)
+
+type fileRecord struct {
+	AggregateID    ksuid.KSUID     `json:"aggregate_id"`
+	SequenceNumber int             `json:"sequence_number"`
+	EventType      string          `json:"event_type"`
+	SchemaVersion  int             `json:"schema_version,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+	Metadata       Metadata        `json:"metadata"`
+}
+
+// NewFileEventStore returns an in-memory event store that appends every save to a JSON lines file before accepting it,
+// and reloads that file on start. It lets the bar run without Postgres, but only one process may use the file at a time.
+func NewFileEventStore(path string) (*InMemoryEventStore, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	es := CreateInMemoryEventStore()
+	err = loadFileRecords(file, es)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error loading events from file: %s, reason: %w", path, err)
+	}
+
+	es.journal = func(aggregateID ksuid.KSUID, recordedEvents []RecordedEvent) error {
+		var lines []byte
+		for _, recordedEvent := range recordedEvents {
+			eventType, schemaVersion, payload, err := DefaultRegistry.Marshal(recordedEvent.Event)
+			if err != nil {
+				return err
+			}
+			line, err := json.Marshal(fileRecord{
+				AggregateID:    aggregateID,
+				SequenceNumber: recordedEvent.SequenceNumber,
+				EventType:      eventType,
+				SchemaVersion:  schemaVersion,
+				Payload:        payload,
+				Metadata:       recordedEvent.Event.GetMetadata(),
+			})
+			if err != nil {
+				return err
+			}
+			lines = append(append(lines, line...), '\n')
+		}
+		if _, err := file.Write(lines); err != nil {
+			return err
+		}
+		return file.Sync()
+	}
+
+	return es, nil
+}
+
+func loadFileRecords(file *os.File, es *InMemoryEventStore) error {
+	decoder := json.NewDecoder(file)
+	for {
+		var record fileRecord
+		err := decoder.Decode(&record)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		expected := len(es.byAggregate[record.AggregateID]) + 1
+		if record.SequenceNumber != expected {
+			return fmt.Errorf("expected sequence number %d for aggregate %s but found %d", expected, record.AggregateID, record.SequenceNumber)
+		}
+
+		event, err := DefaultRegistry.Unmarshal(context.Background(), record.EventType, record.SchemaVersion, record.Payload)
+		if err != nil {
+			return err
+		}
+		es.append(record.AggregateID, []RecordedEvent{{
+			Position:       int64(len(es.log) + 1),
+			SequenceNumber: record.SequenceNumber,
+			Event:          WithMetadata(WithSequenceNumber(event, record.SequenceNumber), record.Metadata),
+		}})
+	}
+}
@@ -0,0 +1,60 @@
+package events_test
+
+import (
+	"context"
+	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/shared"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileEventStoreReloadsSavedEvents(t *testing.T) {
+	// Given
+	ctx := context.TODO()
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	aggregateId := ksuid.New()
+	metadata := events.Metadata{OccurredAt: time.Date(2024, 3, 1, 1, 13, 0, 0, time.UTC), CausationID: ksuid.New(), CorrelationID: "request-1", Actor: "Charles"}
+	tabOpened := events.TabOpened{BaseEvent: events.BaseEvent{ID: aggregateId, EventID: ksuid.New(), Metadata: metadata}, TableNumber: 1, Waiter: "Charles"}
+	drinksOrdered := events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: aggregateId, EventID: ksuid.New()}, Items: []shared.MenuItem{{ID: 1, Description: "water", Price: shared.Cents(150)}}}
+	eventStore, err := events.NewFileEventStore(path)
+	assert.NoError(t, err)
+	assert.NoError(t, eventStore.SaveEvents(ctx, aggregateId, 0, []events.Event{tabOpened, drinksOrdered}))
+
+	// When
+	reopened, err := events.NewFileEventStore(path)
+
+	// Then
+	assert.NoError(t, err)
+	loaded, err := reopened.LoadEvents(ctx, aggregateId)
+	assert.NoError(t, err)
+	assert.Equal(t, []events.Event{events.WithSequenceNumber(tabOpened, 1), events.WithSequenceNumber(drinksOrdered, 2)}, loaded)
+
+	recorded, err := reopened.LoadEventsFrom(ctx, 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, recorded, 2)
+	assert.Equal(t, int64(2), recorded[1].Position)
+
+	err = reopened.SaveEvents(ctx, aggregateId, 1, []events.Event{events.TabClosed{BaseEvent: events.BaseEvent{ID: aggregateId}}})
+	assert.ErrorIs(t, err, events.ErrConcurrencyConflict)
+}
+
+func TestFileEventStoreRefusesAFileWithAGap(t *testing.T) {
+	// Given
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	aggregateId := ksuid.New()
+	line := `{"aggregate_id":"` + aggregateId.String() + `","sequence_number":2,"event_type":"TabClosed","payload":{}}` + "\n"
+	assert.NoError(t, os.WriteFile(path, []byte(line), 0o644))
+
+	// When
+	_, err := events.NewFileEventStore(path)
+
+	// Then
+	if assert.Error(t, err) {
+		assert.Equal(t, "error loading events from file: "+path+", reason: expected sequence number 1 for aggregate "+aggregateId.String()+" but found 2", err.Error())
+	}
+}
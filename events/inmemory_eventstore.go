@@ -0,0 +1,169 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+
+	"github.com/segmentio/ksuid"
+)
+
+// InMemoryEventStore keeps the event log in memory with the same optimistic concurrency rules as the Postgres store.
+// Subscribed listeners get the saved events synchronously, in the order they were saved.
+type InMemoryEventStore struct {
+	lock         sync.RWMutex
+	deliveryLock sync.Mutex
+	log          []RecordedEvent
+	byAggregate  map[ksuid.KSUID][]RecordedEvent
+	listeners    []EventListener
+	undelivered  []delivery
+	journal      func(aggregateID ksuid.KSUID, recordedEvents []RecordedEvent) error
+}
+
+type delivery struct {
+	aggregateID    ksuid.KSUID
+	recordedEvents []RecordedEvent
+	listeners      []EventListener
+}
+
+func CreateInMemoryEventStore() *InMemoryEventStore {
+	return &InMemoryEventStore{
+		byAggregate: make(map[ksuid.KSUID][]RecordedEvent),
+	}
+}
+
+// Subscribe registers a listener for the events saved from now on. Listeners must not save events themselves.
+func (es *InMemoryEventStore) Subscribe(listener EventListener) {
+	defer es.lock.Unlock()
+	es.lock.Lock()
+	es.listeners = append(es.listeners, listener)
+}
+
+func (es *InMemoryEventStore) LoadEvents(ctx context.Context, aggregateID ksuid.KSUID) ([]Event, error) {
+	return es.LoadEventsAfter(ctx, aggregateID, 0)
+}
+
+func (es *InMemoryEventStore) LoadEventsAfter(_ context.Context, aggregateID ksuid.KSUID, sequenceNumber int) ([]Event, error) {
+	defer es.lock.RUnlock()
+	es.lock.RLock()
+
+	var events []Event
+	for _, recordedEvent := range es.byAggregate[aggregateID] {
+		if recordedEvent.SequenceNumber > sequenceNumber {
+			events = append(events, recordedEvent.Event)
+		}
+	}
+	return events, nil
+}
+
+func (es *InMemoryEventStore) LoadAllEvents(_ context.Context) ([]Event, error) {
+	defer es.lock.RUnlock()
+	es.lock.RLock()
+
+	aggregateIDs := make([]ksuid.KSUID, 0, len(es.byAggregate))
+	for aggregateID := range es.byAggregate {
+		aggregateIDs = append(aggregateIDs, aggregateID)
+	}
+	sort.Slice(aggregateIDs, func(i, j int) bool {
+		return aggregateIDs[i].String() < aggregateIDs[j].String()
+	})
+
+	var events []Event
+	for _, aggregateID := range aggregateIDs {
+		for _, recordedEvent := range es.byAggregate[aggregateID] {
+			events = append(events, recordedEvent.Event)
+		}
+	}
+	return events, nil
+}
+
+func (es *InMemoryEventStore) LoadEventsFrom(_ context.Context, position int64, batchSize int) ([]RecordedEvent, error) {
+	defer es.lock.RUnlock()
+	es.lock.RLock()
+
+	if position < 0 {
+		position = 0
+	}
+	if position >= int64(len(es.log)) {
+		return nil, nil
+	}
+	end := min(position+int64(batchSize), int64(len(es.log)))
+	return append([]RecordedEvent(nil), es.log[position:end]...), nil
+}
+
+func (es *InMemoryEventStore) LoadEventsByMetadata(_ context.Context, query MetadataQuery) ([]RecordedEvent, error) {
+	defer es.lock.RUnlock()
+	es.lock.RLock()
+
+	var recordedEvents []RecordedEvent
+	for _, recordedEvent := range es.log {
+		if query.matches(recordedEvent.Event) {
+			recordedEvents = append(recordedEvents, recordedEvent)
+		}
+	}
+	return recordedEvents, nil
+}
+
+func (es *InMemoryEventStore) SaveEvents(_ context.Context, aggregateID ksuid.KSUID, previousEventCount int, events []Event) error {
+	es.lock.Lock()
+
+	lastSequenceNumber := len(es.byAggregate[aggregateID])
+	if lastSequenceNumber != previousEventCount {
+		es.lock.Unlock()
+		return fmt.Errorf("%w: expected %d events for aggregate %s but found %d", ErrConcurrencyConflict, previousEventCount, aggregateID, lastSequenceNumber)
+	}
+
+	recordedEvents := make([]RecordedEvent, 0, len(events))
+	for i, event := range events {
+		recordedEvents = append(recordedEvents, RecordedEvent{
+			Position:       int64(len(es.log) + i + 1),
+			SequenceNumber: previousEventCount + i + 1,
+			Event:          WithSequenceNumber(event, previousEventCount+i+1),
+		})
+	}
+	if es.journal != nil {
+		if err := es.journal(aggregateID, recordedEvents); err != nil {
+			es.lock.Unlock()
+			return err
+		}
+	}
+	es.append(aggregateID, recordedEvents)
+	es.undelivered = append(es.undelivered, delivery{aggregateID: aggregateID, recordedEvents: recordedEvents, listeners: append([]EventListener(nil), es.listeners...)})
+	es.lock.Unlock()
+
+	es.deliverSaved()
+	return nil
+}
+
+// deliverSaved delivers saved events in save order without locking the log, so listeners can load the ones they missed.
+func (es *InMemoryEventStore) deliverSaved() {
+	defer es.deliveryLock.Unlock()
+	es.deliveryLock.Lock()
+	for {
+		es.lock.Lock()
+		if len(es.undelivered) == 0 {
+			es.lock.Unlock()
+			return
+		}
+		next := es.undelivered[0]
+		es.undelivered = es.undelivered[1:]
+		es.lock.Unlock()
+
+		for _, recordedEvent := range next.recordedEvents {
+			for _, listener := range next.listeners {
+				if err := listener.HandleEvent(recordedEvent.Event); err != nil {
+					slog.Error("error delivering saved event", slog.String("aggregate", next.aggregateID.String()), slog.String("error", err.Error()))
+				}
+			}
+		}
+	}
+}
+
+func (es *InMemoryEventStore) append(aggregateID ksuid.KSUID, recordedEvents []RecordedEvent) {
+	for _, recordedEvent := range recordedEvents {
+		es.log = append(es.log, recordedEvent)
+		es.byAggregate[aggregateID] = append(es.byAggregate[aggregateID], recordedEvent)
+	}
+}
@@ -0,0 +1,189 @@
+package events_test
+
+import (
+	"context"
+	"cqrseventsourcingbar/events"
+	mock_events "cqrseventsourcingbar/events/mocks"
+	"cqrseventsourcingbar/shared"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type InMemoryEventStoreTestSuite struct {
+	suite.Suite
+	eventStore *events.InMemoryEventStore
+	ctx        context.Context
+}
+
+func (suite *InMemoryEventStoreTestSuite) SetupTest() {
+	suite.eventStore = events.CreateInMemoryEventStore()
+	suite.ctx = context.TODO()
+}
+
+func (suite *InMemoryEventStoreTestSuite) TestLoadEventsReturnsSavedEventsWithTheirSequenceNumbers() {
+	// Given
+	aggregateId := ksuid.New()
+	tabOpened := events.TabOpened{BaseEvent: events.BaseEvent{ID: aggregateId}, TableNumber: 1, Waiter: "Charles"}
+	drinksOrdered := events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: aggregateId}, Items: []shared.MenuItem{{ID: 1, Description: "water", Price: shared.Cents(150)}}}
+	err := suite.eventStore.SaveEvents(suite.ctx, aggregateId, 0, []events.Event{tabOpened})
+	assert.NoError(suite.T(), err)
+	err = suite.eventStore.SaveEvents(suite.ctx, aggregateId, 1, []events.Event{drinksOrdered})
+	assert.NoError(suite.T(), err)
+	err = suite.eventStore.SaveEvents(suite.ctx, ksuid.New(), 0, []events.Event{events.TabOpened{TableNumber: 2}})
+	assert.NoError(suite.T(), err)
+
+	// When
+	loaded, err := suite.eventStore.LoadEvents(suite.ctx, aggregateId)
+	loadedAfter, errAfter := suite.eventStore.LoadEventsAfter(suite.ctx, aggregateId, 1)
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []events.Event{events.WithSequenceNumber(tabOpened, 1), events.WithSequenceNumber(drinksOrdered, 2)}, loaded)
+	assert.NoError(suite.T(), errAfter)
+	assert.Equal(suite.T(), []events.Event{events.WithSequenceNumber(drinksOrdered, 2)}, loadedAfter)
+}
+
+func (suite *InMemoryEventStoreTestSuite) TestSaveEventsReturnsConflictIfWeAttemptToOverrideExistingEvent() {
+	// Given
+	aggregateId := ksuid.New()
+	err := suite.eventStore.SaveEvents(suite.ctx, aggregateId, 0, []events.Event{events.BaseEvent{ID: aggregateId}, events.BaseEvent{ID: aggregateId}})
+	assert.NoError(suite.T(), err)
+
+	// When
+	err = suite.eventStore.SaveEvents(suite.ctx, aggregateId, 1, []events.Event{events.BaseEvent{ID: aggregateId}})
+
+	// Then
+	assert.ErrorIs(suite.T(), err, events.ErrConcurrencyConflict)
+	loaded, _ := suite.eventStore.LoadEvents(suite.ctx, aggregateId)
+	assert.Len(suite.T(), loaded, 2)
+}
+
+func (suite *InMemoryEventStoreTestSuite) TestOnlyOneOfConcurrentWritersWins() {
+	// Given
+	aggregateId := ksuid.New()
+	writers := 20
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+
+	// When
+	for range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- suite.eventStore.SaveEvents(suite.ctx, aggregateId, 0, []events.Event{events.BaseEvent{ID: aggregateId}})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	// Then
+	conflicts := 0
+	for err := range errs {
+		if errors.Is(err, events.ErrConcurrencyConflict) {
+			conflicts++
+		}
+	}
+	assert.Equal(suite.T(), writers-1, conflicts)
+	loaded, _ := suite.eventStore.LoadEvents(suite.ctx, aggregateId)
+	assert.Len(suite.T(), loaded, 1)
+}
+
+func (suite *InMemoryEventStoreTestSuite) TestLoadEventsFromPagesThroughTheGlobalLog() {
+	// Given
+	aggregateId1 := ksuid.New()
+	aggregateId2 := ksuid.New()
+	assert.NoError(suite.T(), suite.eventStore.SaveEvents(suite.ctx, aggregateId1, 0, []events.Event{events.BaseEvent{ID: aggregateId1}}))
+	assert.NoError(suite.T(), suite.eventStore.SaveEvents(suite.ctx, aggregateId2, 0, []events.Event{events.BaseEvent{ID: aggregateId2}}))
+	assert.NoError(suite.T(), suite.eventStore.SaveEvents(suite.ctx, aggregateId1, 1, []events.Event{events.BaseEvent{ID: aggregateId1}}))
+
+	// When
+	firstBatch, err := suite.eventStore.LoadEventsFrom(suite.ctx, 0, 2)
+	assert.NoError(suite.T(), err)
+	secondBatch, err := suite.eventStore.LoadEventsFrom(suite.ctx, 2, 2)
+	assert.NoError(suite.T(), err)
+	lastBatch, err := suite.eventStore.LoadEventsFrom(suite.ctx, 3, 2)
+	assert.NoError(suite.T(), err)
+
+	// Then
+	assert.Equal(suite.T(), []events.RecordedEvent{
+		{Position: 1, SequenceNumber: 1, Event: events.BaseEvent{ID: aggregateId1, SequenceNumber: 1}},
+		{Position: 2, SequenceNumber: 1, Event: events.BaseEvent{ID: aggregateId2, SequenceNumber: 1}},
+	}, firstBatch)
+	assert.Equal(suite.T(), []events.RecordedEvent{
+		{Position: 3, SequenceNumber: 2, Event: events.BaseEvent{ID: aggregateId1, SequenceNumber: 2}},
+	}, secondBatch)
+	assert.Empty(suite.T(), lastBatch)
+}
+
+func (suite *InMemoryEventStoreTestSuite) TestLoadEventsByMetadataFindsTheEventsOfARequest() {
+	// Given
+	aggregateId := ksuid.New()
+	causationId := ksuid.New()
+	tabOpened := events.TabOpened{BaseEvent: events.BaseEvent{ID: aggregateId, Metadata: events.Metadata{CausationID: causationId, CorrelationID: "request-1", Actor: "Charles"}}, TableNumber: 1, Waiter: "Charles"}
+	drinksOrdered := events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: aggregateId, Metadata: events.Metadata{CausationID: ksuid.New(), CorrelationID: "request-2", Actor: "Charles"}}}
+	assert.NoError(suite.T(), suite.eventStore.SaveEvents(suite.ctx, aggregateId, 0, []events.Event{tabOpened}))
+	assert.NoError(suite.T(), suite.eventStore.SaveEvents(suite.ctx, aggregateId, 1, []events.Event{drinksOrdered}))
+
+	// When
+	byCorrelation, err := suite.eventStore.LoadEventsByMetadata(suite.ctx, events.MetadataQuery{CorrelationID: "request-1"})
+	assert.NoError(suite.T(), err)
+	byActor, err := suite.eventStore.LoadEventsByMetadata(suite.ctx, events.MetadataQuery{Actor: "Charles"})
+	assert.NoError(suite.T(), err)
+	byCausationAndActor, err := suite.eventStore.LoadEventsByMetadata(suite.ctx, events.MetadataQuery{CausationID: causationId, Actor: "Pete"})
+	assert.NoError(suite.T(), err)
+
+	// Then
+	assert.Equal(suite.T(), []events.RecordedEvent{{Position: 1, SequenceNumber: 1, Event: events.WithSequenceNumber(tabOpened, 1)}}, byCorrelation)
+	assert.Equal(suite.T(), []events.RecordedEvent{
+		{Position: 1, SequenceNumber: 1, Event: events.WithSequenceNumber(tabOpened, 1)},
+		{Position: 2, SequenceNumber: 2, Event: events.WithSequenceNumber(drinksOrdered, 2)},
+	}, byActor)
+	assert.Empty(suite.T(), byCausationAndActor)
+}
+
+func (suite *InMemoryEventStoreTestSuite) TestLoadEventsByMetadataFindsTheEventsOfAnAggregateAtATime() {
+	// Given
+	aggregateId := ksuid.New()
+	otherAggregateId := ksuid.New()
+	closedAt := time.Date(2024, 3, 1, 1, 13, 0, 0, time.UTC)
+	tabOpened := events.TabOpened{BaseEvent: events.BaseEvent{ID: aggregateId, Metadata: events.Metadata{OccurredAt: closedAt.Add(-time.Hour), Actor: "Charles"}}, TableNumber: 1, Waiter: "Charles"}
+	tabClosed := events.TabClosed{BaseEvent: events.BaseEvent{ID: aggregateId, Metadata: events.Metadata{OccurredAt: closedAt, Actor: "Pete"}}}
+	otherTabClosed := events.TabClosed{BaseEvent: events.BaseEvent{ID: otherAggregateId, Metadata: events.Metadata{OccurredAt: closedAt, Actor: "Charles"}}}
+	assert.NoError(suite.T(), suite.eventStore.SaveEvents(suite.ctx, aggregateId, 0, []events.Event{tabOpened, tabClosed}))
+	assert.NoError(suite.T(), suite.eventStore.SaveEvents(suite.ctx, otherAggregateId, 0, []events.Event{otherTabClosed}))
+
+	// When
+	found, err := suite.eventStore.LoadEventsByMetadata(suite.ctx, events.MetadataQuery{AggregateID: aggregateId, From: closedAt.Add(-time.Minute), To: closedAt.Add(time.Minute)})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []events.RecordedEvent{{Position: 2, SequenceNumber: 2, Event: events.WithSequenceNumber(tabClosed, 2)}}, found)
+	assert.Equal(suite.T(), "Pete", found[0].Event.GetMetadata().Actor)
+}
+
+func (suite *InMemoryEventStoreTestSuite) TestSubscribersReceiveSavedEvents() {
+	// Given
+	aggregateId := ksuid.New()
+	listener := mock_events.NewEventListener(suite.T())
+	listener.On("HandleEvent", events.BaseEvent{ID: aggregateId, SequenceNumber: 1}).Return(nil).Once()
+	listener.On("HandleEvent", events.BaseEvent{ID: aggregateId, SequenceNumber: 2}).Return(errors.New("all broken")).Once()
+	suite.eventStore.Subscribe(listener)
+
+	// When
+	err := suite.eventStore.SaveEvents(suite.ctx, aggregateId, 0, []events.Event{events.BaseEvent{ID: aggregateId}, events.BaseEvent{ID: aggregateId}})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	err = suite.eventStore.SaveEvents(suite.ctx, aggregateId, 0, []events.Event{events.BaseEvent{ID: aggregateId}})
+	assert.ErrorIs(suite.T(), err, events.ErrConcurrencyConflict)
+}
+
+func TestInMemoryEventStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(InMemoryEventStoreTestSuite))
+}
@@ -1,6 +1,21 @@
 package events
 
+import "errors"
+
 //go:generate mockery --name EventListener
 type EventListener interface {
 	HandleEvent(e Event) error
 }
+
+// EventListeners hands every event to each listener in turn and returns their errors joined.
+type EventListeners []EventListener
+
+func (l EventListeners) HandleEvent(e Event) error {
+	var errs []error
+	for _, listener := range l {
+		if err := listener.HandleEvent(e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
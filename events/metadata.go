@@ -0,0 +1,59 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/ksuid"
+)
+
+// Metadata is the envelope an event is saved in next to its payload, together with the event ID of the BaseEvent.
+// It says when the event happened, which command caused it, which request it was part of and who was acting.
+type Metadata struct {
+	OccurredAt    time.Time   `json:"occurred_at"`
+	CausationID   ksuid.KSUID `json:"causation_id"`
+	CorrelationID string      `json:"correlation_id,omitempty"`
+	Actor         string      `json:"actor,omitempty"`
+}
+
+// WithMetadata returns a copy of the event carrying the given metadata.
+func WithMetadata(event Event, metadata Metadata) Event {
+	return withBaseEvent(event, func(base *BaseEvent) {
+		base.Metadata = metadata
+	})
+}
+
+// MetadataQuery selects events by their metadata, the fields left empty match any event. Tracing a request is
+// looking up its correlation ID, finding who closed a tab is looking up its aggregate around the time it closed.
+// The events found occurred from From, included, until To, excluded.
+type MetadataQuery struct {
+	AggregateID   ksuid.KSUID
+	CausationID   ksuid.KSUID
+	CorrelationID string
+	Actor         string
+	From          time.Time
+	To            time.Time
+}
+
+func (q MetadataQuery) matches(event Event) bool {
+	metadata := event.GetMetadata()
+	return (q.AggregateID.IsNil() || q.AggregateID == event.GetID()) &&
+		(q.CausationID.IsNil() || q.CausationID == metadata.CausationID) &&
+		(q.CorrelationID == "" || q.CorrelationID == metadata.CorrelationID) &&
+		(q.Actor == "" || q.Actor == metadata.Actor) &&
+		(q.From.IsZero() || !metadata.OccurredAt.Before(q.From)) &&
+		(q.To.IsZero() || metadata.OccurredAt.Before(q.To))
+}
+
+type metadataKey struct{}
+
+// ContextWithMetadata attaches the metadata known when a request comes in, like its correlation ID and actor,
+// so the events saved while handling it carry them.
+func ContextWithMetadata(ctx context.Context, metadata Metadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, metadata)
+}
+
+func MetadataFromContext(ctx context.Context) Metadata {
+	metadata, _ := ctx.Value(metadataKey{}).(Metadata)
+	return metadata
+}
@@ -0,0 +1,47 @@
+// Code generated by mockery v2.49.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	events "cqrseventsourcingbar/events"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// DeadLetterSink is an autogenerated mock type for the DeadLetterSink type
+type DeadLetterSink struct {
+	mock.Mock
+}
+
+// DeadLetter provides a mock function with given fields: ctx, unknown
+func (_m *DeadLetterSink) DeadLetter(ctx context.Context, unknown events.UnknownEvent) error {
+	ret := _m.Called(ctx, unknown)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeadLetter")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, events.UnknownEvent) error); ok {
+		r0 = rf(ctx, unknown)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewDeadLetterSink creates a new instance of DeadLetterSink. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewDeadLetterSink(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *DeadLetterSink {
+	mock := &DeadLetterSink{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
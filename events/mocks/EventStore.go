@@ -76,6 +76,96 @@ func (_m *EventStore) LoadEvents(ctx context.Context, aggregateID ksuid.KSUID) (
 	return r0, r1
 }
 
+// LoadEventsAfter provides a mock function with given fields: ctx, aggregateID, sequenceNumber
+func (_m *EventStore) LoadEventsAfter(ctx context.Context, aggregateID ksuid.KSUID, sequenceNumber int) ([]events.Event, error) {
+	ret := _m.Called(ctx, aggregateID, sequenceNumber)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LoadEventsAfter")
+	}
+
+	var r0 []events.Event
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, ksuid.KSUID, int) ([]events.Event, error)); ok {
+		return rf(ctx, aggregateID, sequenceNumber)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, ksuid.KSUID, int) []events.Event); ok {
+		r0 = rf(ctx, aggregateID, sequenceNumber)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]events.Event)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, ksuid.KSUID, int) error); ok {
+		r1 = rf(ctx, aggregateID, sequenceNumber)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LoadEventsByMetadata provides a mock function with given fields: ctx, query
+func (_m *EventStore) LoadEventsByMetadata(ctx context.Context, query events.MetadataQuery) ([]events.RecordedEvent, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LoadEventsByMetadata")
+	}
+
+	var r0 []events.RecordedEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, events.MetadataQuery) ([]events.RecordedEvent, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, events.MetadataQuery) []events.RecordedEvent); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]events.RecordedEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, events.MetadataQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LoadEventsFrom provides a mock function with given fields: ctx, position, batchSize
+func (_m *EventStore) LoadEventsFrom(ctx context.Context, position int64, batchSize int) ([]events.RecordedEvent, error) {
+	ret := _m.Called(ctx, position, batchSize)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LoadEventsFrom")
+	}
+
+	var r0 []events.RecordedEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) ([]events.RecordedEvent, error)); ok {
+		return rf(ctx, position, batchSize)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) []events.RecordedEvent); ok {
+		r0 = rf(ctx, position, batchSize)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]events.RecordedEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int) error); ok {
+		r1 = rf(ctx, position, batchSize)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SaveEvents provides a mock function with given fields: ctx, aggregateID, previousEventCount, _a3
 func (_m *EventStore) SaveEvents(ctx context.Context, aggregateID ksuid.KSUID, previousEventCount int, _a3 []events.Event) error {
 	ret := _m.Called(ctx, aggregateID, previousEventCount, _a3)
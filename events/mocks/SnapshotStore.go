@@ -0,0 +1,79 @@
+// Code generated by mockery v2.49.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	events "cqrseventsourcingbar/events"
+
+	ksuid "github.com/segmentio/ksuid"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SnapshotStore is an autogenerated mock type for the SnapshotStore type
+type SnapshotStore struct {
+	mock.Mock
+}
+
+// LoadSnapshot provides a mock function with given fields: ctx, aggregateID
+func (_m *SnapshotStore) LoadSnapshot(ctx context.Context, aggregateID ksuid.KSUID) (*events.Snapshot, error) {
+	ret := _m.Called(ctx, aggregateID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LoadSnapshot")
+	}
+
+	var r0 *events.Snapshot
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, ksuid.KSUID) (*events.Snapshot, error)); ok {
+		return rf(ctx, aggregateID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, ksuid.KSUID) *events.Snapshot); ok {
+		r0 = rf(ctx, aggregateID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*events.Snapshot)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, ksuid.KSUID) error); ok {
+		r1 = rf(ctx, aggregateID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveSnapshot provides a mock function with given fields: ctx, snapshot
+func (_m *SnapshotStore) SaveSnapshot(ctx context.Context, snapshot events.Snapshot) error {
+	ret := _m.Called(ctx, snapshot)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveSnapshot")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, events.Snapshot) error); ok {
+		r0 = rf(ctx, snapshot)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewSnapshotStore creates a new instance of SnapshotStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSnapshotStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SnapshotStore {
+	mock := &SnapshotStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
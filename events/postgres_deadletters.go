@@ -0,0 +1,33 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postgresDeadLetterSink struct {
+	pool *pgxpool.Pool
+}
+
+// DeadLetter stores the event once, the same event is handed over again every time it is read.
+func (s *postgresDeadLetterSink) DeadLetter(ctx context.Context, unknown UnknownEvent) error {
+	_, err := s.pool.Exec(ctx, "INSERT INTO dead_letter_events (aggregate_id, sequence_number, event_type, schema_version, payload) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (aggregate_id, sequence_number) DO NOTHING",
+		unknown.ID.String(), unknown.SequenceNumber, unknown.EventType, unknown.SchemaVersion, unknown.Payload)
+	return err
+}
+
+func NewPostgresDeadLetterSink(ctx context.Context, connStr string) (DeadLetterSink, error) {
+	pool, err := pgxpool.New(ctx, connStr)
+	if err == nil {
+		err = pool.Ping(ctx)
+	}
+	if err != nil {
+		slog.Error("unable to connect to database", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return &postgresDeadLetterSink{
+		pool: pool,
+	}, nil
+}
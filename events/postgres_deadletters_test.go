@@ -0,0 +1,87 @@
+package events_test
+
+import (
+	"context"
+	"log"
+	"testing"
+
+	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/testhelpers"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type PostgresDeadLetterSinkTestSuite struct {
+	suite.Suite
+	pgContainer    *testhelpers.PostgresContainer
+	deadLetterSink events.DeadLetterSink
+	ctx            context.Context
+}
+
+func (suite *PostgresDeadLetterSinkTestSuite) SetupSuite() {
+	suite.ctx = context.Background()
+	pgContainer, err := testhelpers.CreatePostgresContainer(suite.T(), suite.ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	suite.pgContainer = pgContainer
+	deadLetterSink, err := events.NewPostgresDeadLetterSink(suite.ctx, suite.pgContainer.ConnectionString)
+	if err != nil {
+		log.Fatal(err)
+	}
+	suite.deadLetterSink = deadLetterSink
+}
+
+func (suite *PostgresDeadLetterSinkTestSuite) TearDownSuite() {
+	if err := suite.pgContainer.Terminate(suite.ctx); err != nil {
+		log.Fatalf("error terminating postgres container: %s", err)
+	}
+}
+
+func (suite *PostgresDeadLetterSinkTestSuite) TestDeadLetterIsStored() {
+	// Given
+	aggregateId := ksuid.New()
+	unknown := events.UnknownEvent{BaseEvent: events.BaseEvent{ID: aggregateId, SequenceNumber: 3}, EventType: "TipPooled", SchemaVersion: 1, Payload: []byte(`{"amount": 5}`)}
+
+	// When
+	err := suite.deadLetterSink.DeadLetter(suite.ctx, unknown)
+
+	// Then
+	assert.NoError(suite.T(), err)
+	conn, err := pgx.Connect(suite.ctx, suite.pgContainer.ConnectionString)
+	assert.NoError(suite.T(), err)
+	defer conn.Close(suite.ctx)
+	var eventType string
+	var sequenceNumber int
+	err = conn.QueryRow(suite.ctx, "SELECT event_type, sequence_number FROM dead_letter_events WHERE aggregate_id = $1", aggregateId.String()).Scan(&eventType, &sequenceNumber)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "TipPooled", eventType)
+	assert.Equal(suite.T(), 3, sequenceNumber)
+}
+
+func (suite *PostgresDeadLetterSinkTestSuite) TestDeadLetterIsStoredOnceWhenReadAgain() {
+	// Given
+	aggregateId := ksuid.New()
+	unknown := events.UnknownEvent{BaseEvent: events.BaseEvent{ID: aggregateId, SequenceNumber: 1}, EventType: "TipPooled", SchemaVersion: 1, Payload: []byte(`{"amount": 5}`)}
+	assert.NoError(suite.T(), suite.deadLetterSink.DeadLetter(suite.ctx, unknown))
+
+	// When
+	err := suite.deadLetterSink.DeadLetter(suite.ctx, unknown)
+
+	// Then
+	assert.NoError(suite.T(), err)
+	conn, err := pgx.Connect(suite.ctx, suite.pgContainer.ConnectionString)
+	assert.NoError(suite.T(), err)
+	defer conn.Close(suite.ctx)
+	var count int
+	err = conn.QueryRow(suite.ctx, "SELECT COUNT(*) FROM dead_letter_events WHERE aggregate_id = $1", aggregateId.String()).Scan(&count)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, count)
+}
+
+func TestPostgresDeadLetterSinkTestSuite(t *testing.T) {
+	suite.Run(t, new(PostgresDeadLetterSinkTestSuite))
+}
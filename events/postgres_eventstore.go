@@ -2,60 +2,170 @@ package events
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/segmentio/ksuid"
 )
 
+const eventLogLockID = 7461
+
+const (
+	uniqueViolation      = "23505"
+	serializationFailure = "40001"
+)
+
+// postgresEventStore reads and writes through a pool, it is shared by the dispatchers and the projections that follow the log.
 type postgresEventStore struct {
-	conn *pgx.Conn
+	pool       *pgxpool.Pool
+	withOutbox bool
+}
+
+type PostgresEventStoreOption func(es *postgresEventStore)
+
+// WithOutbox also writes every saved event to the outbox table, in the same transaction, for an OutboxRelay to publish.
+func WithOutbox() PostgresEventStoreOption {
+	return func(es *postgresEventStore) {
+		es.withOutbox = true
+	}
 }
 
 func (es *postgresEventStore) LoadAllEvents(ctx context.Context) ([]Event, error) {
-	rows, err := es.conn.Query(ctx, "SELECT event_type, payload FROM events ORDER BY aggregate_id, sequence_number ASC")
+	rows, err := es.pool.Query(ctx, "SELECT sequence_number, event_type, schema_version, payload, "+metadataColumns+" FROM events ORDER BY aggregate_id, sequence_number ASC")
 
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	return processEvents(rows)
+	return processEvents(ctx, rows)
 }
 
 func (es *postgresEventStore) LoadEvents(ctx context.Context, aggregateID ksuid.KSUID) ([]Event, error) {
-	rows, err := es.conn.Query(ctx, "SELECT event_type, payload FROM events WHERE aggregate_id = $1 ORDER BY sequence_number ASC", aggregateID.String())
+	rows, err := es.pool.Query(ctx, "SELECT sequence_number, event_type, schema_version, payload, "+metadataColumns+" FROM events WHERE aggregate_id = $1 ORDER BY sequence_number ASC", aggregateID.String())
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return processEvents(ctx, rows)
+}
+
+func (es *postgresEventStore) LoadEventsAfter(ctx context.Context, aggregateID ksuid.KSUID, sequenceNumber int) ([]Event, error) {
+	rows, err := es.pool.Query(ctx, "SELECT sequence_number, event_type, schema_version, payload, "+metadataColumns+" FROM events WHERE aggregate_id = $1 AND sequence_number > $2 ORDER BY sequence_number ASC", aggregateID.String(), sequenceNumber)
 
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	return processEvents(rows)
+	return processEvents(ctx, rows)
+}
+
+func (es *postgresEventStore) LoadEventsFrom(ctx context.Context, position int64, batchSize int) ([]RecordedEvent, error) {
+	rows, err := es.pool.Query(ctx, "SELECT position, sequence_number, event_type, schema_version, payload, "+metadataColumns+" FROM events WHERE position > $1 ORDER BY position ASC LIMIT $2", position, batchSize)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return processRecordedEvents(ctx, rows)
+}
+
+func (es *postgresEventStore) LoadEventsByMetadata(ctx context.Context, query MetadataQuery) ([]RecordedEvent, error) {
+	var aggregateID, causationID string
+	if !query.AggregateID.IsNil() {
+		aggregateID = query.AggregateID.String()
+	}
+	if !query.CausationID.IsNil() {
+		causationID = query.CausationID.String()
+	}
+	var from, to *time.Time
+	if !query.From.IsZero() {
+		from = &query.From
+	}
+	if !query.To.IsZero() {
+		to = &query.To
+	}
+	rows, err := es.pool.Query(ctx, "SELECT position, sequence_number, event_type, schema_version, payload, "+metadataColumns+" FROM events WHERE ($1::text = '' OR aggregate_id = $1) AND ($2::text = '' OR causation_id = $2) AND ($3::text = '' OR correlation_id = $3) AND ($4::text = '' OR actor = $4) AND ($5::timestamptz IS NULL OR timestamp >= $5) AND ($6::timestamptz IS NULL OR timestamp < $6) ORDER BY position ASC",
+		aggregateID, causationID, query.CorrelationID, query.Actor, from, to)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return processRecordedEvents(ctx, rows)
+}
+
+func processRecordedEvents(ctx context.Context, rows pgx.Rows) ([]RecordedEvent, error) {
+	var recordedEvents []RecordedEvent
+	for rows.Next() {
+		var recordedEvent RecordedEvent
+		var eventType string
+		var schemaVersion int
+		var payload []byte
+		var row metadataRow
+		if err := rows.Scan(append([]any{&recordedEvent.Position, &recordedEvent.SequenceNumber, &eventType, &schemaVersion, &payload}, row.targets()...)...); err != nil {
+			return nil, err
+		}
+		event, err := DefaultRegistry.Unmarshal(ctx, eventType, schemaVersion, payload)
+		if err != nil {
+			return nil, err
+		}
+		metadata, err := row.metadata()
+		if err != nil {
+			return nil, err
+		}
+		recordedEvent.Event = WithMetadata(WithSequenceNumber(event, recordedEvent.SequenceNumber), metadata)
+		recordedEvents = append(recordedEvents, recordedEvent)
+	}
+	return recordedEvents, rows.Err()
 }
 
-func processEvents(rows pgx.Rows) ([]Event, error) {
+func processEvents(ctx context.Context, rows pgx.Rows) ([]Event, error) {
 	var events []Event
 	for rows.Next() {
-		var event Event
+		var sequenceNumber int
 		var eventType string
+		var schemaVersion int
 		var payload []byte
-		if err := rows.Scan(&eventType, &payload); err != nil {
+		var row metadataRow
+		if err := rows.Scan(append([]any{&sequenceNumber, &eventType, &schemaVersion, &payload}, row.targets()...)...); err != nil {
 			return nil, err
 		}
-		event, err := UnmarshallPayload(eventType, payload)
+		event, err := DefaultRegistry.Unmarshal(ctx, eventType, schemaVersion, payload)
 		if err != nil {
 			return nil, err
 		}
-		events = append(events, event)
+		metadata, err := row.metadata()
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, WithMetadata(WithSequenceNumber(event, sequenceNumber), metadata))
 	}
 	return events, nil
 }
 
-func (es *postgresEventStore) SaveEvents(ctx context.Context, aggregateID ksuid.KSUID, previousEventCount int, events []Event) (err error) {
-	tx, err := es.conn.BeginTx(ctx, pgx.TxOptions{
+func (es *postgresEventStore) SaveEvents(ctx context.Context, aggregateID ksuid.KSUID, previousEventCount int, events []Event) error {
+	err := es.saveEvents(ctx, aggregateID, previousEventCount, events)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && (pgErr.Code == uniqueViolation || pgErr.Code == serializationFailure) {
+		return fmt.Errorf("%w: %s", ErrConcurrencyConflict, pgErr.Message)
+	}
+	return err
+}
+
+func (es *postgresEventStore) saveEvents(ctx context.Context, aggregateID ksuid.KSUID, previousEventCount int, events []Event) (err error) {
+	tx, err := es.pool.BeginTx(ctx, pgx.TxOptions{
 		IsoLevel:   pgx.Serializable,
 		AccessMode: pgx.ReadWrite,
 	})
@@ -70,27 +180,102 @@ func (es *postgresEventStore) SaveEvents(ctx context.Context, aggregateID ksuid.
 		}
 	}()
 
+	// Writers take turns so positions become visible in commit order and readers following the log never skip one.
+	_, err = tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", eventLogLockID)
+	if err != nil {
+		return err
+	}
+
+	var lastSequenceNumber int
+	err = tx.QueryRow(ctx, "SELECT COALESCE(MAX(sequence_number), 0) FROM events WHERE aggregate_id = $1", aggregateID).Scan(&lastSequenceNumber)
+	if err != nil {
+		return err
+	}
+	if lastSequenceNumber != previousEventCount {
+		return fmt.Errorf("%w: expected %d events for aggregate %s but found %d", ErrConcurrencyConflict, previousEventCount, aggregateID, lastSequenceNumber)
+	}
+
 	for i, event := range events {
-		payload, err := json.Marshal(event)
+		eventType, schemaVersion, payload, err := DefaultRegistry.Marshal(event)
 		if err != nil {
 			return err
 		}
-		_, err = tx.Exec(ctx, "INSERT INTO events (aggregate_id, sequence_number, timestamp, event_type, payload) VALUES ($1, $2, NOW(), $3, $4)", aggregateID, previousEventCount+i+1, GetEventTypeAsString(event), payload)
+		row := newMetadataRow(event.GetMetadata())
+		_, err = tx.Exec(ctx, "INSERT INTO events (aggregate_id, sequence_number, event_type, schema_version, payload, "+metadataColumns+") VALUES ($1, $2, $3, $4, $5, COALESCE($6, NOW()), $7, $8, $9)",
+			append([]any{aggregateID, previousEventCount + i + 1, eventType, schemaVersion, payload}, row.values()...)...)
 		if err != nil {
 			return err
 		}
+		if es.withOutbox {
+			_, err = tx.Exec(ctx, "INSERT INTO outbox (aggregate_id, sequence_number, event_type, schema_version, payload, "+metadataColumns+") VALUES ($1, $2, $3, $4, $5, COALESCE($6, NOW()), $7, $8, $9)",
+				append([]any{aggregateID, previousEventCount + i + 1, eventType, schemaVersion, payload}, row.values()...)...)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	return tx.Commit(ctx)
 }
 
-func NewPostgresEventStore(ctx context.Context, connStr string) (EventStore, error) {
-	conn, err := pgx.Connect(ctx, connStr)
+const metadataColumns = "timestamp, causation_id, correlation_id, actor"
+
+// metadataRow is the metadata as kept in the metadataColumns, events saved without a time get the one of the database.
+type metadataRow struct {
+	occurredAt    *time.Time
+	causationID   string
+	correlationID string
+	actor         string
+}
+
+func newMetadataRow(metadata Metadata) metadataRow {
+	row := metadataRow{correlationID: metadata.CorrelationID, actor: metadata.Actor}
+	if !metadata.OccurredAt.IsZero() {
+		row.occurredAt = &metadata.OccurredAt
+	}
+	if metadata.CausationID != ksuid.Nil {
+		row.causationID = metadata.CausationID.String()
+	}
+	return row
+}
+
+func (row *metadataRow) values() []any {
+	return []any{row.occurredAt, row.causationID, row.correlationID, row.actor}
+}
+
+func (row *metadataRow) targets() []any {
+	return []any{&row.occurredAt, &row.causationID, &row.correlationID, &row.actor}
+}
+
+func (row *metadataRow) metadata() (Metadata, error) {
+	metadata := Metadata{CorrelationID: row.correlationID, Actor: row.actor}
+	if row.occurredAt != nil {
+		metadata.OccurredAt = row.occurredAt.UTC()
+	}
+	if row.causationID != "" {
+		causationID, err := ksuid.Parse(row.causationID)
+		if err != nil {
+			return Metadata{}, fmt.Errorf("could not parse causation id: %s, reason: %w", row.causationID, err)
+		}
+		metadata.CausationID = causationID
+	}
+	return metadata, nil
+}
+
+func NewPostgresEventStore(ctx context.Context, connStr string, options ...PostgresEventStoreOption) (EventStore, error) {
+	pool, err := pgxpool.New(ctx, connStr)
+	if err == nil {
+		err = pool.Ping(ctx)
+	}
 	if err != nil {
 		slog.Error("unable to connect to database", slog.String("error", err.Error()))
 		return nil, err
 	}
-	return &postgresEventStore{
-		conn: conn,
-	}, nil
+	es := &postgresEventStore{
+		pool: pool,
+	}
+	for _, option := range options {
+		option(es)
+	}
+	return es, nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"log"
 	"testing"
+	"time"
 
 	"cqrseventsourcingbar/events"
 	"cqrseventsourcingbar/shared"
@@ -55,23 +56,37 @@ func (suite *PostgresEventStoreTestSuite) TestLoadEvents() {
 	tabOpened, ok := loadedEvents[0].(events.TabOpened)
 	assert.True(t, ok)
 	assert.Equal(t, events.TabOpened{
-		BaseEvent:   events.BaseEvent{ID: aggregateId},
+		BaseEvent:   events.BaseEvent{ID: aggregateId, SequenceNumber: 1, Metadata: fixtureMetadata(t, tabOpened)},
 		TableNumber: 1,
 		Waiter:      "w1",
 	}, tabOpened)
 	drinksOrdered, ok := loadedEvents[1].(events.DrinksOrdered)
 	assert.True(t, ok)
 	assert.Equal(t, events.DrinksOrdered{
-		BaseEvent: events.BaseEvent{ID: aggregateId},
+		BaseEvent: events.BaseEvent{ID: aggregateId, SequenceNumber: 2, Metadata: fixtureMetadata(t, drinksOrdered)},
 		Items: []shared.MenuItem{{
 			ID:          1,
 			Description: "water",
-			Price:       1.5,
+			Price:       shared.Cents(150),
+			IsDrink:     true,
 		}},
 	}, drinksOrdered)
 
 }
 
+func (suite *PostgresEventStoreTestSuite) TestLoadEventsAfter() {
+	t := suite.T()
+	// Given
+	aggregateId, _ := ksuid.Parse("2qPTBJCN6ib7iJ6WaIVvoSmySSV")
+	// When
+	loadedEvents, err := suite.eventStorePostgres.LoadEventsAfter(context.TODO(), aggregateId, 1)
+	// Then
+	assert.NoError(t, err)
+	assert.Len(t, loadedEvents, 1)
+	_, ok := loadedEvents[0].(events.DrinksOrdered)
+	assert.True(t, ok)
+}
+
 func (suite *PostgresEventStoreTestSuite) TestLoadAllEvents() {
 	t := suite.T()
 	// Given
@@ -87,20 +102,42 @@ func (suite *PostgresEventStoreTestSuite) TestLoadAllEvents() {
 	tabOpened, ok := loadedEvents[0].(events.TabOpened)
 	assert.True(t, ok)
 	assert.Equal(t, events.TabOpened{
-		BaseEvent:   events.BaseEvent{ID: aggregateId1},
+		BaseEvent:   events.BaseEvent{ID: aggregateId1, SequenceNumber: 1, Metadata: fixtureMetadata(t, tabOpened)},
 		TableNumber: 2,
 		Waiter:      "w2",
 	}, tabOpened)
 	tabOpened2, ok := loadedEvents[1].(events.TabOpened)
 	assert.True(t, ok)
 	assert.Equal(t, events.TabOpened{
-		BaseEvent:   events.BaseEvent{ID: aggregateId2},
+		BaseEvent:   events.BaseEvent{ID: aggregateId2, SequenceNumber: 1, Metadata: fixtureMetadata(t, tabOpened2)},
 		TableNumber: 1,
 		Waiter:      "w1",
 	}, tabOpened2)
 
 }
 
+func (suite *PostgresEventStoreTestSuite) TestLoadEventsFrom() {
+	t := suite.T()
+	// Given
+	aggregateId1, _ := ksuid.Parse("1qPTBJCN6ib7iJ6WaIVvoSmySSV")
+	aggregateId2, _ := ksuid.Parse("2qPTBJCN6ib7iJ6WaIVvoSmySSV")
+	// When
+	firstBatch, err := suite.eventStorePostgres.LoadEventsFrom(context.TODO(), 0, 2)
+	assert.NoError(t, err)
+	secondBatch, err := suite.eventStorePostgres.LoadEventsFrom(context.TODO(), firstBatch[1].Position, 2)
+	// Then
+	assert.NoError(t, err)
+	assert.Len(t, firstBatch, 2)
+	assert.Equal(t, int64(1), firstBatch[0].Position)
+	assert.Equal(t, 1, firstBatch[0].SequenceNumber)
+	assert.Equal(t, aggregateId2, firstBatch[0].Event.GetID())
+	assert.Equal(t, int64(2), firstBatch[1].Position)
+	assert.Equal(t, 2, firstBatch[1].SequenceNumber)
+	assert.Len(t, secondBatch, 1)
+	assert.Equal(t, int64(3), secondBatch[0].Position)
+	assert.Equal(t, aggregateId1, secondBatch[0].Event.GetID())
+}
+
 func (suite *PostgresEventStoreTestSuite) TestSaveEventsErrorsIfWeAttemptToOverrideExistingEvent() {
 	// Given
 	aggregateId, _ := ksuid.Parse("2qPTBJCN6ib7iJ6WaIVvoSmySSV")
@@ -110,14 +147,13 @@ func (suite *PostgresEventStoreTestSuite) TestSaveEventsErrorsIfWeAttemptToOverr
 		Items: []shared.MenuItem{{
 			ID:          1,
 			Description: "water",
-			Price:       1.5,
+			Price:       shared.Cents(150),
 		}}}}
 
 	// When
 	err := suite.eventStorePostgres.SaveEvents(context.TODO(), aggregateId, 1, eventsToSave)
 	// Then
-	assert.Error(suite.T(), err)
-	assert.Equal(suite.T(), "ERROR: duplicate key value violates unique constraint \"events_pkey\" (SQLSTATE 23505)", err.Error())
+	assert.ErrorIs(suite.T(), err, events.ErrConcurrencyConflict)
 }
 
 func (suite *PostgresEventStoreTestSuite) TestSaveEvents() {
@@ -129,7 +165,7 @@ func (suite *PostgresEventStoreTestSuite) TestSaveEvents() {
 		Items: []shared.MenuItem{{
 			ID:          1,
 			Description: "water",
-			Price:       1.5,
+			Price:       shared.Cents(150),
 		}}}}
 
 	// When
@@ -138,6 +174,83 @@ func (suite *PostgresEventStoreTestSuite) TestSaveEvents() {
 	assert.NoError(suite.T(), err)
 }
 
+func (suite *PostgresEventStoreTestSuite) TestSavedMetadataIsLoadedBack() {
+	// Given
+	aggregateId := ksuid.New()
+	metadata := events.Metadata{OccurredAt: time.Date(2024, 3, 1, 1, 13, 0, 123456000, time.UTC), CausationID: ksuid.New(), CorrelationID: "request-2", Actor: "Charles"}
+	tabClosed := events.TabClosed{BaseEvent: events.BaseEvent{ID: aggregateId, EventID: ksuid.New(), Metadata: metadata}, AmountPaid: shared.Cents(150), OrderAmount: shared.Cents(150), Tip: shared.Cents(0)}
+	tabOpened := events.TabOpened{BaseEvent: events.BaseEvent{ID: aggregateId}, TableNumber: 9, Waiter: "Charles"}
+
+	// When
+	err := suite.eventStorePostgres.SaveEvents(suite.ctx, aggregateId, 0, []events.Event{tabOpened, tabClosed})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	loadedEvents, err := suite.eventStorePostgres.LoadEvents(suite.ctx, aggregateId)
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), loadedEvents, 2) {
+		assert.False(suite.T(), loadedEvents[0].GetMetadata().OccurredAt.IsZero(), "events saved without a time get the one of the database")
+		assert.Equal(suite.T(), events.WithSequenceNumber(tabClosed, 2), loadedEvents[1])
+	}
+}
+
+func (suite *PostgresEventStoreTestSuite) TestSavedEventsAreFoundByTheirMetadata() {
+	// Given
+	aggregateId := ksuid.New()
+	causationId := ksuid.New()
+	tabOpened := events.TabOpened{BaseEvent: events.BaseEvent{ID: aggregateId, EventID: ksuid.New(), Metadata: events.Metadata{CausationID: causationId, CorrelationID: "request-3", Actor: "Pete"}}, TableNumber: 10, Waiter: "Pete"}
+	drinksOrdered := events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: aggregateId, EventID: ksuid.New(), Metadata: events.Metadata{CausationID: ksuid.New(), CorrelationID: "request-4", Actor: "Pete"}}}
+	assert.NoError(suite.T(), suite.eventStorePostgres.SaveEvents(suite.ctx, aggregateId, 0, []events.Event{tabOpened, drinksOrdered}))
+
+	// When
+	byCorrelation, err := suite.eventStorePostgres.LoadEventsByMetadata(suite.ctx, events.MetadataQuery{CorrelationID: "request-3"})
+	assert.NoError(suite.T(), err)
+	byActor, err := suite.eventStorePostgres.LoadEventsByMetadata(suite.ctx, events.MetadataQuery{Actor: "Pete"})
+	assert.NoError(suite.T(), err)
+	byCausationAndActor, err := suite.eventStorePostgres.LoadEventsByMetadata(suite.ctx, events.MetadataQuery{CausationID: causationId, Actor: "Charles"})
+	assert.NoError(suite.T(), err)
+
+	// Then
+	if assert.Len(suite.T(), byCorrelation, 1) {
+		assert.Equal(suite.T(), aggregateId, byCorrelation[0].Event.GetID())
+		assert.Equal(suite.T(), 1, byCorrelation[0].SequenceNumber)
+	}
+	if assert.Len(suite.T(), byActor, 2) {
+		assert.Less(suite.T(), byActor[0].Position, byActor[1].Position)
+		assert.IsType(suite.T(), events.DrinksOrdered{}, byActor[1].Event)
+	}
+	assert.Empty(suite.T(), byCausationAndActor)
+}
+
+func (suite *PostgresEventStoreTestSuite) TestSavedEventsAreFoundByAggregateAndTime() {
+	// Given
+	aggregateId := ksuid.New()
+	otherAggregateId := ksuid.New()
+	closedAt := time.Date(2024, 3, 1, 1, 13, 0, 0, time.UTC)
+	tabOpened := events.TabOpened{BaseEvent: events.BaseEvent{ID: aggregateId, EventID: ksuid.New(), Metadata: events.Metadata{OccurredAt: closedAt.Add(-time.Hour), Actor: "Charles"}}, TableNumber: 11, Waiter: "Charles"}
+	tabClosed := events.TabClosed{BaseEvent: events.BaseEvent{ID: aggregateId, EventID: ksuid.New(), Metadata: events.Metadata{OccurredAt: closedAt, Actor: "Pete"}}, AmountPaid: shared.Cents(0), OrderAmount: shared.Cents(0), Tip: shared.Cents(0)}
+	otherTabClosed := events.TabClosed{BaseEvent: events.BaseEvent{ID: otherAggregateId, EventID: ksuid.New(), Metadata: events.Metadata{OccurredAt: closedAt, Actor: "Charles"}}, AmountPaid: shared.Cents(0), OrderAmount: shared.Cents(0), Tip: shared.Cents(0)}
+	assert.NoError(suite.T(), suite.eventStorePostgres.SaveEvents(suite.ctx, aggregateId, 0, []events.Event{tabOpened, tabClosed}))
+	assert.NoError(suite.T(), suite.eventStorePostgres.SaveEvents(suite.ctx, otherAggregateId, 0, []events.Event{otherTabClosed}))
+
+	// When
+	found, err := suite.eventStorePostgres.LoadEventsByMetadata(suite.ctx, events.MetadataQuery{AggregateID: aggregateId, From: closedAt.Add(-time.Minute), To: closedAt.Add(time.Minute)})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), found, 1) {
+		assert.Equal(suite.T(), events.WithSequenceNumber(tabClosed, 2), found[0].Event)
+	}
+}
+
+// fixtureMetadata is the metadata of an event of testdata/init-db.sql, inserted without any, so only with the time
+// the database was set up at.
+func fixtureMetadata(t *testing.T, event events.Event) events.Metadata {
+	occurredAt := event.GetMetadata().OccurredAt
+	assert.WithinDuration(t, time.Now(), occurredAt, time.Hour)
+	return events.Metadata{OccurredAt: occurredAt}
+}
+
 func TestPostgresEventStoreTestSuite(t *testing.T) {
 	suite.Run(t, new(PostgresEventStoreTestSuite))
 }
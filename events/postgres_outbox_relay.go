@@ -0,0 +1,138 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// OutboxRelay publishes the events written to the outbox table and marks them as sent.
+// Delivery is at least once: a crash between emitting and committing publishes the same rows again.
+type OutboxRelay struct {
+	conn         *pgx.Conn
+	eventEmitter EventEmitter
+	batchSize    int
+	pollInterval time.Duration
+}
+
+func NewPostgresOutboxRelay(ctx context.Context, connStr string, eventEmitter EventEmitter, batchSize int, pollInterval time.Duration) (*OutboxRelay, error) {
+	conn, err := pgx.Connect(ctx, connStr)
+	if err != nil {
+		slog.Error("unable to connect to database", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return &OutboxRelay{
+		conn:         conn,
+		eventEmitter: eventEmitter,
+		batchSize:    batchSize,
+		pollInterval: pollInterval,
+	}, nil
+}
+
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+	for {
+		relayed, err := r.RelayPending(ctx)
+		if err != nil {
+			slog.Error("error relaying outbox", slog.String("error", err.Error()))
+		}
+		if relayed == r.batchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayPending emits up to batchSize pending events in the order they were saved and returns how many were sent.
+// It stops at the first event that cannot be emitted so later events never overtake it. An entry that cannot be
+// read back as an event never will be, it is moved to the dead_letter_events table so it doesn't hold up the rest.
+func (r *OutboxRelay) RelayPending(ctx context.Context) (relayed int, err error) {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			if err := tx.Rollback(ctx); err != nil {
+				slog.Error("error, rollback", slog.String("error", err.Error()))
+			}
+		}
+	}()
+
+	rows, err := tx.Query(ctx, "SELECT id, sequence_number, event_type, schema_version, payload, "+metadataColumns+" FROM outbox WHERE sent_at IS NULL ORDER BY id ASC LIMIT $1 FOR UPDATE SKIP LOCKED", r.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	type pendingEvent struct {
+		id    int64
+		event Event
+	}
+	var pendingEvents []pendingEvent
+	var corruptIDs []int64
+	for rows.Next() {
+		var id int64
+		var sequenceNumber int
+		var eventType string
+		var schemaVersion int
+		var payload []byte
+		var row metadataRow
+		if err = rows.Scan(append([]any{&id, &sequenceNumber, &eventType, &schemaVersion, &payload}, row.targets()...)...); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		event, unmarshalErr := DefaultRegistry.Unmarshal(ctx, eventType, schemaVersion, payload)
+		if unmarshalErr != nil {
+			slog.Error("dead-lettering outbox entry", slog.Int64("id", id), slog.String("error", unmarshalErr.Error()))
+			corruptIDs = append(corruptIDs, id)
+			continue
+		}
+		metadata, metadataErr := row.metadata()
+		if metadataErr != nil {
+			slog.Error("dead-lettering outbox entry", slog.Int64("id", id), slog.String("error", metadataErr.Error()))
+			corruptIDs = append(corruptIDs, id)
+			continue
+		}
+		pendingEvents = append(pendingEvents, pendingEvent{id: id, event: WithMetadata(WithSequenceNumber(event, sequenceNumber), metadata)})
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, id := range corruptIDs {
+		if _, err = tx.Exec(ctx, deadLetterOutboxEntry, id); err != nil {
+			return 0, err
+		}
+	}
+
+	var emitErr error
+	for _, pending := range pendingEvents {
+		if emitErr = r.eventEmitter.EmitEvent(pending.event); emitErr != nil {
+			emitErr = fmt.Errorf("error when emitting event [%s] from outbox entry %d, reason: %w", GetEventTypeAsString(pending.event), pending.id, emitErr)
+			break
+		}
+		if _, err = tx.Exec(ctx, "UPDATE outbox SET sent_at = NOW() WHERE id = $1", pending.id); err != nil {
+			return 0, err
+		}
+		relayed++
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return relayed, emitErr
+}
+
+const deadLetterOutboxEntry = `WITH corrupt AS (DELETE FROM outbox WHERE id = $1 RETURNING aggregate_id, sequence_number, event_type, schema_version, payload)
+INSERT INTO dead_letter_events (aggregate_id, sequence_number, event_type, schema_version, payload) SELECT aggregate_id, sequence_number, event_type, schema_version, payload FROM corrupt
+ON CONFLICT (aggregate_id, sequence_number) DO NOTHING`
@@ -0,0 +1,136 @@
+package events_test
+
+import (
+	"context"
+	"errors"
+	"log"
+	"testing"
+	"time"
+
+	"cqrseventsourcingbar/events"
+	mock_events "cqrseventsourcingbar/events/mocks"
+	"cqrseventsourcingbar/testhelpers"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type PostgresOutboxRelayTestSuite struct {
+	suite.Suite
+	pgContainer  *testhelpers.PostgresContainer
+	eventStore   events.EventStore
+	eventEmitter *mock_events.EventEmitter
+	relay        *events.OutboxRelay
+	ctx          context.Context
+}
+
+func (suite *PostgresOutboxRelayTestSuite) SetupSuite() {
+	suite.ctx = context.Background()
+	pgContainer, err := testhelpers.CreatePostgresContainer(suite.T(), suite.ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	suite.pgContainer = pgContainer
+	eventStore, err := events.NewPostgresEventStore(suite.ctx, suite.pgContainer.ConnectionString, events.WithOutbox())
+	if err != nil {
+		log.Fatal(err)
+	}
+	suite.eventStore = eventStore
+}
+
+func (suite *PostgresOutboxRelayTestSuite) SetupTest() {
+	suite.eventEmitter = mock_events.NewEventEmitter(suite.T())
+	relay, err := events.NewPostgresOutboxRelay(suite.ctx, suite.pgContainer.ConnectionString, suite.eventEmitter, 10, time.Second)
+	if err != nil {
+		log.Fatal(err)
+	}
+	suite.relay = relay
+}
+
+func (suite *PostgresOutboxRelayTestSuite) TearDownSuite() {
+	if err := suite.pgContainer.Terminate(suite.ctx); err != nil {
+		log.Fatalf("error terminating postgres container: %s", err)
+	}
+}
+
+func (suite *PostgresOutboxRelayTestSuite) TestSavedEventsAreRelayedOnce() {
+	// Given
+	aggregateId := ksuid.New()
+	metadata := events.Metadata{OccurredAt: time.Date(2024, 3, 1, 1, 13, 0, 0, time.UTC), CausationID: ksuid.New(), CorrelationID: "request-1", Actor: "w1"}
+	tabOpened := events.TabOpened{BaseEvent: events.BaseEvent{ID: aggregateId, Metadata: metadata}, TableNumber: 5, Waiter: "w1"}
+	tabClosed := events.TabClosed{BaseEvent: events.BaseEvent{ID: aggregateId, Metadata: metadata}}
+	err := suite.eventStore.SaveEvents(suite.ctx, aggregateId, 0, []events.Event{tabOpened, tabClosed})
+	assert.NoError(suite.T(), err)
+	suite.eventEmitter.On("EmitEvent", events.WithSequenceNumber(tabOpened, 1)).Return(nil).Once()
+	suite.eventEmitter.On("EmitEvent", events.WithSequenceNumber(tabClosed, 2)).Return(nil).Once()
+
+	// When
+	relayed, err := suite.relay.RelayPending(suite.ctx)
+	assert.NoError(suite.T(), err)
+	relayedAgain, err := suite.relay.RelayPending(suite.ctx)
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, relayed)
+	assert.Equal(suite.T(), 0, relayedAgain)
+}
+
+func (suite *PostgresOutboxRelayTestSuite) TestEventThatFailsToEmitIsRetried() {
+	// Given
+	aggregateId := ksuid.New()
+	metadata := events.Metadata{OccurredAt: time.Date(2024, 3, 1, 1, 14, 0, 0, time.UTC)}
+	tabOpened := events.TabOpened{BaseEvent: events.BaseEvent{ID: aggregateId, Metadata: metadata}, TableNumber: 6, Waiter: "w2"}
+	err := suite.eventStore.SaveEvents(suite.ctx, aggregateId, 0, []events.Event{tabOpened})
+	assert.NoError(suite.T(), err)
+	suite.eventEmitter.On("EmitEvent", events.WithSequenceNumber(tabOpened, 1)).Return(errors.New("all broken")).Once()
+	suite.eventEmitter.On("EmitEvent", events.WithSequenceNumber(tabOpened, 1)).Return(nil).Once()
+
+	// When
+	relayed, err := suite.relay.RelayPending(suite.ctx)
+	assert.Error(suite.T(), err)
+	relayedAgain, errAgain := suite.relay.RelayPending(suite.ctx)
+
+	// Then
+	assert.Equal(suite.T(), 0, relayed)
+	assert.NoError(suite.T(), errAgain)
+	assert.Equal(suite.T(), 1, relayedAgain)
+}
+
+func (suite *PostgresOutboxRelayTestSuite) TestCorruptEntryIsDeadLetteredWithoutHoldingUpTheOthers() {
+	// Given
+	conn, err := pgx.Connect(suite.ctx, suite.pgContainer.ConnectionString)
+	assert.NoError(suite.T(), err)
+	defer conn.Close(suite.ctx)
+	corruptId := ksuid.New()
+	_, err = conn.Exec(suite.ctx, "INSERT INTO outbox (aggregate_id, sequence_number, event_type, payload) VALUES ($1, 1, 'TabOpened', '{\"table_number\": \"seven\"}')", corruptId.String())
+	assert.NoError(suite.T(), err)
+	aggregateId := ksuid.New()
+	metadata := events.Metadata{OccurredAt: time.Date(2024, 3, 1, 1, 15, 0, 0, time.UTC)}
+	tabOpened := events.TabOpened{BaseEvent: events.BaseEvent{ID: aggregateId, Metadata: metadata}, TableNumber: 7, Waiter: "w1"}
+	err = suite.eventStore.SaveEvents(suite.ctx, aggregateId, 0, []events.Event{tabOpened})
+	assert.NoError(suite.T(), err)
+	suite.eventEmitter.On("EmitEvent", events.WithSequenceNumber(tabOpened, 1)).Return(nil).Once()
+
+	// When
+	relayed, err := suite.relay.RelayPending(suite.ctx)
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, relayed)
+	var eventType string
+	var payload string
+	err = conn.QueryRow(suite.ctx, "SELECT event_type, payload FROM dead_letter_events WHERE aggregate_id = $1", corruptId.String()).Scan(&eventType, &payload)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "TabOpened", eventType)
+	assert.JSONEq(suite.T(), `{"table_number": "seven"}`, payload)
+	var pending int
+	err = conn.QueryRow(suite.ctx, "SELECT COUNT(*) FROM outbox WHERE aggregate_id = $1", corruptId.String()).Scan(&pending)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, pending)
+}
+
+func TestPostgresOutboxRelayTestSuite(t *testing.T) {
+	suite.Run(t, new(PostgresOutboxRelayTestSuite))
+}
@@ -0,0 +1,56 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/segmentio/ksuid"
+)
+
+type postgresSnapshotStore struct {
+	pool *pgxpool.Pool
+}
+
+func (ss *postgresSnapshotStore) LoadSnapshot(ctx context.Context, aggregateID ksuid.KSUID) (*Snapshot, error) {
+	var version int
+	var state []byte
+	err := ss.pool.QueryRow(ctx, "SELECT version, state FROM snapshots WHERE aggregate_id = $1", aggregateID.String()).Scan(&version, &state)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Snapshot{
+		AggregateID: aggregateID,
+		Version:     version,
+		State:       state,
+	}, nil
+}
+
+func (ss *postgresSnapshotStore) SaveSnapshot(ctx context.Context, snapshot Snapshot) error {
+	// A slower writer must never replace a newer snapshot with an older one.
+	_, err := ss.pool.Exec(ctx, `INSERT INTO snapshots (aggregate_id, version, timestamp, state) VALUES ($1, $2, NOW(), $3)
+		ON CONFLICT (aggregate_id) DO UPDATE SET version = EXCLUDED.version, timestamp = EXCLUDED.timestamp, state = EXCLUDED.state
+		WHERE snapshots.version < EXCLUDED.version`, snapshot.AggregateID.String(), snapshot.Version, snapshot.State)
+	return err
+}
+
+func NewPostgresSnapshotStore(ctx context.Context, connStr string) (SnapshotStore, error) {
+	pool, err := pgxpool.New(ctx, connStr)
+	if err == nil {
+		err = pool.Ping(ctx)
+	}
+	if err != nil {
+		slog.Error("unable to connect to database", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return &postgresSnapshotStore{
+		pool: pool,
+	}, nil
+}
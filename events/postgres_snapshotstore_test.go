@@ -0,0 +1,79 @@
+package events_test
+
+import (
+	"context"
+	"log"
+	"testing"
+
+	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/testhelpers"
+
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type PostgresSnapshotStoreTestSuite struct {
+	suite.Suite
+	pgContainer   *testhelpers.PostgresContainer
+	snapshotStore events.SnapshotStore
+	ctx           context.Context
+}
+
+func (suite *PostgresSnapshotStoreTestSuite) SetupSuite() {
+	suite.ctx = context.Background()
+	pgContainer, err := testhelpers.CreatePostgresContainer(suite.T(), suite.ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	suite.pgContainer = pgContainer
+	snapshotStore, err := events.NewPostgresSnapshotStore(suite.ctx, suite.pgContainer.ConnectionString)
+	if err != nil {
+		log.Fatal(err)
+	}
+	suite.snapshotStore = snapshotStore
+}
+
+func (suite *PostgresSnapshotStoreTestSuite) TearDownSuite() {
+	if err := suite.pgContainer.Terminate(suite.ctx); err != nil {
+		log.Fatalf("error terminating postgres container: %s", err)
+	}
+}
+
+func (suite *PostgresSnapshotStoreTestSuite) TestLoadSnapshotReturnsNilWhenThereIsNone() {
+	// When
+	snapshot, err := suite.snapshotStore.LoadSnapshot(suite.ctx, ksuid.New())
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), snapshot)
+}
+
+func (suite *PostgresSnapshotStoreTestSuite) TestSaveAndLoadSnapshot() {
+	// Given
+	aggregateId := ksuid.New()
+	// When
+	err := suite.snapshotStore.SaveSnapshot(suite.ctx, events.Snapshot{AggregateID: aggregateId, Version: 10, State: []byte(`{"tab_open": true}`)})
+	assert.NoError(suite.T(), err)
+	snapshot, err := suite.snapshotStore.LoadSnapshot(suite.ctx, aggregateId)
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), &events.Snapshot{AggregateID: aggregateId, Version: 10, State: []byte(`{"tab_open": true}`)}, snapshot)
+}
+
+func (suite *PostgresSnapshotStoreTestSuite) TestSaveSnapshotDoesNotReplaceNewerSnapshot() {
+	// Given
+	aggregateId := ksuid.New()
+	err := suite.snapshotStore.SaveSnapshot(suite.ctx, events.Snapshot{AggregateID: aggregateId, Version: 20, State: []byte(`{"tab_open": true}`)})
+	assert.NoError(suite.T(), err)
+	// When
+	err = suite.snapshotStore.SaveSnapshot(suite.ctx, events.Snapshot{AggregateID: aggregateId, Version: 10, State: []byte(`{"tab_open": false}`)})
+	// Then
+	assert.NoError(suite.T(), err)
+	snapshot, err := suite.snapshotStore.LoadSnapshot(suite.ctx, aggregateId)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 20, snapshot.Version)
+}
+
+func TestPostgresSnapshotStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(PostgresSnapshotStoreTestSuite))
+}
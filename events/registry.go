@@ -0,0 +1,177 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync"
+)
+
+// UnknownEvent stands in for a stored or received event whose type is not registered, when the unknown type policy
+// lets it through. It keeps the aggregate ID and sequence number so the stream has no gap, and read models ignore it.
+type UnknownEvent struct {
+	BaseEvent
+	EventType     string
+	SchemaVersion int
+	Payload       []byte
+}
+
+// UnknownTypePolicy decides what to do with an event of an unknown type: returning an error fails the read,
+// returning an event puts it in the place of the unknown one.
+type UnknownTypePolicy func(ctx context.Context, unknown UnknownEvent) (Event, error)
+
+// FailOnUnknownTypes refuses to read events of unknown types, it is the policy of a new registry.
+func FailOnUnknownTypes() UnknownTypePolicy {
+	return func(_ context.Context, unknown UnknownEvent) (Event, error) {
+		return nil, fmt.Errorf("unsupported type: %s", unknown.EventType)
+	}
+}
+
+// SkipUnknownTypes reads events of unknown types as an UnknownEvent.
+func SkipUnknownTypes() UnknownTypePolicy {
+	return func(_ context.Context, unknown UnknownEvent) (Event, error) {
+		slog.Warn("skipping event of unknown type", slog.String("type", unknown.EventType), slog.String("aggregate", unknown.ID.String()))
+		return unknown, nil
+	}
+}
+
+// DeadLetterUnknownTypes hands events of unknown types to the dead letter sink and then skips them.
+func DeadLetterUnknownTypes(deadLetterSink DeadLetterSink) UnknownTypePolicy {
+	return func(ctx context.Context, unknown UnknownEvent) (Event, error) {
+		if err := deadLetterSink.DeadLetter(ctx, unknown); err != nil {
+			return nil, fmt.Errorf("could not dead letter event of unknown type: %s, reason: %w", unknown.EventType, err)
+		}
+		return unknown, nil
+	}
+}
+
+// Registry maps the stable names events are stored and sent with to factories of their Go types.
+type Registry struct {
+	factories         map[string]func() Event
+	typeNames         map[reflect.Type]string
+	upcasters         *UpcasterRegistry
+	unknownTypePolicy UnknownTypePolicy
+	lock              sync.RWMutex
+}
+
+func CreateRegistry(upcasters *UpcasterRegistry) *Registry {
+	return &Registry{
+		factories:         make(map[string]func() Event),
+		typeNames:         make(map[reflect.Type]string),
+		upcasters:         upcasters,
+		unknownTypePolicy: FailOnUnknownTypes(),
+	}
+}
+
+// Register adds an event type under typeName, the factory returns the zero value of the event.
+func (r *Registry) Register(typeName string, factory func() Event) {
+	defer r.lock.Unlock()
+	r.lock.Lock()
+
+	r.factories[typeName] = factory
+	r.typeNames[reflect.TypeOf(factory())] = typeName
+}
+
+func (r *Registry) SetUnknownTypePolicy(policy UnknownTypePolicy) {
+	defer r.lock.Unlock()
+	r.lock.Lock()
+
+	r.unknownTypePolicy = policy
+}
+
+func (r *Registry) TypeName(event Event) (string, error) {
+	defer r.lock.RUnlock()
+	r.lock.RLock()
+
+	if unknown, ok := event.(UnknownEvent); ok {
+		return unknown.EventType, nil
+	}
+	typeName, ok := r.typeNames[reflect.TypeOf(event)]
+	if !ok {
+		return "", fmt.Errorf("event type is not registered: %T", event)
+	}
+	return typeName, nil
+}
+
+// Marshal returns the type name, the current schema version and the payload an event is stored or sent with.
+// An UnknownEvent is passed on as it was read.
+func (r *Registry) Marshal(event Event) (string, int, []byte, error) {
+	if unknown, ok := event.(UnknownEvent); ok {
+		return unknown.EventType, unknown.SchemaVersion, unknown.Payload, nil
+	}
+	typeName, err := r.TypeName(event)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	return typeName, r.upcasters.CurrentVersion(typeName), payload, nil
+}
+
+// Unmarshal upcasts a payload stored with the given schema version and reads it into the event registered as typeName.
+// Events of unknown types go through the unknown type policy.
+func (r *Registry) Unmarshal(ctx context.Context, typeName string, schemaVersion int, payload []byte) (Event, error) {
+	r.lock.RLock()
+	factory, ok := r.factories[typeName]
+	policy := r.unknownTypePolicy
+	r.lock.RUnlock()
+
+	if !ok {
+		unknown := UnknownEvent{EventType: typeName, SchemaVersion: schemaVersion, Payload: payload}
+		_ = json.Unmarshal(payload, &unknown.BaseEvent)
+		return policy(ctx, unknown)
+	}
+
+	upcasted, err := r.upcasters.Upcast(typeName, schemaVersion, payload)
+	if err != nil {
+		return nil, err
+	}
+	event := reflect.New(reflect.TypeOf(factory()))
+	if err := json.Unmarshal(upcasted, event.Interface()); err != nil {
+		return nil, fmt.Errorf("could not create %s event from payload: %s", typeName, upcasted)
+	}
+	return event.Elem().Interface().(Event), nil
+}
+
+// DefaultRegistry knows every event of the bar, it is the registry the event stores and the messaging use.
+var DefaultRegistry = barRegistry()
+
+func barRegistry() *Registry {
+	registry := CreateRegistry(historicUpcasters())
+	registry.Register("TabOpened", func() Event { return TabOpened{} })
+	registry.Register("DrinksOrdered", func() Event { return DrinksOrdered{} })
+	registry.Register("DrinksServed", func() Event { return DrinksServed{} })
+	registry.Register("FoodOrdered", func() Event { return FoodOrdered{} })
+	registry.Register("FoodPrepared", func() Event { return FoodPrepared{} })
+	registry.Register("FoodServed", func() Event { return FoodServed{} })
+	registry.Register("ItemsCancelled", func() Event { return ItemsCancelled{} })
+	registry.Register("DiscountApplied", func() Event { return DiscountApplied{} })
+	registry.Register("ItemComped", func() Event { return ItemComped{} })
+	registry.Register("PaymentRecorded", func() Event { return PaymentRecorded{} })
+	registry.Register("TabClosed", func() Event { return TabClosed{} })
+	registry.Register("TableClaimed", func() Event { return TableClaimed{} })
+	registry.Register("TableReleased", func() Event { return TableReleased{} })
+	registry.Register("MenuItemAdded", func() Event { return MenuItemAdded{} })
+	registry.Register("MenuItemPriceChanged", func() Event { return MenuItemPriceChanged{} })
+	registry.Register("MenuItemRenamed", func() Event { return MenuItemRenamed{} })
+	registry.Register("MenuItemCategorised", func() Event { return MenuItemCategorised{} })
+	registry.Register("MenuItemRecipeSet", func() Event { return MenuItemRecipeSet{} })
+	registry.Register("MenuItemRetired", func() Event { return MenuItemRetired{} })
+	registry.Register("StockRestocked", func() Event { return StockRestocked{} })
+	registry.Register("StockCounted", func() Event { return StockCounted{} })
+	registry.Register("ReorderLevelSet", func() Event { return ReorderLevelSet{} })
+	registry.Register("StockReserved", func() Event { return StockReserved{} })
+	registry.Register("StockReleased", func() Event { return StockReleased{} })
+	registry.Register("StockConsumed", func() Event { return StockConsumed{} })
+	registry.Register("StockRanLow", func() Event { return StockRanLow{} })
+	registry.Register("IngredientRestocked", func() Event { return IngredientRestocked{} })
+	registry.Register("IngredientCounted", func() Event { return IngredientCounted{} })
+	registry.Register("IngredientConsumed", func() Event { return IngredientConsumed{} })
+	registry.Register("ShiftOpened", func() Event { return ShiftOpened{} })
+	registry.Register("ShiftClosed", func() Event { return ShiftClosed{} })
+	return registry
+}
@@ -0,0 +1,102 @@
+package events_test
+
+import (
+	"context"
+	"cqrseventsourcingbar/events"
+	mock_events "cqrseventsourcingbar/events/mocks"
+	"errors"
+	"testing"
+
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type TipPooled struct {
+	events.BaseEvent
+	Amount int `json:"amount"`
+}
+
+const unknownPayload = `{"id":"2qPTBJCN6ib7iJ6WaIVvoSmySSV","sequence_number":3,"amount":5}`
+
+func TestRegistryReadsAndWritesEventsUnderTheirRegisteredName(t *testing.T) {
+	// Given
+	registry := events.CreateRegistry(events.CreateUpcasterRegistry())
+	registry.Register("tips.pooled", func() events.Event { return TipPooled{} })
+	tipPooled := TipPooled{BaseEvent: events.BaseEvent{ID: ksuid.New()}, Amount: 5}
+
+	// When
+	eventType, schemaVersion, payload, err := registry.Marshal(tipPooled)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "tips.pooled", eventType)
+	assert.Equal(t, 1, schemaVersion)
+	event, err := registry.Unmarshal(context.TODO(), eventType, schemaVersion, payload)
+	assert.NoError(t, err)
+	assert.Equal(t, tipPooled, event)
+}
+
+func TestRegistryRefusesToWriteAnUnregisteredEvent(t *testing.T) {
+	_, _, _, err := events.DefaultRegistry.Marshal(TipPooled{})
+
+	assert.EqualError(t, err, "event type is not registered: events_test.TipPooled")
+}
+
+func TestRegistryFailsOnUnknownTypesByDefault(t *testing.T) {
+	// Given
+	registry := events.CreateRegistry(events.CreateUpcasterRegistry())
+
+	// When
+	_, err := registry.Unmarshal(context.TODO(), "TipPooled", 1, []byte(unknownPayload))
+
+	// Then
+	assert.EqualError(t, err, "unsupported type: TipPooled")
+}
+
+func TestRegistrySkipsUnknownTypes(t *testing.T) {
+	// Given
+	registry := events.CreateRegistry(events.CreateUpcasterRegistry())
+	registry.SetUnknownTypePolicy(events.SkipUnknownTypes())
+	aggregateId, _ := ksuid.Parse("2qPTBJCN6ib7iJ6WaIVvoSmySSV")
+
+	// When
+	event, err := registry.Unmarshal(context.TODO(), "TipPooled", 1, []byte(unknownPayload))
+
+	// Then
+	assert.NoError(t, err)
+	unknown := events.UnknownEvent{BaseEvent: events.BaseEvent{ID: aggregateId, SequenceNumber: 3}, EventType: "TipPooled", SchemaVersion: 1, Payload: []byte(unknownPayload)}
+	assert.Equal(t, unknown, event)
+	eventType, schemaVersion, payload, err := registry.Marshal(event)
+	assert.NoError(t, err)
+	assert.Equal(t, "TipPooled", eventType)
+	assert.Equal(t, 1, schemaVersion)
+	assert.Equal(t, []byte(unknownPayload), payload)
+}
+
+func TestRegistryDeadLettersUnknownTypes(t *testing.T) {
+	// Given
+	deadLetterSink := events.CreateInMemoryDeadLetterSink()
+	registry := events.CreateRegistry(events.CreateUpcasterRegistry())
+	registry.SetUnknownTypePolicy(events.DeadLetterUnknownTypes(deadLetterSink))
+
+	// When
+	event, err := registry.Unmarshal(context.TODO(), "TipPooled", 1, []byte(unknownPayload))
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []events.UnknownEvent{event.(events.UnknownEvent)}, deadLetterSink.DeadLetters())
+}
+
+func TestRegistryFailsWhenTheDeadLetterSinkFails(t *testing.T) {
+	// Given
+	deadLetterSink := mock_events.NewDeadLetterSink(t)
+	deadLetterSink.On("DeadLetter", context.TODO(), events.UnknownEvent{EventType: "TipPooled", SchemaVersion: 1, Payload: []byte(`{}`)}).Return(errors.New("all broken"))
+	registry := events.CreateRegistry(events.CreateUpcasterRegistry())
+	registry.SetUnknownTypePolicy(events.DeadLetterUnknownTypes(deadLetterSink))
+
+	// When
+	_, err := registry.Unmarshal(context.TODO(), "TipPooled", 1, []byte(`{}`))
+
+	// Then
+	assert.EqualError(t, err, "could not dead letter event of unknown type: TipPooled, reason: all broken")
+}
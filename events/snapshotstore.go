@@ -0,0 +1,19 @@
+package events
+
+import (
+	"context"
+
+	"github.com/segmentio/ksuid"
+)
+
+type Snapshot struct {
+	AggregateID ksuid.KSUID
+	Version     int
+	State       []byte
+}
+
+//go:generate mockery --name SnapshotStore
+type SnapshotStore interface {
+	LoadSnapshot(ctx context.Context, aggregateID ksuid.KSUID) (*Snapshot, error)
+	SaveSnapshot(ctx context.Context, snapshot Snapshot) error
+}
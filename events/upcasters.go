@@ -0,0 +1,143 @@
+package events
+
+import (
+	"cqrseventsourcingbar/shared"
+	"encoding/json"
+	"fmt"
+)
+
+// Upcaster rewrites the payload of an event from one schema version into the next one.
+type Upcaster func(payload []byte) ([]byte, error)
+
+type upcasterKey struct {
+	eventType   string
+	fromVersion int
+}
+
+// UpcasterRegistry knows the current schema version of every event type and how to bring older payloads up to it,
+// one version at a time. Event types without upcasters are at version 1.
+type UpcasterRegistry struct {
+	currentVersions map[string]int
+	upcasters       map[upcasterKey]Upcaster
+}
+
+func CreateUpcasterRegistry() *UpcasterRegistry {
+	return &UpcasterRegistry{
+		currentVersions: make(map[string]int),
+		upcasters:       make(map[upcasterKey]Upcaster),
+	}
+}
+
+// Register adds the upcaster from fromVersion to fromVersion+1, which becomes the current version if it is the newest.
+func (r *UpcasterRegistry) Register(eventType string, fromVersion int, upcaster Upcaster) {
+	r.upcasters[upcasterKey{eventType: eventType, fromVersion: fromVersion}] = upcaster
+	if fromVersion+1 > r.CurrentVersion(eventType) {
+		r.currentVersions[eventType] = fromVersion + 1
+	}
+}
+
+func (r *UpcasterRegistry) CurrentVersion(eventType string) int {
+	if version, ok := r.currentVersions[eventType]; ok {
+		return version
+	}
+	return 1
+}
+
+// Upcast brings a payload stored with the given schema version up to the current one.
+// Payloads stored before events carried a version are read as version 1.
+func (r *UpcasterRegistry) Upcast(eventType string, version int, payload []byte) ([]byte, error) {
+	if version == 0 {
+		version = 1
+	}
+	currentVersion := r.CurrentVersion(eventType)
+	if version > currentVersion {
+		return nil, fmt.Errorf("unsupported schema version %d for %s event, the latest known is %d", version, eventType, currentVersion)
+	}
+	for ; version < currentVersion; version++ {
+		upcaster, ok := r.upcasters[upcasterKey{eventType: eventType, fromVersion: version}]
+		if !ok {
+			return nil, fmt.Errorf("no upcaster for %s event from schema version %d", eventType, version)
+		}
+		upcasted, err := upcaster(payload)
+		if err != nil {
+			return nil, fmt.Errorf("could not upcast %s event from schema version %d, reason: %w", eventType, version, err)
+		}
+		payload = upcasted
+	}
+	return payload, nil
+}
+
+// Version 1 payloads are everything written before events carried a schema version: amounts were plain numbers
+// of euros and the items of a DrinksOrdered could miss is_drink. Version 2 keeps money in cents with its currency.
+func historicUpcasters() *UpcasterRegistry {
+	registry := CreateUpcasterRegistry()
+	registry.Register("DrinksOrdered", 1, upcastOrderedItems(true))
+	registry.Register("FoodOrdered", 1, upcastOrderedItems(false))
+	registry.Register("DiscountApplied", 1, upcastMoneyFields("amount"))
+	registry.Register("ItemComped", 1, upcastMoneyFields("amount"))
+	registry.Register("PaymentRecorded", 1, upcastMoneyFields("amount"))
+	registry.Register("TabClosed", 1, upcastMoneyFields("amount_paid", "order_amount", "tip"))
+	return registry
+}
+
+func upcastMoneyFields(fields ...string) Upcaster {
+	return func(payload []byte) ([]byte, error) {
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(payload, &object); err != nil {
+			return nil, err
+		}
+		for _, field := range fields {
+			if err := upcastMoney(object, field); err != nil {
+				return nil, err
+			}
+		}
+		return json.Marshal(object)
+	}
+}
+
+func upcastOrderedItems(isDrink bool) Upcaster {
+	return func(payload []byte) ([]byte, error) {
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(payload, &object); err != nil {
+			return nil, err
+		}
+		var items []map[string]json.RawMessage
+		if err := json.Unmarshal(object["items"], &items); err != nil && object["items"] != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if err := upcastMoney(item, "price"); err != nil {
+				return nil, err
+			}
+			if _, ok := item["is_drink"]; !ok {
+				item["is_drink"], _ = json.Marshal(isDrink)
+			}
+		}
+		if items != nil {
+			upcastedItems, err := json.Marshal(items)
+			if err != nil {
+				return nil, err
+			}
+			object["items"] = upcastedItems
+		}
+		return json.Marshal(object)
+	}
+}
+
+// upcastMoney turns a plain number of euros into money, leaving fields that are already money alone.
+func upcastMoney(object map[string]json.RawMessage, field string) error {
+	value, ok := object[field]
+	if !ok || len(value) == 0 || value[0] == '{' || string(value) == "null" {
+		return nil
+	}
+	var amount float64
+	if err := json.Unmarshal(value, &amount); err != nil {
+		return fmt.Errorf("could not read %s as an amount: %s", field, value)
+	}
+	money, err := json.Marshal(shared.MoneyFromFloat(amount))
+	if err != nil {
+		return err
+	}
+	object[field] = money
+	return nil
+}
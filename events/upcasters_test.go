@@ -0,0 +1,94 @@
+package events_test
+
+import (
+	"context"
+	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/shared"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func historicTab(id ksuid.KSUID, tableNumber int, waiter string) []events.Event {
+	base := func(sequenceNumber int) events.BaseEvent {
+		return events.BaseEvent{ID: id, SequenceNumber: sequenceNumber}
+	}
+	return []events.Event{
+		events.TabOpened{BaseEvent: base(1), TableNumber: tableNumber, Waiter: waiter},
+		events.DrinksOrdered{BaseEvent: base(2), Items: []shared.MenuItem{{ID: 1, Description: "blue water", Price: shared.Cents(110), IsDrink: true}}},
+		events.FoodOrdered{BaseEvent: base(3), Items: []shared.MenuItem{{ID: 4, Description: "burger", Price: shared.Cents(820)}}},
+		events.DrinksServed{BaseEvent: base(4), MenuNumbers: []int{1}},
+		events.ItemComped{BaseEvent: base(5), MenuNumber: 1, Description: "blue water", Amount: shared.Cents(110), Reason: "spilled", AuthorisedBy: "manager"},
+		events.DiscountApplied{BaseEvent: base(6), Amount: shared.Cents(30), Reason: "regular", AuthorisedBy: "manager"},
+		events.PaymentRecorded{BaseEvent: base(7), Method: "card", Amount: shared.Cents(410), Payer: "Alice"},
+		events.TabClosed{BaseEvent: base(8), AmountPaid: shared.Cents(820), OrderAmount: shared.Cents(790), Tip: shared.Cents(30)},
+	}
+}
+
+func TestReplaysFixturesOfEveryHistoricSchemaVersion(t *testing.T) {
+	// Given
+	fixtures, err := os.ReadFile(filepath.Join("testdata", "historic_events.jsonl"))
+	assert.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	assert.NoError(t, os.WriteFile(path, fixtures, 0o644))
+	versionOneTab, _ := ksuid.Parse("2qPTBJCN6ib7iJ6WaIVvoSmySSV")
+	versionTwoTab, _ := ksuid.Parse("1qPTBJCN6ib7iJ6WaIVvoSmySSV")
+
+	// When
+	eventStore, err := events.NewFileEventStore(path)
+
+	// Then
+	assert.NoError(t, err)
+	versionOneEvents, err := eventStore.LoadEvents(context.TODO(), versionOneTab)
+	assert.NoError(t, err)
+	assert.Equal(t, historicTab(versionOneTab, 4, "Charles"), versionOneEvents)
+	versionTwoEvents, err := eventStore.LoadEvents(context.TODO(), versionTwoTab)
+	assert.NoError(t, err)
+	assert.Equal(t, historicTab(versionTwoTab, 5, "Jenkins"), versionTwoEvents)
+}
+
+func TestPayloadsWithoutAVersionAreReadAsVersionOne(t *testing.T) {
+	// When
+	event, err := events.DefaultRegistry.Unmarshal(context.TODO(), "PaymentRecorded", 0, []byte(`{"method":"cash","amount":0.1}`))
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, events.PaymentRecorded{Method: "cash", Amount: shared.Cents(10)}, event)
+}
+
+func TestSchemaVersionsFromTheFutureAreRefused(t *testing.T) {
+	// When
+	_, err := events.DefaultRegistry.Unmarshal(context.TODO(), "TabClosed", 3, []byte(`{}`))
+
+	// Then
+	assert.EqualError(t, err, "unsupported schema version 3 for TabClosed event, the latest known is 2")
+}
+
+func TestEventsAreWrittenWithTheCurrentVersionOfTheirType(t *testing.T) {
+	_, tabClosedVersion, _, err := events.DefaultRegistry.Marshal(events.TabClosed{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, tabClosedVersion)
+	_, tabOpenedVersion, _, err := events.DefaultRegistry.Marshal(events.TabOpened{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, tabOpenedVersion)
+}
+
+func TestUpcasterRegistryUpcastsOneVersionAtATime(t *testing.T) {
+	// Given
+	registry := events.CreateUpcasterRegistry()
+	registry.Register("TabOpened", 2, func(payload []byte) ([]byte, error) { return append(payload, '3'), nil })
+	registry.Register("TabOpened", 1, func(payload []byte) ([]byte, error) { return append(payload, '2'), nil })
+
+	// When
+	upcasted, err := registry.Upcast("TabOpened", 1, []byte("1"))
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "123", string(upcasted))
+	assert.Equal(t, 3, registry.CurrentVersion("TabOpened"))
+	_, err = registry.Upcast("TabClosed", 2, []byte("{}"))
+	assert.EqualError(t, err, "unsupported schema version 2 for TabClosed event, the latest known is 1")
+}
@@ -0,0 +1,88 @@
+package messaging
+
+import (
+	"context"
+	"cqrseventsourcingbar/events"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/ksuid"
+)
+
+const cloudEventsSpecVersion = "1.0"
+const cloudEventsSource = "/cqrseventsourcingbar"
+
+// cloudEvent is a CloudEvents 1.0 envelope in structured mode, the metadata of the event goes in extension attributes.
+type cloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject"`
+	Time            *time.Time      `json:"time,omitempty"`
+	DataContentType string          `json:"datacontenttype"`
+	SchemaVersion   int             `json:"schemaversion"`
+	CausationID     string          `json:"causationid,omitempty"`
+	CorrelationID   string          `json:"correlationid,omitempty"`
+	Actor           string          `json:"actor,omitempty"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// CloudEventsJSONCodec sends events as plain JSON CloudEvents, which any consumer can read.
+type CloudEventsJSONCodec struct{}
+
+func (CloudEventsJSONCodec) ContentType() string {
+	return "application/cloudevents+json"
+}
+
+func (CloudEventsJSONCodec) Encode(event events.Event) ([]byte, error) {
+	eventType, schemaVersion, payload, err := events.DefaultRegistry.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	metadata := event.GetMetadata()
+	envelope := cloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		ID:              event.GetEventID().String(),
+		Source:          cloudEventsSource,
+		Type:            eventType,
+		Subject:         event.GetID().String(),
+		DataContentType: "application/json",
+		SchemaVersion:   schemaVersion,
+		CorrelationID:   metadata.CorrelationID,
+		Actor:           metadata.Actor,
+		Data:            payload,
+	}
+	if !metadata.OccurredAt.IsZero() {
+		envelope.Time = &metadata.OccurredAt
+	}
+	if metadata.CausationID != ksuid.Nil {
+		envelope.CausationID = metadata.CausationID.String()
+	}
+	return json.Marshal(envelope)
+}
+
+func (CloudEventsJSONCodec) Decode(data []byte) (events.Event, error) {
+	var envelope cloudEvent
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, err
+	}
+	if envelope.SpecVersion != cloudEventsSpecVersion {
+		return nil, fmt.Errorf("unsupported cloud events spec version: %s", envelope.SpecVersion)
+	}
+	event, err := events.DefaultRegistry.Unmarshal(context.Background(), envelope.Type, envelope.SchemaVersion, envelope.Data)
+	if err != nil {
+		return nil, err
+	}
+	metadata := events.Metadata{CorrelationID: envelope.CorrelationID, Actor: envelope.Actor}
+	if envelope.Time != nil {
+		metadata.OccurredAt = envelope.Time.UTC()
+	}
+	if envelope.CausationID != "" {
+		if metadata.CausationID, err = ksuid.Parse(envelope.CausationID); err != nil {
+			return nil, fmt.Errorf("could not parse causation id: %s, reason: %w", envelope.CausationID, err)
+		}
+	}
+	return events.WithMetadata(event, metadata), nil
+}
@@ -0,0 +1,137 @@
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"cqrseventsourcingbar/events"
+	"encoding/gob"
+	"fmt"
+	"log/slog"
+
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	contentTypeHeader = "Content-Type"
+	eventTypeHeader   = "Event-Type"
+)
+
+// Codec turns events into the body of NATS messages and back. The content type of the codec travels in the
+// Content-Type header of every message, so subscribers read the messages of any codec they know.
+type Codec interface {
+	ContentType() string
+	Encode(event events.Event) ([]byte, error)
+	Decode(data []byte) (events.Event, error)
+}
+
+var codecs = []Codec{GobCodec{}, CloudEventsJSONCodec{}, ProtobufCodec{}}
+
+// CodecByName returns the codec for gob, json or protobuf.
+func CodecByName(name string) (Codec, error) {
+	switch name {
+	case "gob":
+		return GobCodec{}, nil
+	case "json":
+		return CloudEventsJSONCodec{}, nil
+	case "protobuf":
+		return ProtobufCodec{}, nil
+	default:
+		return nil, fmt.Errorf("unknown codec: %s", name)
+	}
+}
+
+// EmitterOption configures the NATS and JetStream emitters.
+type EmitterOption func(codec *Codec)
+
+// WithCodec encodes the emitted events with codec instead of gob, which remains the default until every subscriber reads other codecs.
+func WithCodec(codec Codec) EmitterOption {
+	return func(c *Codec) {
+		*c = codec
+	}
+}
+
+func codecFromOptions(options []EmitterOption) Codec {
+	var codec Codec = GobCodec{}
+	for _, option := range options {
+		option(&codec)
+	}
+	return codec
+}
+
+func encodeMessage(codec Codec, subject string, event events.Event) (*nats.Msg, error) {
+	data, err := codec.Encode(event)
+	if err != nil {
+		return nil, err
+	}
+	msg := nats.NewMsg(subject)
+	msg.Header.Set(contentTypeHeader, codec.ContentType())
+	msg.Header.Set(eventTypeHeader, events.GetEventTypeAsString(event))
+	msg.Data = data
+	return msg, nil
+}
+
+// decodeMessage picks the codec from the Content-Type header. Messages without one were sent before there was a choice and are gob.
+func decodeMessage(header nats.Header, data []byte) (events.Event, error) {
+	contentType := header.Get(contentTypeHeader)
+	if contentType == "" {
+		return GobCodec{}.Decode(data)
+	}
+	for _, codec := range codecs {
+		if codec.ContentType() == contentType {
+			return codec.Decode(data)
+		}
+	}
+	return nil, fmt.Errorf("no codec for content type: %s", contentType)
+}
+
+type wrappedEvent struct {
+	EventType     string
+	SchemaVersion int
+	Payload       []byte
+	Metadata      events.Metadata
+}
+
+// GobCodec wraps the JSON payload in a gob encoded struct, it can only be read by Go services.
+type GobCodec struct{}
+
+func (GobCodec) ContentType() string {
+	return "application/x-gob"
+}
+
+func (GobCodec) Encode(event events.Event) ([]byte, error) {
+	eventType, schemaVersion, payload, err := events.DefaultRegistry.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	wrappedEvent := wrappedEvent{
+		EventType:     eventType,
+		SchemaVersion: schemaVersion,
+		Payload:       payload,
+		Metadata:      event.GetMetadata(),
+	}
+	b := bytes.Buffer{}
+	err = gob.NewEncoder(&b).Encode(wrappedEvent)
+	if err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (GobCodec) Decode(data []byte) (events.Event, error) {
+	msg := wrappedEvent{}
+	b := bytes.Buffer{}
+	b.Write(data)
+	err := gob.NewDecoder(&b).Decode(&msg)
+	if err != nil {
+		return nil, err
+	}
+	event, err := events.DefaultRegistry.Unmarshal(context.Background(), msg.EventType, msg.SchemaVersion, msg.Payload)
+	if err != nil {
+		return nil, err
+	}
+	return events.WithMetadata(event, msg.Metadata), nil
+}
+
+func logIncomingEventError(err error) {
+	slog.Error("error when processing incoming event", slog.Any("error", err.Error()))
+}
@@ -0,0 +1,121 @@
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/shared"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func tabClosedWithMetadata() events.TabClosed {
+	metadata := events.Metadata{OccurredAt: time.Date(2024, 3, 1, 1, 13, 0, 0, time.UTC), CausationID: ksuid.New(), CorrelationID: "request-1", Actor: "Charles"}
+	return events.TabClosed{BaseEvent: events.BaseEvent{ID: ksuid.New(), EventID: ksuid.New(), SequenceNumber: 4, Metadata: metadata}, AmountPaid: shared.Cents(300), OrderAmount: shared.Cents(250), Tip: shared.Cents(50)}
+}
+
+func TestEveryCodecCarriesTheSchemaVersionAndMetadata(t *testing.T) {
+	for _, codec := range codecs {
+		t.Run(codec.ContentType(), func(t *testing.T) {
+			// Given
+			tabClosed := tabClosedWithMetadata()
+
+			// When
+			msg, err := encodeMessage(codec, "event", tabClosed)
+
+			// Then
+			assert.NoError(t, err)
+			assert.Equal(t, codec.ContentType(), msg.Header.Get("Content-Type"))
+			assert.Equal(t, "TabClosed", msg.Header.Get("Event-Type"))
+			decoded, err := decodeMessage(msg.Header, msg.Data)
+			assert.NoError(t, err)
+			assert.Equal(t, tabClosed, decoded)
+		})
+	}
+}
+
+func TestCloudEventsCanBeReadAsPlainJSON(t *testing.T) {
+	// Given
+	tabClosed := tabClosedWithMetadata()
+
+	// When
+	data, err := CloudEventsJSONCodec{}.Encode(tabClosed)
+
+	// Then
+	assert.NoError(t, err)
+	var cloudEvent map[string]any
+	assert.NoError(t, json.Unmarshal(data, &cloudEvent))
+	assert.Equal(t, "1.0", cloudEvent["specversion"])
+	assert.Equal(t, tabClosed.EventID.String(), cloudEvent["id"])
+	assert.Equal(t, "TabClosed", cloudEvent["type"])
+	assert.Equal(t, tabClosed.ID.String(), cloudEvent["subject"])
+	assert.Equal(t, "2024-03-01T01:13:00Z", cloudEvent["time"])
+	assert.Equal(t, float64(2), cloudEvent["schemaversion"])
+	assert.Equal(t, "Charles", cloudEvent["actor"])
+	assert.Equal(t, map[string]any{"amount": float64(300), "currency": "EUR"}, cloudEvent["data"].(map[string]any)["amount_paid"])
+}
+
+func TestProtobufEnvelopesSkipFieldsTheyDoNotKnow(t *testing.T) {
+	// Given
+	tabClosed := tabClosedWithMetadata()
+	data, err := ProtobufCodec{}.Encode(tabClosed)
+	assert.NoError(t, err)
+	data = protowire.AppendTag(data, 99, protowire.VarintType)
+	data = protowire.AppendVarint(data, 7)
+
+	// When
+	decoded, err := ProtobufCodec{}.Decode(data)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, tabClosed, decoded)
+}
+
+func TestMessagesWithAnUnknownContentTypeAreRefused(t *testing.T) {
+	header := nats.Header{}
+	header.Set("Content-Type", "application/xml")
+
+	_, err := decodeMessage(header, []byte("<TabClosed/>"))
+
+	assert.EqualError(t, err, "no codec for content type: application/xml")
+}
+
+func TestCodecsAreChosenByName(t *testing.T) {
+	codec, err := CodecByName("protobuf")
+	assert.NoError(t, err)
+	assert.Equal(t, ProtobufCodec{}, codec)
+	_, err = CodecByName("xml")
+	assert.EqualError(t, err, "unknown codec: xml")
+}
+
+func TestUnknownTransportsAreRefused(t *testing.T) {
+	_, err := NewEventEmitter(context.Background(), "kafka", "nats://localhost:4222")
+	assert.EqualError(t, err, "unknown transport: kafka")
+	_, err = NewEventSubscriber(context.Background(), "kafka", "nats://localhost:4222", "readservice", events.EventListeners{})
+	assert.EqualError(t, err, "unknown transport: kafka")
+}
+
+func TestDecodesMessagesSentBeforeEventsCarriedASchemaVersion(t *testing.T) {
+	// Given
+	type legacyWrappedEvent struct {
+		EventType string
+		Payload   []byte
+	}
+	b := bytes.Buffer{}
+	err := gob.NewEncoder(&b).Encode(legacyWrappedEvent{EventType: "TabClosed", Payload: []byte(`{"amount_paid":3.0,"order_amount":2.5,"tip":0.5}`)})
+	assert.NoError(t, err)
+
+	// When
+	decoded, err := decodeMessage(nil, b.Bytes())
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, events.TabClosed{AmountPaid: shared.Cents(300), OrderAmount: shared.Cents(250), Tip: shared.Cents(50)}, decoded)
+}
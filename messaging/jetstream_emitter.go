@@ -0,0 +1,93 @@
+package messaging
+
+import (
+	"context"
+	"cqrseventsourcingbar/events"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const eventStreamName = "EVENTS"
+const publishTimeout = 5 * time.Second
+
+type JetStreamEventEmitter struct {
+	conn  *nats.Conn
+	js    jetstream.JetStream
+	codec Codec
+}
+
+func NewJetStreamEventEmitter(ctx context.Context, url string, options ...EmitterOption) (*JetStreamEventEmitter, error) {
+	conn, js, err := connectJetStream(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JetStreamEventEmitter{
+		conn:  conn,
+		js:    js,
+		codec: codecFromOptions(options),
+	}, nil
+}
+
+func (j *JetStreamEventEmitter) Close() {
+	if j.conn != nil {
+		j.conn.Close()
+	}
+}
+
+// EmitEvent only returns once the stream has persisted the event.
+func (j *JetStreamEventEmitter) EmitEvent(event events.Event) error {
+	msg, err := encodeMessage(j.codec, subjectForEvent(event), event)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+	_, err = j.js.PublishMsg(ctx, msg)
+	return err
+}
+
+func subjectForEvent(event events.Event) string {
+	switch event.(type) {
+	case events.TableClaimed, events.TableReleased:
+		return fmt.Sprintf("event.table.%s", event.GetID().String())
+	case events.MenuItemAdded, events.MenuItemPriceChanged, events.MenuItemRenamed, events.MenuItemCategorised, events.MenuItemRecipeSet, events.MenuItemRetired:
+		return fmt.Sprintf("event.menu.%s", event.GetID().String())
+	case events.StockRestocked, events.StockCounted, events.ReorderLevelSet, events.StockReserved, events.StockReleased, events.StockConsumed, events.StockRanLow:
+		return fmt.Sprintf("event.stock.%s", event.GetID().String())
+	case events.IngredientRestocked, events.IngredientCounted, events.IngredientConsumed:
+		return fmt.Sprintf("event.ingredient.%s", event.GetID().String())
+	case events.ShiftOpened, events.ShiftClosed:
+		return fmt.Sprintf("event.shift.%s", event.GetID().String())
+	default:
+		return fmt.Sprintf("event.tab.%s", event.GetID().String())
+	}
+}
+
+func connectJetStream(ctx context.Context, url string) (*nats.Conn, jetstream.JetStream, error) {
+	conn, err := nats.Connect(url)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     eventStreamName,
+		Subjects: []string{"event.>"},
+		Storage:  jetstream.FileStorage,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	return conn, js, nil
+}
@@ -0,0 +1,170 @@
+package messaging_test
+
+import (
+	"context"
+	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/messaging"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mock_events "cqrseventsourcingbar/events/mocks"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type JetStreamRoundtripTestSuite struct {
+	suite.Suite
+	natsServer    *server.Server
+	emitter       *messaging.JetStreamEventEmitter
+	eventListener *mock_events.EventListener
+	handled       atomic.Int32
+	ctx           context.Context
+}
+
+func (suite *JetStreamRoundtripTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	opts := server.Options{
+		Host:      "localhost",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  suite.T().TempDir(),
+	}
+	natsServer, err := server.NewServer(&opts)
+	if err != nil {
+		suite.T().Fatal(err.Error())
+	}
+	suite.natsServer = natsServer
+	suite.natsServer.Start()
+	if !suite.natsServer.ReadyForConnections(5 * time.Second) {
+		suite.T().Fatal("nats server not ready")
+	}
+
+	emitter, err := messaging.NewJetStreamEventEmitter(suite.ctx, suite.natsServer.ClientURL())
+	if err != nil {
+		suite.T().Fatal(err.Error())
+	}
+	suite.emitter = emitter
+	suite.eventListener = mock_events.NewEventListener(suite.T())
+	suite.handled.Store(0)
+}
+
+func (suite *JetStreamRoundtripTestSuite) countHandled(mock.Arguments) {
+	suite.handled.Add(1)
+}
+
+func (suite *JetStreamRoundtripTestSuite) TearDownTest() {
+	suite.emitter.Close()
+	suite.natsServer.Shutdown()
+}
+
+func (suite *JetStreamRoundtripTestSuite) subscribe() *messaging.JetStreamEventSubscriber {
+	subscriber, err := messaging.NewJetStreamEventSubscriber(suite.ctx, suite.natsServer.ClientURL(), "readservice", suite.eventListener)
+	if err != nil {
+		suite.T().Fatal(err.Error())
+	}
+	if err := subscriber.OnCreatedEvent(); err != nil {
+		suite.T().Fatal(err.Error())
+	}
+	return subscriber
+}
+
+func (suite *JetStreamRoundtripTestSuite) TestEmitEventShouldCallEventListener() {
+	// Given
+	subscriber := suite.subscribe()
+	defer subscriber.Close()
+	tabOpened := events.TabOpened{
+		BaseEvent:   events.BaseEvent{ID: ksuid.New()},
+		TableNumber: 1,
+		Waiter:      "waiter_1",
+	}
+	suite.eventListener.On("HandleEvent", tabOpened).Return(nil).Run(suite.countHandled)
+
+	// When
+	err := suite.emitter.EmitEvent(tabOpened)
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Eventually(suite.T(), func() bool {
+		return suite.handled.Load() == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (suite *JetStreamRoundtripTestSuite) TestEventsEmittedWhileSubscriberIsDownAreDeliveredLater() {
+	// Given
+	tabOpened := events.TabOpened{
+		BaseEvent:   events.BaseEvent{ID: ksuid.New()},
+		TableNumber: 2,
+		Waiter:      "waiter_2",
+	}
+	err := suite.emitter.EmitEvent(tabOpened)
+	assert.NoError(suite.T(), err)
+	suite.eventListener.On("HandleEvent", tabOpened).Return(nil).Run(suite.countHandled)
+
+	// When
+	subscriber := suite.subscribe()
+	defer subscriber.Close()
+
+	// Then
+	assert.Eventually(suite.T(), func() bool {
+		return suite.handled.Load() == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (suite *JetStreamRoundtripTestSuite) TestFailedEventIsRedelivered() {
+	// Given
+	subscriber := suite.subscribe()
+	defer subscriber.Close()
+	tabOpened := events.TabOpened{
+		BaseEvent:   events.BaseEvent{ID: ksuid.New()},
+		TableNumber: 3,
+		Waiter:      "waiter_1",
+	}
+	suite.eventListener.On("HandleEvent", tabOpened).Return(errors.New("all broken")).Once().Run(suite.countHandled)
+	suite.eventListener.On("HandleEvent", tabOpened).Return(nil).Once().Run(suite.countHandled)
+
+	// When
+	err := suite.emitter.EmitEvent(tabOpened)
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Eventually(suite.T(), func() bool {
+		return suite.handled.Load() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func (suite *JetStreamRoundtripTestSuite) TestFailedEventIsRedeliveredAfterTheSubscriberRestarts() {
+	// Given
+	tabOpened := events.TabOpened{
+		BaseEvent:   events.BaseEvent{ID: ksuid.New()},
+		TableNumber: 4,
+		Waiter:      "waiter_2",
+	}
+	suite.eventListener.On("HandleEvent", tabOpened).Return(errors.New("all broken")).Once().Run(suite.countHandled)
+	subscriber := suite.subscribe()
+	err := suite.emitter.EmitEvent(tabOpened)
+	assert.NoError(suite.T(), err)
+	assert.Eventually(suite.T(), func() bool {
+		return suite.handled.Load() == 1
+	}, time.Second, 10*time.Millisecond)
+	subscriber.Close()
+	suite.eventListener.On("HandleEvent", tabOpened).Return(nil).Once().Run(suite.countHandled)
+
+	// When
+	restarted := suite.subscribe()
+	defer restarted.Close()
+
+	// Then
+	assert.Eventually(suite.T(), func() bool {
+		return suite.handled.Load() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestJetStreamTestSuite(t *testing.T) {
+	suite.Run(t, new(JetStreamRoundtripTestSuite))
+}
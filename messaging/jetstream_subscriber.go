@@ -0,0 +1,93 @@
+package messaging
+
+import (
+	"context"
+	"cqrseventsourcingbar/events"
+	"log/slog"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const maxDeliveries = 10
+const ackWait = 30 * time.Second
+const redeliveryDelay = time.Second
+
+type JetStreamEventSubscriber struct {
+	conn          *nats.Conn
+	js            jetstream.JetStream
+	durableName   string
+	consumeCtx    jetstream.ConsumeContext
+	eventListener events.EventListener
+}
+
+func NewJetStreamEventSubscriber(ctx context.Context, url string, durableName string, eventListener events.EventListener) (*JetStreamEventSubscriber, error) {
+	conn, js, err := connectJetStream(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JetStreamEventSubscriber{
+		conn:          conn,
+		js:            js,
+		durableName:   durableName,
+		eventListener: eventListener,
+	}, nil
+}
+
+// OnCreatedEvent resumes the durable consumer where it left off, so events published while the subscriber was down are delivered now.
+func (j *JetStreamEventSubscriber) OnCreatedEvent() error {
+	consumer, err := j.js.CreateOrUpdateConsumer(context.Background(), eventStreamName, jetstream.ConsumerConfig{
+		Durable:       j.durableName,
+		FilterSubject: "event.>",
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       ackWait,
+		MaxDeliver:    maxDeliveries,
+		DeliverPolicy: jetstream.DeliverAllPolicy,
+	})
+	if err != nil {
+		return err
+	}
+
+	j.consumeCtx, err = consumer.Consume(j.handleMessage)
+	return err
+}
+
+func (j *JetStreamEventSubscriber) handleMessage(m jetstream.Msg) {
+	event, err := decodeMessage(m.Headers(), m.Data())
+	if err != nil {
+		logIncomingEventError(err)
+		// A message that cannot be decoded will never succeed, so stop redelivering it.
+		if err := m.Term(); err != nil {
+			logIncomingEventError(err)
+		}
+		return
+	}
+
+	err = j.eventListener.HandleEvent(event)
+
+	if err != nil {
+		logIncomingEventError(err)
+		if err := m.NakWithDelay(redeliveryDelay); err != nil {
+			logIncomingEventError(err)
+		}
+		return
+	}
+
+	if err := m.Ack(); err != nil {
+		logIncomingEventError(err)
+	}
+}
+
+func (j *JetStreamEventSubscriber) Close() {
+	if j.consumeCtx != nil {
+		j.consumeCtx.Stop()
+	}
+	if j.conn != nil {
+		err := j.conn.Drain()
+		if err != nil {
+			slog.Error("error closing subscription", slog.Any("error", err.Error()))
+		}
+	}
+}
@@ -1,31 +1,25 @@
 package messaging
 
 import (
-	"bytes"
 	"cqrseventsourcingbar/events"
-	"encoding/gob"
-	"encoding/json"
 
 	"github.com/nats-io/nats.go"
 )
 
 type NatsEventEmitter struct {
-	conn *nats.Conn
+	conn  *nats.Conn
+	codec Codec
 }
 
-type wrappedEvent struct {
-	EventType string
-	Payload   []byte
-}
-
-func NewNatsEventEmitter(url string) (*NatsEventEmitter, error) {
+func NewNatsEventEmitter(url string, options ...EmitterOption) (*NatsEventEmitter, error) {
 	conn, err := nats.Connect(url)
 	if err != nil {
 		return nil, err
 	}
 
 	return &NatsEventEmitter{
-		conn: conn,
+		conn:  conn,
+		codec: codecFromOptions(options),
 	}, nil
 }
 
@@ -35,27 +29,10 @@ func (n *NatsEventEmitter) Close() {
 	}
 }
 
-func (n *NatsEventEmitter) encodeMessage(event events.Event) ([]byte, error) {
-	payload, err := json.Marshal(event)
-	if err != nil {
-		return nil, err
-	}
-	wrappedEvent := wrappedEvent{
-		EventType: events.GetEventTypeAsString(event),
-		Payload:   payload,
-	}
-	b := bytes.Buffer{}
-	err = gob.NewEncoder(&b).Encode(wrappedEvent)
-	if err != nil {
-		return nil, err
-	}
-	return b.Bytes(), nil
-}
-
 func (n *NatsEventEmitter) EmitEvent(event events.Event) error {
-	data, err := n.encodeMessage(event)
+	msg, err := encodeMessage(n.codec, "event", event)
 	if err != nil {
 		return err
 	}
-	return n.conn.Publish("event", data)
+	return n.conn.PublishMsg(msg)
 }
@@ -3,6 +3,7 @@ package messaging_test
 import (
 	"cqrseventsourcingbar/events"
 	"cqrseventsourcingbar/messaging"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/nats-io/nats-server/v2/server"
 	"github.com/segmentio/ksuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -81,3 +83,47 @@ func (suite *NatsRoundtripTestSuite) TearDownSuite() {
 func TestNatsTestSuite(t *testing.T) {
 	suite.Run(t, new(NatsRoundtripTestSuite))
 }
+
+func TestNatsSubscriberReadsEventsOfEveryCodec(t *testing.T) {
+	// Given
+	natsServer, err := server.NewServer(&server.Options{Host: "localhost", Port: server.RANDOM_PORT})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	natsServer.Start()
+	defer natsServer.Shutdown()
+	if !natsServer.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server not ready")
+	}
+	eventListener := mock_events.NewEventListener(t)
+	subscriber, err := messaging.NewNatsEventSubscriber(natsServer.ClientURL(), eventListener)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer subscriber.Close()
+	if err := subscriber.OnCreatedEvent(); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var handled atomic.Int32
+	var tabsOpened []events.TabOpened
+	for tableNumber, codec := range []messaging.Codec{messaging.GobCodec{}, messaging.CloudEventsJSONCodec{}, messaging.ProtobufCodec{}} {
+		emitter, err := messaging.NewNatsEventEmitter(natsServer.ClientURL(), messaging.WithCodec(codec))
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		defer emitter.Close()
+		tabOpened := events.TabOpened{BaseEvent: events.BaseEvent{ID: ksuid.New()}, TableNumber: tableNumber + 1, Waiter: "waiter_1"}
+		eventListener.On("HandleEvent", tabOpened).Return(nil).Once().Run(func(mock.Arguments) { handled.Add(1) })
+		tabsOpened = append(tabsOpened, tabOpened)
+
+		// When
+		err = emitter.EmitEvent(tabOpened)
+		assert.NoError(t, err)
+	}
+
+	// Then
+	assert.Eventually(t, func() bool {
+		return int(handled.Load()) == len(tabsOpened)
+	}, time.Second, 10*time.Millisecond)
+}
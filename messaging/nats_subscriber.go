@@ -1,9 +1,7 @@
 package messaging
 
 import (
-	"bytes"
 	"cqrseventsourcingbar/events"
-	"encoding/gob"
 	"log/slog"
 
 	"github.com/nats-io/nats.go"
@@ -16,16 +14,9 @@ type NatsEventSubscriber struct {
 }
 
 func (n *NatsEventSubscriber) OnCreatedEvent() error {
-	msg := wrappedEvent{}
 	var err error
 	n.eventCreatedSub, err = n.conn.Subscribe("event", func(m *nats.Msg) {
-		err := n.decodeMessage(m.Data, &msg)
-		if err != nil {
-			logIncomingEventError(err)
-			return
-		}
-
-		event, err := events.UnmarshallPayload(msg.EventType, msg.Payload)
+		event, err := decodeMessage(m.Header, m.Data)
 		if err != nil {
 			logIncomingEventError(err)
 			return
@@ -64,13 +55,3 @@ func (n *NatsEventSubscriber) Close() {
 		}
 	}
 }
-
-func (n *NatsEventSubscriber) decodeMessage(data []byte, m interface{}) error {
-	b := bytes.Buffer{}
-	b.Write(data)
-	return gob.NewDecoder(&b).Decode(m)
-}
-
-func logIncomingEventError(err error) {
-	slog.Error("error when processing incoming event", slog.Any("error", err.Error()))
-}
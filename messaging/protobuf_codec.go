@@ -0,0 +1,141 @@
+package messaging
+
+import (
+	"context"
+	"cqrseventsourcingbar/events"
+	"fmt"
+
+	"github.com/segmentio/ksuid"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Field numbers of the Envelope message in envelope.proto.
+const (
+	envelopeEventType     protowire.Number = 1
+	envelopeSchemaVersion protowire.Number = 2
+	envelopePayload       protowire.Number = 3
+	envelopeEventID       protowire.Number = 4
+	envelopeOccurredAt    protowire.Number = 5
+	envelopeCausationID   protowire.Number = 6
+	envelopeCorrelationID protowire.Number = 7
+	envelopeActor         protowire.Number = 8
+)
+
+// ProtobufCodec sends events in the Envelope message of envelope.proto, with the payload kept as JSON.
+type ProtobufCodec struct{}
+
+func (ProtobufCodec) ContentType() string {
+	return "application/x-protobuf"
+}
+
+func (ProtobufCodec) Encode(event events.Event) ([]byte, error) {
+	eventType, schemaVersion, payload, err := events.DefaultRegistry.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	metadata := event.GetMetadata()
+
+	var b []byte
+	b = appendString(b, envelopeEventType, eventType)
+	b = protowire.AppendTag(b, envelopeSchemaVersion, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(schemaVersion))
+	b = protowire.AppendTag(b, envelopePayload, protowire.BytesType)
+	b = protowire.AppendBytes(b, payload)
+	b = appendString(b, envelopeEventID, event.GetEventID().String())
+	if !metadata.OccurredAt.IsZero() {
+		occurredAt, err := proto.Marshal(timestamppb.New(metadata.OccurredAt))
+		if err != nil {
+			return nil, err
+		}
+		b = protowire.AppendTag(b, envelopeOccurredAt, protowire.BytesType)
+		b = protowire.AppendBytes(b, occurredAt)
+	}
+	if metadata.CausationID != ksuid.Nil {
+		b = appendString(b, envelopeCausationID, metadata.CausationID.String())
+	}
+	b = appendString(b, envelopeCorrelationID, metadata.CorrelationID)
+	b = appendString(b, envelopeActor, metadata.Actor)
+	return b, nil
+}
+
+func appendString(b []byte, number protowire.Number, value string) []byte {
+	if value == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, number, protowire.BytesType)
+	return protowire.AppendString(b, value)
+}
+
+func (ProtobufCodec) Decode(data []byte) (events.Event, error) {
+	var eventType string
+	var schemaVersion int
+	var payload []byte
+	var metadata events.Metadata
+	for len(data) > 0 {
+		number, wireType, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		switch {
+		case number == envelopeSchemaVersion && wireType == protowire.VarintType:
+			value, n := protowire.ConsumeVarint(data)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			schemaVersion = int(value)
+			data = data[n:]
+		case wireType == protowire.BytesType:
+			value, n := protowire.ConsumeBytes(data)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			if err := decodeEnvelopeField(number, value, &eventType, &payload, &metadata); err != nil {
+				return nil, err
+			}
+			data = data[n:]
+		default:
+			// Fields added by newer versions of the envelope are skipped.
+			n := protowire.ConsumeFieldValue(number, wireType, data)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			data = data[n:]
+		}
+	}
+
+	event, err := events.DefaultRegistry.Unmarshal(context.Background(), eventType, schemaVersion, payload)
+	if err != nil {
+		return nil, err
+	}
+	return events.WithMetadata(event, metadata), nil
+}
+
+func decodeEnvelopeField(number protowire.Number, value []byte, eventType *string, payload *[]byte, metadata *events.Metadata) error {
+	switch number {
+	case envelopeEventType:
+		*eventType = string(value)
+	case envelopePayload:
+		*payload = append([]byte(nil), value...)
+	case envelopeOccurredAt:
+		var occurredAt timestamppb.Timestamp
+		if err := proto.Unmarshal(value, &occurredAt); err != nil {
+			return fmt.Errorf("could not read occurred at, reason: %w", err)
+		}
+		metadata.OccurredAt = occurredAt.AsTime()
+	case envelopeCausationID:
+		causationID, err := ksuid.Parse(string(value))
+		if err != nil {
+			return fmt.Errorf("could not parse causation id: %s, reason: %w", value, err)
+		}
+		metadata.CausationID = causationID
+	case envelopeCorrelationID:
+		metadata.CorrelationID = string(value)
+	case envelopeActor:
+		metadata.Actor = string(value)
+	}
+	return nil
+}
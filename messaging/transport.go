@@ -0,0 +1,39 @@
+package messaging
+
+import (
+	"context"
+	"cqrseventsourcingbar/events"
+	"fmt"
+)
+
+// EventSubscriber hands the events published on NATS to its event listener from the moment OnCreatedEvent is called.
+type EventSubscriber interface {
+	OnCreatedEvent() error
+	Close()
+}
+
+// NewEventEmitter publishes the events with core NATS, "nats", or to the JetStream stream, "jetstream", where they
+// stay until every durable consumer got them.
+func NewEventEmitter(ctx context.Context, transport string, url string, options ...EmitterOption) (events.EventEmitter, error) {
+	switch transport {
+	case "nats":
+		return NewNatsEventEmitter(url, options...)
+	case "jetstream":
+		return NewJetStreamEventEmitter(ctx, url, options...)
+	default:
+		return nil, fmt.Errorf("unknown transport: %s", transport)
+	}
+}
+
+// NewEventSubscriber subscribes to the events of the transport NewEventEmitter publishes them with. On JetStream the
+// subscriber is the durable consumer durableName, it gets the events published while it was down once it is back.
+func NewEventSubscriber(ctx context.Context, transport string, url string, durableName string, eventListener events.EventListener) (EventSubscriber, error) {
+	switch transport {
+	case "nats":
+		return NewNatsEventSubscriber(url, eventListener)
+	case "jetstream":
+		return NewJetStreamEventSubscriber(ctx, url, durableName, eventListener)
+	default:
+		return nil, fmt.Errorf("unknown transport: %s", transport)
+	}
+}
@@ -0,0 +1,121 @@
+package queries
+
+import (
+	"context"
+	"cqrseventsourcingbar/events"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/segmentio/ksuid"
+)
+
+// CatchUpRunner hands in-memory projections over from the history in the event store to live events without gaps.
+// It must be subscribed to live events before CatchUp starts; live events are buffered until the history is applied,
+// and every event is applied once, in aggregate sequence order. A live event arriving after a gap in its aggregate's
+// sequence is applied after the events missed, loaded from the event store while the history may still be read, so
+// the event store must be safe for concurrent use, as the Postgres one is with its pool.
+//
+// The listeners of an EventListeners are followed one by one, so when one of them fails the event is retried for that
+// listener only.
+type CatchUpRunner struct {
+	eventStore events.EventStore
+	listeners  events.EventListeners
+	batchSize  int
+	lock       sync.Mutex
+	caughtUp   bool
+	buffered   []events.Event
+	// lastSequenceNumberBy has the last sequence number applied per aggregate for each listener.
+	lastSequenceNumberBy []map[ksuid.KSUID]int
+}
+
+func CreateCatchUpRunner(eventStore events.EventStore, listener events.EventListener, batchSize int) *CatchUpRunner {
+	listeners, ok := listener.(events.EventListeners)
+	if !ok {
+		listeners = events.EventListeners{listener}
+	}
+	lastSequenceNumberBy := make([]map[ksuid.KSUID]int, len(listeners))
+	for i := range lastSequenceNumberBy {
+		lastSequenceNumberBy[i] = make(map[ksuid.KSUID]int)
+	}
+	return &CatchUpRunner{
+		eventStore:           eventStore,
+		listeners:            listeners,
+		batchSize:            batchSize,
+		lastSequenceNumberBy: lastSequenceNumberBy,
+	}
+}
+
+func (r *CatchUpRunner) CatchUp(ctx context.Context) error {
+	_, err := events.ForEachEventFrom(ctx, r.eventStore, 0, r.batchSize, func(recordedEvent events.RecordedEvent) error {
+		defer r.lock.Unlock()
+		r.lock.Lock()
+		return r.apply(ctx, recordedEvent.Event)
+	})
+	if err != nil {
+		return err
+	}
+
+	defer r.lock.Unlock()
+	r.lock.Lock()
+	for _, event := range r.buffered {
+		if err := r.apply(ctx, event); err != nil {
+			return err
+		}
+	}
+	r.buffered = nil
+	r.caughtUp = true
+	return nil
+}
+
+func (r *CatchUpRunner) HandleEvent(e events.Event) error {
+	defer r.lock.Unlock()
+	r.lock.Lock()
+	if !r.caughtUp {
+		r.buffered = append(r.buffered, e)
+		return nil
+	}
+	return r.apply(context.Background(), e)
+}
+
+func (r *CatchUpRunner) apply(ctx context.Context, e events.Event) error {
+	var errs []error
+	for i := range r.listeners {
+		if err := r.applyTo(ctx, i, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *CatchUpRunner) applyTo(ctx context.Context, listener int, e events.Event) error {
+	lastSequenceNumberBy := r.lastSequenceNumberBy[listener]
+	lastSequenceNumber := lastSequenceNumberBy[e.GetID()]
+	if e.GetSequenceNumber() <= lastSequenceNumber {
+		return nil
+	}
+
+	var toApply []events.Event
+	if e.GetSequenceNumber() > lastSequenceNumber+1 {
+		slog.Warn("loading the events missed", slog.String("aggregate", e.GetID().String()), slog.Int("after", lastSequenceNumber), slog.Int("sequence_number", e.GetSequenceNumber()))
+		missed, err := r.eventStore.LoadEventsAfter(ctx, e.GetID(), lastSequenceNumber)
+		if err != nil {
+			return fmt.Errorf("error loading events for aggregate: %s, reason: %w", e.GetID().String(), err)
+		}
+		for _, missedEvent := range missed {
+			if missedEvent.GetSequenceNumber() < e.GetSequenceNumber() {
+				toApply = append(toApply, missedEvent)
+			}
+		}
+	}
+	toApply = append(toApply, e)
+
+	for _, event := range toApply {
+		if err := r.listeners[listener].HandleEvent(event); err != nil {
+			return err
+		}
+		lastSequenceNumberBy[event.GetID()] = event.GetSequenceNumber()
+	}
+	return nil
+}
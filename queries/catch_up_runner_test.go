@@ -0,0 +1,173 @@
+package queries_test
+
+import (
+	"context"
+	"cqrseventsourcingbar/events"
+	mock_events "cqrseventsourcingbar/events/mocks"
+	"cqrseventsourcingbar/queries"
+	"cqrseventsourcingbar/shared"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type CatchUpRunnerTestSuite struct {
+	suite.Suite
+	eventStore     *mock_events.EventStore
+	openTabQueries queries.OpenTabQueries
+	runner         *queries.CatchUpRunner
+	ctx            context.Context
+}
+
+func (suite *CatchUpRunnerTestSuite) SetupTest() {
+	suite.eventStore = mock_events.NewEventStore(suite.T())
+	suite.openTabQueries = queries.CreateOpenTabs()
+	suite.runner = queries.CreateCatchUpRunner(suite.eventStore, suite.openTabQueries, 10)
+	suite.ctx = context.Background()
+}
+
+func (suite *CatchUpRunnerTestSuite) TestLiveEventsDuringCatchUpAreAppliedOnceAndInOrder() {
+	// Given
+	tabId := ksuid.New()
+	tabOpened := events.TabOpened{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 1}, TableNumber: 1, Waiter: "Charles"}
+	drinksOrdered := events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 2}, Items: []shared.MenuItem{{ID: 10, Description: "Water", Price: shared.Cents(100)}}}
+	drinksServed := events.DrinksServed{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 3}, MenuNumbers: []int{10}}
+	suite.eventStore.On("LoadEventsFrom", suite.ctx, int64(0), 10).Return([]events.RecordedEvent{
+		{Position: 1, SequenceNumber: 1, Event: tabOpened},
+		{Position: 2, SequenceNumber: 2, Event: drinksOrdered},
+	}, nil).Run(func(mock.Arguments) {
+		// committed while the history was being read, so it arrives live as well as in the history
+		assert.NoError(suite.T(), suite.runner.HandleEvent(drinksOrdered))
+		assert.NoError(suite.T(), suite.runner.HandleEvent(drinksServed))
+	})
+
+	// When
+	err := suite.runner.CatchUp(suite.ctx)
+
+	// Then
+	assert.NoError(suite.T(), err)
+	tabStatus, err := suite.openTabQueries.TabForTable(1)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), tabStatus.ToServe)
+	assert.Equal(suite.T(), []queries.TabItem{{MenuNumber: 10, Description: "Water", Price: shared.Cents(100)}}, tabStatus.Served)
+}
+
+func (suite *CatchUpRunnerTestSuite) TestRedeliveredLiveEventIsSkippedAfterCatchUp() {
+	// Given
+	tabId := ksuid.New()
+	tabOpened := events.TabOpened{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 1}, TableNumber: 2, Waiter: "Charles"}
+	drinksOrdered := events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 2}, Items: []shared.MenuItem{{ID: 10, Description: "Water", Price: shared.Cents(100)}}}
+	suite.eventStore.On("LoadEventsFrom", suite.ctx, int64(0), 10).Return([]events.RecordedEvent{
+		{Position: 1, SequenceNumber: 1, Event: tabOpened},
+	}, nil)
+	assert.NoError(suite.T(), suite.runner.CatchUp(suite.ctx))
+
+	// When
+	assert.NoError(suite.T(), suite.runner.HandleEvent(drinksOrdered))
+	assert.NoError(suite.T(), suite.runner.HandleEvent(drinksOrdered))
+
+	// Then
+	tabStatus, err := suite.openTabQueries.TabForTable(2)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []queries.TabItem{{MenuNumber: 10, Description: "Water", Price: shared.Cents(100)}}, tabStatus.ToServe)
+}
+
+func (suite *CatchUpRunnerTestSuite) TestLiveEventAfterAGapIsAppliedAfterTheEventsMissed() {
+	// Given
+	tabId := ksuid.New()
+	tabOpened := events.TabOpened{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 1}, TableNumber: 4, Waiter: "Charles"}
+	drinksOrdered := events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 2}, Items: []shared.MenuItem{{ID: 10, Description: "Water", Price: shared.Cents(100)}}}
+	drinksServed := events.DrinksServed{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 3}, MenuNumbers: []int{10}}
+	suite.eventStore.On("LoadEventsFrom", suite.ctx, int64(0), 10).Return([]events.RecordedEvent{
+		{Position: 1, SequenceNumber: 1, Event: tabOpened},
+	}, nil)
+	suite.eventStore.On("LoadEventsAfter", mock.Anything, tabId, 1).Return([]events.Event{drinksOrdered, drinksServed}, nil)
+	assert.NoError(suite.T(), suite.runner.CatchUp(suite.ctx))
+
+	// When
+	err := suite.runner.HandleEvent(drinksServed)
+
+	// Then
+	assert.NoError(suite.T(), err)
+	tabStatus, err := suite.openTabQueries.TabForTable(4)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), tabStatus.ToServe)
+	assert.Equal(suite.T(), []queries.TabItem{{MenuNumber: 10, Description: "Water", Price: shared.Cents(100)}}, tabStatus.Served)
+}
+
+func (suite *CatchUpRunnerTestSuite) TestEventIsOnlyRetriedForTheListenerThatFailed() {
+	// Given
+	succeeding := mock_events.NewEventListener(suite.T())
+	failing := mock_events.NewEventListener(suite.T())
+	runner := queries.CreateCatchUpRunner(suite.eventStore, events.EventListeners{succeeding, failing}, 10)
+	suite.eventStore.On("LoadEventsFrom", suite.ctx, int64(0), 10).Return(nil, nil)
+	assert.NoError(suite.T(), runner.CatchUp(suite.ctx))
+	tabOpened := events.TabOpened{BaseEvent: events.BaseEvent{ID: ksuid.New(), SequenceNumber: 1}, TableNumber: 5, Waiter: "Charles"}
+	succeeding.On("HandleEvent", tabOpened).Return(nil).Once()
+	failing.On("HandleEvent", tabOpened).Return(errors.New("broken")).Once()
+	failing.On("HandleEvent", tabOpened).Return(nil).Once()
+	assert.Error(suite.T(), runner.HandleEvent(tabOpened))
+
+	// When
+	err := runner.HandleEvent(tabOpened)
+
+	// Then
+	assert.NoError(suite.T(), err)
+	succeeding.AssertNumberOfCalls(suite.T(), "HandleEvent", 1)
+	failing.AssertNumberOfCalls(suite.T(), "HandleEvent", 2)
+}
+
+// droppingOrders loses the DrinksOrdered events on their way to the listener, as a subscriber missing messages would.
+type droppingOrders struct {
+	listener events.EventListener
+}
+
+func (d droppingOrders) HandleEvent(e events.Event) error {
+	if _, ok := e.(events.DrinksOrdered); ok {
+		return nil
+	}
+	return d.listener.HandleEvent(e)
+}
+
+func TestGapsInLiveEventsAreLoadedWhileTheHistoryIsRead(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	eventStore := events.CreateInMemoryEventStore()
+	openTabQueries := queries.CreateOpenTabs()
+	runner := queries.CreateCatchUpRunner(eventStore, openTabQueries, 2)
+	eventStore.Subscribe(droppingOrders{listener: runner})
+	water := shared.MenuItem{ID: 10, Description: "Water", Price: shared.Cents(100), IsDrink: true}
+	var wg sync.WaitGroup
+	for table := 1; table <= 20; table++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tabId := ksuid.New()
+			assert.NoError(t, eventStore.SaveEvents(ctx, tabId, 0, []events.Event{events.TabOpened{BaseEvent: events.BaseEvent{ID: tabId}, TableNumber: table, Waiter: "Charles"}}))
+			assert.NoError(t, eventStore.SaveEvents(ctx, tabId, 1, []events.Event{events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: tabId}, Items: []shared.MenuItem{water}}}))
+			assert.NoError(t, eventStore.SaveEvents(ctx, tabId, 2, []events.Event{events.DrinksServed{BaseEvent: events.BaseEvent{ID: tabId}, MenuNumbers: []int{10}}}))
+		}()
+	}
+
+	// When
+	err := runner.CatchUp(ctx)
+	wg.Wait()
+
+	// Then
+	assert.NoError(t, err)
+	for table := 1; table <= 20; table++ {
+		tabStatus, err := openTabQueries.TabForTable(table)
+		assert.NoError(t, err)
+		assert.Empty(t, tabStatus.ToServe, "table %d", table)
+		assert.Equal(t, []queries.TabItem{{MenuNumber: 10, Description: "Water", Price: shared.Cents(100), IsDrink: true}}, tabStatus.Served, "table %d", table)
+	}
+}
+
+func TestCatchUpRunnerTestSuite(t *testing.T) {
+	suite.Run(t, new(CatchUpRunnerTestSuite))
+}
@@ -0,0 +1,105 @@
+package queries
+
+import (
+	"cqrseventsourcingbar/events"
+	"slices"
+	"sync"
+
+	"github.com/segmentio/ksuid"
+)
+
+//go:generate mockery --name ChefTodoListQueries
+type ChefTodoListQueries interface {
+	TodoList() []ChefTodoGroup
+	events.EventListener
+}
+
+type ChefTodoGroup struct {
+	TabID       string         `json:"tab_id"`
+	TableNumber int            `json:"table_number"`
+	Items       []ChefTodoItem `json:"items"`
+}
+
+type ChefTodoItem struct {
+	MenuNumber  int    `json:"menu_number"`
+	Description string `json:"description"`
+}
+
+// chefTodoList keeps one group per food order still being prepared, oldest order first.
+type chefTodoList struct {
+	groups                  []ChefTodoGroup
+	tableNumberByTab        map[ksuid.KSUID]int
+	lastSequenceNumberByTab sequenceTracker
+	lock                    sync.RWMutex
+}
+
+func (c *chefTodoList) TodoList() []ChefTodoGroup {
+	defer c.lock.RUnlock()
+	c.lock.RLock()
+
+	todoList := make([]ChefTodoGroup, 0, len(c.groups))
+	for _, group := range c.groups {
+		group.Items = slices.Clone(group.Items)
+		todoList = append(todoList, group)
+	}
+	return todoList
+}
+
+func (c *chefTodoList) HandleEvent(e events.Event) error {
+	defer c.lock.Unlock()
+	c.lock.Lock()
+
+	apply, err := c.lastSequenceNumberByTab.shouldApply(e)
+	if !apply {
+		return err
+	}
+
+	switch event := e.(type) {
+	case events.TabOpened:
+		c.tableNumberByTab[event.ID] = event.TableNumber
+	case events.FoodOrdered:
+		c.handleFoodOrdered(event)
+	case events.FoodPrepared:
+		c.handleFoodPrepared(event)
+	case events.TabClosed:
+		delete(c.tableNumberByTab, event.ID)
+	}
+	c.lastSequenceNumberByTab.applied(e)
+	return nil
+}
+
+func (c *chefTodoList) handleFoodOrdered(e events.FoodOrdered) {
+	group := ChefTodoGroup{
+		TabID:       e.ID.String(),
+		TableNumber: c.tableNumberByTab[e.ID],
+		Items:       []ChefTodoItem{},
+	}
+	for _, orderedItem := range e.Items {
+		group.Items = append(group.Items, ChefTodoItem{MenuNumber: orderedItem.ID, Description: orderedItem.Description})
+	}
+	c.groups = append(c.groups, group)
+}
+
+func (c *chefTodoList) handleFoodPrepared(e events.FoodPrepared) {
+	for _, menuNumber := range e.MenuNumbers {
+		for i := range c.groups {
+			if c.groups[i].TabID != e.ID.String() {
+				continue
+			}
+			index := slices.IndexFunc(c.groups[i].Items, func(item ChefTodoItem) bool { return item.MenuNumber == menuNumber })
+			if index >= 0 {
+				c.groups[i].Items = slices.Delete(c.groups[i].Items, index, index+1)
+				break
+			}
+		}
+	}
+	c.groups = slices.DeleteFunc(c.groups, func(group ChefTodoGroup) bool { return len(group.Items) == 0 })
+}
+
+func CreateChefTodoList() ChefTodoListQueries {
+	return &chefTodoList{
+		groups:                  []ChefTodoGroup{},
+		tableNumberByTab:        make(map[ksuid.KSUID]int),
+		lastSequenceNumberByTab: make(sequenceTracker),
+	}
+}
@@ -0,0 +1,71 @@
+package queries_test
+
+import (
+	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/queries"
+	"cqrseventsourcingbar/shared"
+	"testing"
+
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ChefTodoListTestSuite struct {
+	suite.Suite
+	chefTodoList queries.ChefTodoListQueries
+}
+
+func (suite *ChefTodoListTestSuite) SetupTest() {
+	suite.chefTodoList = queries.CreateChefTodoList()
+}
+
+func (suite *ChefTodoListTestSuite) TestNoFoodOrdered() {
+	assert.Empty(suite.T(), suite.chefTodoList.TodoList())
+}
+
+func (suite *ChefTodoListTestSuite) TestFoodOrdersAreListedUntilPrepared() {
+	// Given
+	tabId := ksuid.New()
+	burger := shared.MenuItem{ID: 21, Description: "burger", Price: shared.Cents(800)}
+	fries := shared.MenuItem{ID: 22, Description: "fries", Price: shared.Cents(300)}
+	beer := shared.MenuItem{ID: 11, Description: "beer", Price: shared.Cents(150), IsDrink: true}
+	for _, event := range []events.Event{
+		events.TabOpened{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 1}, TableNumber: 4, Waiter: "Charles"},
+		events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 2}, Items: []shared.MenuItem{beer}},
+		events.FoodOrdered{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 3}, Items: []shared.MenuItem{burger, fries}},
+		events.FoodOrdered{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 4}, Items: []shared.MenuItem{burger}},
+	} {
+		assert.NoError(suite.T(), suite.chefTodoList.HandleEvent(event))
+	}
+
+	// When
+	err := suite.chefTodoList.HandleEvent(events.FoodPrepared{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 5}, MenuNumbers: []int{21, 22}})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []queries.ChefTodoGroup{{
+		TabID:       tabId.String(),
+		TableNumber: 4,
+		Items:       []queries.ChefTodoItem{{MenuNumber: 21, Description: "burger"}},
+	}}, suite.chefTodoList.TodoList())
+}
+
+func (suite *ChefTodoListTestSuite) TestRedeliveredFoodOrderIsListedOnce() {
+	// Given
+	tabId := ksuid.New()
+	foodOrdered := events.FoodOrdered{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 2}, Items: []shared.MenuItem{{ID: 21, Description: "burger", Price: shared.Cents(800)}}}
+	assert.NoError(suite.T(), suite.chefTodoList.HandleEvent(events.TabOpened{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 1}, TableNumber: 4}))
+	assert.NoError(suite.T(), suite.chefTodoList.HandleEvent(foodOrdered))
+
+	// When
+	err := suite.chefTodoList.HandleEvent(foodOrdered)
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), suite.chefTodoList.TodoList(), 1)
+}
+
+func TestChefTodoListTestSuite(t *testing.T) {
+	suite.Run(t, new(ChefTodoListTestSuite))
+}
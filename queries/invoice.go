@@ -0,0 +1,49 @@
+package queries
+
+import (
+	"cqrseventsourcingbar/shared"
+)
+
+// buildInvoice prices the served items the same way the tab aggregate does when the tab is closed:
+// comps come off first, then the percentage discounts and last the fixed ones.
+// The balance due is what is left of the total once the payments so far are taken off.
+func buildInvoice(served []TabItem, comps []TabAdjustment, discounts []TabAdjustment, payments []TabPayment) TabInvoice {
+	subtotal := shared.Cents(0)
+	for _, item := range served {
+		subtotal = subtotal.Add(item.Price)
+	}
+	comped := shared.Cents(0)
+	for _, comp := range comps {
+		comped = comped.Add(comp.Amount)
+	}
+
+	tabDiscounts := []shared.Discount{}
+	tabAdjustments := []TabAdjustment{}
+	for _, discount := range discounts {
+		tabDiscounts = append(tabDiscounts, shared.Discount{Percentage: discount.Percentage, Amount: discount.Amount})
+		if discount.Percentage > 0 {
+			discount.Amount = subtotal.Sub(comped).Percent(discount.Percentage)
+		}
+		tabAdjustments = append(tabAdjustments, discount)
+	}
+
+	total := shared.ApplyDiscounts(subtotal.Sub(comped), tabDiscounts)
+	paid := shared.Cents(0)
+	for _, payment := range payments {
+		paid = paid.Add(payment.Amount)
+	}
+	balanceDue := total.Sub(paid)
+	if !balanceDue.IsPositive() {
+		balanceDue = shared.NewMoney(0, total.Currency)
+	}
+
+	return TabInvoice{
+		Items:           served,
+		Subtotal:        subtotal,
+		LineAdjustments: comps,
+		TabAdjustments:  tabAdjustments,
+		Total:           total,
+		Payments:        payments,
+		BalanceDue:      balanceDue,
+	}
+}
@@ -0,0 +1,136 @@
+package queries
+
+import (
+	"cmp"
+	"context"
+	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/shared"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+//go:generate mockery --name MenuCatalogueQueries
+type MenuCatalogueQueries interface {
+	shared.MenuItemRepository
+	PriceHistory(menuNumber int) ([]PriceChange, error)
+	events.EventListener
+}
+
+type PriceChange struct {
+	Price     shared.Money `json:"price"`
+	ChangedAt time.Time    `json:"changed_at"`
+	ChangedBy string       `json:"changed_by,omitempty"`
+}
+
+type catalogueEntry struct {
+	item         shared.MenuItem
+	retired      bool
+	priceHistory []PriceChange
+}
+
+// menuCatalogue is the menu as built from the menu item events, retired items are kept for their price history.
+type menuCatalogue struct {
+	entries                      map[int]*catalogueEntry
+	lastSequenceNumberByMenuItem sequenceTracker
+	lock                         sync.RWMutex
+}
+
+func (m *menuCatalogue) ReadItems(_ context.Context, menuItems []int) ([]shared.MenuItem, error) {
+	defer m.lock.RUnlock()
+	m.lock.RLock()
+
+	orderedItems := []shared.MenuItem{}
+	for _, id := range slices.Sorted(slices.Values(menuItems)) {
+		entry, ok := m.entries[id]
+		if !ok || entry.retired {
+			return nil, fmt.Errorf("couldn't find menu item: %d", id)
+		}
+		orderedItems = append(orderedItems, entry.item)
+	}
+	return orderedItems, nil
+}
+
+func (m *menuCatalogue) ReadAllItems(_ context.Context) ([]shared.MenuItem, error) {
+	defer m.lock.RUnlock()
+	m.lock.RLock()
+
+	items := []shared.MenuItem{}
+	for _, entry := range m.entries {
+		if !entry.retired {
+			items = append(items, entry.item)
+		}
+	}
+	slices.SortFunc(items, func(a, b shared.MenuItem) int { return cmp.Compare(a.ID, b.ID) })
+	return items, nil
+}
+
+func (m *menuCatalogue) PriceHistory(menuNumber int) ([]PriceChange, error) {
+	defer m.lock.RUnlock()
+	m.lock.RLock()
+
+	entry, ok := m.entries[menuNumber]
+	if !ok {
+		return nil, fmt.Errorf("couldn't find menu item: %d", menuNumber)
+	}
+	return slices.Clone(entry.priceHistory), nil
+}
+
+func (m *menuCatalogue) HandleEvent(e events.Event) error {
+	defer m.lock.Unlock()
+	m.lock.Lock()
+
+	apply, err := m.lastSequenceNumberByMenuItem.shouldApply(e)
+	if !apply {
+		return err
+	}
+
+	var entry *catalogueEntry
+	switch event := e.(type) {
+	case events.MenuItemAdded:
+		entry = &catalogueEntry{
+			item:         shared.MenuItem{ID: event.MenuNumber, Description: event.Description, Price: event.Price, IsDrink: event.IsDrink, Category: event.Category},
+			priceHistory: []PriceChange{priceChange(event.Price, event.Metadata)},
+		}
+		m.entries[event.MenuNumber] = entry
+	case events.MenuItemPriceChanged:
+		if entry = m.entries[event.MenuNumber]; entry != nil {
+			entry.item.Price = event.Price
+			entry.priceHistory = append(entry.priceHistory, priceChange(event.Price, event.Metadata))
+		}
+	case events.MenuItemRenamed:
+		if entry = m.entries[event.MenuNumber]; entry != nil {
+			entry.item.Description = event.Description
+		}
+	case events.MenuItemCategorised:
+		if entry = m.entries[event.MenuNumber]; entry != nil {
+			entry.item.Category = event.Category
+		}
+	case events.MenuItemRecipeSet:
+		if entry = m.entries[event.MenuNumber]; entry != nil {
+			entry.item.Recipe = event.Recipe
+		}
+	case events.MenuItemRetired:
+		if entry = m.entries[event.MenuNumber]; entry != nil {
+			entry.retired = true
+		}
+	}
+	// The version follows the menu item aggregate, so clients can send it back with their orders.
+	if entry != nil {
+		entry.item.Version = e.GetSequenceNumber()
+	}
+	m.lastSequenceNumberByMenuItem.applied(e)
+	return nil
+}
+
+func priceChange(price shared.Money, metadata events.Metadata) PriceChange {
+	return PriceChange{Price: price, ChangedAt: metadata.OccurredAt, ChangedBy: metadata.Actor}
+}
+
+func CreateMenuCatalogue() MenuCatalogueQueries {
+	return &menuCatalogue{
+		entries:                      make(map[int]*catalogueEntry),
+		lastSequenceNumberByMenuItem: make(sequenceTracker),
+	}
+}
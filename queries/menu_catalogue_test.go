@@ -0,0 +1,130 @@
+package queries_test
+
+import (
+	"context"
+	"cqrseventsourcingbar/commands"
+	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/queries"
+	"cqrseventsourcingbar/shared"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type MenuCatalogueTestSuite struct {
+	suite.Suite
+	menuCatalogue queries.MenuCatalogueQueries
+	ctx           context.Context
+}
+
+func (suite *MenuCatalogueTestSuite) SetupTest() {
+	suite.menuCatalogue = queries.CreateMenuCatalogue()
+	suite.ctx = context.Background()
+}
+
+func (suite *MenuCatalogueTestSuite) handleEvents(menuEvents ...events.Event) {
+	for _, event := range menuEvents {
+		assert.NoError(suite.T(), suite.menuCatalogue.HandleEvent(event))
+	}
+}
+
+func (suite *MenuCatalogueTestSuite) TestItemsReflectTheLatestChanges() {
+	// Given
+	pieId := commands.MenuItemID(6)
+	beerId := commands.MenuItemID(7)
+
+	// When
+	suite.handleEvents(
+		events.MenuItemAdded{BaseEvent: events.BaseEvent{ID: pieId, SequenceNumber: 1}, MenuNumber: 6, Description: "pie", Price: shared.Cents(450)},
+		events.MenuItemAdded{BaseEvent: events.BaseEvent{ID: beerId, SequenceNumber: 1}, MenuNumber: 7, Description: "beer", Price: shared.Cents(300), IsDrink: true},
+		events.MenuItemPriceChanged{BaseEvent: events.BaseEvent{ID: pieId, SequenceNumber: 2}, MenuNumber: 6, Price: shared.Cents(500), PreviousPrice: shared.Cents(450)},
+		events.MenuItemRenamed{BaseEvent: events.BaseEvent{ID: pieId, SequenceNumber: 3}, MenuNumber: 6, Description: "pork pie"},
+		events.MenuItemCategorised{BaseEvent: events.BaseEvent{ID: beerId, SequenceNumber: 2}, MenuNumber: 7, Category: "beers"},
+	)
+
+	// Then
+	items, err := suite.menuCatalogue.ReadAllItems(suite.ctx)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []shared.MenuItem{
+		{ID: 6, Description: "pork pie", Price: shared.Cents(500), Version: 3},
+		{ID: 7, Description: "beer", Price: shared.Cents(300), IsDrink: true, Category: "beers", Version: 2},
+	}, items)
+	orderedItems, err := suite.menuCatalogue.ReadItems(suite.ctx, []int{7, 6})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), items, orderedItems)
+}
+
+func (suite *MenuCatalogueTestSuite) TestItemsCarryTheirRecipe() {
+	// Given
+	ginAndTonicId := commands.MenuItemID(8)
+	recipe := []shared.RecipeIngredient{{Ingredient: "gin", Quantity: 50}, {Ingredient: "tonic", Quantity: 150}}
+
+	// When
+	suite.handleEvents(
+		events.MenuItemAdded{BaseEvent: events.BaseEvent{ID: ginAndTonicId, SequenceNumber: 1}, MenuNumber: 8, Description: "gin and tonic", Price: shared.Cents(900), IsDrink: true},
+		events.MenuItemRecipeSet{BaseEvent: events.BaseEvent{ID: ginAndTonicId, SequenceNumber: 2}, MenuNumber: 8, Recipe: recipe},
+	)
+
+	// Then
+	items, err := suite.menuCatalogue.ReadItems(suite.ctx, []int{8})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []shared.MenuItem{{ID: 8, Description: "gin and tonic", Price: shared.Cents(900), IsDrink: true, Version: 2, Recipe: recipe}}, items)
+}
+
+func (suite *MenuCatalogueTestSuite) TestRetiredItemsLeaveTheMenuButKeepTheirPriceHistory() {
+	// Given
+	pieId := commands.MenuItemID(6)
+	added := events.Metadata{OccurredAt: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC), Actor: "manager"}
+	changed := events.Metadata{OccurredAt: time.Date(2024, 3, 8, 9, 0, 0, 0, time.UTC), Actor: "owner"}
+	suite.handleEvents(
+		events.MenuItemAdded{BaseEvent: events.BaseEvent{ID: pieId, SequenceNumber: 1, Metadata: added}, MenuNumber: 6, Description: "pie", Price: shared.Cents(450)},
+		events.MenuItemPriceChanged{BaseEvent: events.BaseEvent{ID: pieId, SequenceNumber: 2, Metadata: changed}, MenuNumber: 6, Price: shared.Cents(500), PreviousPrice: shared.Cents(450)},
+	)
+
+	// When
+	suite.handleEvents(events.MenuItemRetired{BaseEvent: events.BaseEvent{ID: pieId, SequenceNumber: 3}, MenuNumber: 6})
+
+	// Then
+	items, err := suite.menuCatalogue.ReadAllItems(suite.ctx)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), items)
+	_, err = suite.menuCatalogue.ReadItems(suite.ctx, []int{6})
+	assert.EqualError(suite.T(), err, "couldn't find menu item: 6")
+	history, err := suite.menuCatalogue.PriceHistory(6)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []queries.PriceChange{
+		{Price: shared.Cents(450), ChangedAt: added.OccurredAt, ChangedBy: "manager"},
+		{Price: shared.Cents(500), ChangedAt: changed.OccurredAt, ChangedBy: "owner"},
+	}, history)
+}
+
+func (suite *MenuCatalogueTestSuite) TestRedeliveredEventsAreSkipped() {
+	// Given
+	pieId := commands.MenuItemID(6)
+	priceChanged := events.MenuItemPriceChanged{BaseEvent: events.BaseEvent{ID: pieId, SequenceNumber: 2}, MenuNumber: 6, Price: shared.Cents(500), PreviousPrice: shared.Cents(450)}
+	suite.handleEvents(
+		events.MenuItemAdded{BaseEvent: events.BaseEvent{ID: pieId, SequenceNumber: 1}, MenuNumber: 6, Description: "pie", Price: shared.Cents(450)},
+		priceChanged,
+	)
+
+	// When
+	err := suite.menuCatalogue.HandleEvent(priceChanged)
+
+	// Then
+	assert.NoError(suite.T(), err)
+	history, err := suite.menuCatalogue.PriceHistory(6)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), history, 2)
+}
+
+func (suite *MenuCatalogueTestSuite) TestPriceHistoryOfAnUnknownItem() {
+	_, err := suite.menuCatalogue.PriceHistory(42)
+
+	assert.EqualError(suite.T(), err, "couldn't find menu item: 42")
+}
+
+func TestMenuCatalogueTestSuite(t *testing.T) {
+	suite.Run(t, new(MenuCatalogueTestSuite))
+}
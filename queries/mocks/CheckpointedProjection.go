@@ -0,0 +1,75 @@
+// Code generated by mockery v2.49.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	events "cqrseventsourcingbar/events"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CheckpointedProjection is an autogenerated mock type for the CheckpointedProjection type
+type CheckpointedProjection struct {
+	mock.Mock
+}
+
+// HandleRecordedEvent provides a mock function with given fields: ctx, recordedEvent
+func (_m *CheckpointedProjection) HandleRecordedEvent(ctx context.Context, recordedEvent events.RecordedEvent) error {
+	ret := _m.Called(ctx, recordedEvent)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HandleRecordedEvent")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, events.RecordedEvent) error); ok {
+		r0 = rf(ctx, recordedEvent)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// LastPosition provides a mock function with given fields: ctx
+func (_m *CheckpointedProjection) LastPosition(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LastPosition")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewCheckpointedProjection creates a new instance of CheckpointedProjection. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCheckpointedProjection(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CheckpointedProjection {
+	mock := &CheckpointedProjection{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,141 @@
+// Code generated by mockery v2.49.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	events "cqrseventsourcingbar/events"
+
+	mock "github.com/stretchr/testify/mock"
+
+	queries "cqrseventsourcingbar/queries"
+
+	shared "cqrseventsourcingbar/shared"
+)
+
+// MenuCatalogueQueries is an autogenerated mock type for the MenuCatalogueQueries type
+type MenuCatalogueQueries struct {
+	mock.Mock
+}
+
+// HandleEvent provides a mock function with given fields: e
+func (_m *MenuCatalogueQueries) HandleEvent(e events.Event) error {
+	ret := _m.Called(e)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HandleEvent")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(events.Event) error); ok {
+		r0 = rf(e)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PriceHistory provides a mock function with given fields: menuNumber
+func (_m *MenuCatalogueQueries) PriceHistory(menuNumber int) ([]queries.PriceChange, error) {
+	ret := _m.Called(menuNumber)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PriceHistory")
+	}
+
+	var r0 []queries.PriceChange
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int) ([]queries.PriceChange, error)); ok {
+		return rf(menuNumber)
+	}
+	if rf, ok := ret.Get(0).(func(int) []queries.PriceChange); ok {
+		r0 = rf(menuNumber)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]queries.PriceChange)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(menuNumber)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReadAllItems provides a mock function with given fields: ctx
+func (_m *MenuCatalogueQueries) ReadAllItems(ctx context.Context) ([]shared.MenuItem, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReadAllItems")
+	}
+
+	var r0 []shared.MenuItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]shared.MenuItem, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []shared.MenuItem); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]shared.MenuItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReadItems provides a mock function with given fields: ctx, menuItems
+func (_m *MenuCatalogueQueries) ReadItems(ctx context.Context, menuItems []int) ([]shared.MenuItem, error) {
+	ret := _m.Called(ctx, menuItems)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReadItems")
+	}
+
+	var r0 []shared.MenuItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int) ([]shared.MenuItem, error)); ok {
+		return rf(ctx, menuItems)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []int) []shared.MenuItem); ok {
+		r0 = rf(ctx, menuItems)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]shared.MenuItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []int) error); ok {
+		r1 = rf(ctx, menuItems)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewMenuCatalogueQueries creates a new instance of MenuCatalogueQueries. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMenuCatalogueQueries(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MenuCatalogueQueries {
+	mock := &MenuCatalogueQueries{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
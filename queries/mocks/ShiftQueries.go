@@ -0,0 +1,68 @@
+// Code generated by mockery v2.49.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	events "cqrseventsourcingbar/events"
+
+	mock "github.com/stretchr/testify/mock"
+
+	queries "cqrseventsourcingbar/queries"
+)
+
+// ShiftQueries is an autogenerated mock type for the ShiftQueries type
+type ShiftQueries struct {
+	mock.Mock
+}
+
+// HandleEvent provides a mock function with given fields: e
+func (_m *ShiftQueries) HandleEvent(e events.Event) error {
+	ret := _m.Called(e)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HandleEvent")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(events.Event) error); ok {
+		r0 = rf(e)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Reconciliations provides a mock function with no fields
+func (_m *ShiftQueries) Reconciliations() []queries.ShiftReconciliation {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reconciliations")
+	}
+
+	var r0 []queries.ShiftReconciliation
+	if rf, ok := ret.Get(0).(func() []queries.ShiftReconciliation); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]queries.ShiftReconciliation)
+		}
+	}
+
+	return r0
+}
+
+// NewShiftQueries creates a new instance of ShiftQueries. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewShiftQueries(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ShiftQueries {
+	mock := &ShiftQueries{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,128 @@
+// Code generated by mockery v2.49.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	events "cqrseventsourcingbar/events"
+
+	mock "github.com/stretchr/testify/mock"
+
+	queries "cqrseventsourcingbar/queries"
+)
+
+// StockQueries is an autogenerated mock type for the StockQueries type
+type StockQueries struct {
+	mock.Mock
+}
+
+// HandleEvent provides a mock function with given fields: e
+func (_m *StockQueries) HandleEvent(e events.Event) error {
+	ret := _m.Called(e)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HandleEvent")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(events.Event) error); ok {
+		r0 = rf(e)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IngredientLevels provides a mock function with no fields
+func (_m *StockQueries) IngredientLevels() []queries.IngredientLevel {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for IngredientLevels")
+	}
+
+	var r0 []queries.IngredientLevel
+	if rf, ok := ret.Get(0).(func() []queries.IngredientLevel); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]queries.IngredientLevel)
+		}
+	}
+
+	return r0
+}
+
+// LowStock provides a mock function with no fields
+func (_m *StockQueries) LowStock() []queries.StockLevel {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for LowStock")
+	}
+
+	var r0 []queries.StockLevel
+	if rf, ok := ret.Get(0).(func() []queries.StockLevel); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]queries.StockLevel)
+		}
+	}
+
+	return r0
+}
+
+// StockAlerts provides a mock function with no fields
+func (_m *StockQueries) StockAlerts() []queries.StockAlert {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for StockAlerts")
+	}
+
+	var r0 []queries.StockAlert
+	if rf, ok := ret.Get(0).(func() []queries.StockAlert); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]queries.StockAlert)
+		}
+	}
+
+	return r0
+}
+
+// StockLevels provides a mock function with no fields
+func (_m *StockQueries) StockLevels() []queries.StockLevel {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for StockLevels")
+	}
+
+	var r0 []queries.StockLevel
+	if rf, ok := ret.Get(0).(func() []queries.StockLevel); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]queries.StockLevel)
+		}
+	}
+
+	return r0
+}
+
+// NewStockQueries creates a new instance of StockQueries. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewStockQueries(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *StockQueries {
+	mock := &StockQueries{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
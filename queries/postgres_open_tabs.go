@@ -0,0 +1,386 @@
+package queries
+
+import (
+	"context"
+	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/shared"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/segmentio/ksuid"
+)
+
+const openTabsProjectionName = "open_tabs"
+
+type PersistentOpenTabQueries interface {
+	OpenTabQueries
+	CheckpointedProjection
+}
+
+type postgresOpenTabs struct {
+	conn *pgx.Conn
+	lock sync.Mutex
+}
+
+func (p *postgresOpenTabs) LastPosition(ctx context.Context) (int64, error) {
+	defer p.lock.Unlock()
+	p.lock.Lock()
+
+	var position int64
+	err := p.conn.QueryRow(ctx, "SELECT position FROM projection_checkpoint WHERE name = $1", openTabsProjectionName).Scan(&position)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	return position, err
+}
+
+// HandleRecordedEvent updates the tables and the checkpoint in one transaction, and skips events at or before the checkpoint.
+func (p *postgresOpenTabs) HandleRecordedEvent(ctx context.Context, recordedEvent events.RecordedEvent) (err error) {
+	defer p.lock.Unlock()
+	p.lock.Lock()
+
+	tx, err := p.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if err := tx.Rollback(ctx); err != nil {
+				slog.Error("error, rollback", slog.String("error", err.Error()))
+			}
+		}
+	}()
+
+	var position int64
+	err = tx.QueryRow(ctx, "SELECT position FROM projection_checkpoint WHERE name = $1 FOR UPDATE", openTabsProjectionName).Scan(&position)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	if recordedEvent.Position <= position {
+		return tx.Rollback(ctx)
+	}
+
+	switch event := recordedEvent.Event.(type) {
+	case events.TabOpened:
+		err = p.handleTabOpened(ctx, tx, event)
+	case events.DrinksOrdered:
+		err = p.insertItems(ctx, tx, "open_tab_to_serve", event.ID, event.Items)
+	case events.DrinksServed:
+		err = p.moveItems(ctx, tx, "open_tab_to_serve", "open_tab_served", event.ID, event.MenuNumbers)
+	case events.FoodOrdered:
+		err = p.insertItems(ctx, tx, "open_tab_in_preparation", event.ID, event.Items)
+	case events.FoodPrepared:
+		err = p.moveItems(ctx, tx, "open_tab_in_preparation", "open_tab_to_serve", event.ID, event.MenuNumbers)
+	case events.FoodServed:
+		err = p.moveItems(ctx, tx, "open_tab_to_serve", "open_tab_served", event.ID, event.MenuNumbers)
+	case events.ItemsCancelled:
+		err = p.cancelDrinks(ctx, tx, event.ID, event.MenuNumbers)
+	case events.ItemComped:
+		_, err = tx.Exec(ctx, "INSERT INTO open_tab_adjustment (tab_id, menu_number, description, amount, currency, reason, authorised_by) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+			event.ID.String(), event.MenuNumber, event.Description, event.Amount.Amount, event.Amount.Currency, event.Reason, event.AuthorisedBy)
+	case events.DiscountApplied:
+		_, err = tx.Exec(ctx, "INSERT INTO open_tab_adjustment (tab_id, percentage, amount, currency, reason, authorised_by) VALUES ($1, $2, $3, $4, $5, $6)",
+			event.ID.String(), event.Percentage, event.Amount.Amount, event.Amount.Currency, event.Reason, event.AuthorisedBy)
+	case events.PaymentRecorded:
+		_, err = tx.Exec(ctx, "INSERT INTO open_tab_payment (tab_id, method, amount, currency, payer) VALUES ($1, $2, $3, $4, $5)", event.ID.String(), event.Method, event.Amount.Amount, event.Amount.Currency, event.Payer)
+	case events.TabClosed:
+		err = p.handleTabClosed(ctx, tx, event)
+	case events.TableClaimed, events.TableReleased,
+		events.MenuItemAdded, events.MenuItemPriceChanged, events.MenuItemRenamed, events.MenuItemCategorised, events.MenuItemRecipeSet, events.MenuItemRetired,
+		events.StockRestocked, events.StockCounted, events.ReorderLevelSet, events.StockReserved, events.StockReleased, events.StockConsumed, events.StockRanLow,
+		events.IngredientRestocked, events.IngredientCounted, events.IngredientConsumed,
+		events.ShiftOpened, events.ShiftClosed,
+		events.UnknownEvent:
+	default:
+		err = fmt.Errorf("unexpected events.Event: %#v", recordedEvent.Event)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `INSERT INTO projection_checkpoint (name, position) VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET position = EXCLUDED.position`, openTabsProjectionName, recordedEvent.Position)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (p *postgresOpenTabs) handleTabOpened(ctx context.Context, tx pgx.Tx, e events.TabOpened) error {
+	_, err := tx.Exec(ctx, "INSERT INTO open_tab (tab_id, table_number, waiter) VALUES ($1, $2, $3)", e.ID.String(), e.TableNumber, e.Waiter)
+	return err
+}
+
+func (p *postgresOpenTabs) insertItems(ctx context.Context, tx pgx.Tx, table string, tabId ksuid.KSUID, items []shared.MenuItem) error {
+	for _, orderedItem := range items {
+		_, err := tx.Exec(ctx, fmt.Sprintf("INSERT INTO %s (tab_id, menu_number, description, price, currency, is_drink) VALUES ($1, $2, $3, $4, $5, $6)", table), tabId.String(), orderedItem.ID, orderedItem.Description, orderedItem.Price.Amount, orderedItem.Price.Currency, orderedItem.IsDrink)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cancelDrinks deletes the oldest matching drink still to serve for each menu number.
+func (p *postgresOpenTabs) cancelDrinks(ctx context.Context, tx pgx.Tx, tabId ksuid.KSUID, menuNumbers []int) error {
+	for _, menuNumber := range menuNumbers {
+		_, err := tx.Exec(ctx, `DELETE FROM open_tab_to_serve WHERE id = (
+				SELECT id FROM open_tab_to_serve WHERE tab_id = $1 AND menu_number = $2 AND is_drink ORDER BY id LIMIT 1
+			)`, tabId.String(), menuNumber)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveItems moves the oldest matching item for each menu number from one table to the other.
+func (p *postgresOpenTabs) moveItems(ctx context.Context, tx pgx.Tx, from string, to string, tabId ksuid.KSUID, menuNumbers []int) error {
+	for _, menuNumber := range menuNumbers {
+		_, err := tx.Exec(ctx, fmt.Sprintf(`WITH moved AS (
+				DELETE FROM %[1]s WHERE id = (
+					SELECT id FROM %[1]s WHERE tab_id = $1 AND menu_number = $2 ORDER BY id LIMIT 1
+				) RETURNING tab_id, menu_number, description, price, currency, is_drink
+			)
+			INSERT INTO %[2]s (tab_id, menu_number, description, price, currency, is_drink) SELECT tab_id, menu_number, description, price, currency, is_drink FROM moved`, from, to), tabId.String(), menuNumber)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *postgresOpenTabs) handleTabClosed(ctx context.Context, tx pgx.Tx, e events.TabClosed) error {
+	_, err := tx.Exec(ctx, "DELETE FROM open_tab WHERE tab_id = $1", e.ID.String())
+	return err
+}
+
+func (p *postgresOpenTabs) HandleEvent(e events.Event) error {
+	return fmt.Errorf("postgres open tabs need the event position, feed them through a Projector: %#v", e)
+}
+
+func (p *postgresOpenTabs) ActiveTableNumbers() []int {
+	defer p.lock.Unlock()
+	p.lock.Lock()
+
+	tableNumbers := []int{}
+	rows, err := p.conn.Query(context.Background(), "SELECT table_number FROM open_tab ORDER BY table_number")
+	if err != nil {
+		slog.Error("error reading active table numbers", slog.String("error", err.Error()))
+		return tableNumbers
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tableNumber int
+		if err := rows.Scan(&tableNumber); err != nil {
+			slog.Error("error reading active table numbers", slog.String("error", err.Error()))
+			return tableNumbers
+		}
+		tableNumbers = append(tableNumbers, tableNumber)
+	}
+	return tableNumbers
+}
+
+func (p *postgresOpenTabs) InvoiceForTable(table int) (TabInvoice, error) {
+	defer p.lock.Unlock()
+	p.lock.Lock()
+	ctx := context.Background()
+
+	tabId, err := p.tabIdForTable(ctx, table)
+	if err != nil {
+		return TabInvoice{}, err
+	}
+
+	served, err := p.readItems(ctx, "open_tab_served", tabId)
+	if err != nil {
+		return TabInvoice{}, err
+	}
+	toServe, err := p.readItems(ctx, "open_tab_to_serve", tabId)
+	if err != nil {
+		return TabInvoice{}, err
+	}
+	inPreparation, err := p.readItems(ctx, "open_tab_in_preparation", tabId)
+	if err != nil {
+		return TabInvoice{}, err
+	}
+
+	comps, discounts, err := p.readAdjustments(ctx, tabId)
+	if err != nil {
+		return TabInvoice{}, err
+	}
+
+	payments, err := p.readPayments(ctx, tabId)
+	if err != nil {
+		return TabInvoice{}, err
+	}
+
+	invoice := buildInvoice(served, comps, discounts, payments)
+	invoice.TabID = tabId.String()
+	invoice.TableNumber = table
+	invoice.HasUnservedItems = len(toServe) > 0 || len(inPreparation) > 0
+	return invoice, nil
+}
+
+func (p *postgresOpenTabs) readPayments(ctx context.Context, tabId ksuid.KSUID) ([]TabPayment, error) {
+	rows, err := p.conn.Query(ctx, "SELECT method, amount, currency, payer FROM open_tab_payment WHERE tab_id = $1 ORDER BY id", tabId.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := []TabPayment{}
+	for rows.Next() {
+		var payment TabPayment
+		if err := rows.Scan(&payment.Method, &payment.Amount.Amount, &payment.Amount.Currency, &payment.Payer); err != nil {
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
+	return payments, rows.Err()
+}
+
+// readAdjustments returns the comps, which are the adjustments for a menu number, and the discounts on the whole tab.
+func (p *postgresOpenTabs) readAdjustments(ctx context.Context, tabId ksuid.KSUID) ([]TabAdjustment, []TabAdjustment, error) {
+	rows, err := p.conn.Query(ctx, "SELECT menu_number, description, percentage, amount, currency, reason, authorised_by FROM open_tab_adjustment WHERE tab_id = $1 ORDER BY id", tabId.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	comps := []TabAdjustment{}
+	discounts := []TabAdjustment{}
+	for rows.Next() {
+		var menuNumber *int
+		var adjustment TabAdjustment
+		if err := rows.Scan(&menuNumber, &adjustment.Description, &adjustment.Percentage, &adjustment.Amount.Amount, &adjustment.Amount.Currency, &adjustment.Reason, &adjustment.AuthorisedBy); err != nil {
+			return nil, nil, err
+		}
+		if menuNumber != nil {
+			adjustment.MenuNumber = *menuNumber
+			comps = append(comps, adjustment)
+		} else {
+			discounts = append(discounts, adjustment)
+		}
+	}
+	return comps, discounts, rows.Err()
+}
+
+func (p *postgresOpenTabs) TabForTable(table int) (TabStatus, error) {
+	defer p.lock.Unlock()
+	p.lock.Lock()
+	ctx := context.Background()
+
+	tabId, err := p.tabIdForTable(ctx, table)
+	if err != nil {
+		return TabStatus{}, err
+	}
+
+	toServe, err := p.readItems(ctx, "open_tab_to_serve", tabId)
+	if err != nil {
+		return TabStatus{}, err
+	}
+	inPreparation, err := p.readItems(ctx, "open_tab_in_preparation", tabId)
+	if err != nil {
+		return TabStatus{}, err
+	}
+	served, err := p.readItems(ctx, "open_tab_served", tabId)
+	if err != nil {
+		return TabStatus{}, err
+	}
+
+	return TabStatus{
+		TabID:         tabId.String(),
+		TableNumber:   table,
+		ToServe:       toServe,
+		InPreparation: inPreparation,
+		Served:        served,
+	}, nil
+}
+
+func (p *postgresOpenTabs) TabIdForTable(table int) (ksuid.KSUID, error) {
+	defer p.lock.Unlock()
+	p.lock.Lock()
+
+	return p.tabIdForTable(context.Background(), table)
+}
+
+func (p *postgresOpenTabs) TodoListForWaiter(waiter string) map[int][]TabItem {
+	defer p.lock.Unlock()
+	p.lock.Lock()
+
+	todoListForWaiter := make(map[int][]TabItem)
+	rows, err := p.conn.Query(context.Background(), `SELECT t.table_number, s.menu_number, s.description, s.price, s.currency, s.is_drink
+		FROM open_tab t LEFT JOIN open_tab_to_serve s ON s.tab_id = t.tab_id
+		WHERE t.waiter = $1 ORDER BY t.table_number, s.id`, waiter)
+	if err != nil {
+		slog.Error("error reading todo list for waiter", slog.String("error", err.Error()))
+		return todoListForWaiter
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tableNumber int
+		var menuNumber *int
+		var description *string
+		var price *int64
+		var currency *string
+		var isDrink *bool
+		if err := rows.Scan(&tableNumber, &menuNumber, &description, &price, &currency, &isDrink); err != nil {
+			slog.Error("error reading todo list for waiter", slog.String("error", err.Error()))
+			return todoListForWaiter
+		}
+		if _, ok := todoListForWaiter[tableNumber]; !ok {
+			todoListForWaiter[tableNumber] = []TabItem{}
+		}
+		if menuNumber != nil {
+			todoListForWaiter[tableNumber] = append(todoListForWaiter[tableNumber], TabItem{MenuNumber: *menuNumber, Description: *description, Price: shared.NewMoney(*price, *currency), IsDrink: *isDrink})
+		}
+	}
+	return todoListForWaiter
+}
+
+func (p *postgresOpenTabs) tabIdForTable(ctx context.Context, table int) (ksuid.KSUID, error) {
+	var tabId string
+	err := p.conn.QueryRow(ctx, "SELECT tab_id FROM open_tab WHERE table_number = $1 LIMIT 1", table).Scan(&tabId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ksuid.KSUID{}, fmt.Errorf("couldn't find a tab for table: %d", table)
+	}
+	if err != nil {
+		return ksuid.KSUID{}, err
+	}
+	return ksuid.Parse(tabId)
+}
+
+func (p *postgresOpenTabs) readItems(ctx context.Context, table string, tabId ksuid.KSUID) ([]TabItem, error) {
+	rows, err := p.conn.Query(ctx, fmt.Sprintf("SELECT menu_number, description, price, currency, is_drink FROM %s WHERE tab_id = $1 ORDER BY id", table), tabId.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []TabItem{}
+	for rows.Next() {
+		var item TabItem
+		if err := rows.Scan(&item.MenuNumber, &item.Description, &item.Price.Amount, &item.Price.Currency, &item.IsDrink); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func NewPostgresOpenTabs(ctx context.Context, connStr string) (PersistentOpenTabQueries, error) {
+	conn, err := pgx.Connect(ctx, connStr)
+	if err != nil {
+		slog.Error("unable to connect to database", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return &postgresOpenTabs{
+		conn: conn,
+	}, nil
+}
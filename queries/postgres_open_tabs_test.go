@@ -0,0 +1,138 @@
+package queries_test
+
+import (
+	"context"
+	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/queries"
+	"cqrseventsourcingbar/shared"
+	"cqrseventsourcingbar/testhelpers"
+	"log"
+	"testing"
+
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type PostgresOpenTabsTestSuite struct {
+	suite.Suite
+	pgContainer    *testhelpers.PostgresContainer
+	openTabQueries queries.PersistentOpenTabQueries
+	ctx            context.Context
+}
+
+func (suite *PostgresOpenTabsTestSuite) SetupSuite() {
+	suite.ctx = context.Background()
+	pgContainer, err := testhelpers.CreatePostgresContainer(suite.T(), suite.ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	suite.pgContainer = pgContainer
+	openTabQueries, err := queries.NewPostgresOpenTabs(suite.ctx, suite.pgContainer.ConnectionString)
+	if err != nil {
+		log.Fatal(err)
+	}
+	suite.openTabQueries = openTabQueries
+}
+
+func (suite *PostgresOpenTabsTestSuite) TearDownSuite() {
+	if err := suite.pgContainer.Terminate(suite.ctx); err != nil {
+		log.Fatalf("error terminating postgres container: %s", err)
+	}
+}
+
+func (suite *PostgresOpenTabsTestSuite) TestTabLifecycleAndCheckpoint() {
+	t := suite.T()
+	// Given
+	tabId := ksuid.New()
+	recordedEvents := []events.RecordedEvent{
+		{Position: 1, SequenceNumber: 1, Event: events.TabOpened{BaseEvent: events.BaseEvent{ID: tabId}, TableNumber: 4, Waiter: "Charles"}},
+		{Position: 2, SequenceNumber: 2, Event: events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: tabId}, Items: []shared.MenuItem{
+			{ID: 10, Description: "Water", Price: shared.Cents(100)},
+			{ID: 11, Description: "Beer", Price: shared.Cents(200)},
+		}}},
+		{Position: 3, SequenceNumber: 3, Event: events.DrinksServed{BaseEvent: events.BaseEvent{ID: tabId}, MenuNumbers: []int{11}}},
+	}
+
+	// When
+	for _, recordedEvent := range recordedEvents {
+		assert.NoError(t, suite.openTabQueries.HandleRecordedEvent(suite.ctx, recordedEvent))
+	}
+	// a redelivered event is skipped
+	assert.NoError(t, suite.openTabQueries.HandleRecordedEvent(suite.ctx, recordedEvents[2]))
+
+	// Then
+	position, err := suite.openTabQueries.LastPosition(suite.ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), position)
+	assert.Equal(t, []int{4}, suite.openTabQueries.ActiveTableNumbers())
+
+	tabIdForTable, err := suite.openTabQueries.TabIdForTable(4)
+	assert.NoError(t, err)
+	assert.Equal(t, tabId, tabIdForTable)
+
+	tabStatus, err := suite.openTabQueries.TabForTable(4)
+	assert.NoError(t, err)
+	assert.Equal(t, []queries.TabItem{{MenuNumber: 10, Description: "Water", Price: shared.Cents(100)}}, tabStatus.ToServe)
+	assert.Equal(t, []queries.TabItem{{MenuNumber: 11, Description: "Beer", Price: shared.Cents(200)}}, tabStatus.Served)
+
+	invoice, err := suite.openTabQueries.InvoiceForTable(4)
+	assert.NoError(t, err)
+	assert.Equal(t, 2.0, invoice.Total)
+	assert.True(t, invoice.HasUnservedItems)
+
+	assert.Equal(t, map[int][]queries.TabItem{4: {{MenuNumber: 10, Description: "Water", Price: shared.Cents(100)}}}, suite.openTabQueries.TodoListForWaiter("Charles"))
+
+	// When
+	burger := shared.MenuItem{ID: 21, Description: "Burger", Price: shared.Cents(800)}
+	for _, recordedEvent := range []events.RecordedEvent{
+		{Position: 4, SequenceNumber: 4, Event: events.FoodOrdered{BaseEvent: events.BaseEvent{ID: tabId}, Items: []shared.MenuItem{burger, burger}}},
+		{Position: 5, SequenceNumber: 5, Event: events.FoodPrepared{BaseEvent: events.BaseEvent{ID: tabId}, MenuNumbers: []int{21}}},
+		{Position: 6, SequenceNumber: 6, Event: events.FoodServed{BaseEvent: events.BaseEvent{ID: tabId}, MenuNumbers: []int{21}}},
+	} {
+		assert.NoError(t, suite.openTabQueries.HandleRecordedEvent(suite.ctx, recordedEvent))
+	}
+
+	// Then
+	tabStatus, err = suite.openTabQueries.TabForTable(4)
+	assert.NoError(t, err)
+	assert.Equal(t, []queries.TabItem{{MenuNumber: 21, Description: "Burger", Price: shared.Cents(800)}}, tabStatus.InPreparation)
+	assert.Equal(t, []queries.TabItem{{MenuNumber: 11, Description: "Beer", Price: shared.Cents(200)}, {MenuNumber: 21, Description: "Burger", Price: shared.Cents(800)}}, tabStatus.Served)
+
+	invoice, err = suite.openTabQueries.InvoiceForTable(4)
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, invoice.Total)
+
+	// When
+	assert.NoError(t, suite.openTabQueries.HandleRecordedEvent(suite.ctx, events.RecordedEvent{Position: 7, SequenceNumber: 7, Event: events.ItemComped{BaseEvent: events.BaseEvent{ID: tabId}, MenuNumber: 11, Description: "Beer", Amount: shared.Cents(200), Reason: "spilled", AuthorisedBy: "manager"}}))
+	assert.NoError(t, suite.openTabQueries.HandleRecordedEvent(suite.ctx, events.RecordedEvent{Position: 8, SequenceNumber: 8, Event: events.DiscountApplied{BaseEvent: events.BaseEvent{ID: tabId}, Percentage: 10, Reason: "regular", AuthorisedBy: "manager"}}))
+
+	// Then
+	invoice, err = suite.openTabQueries.InvoiceForTable(4)
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, invoice.Subtotal)
+	assert.Equal(t, []queries.TabAdjustment{{MenuNumber: 11, Description: "Beer", Amount: shared.Cents(200), Reason: "spilled", AuthorisedBy: "manager"}}, invoice.LineAdjustments)
+	assert.Equal(t, []queries.TabAdjustment{{Percentage: 10, Amount: shared.Cents(80), Reason: "regular", AuthorisedBy: "manager"}}, invoice.TabAdjustments)
+	assert.Equal(t, 7.2, invoice.Total)
+
+	// When
+	assert.NoError(t, suite.openTabQueries.HandleRecordedEvent(suite.ctx, events.RecordedEvent{Position: 9, SequenceNumber: 9, Event: events.PaymentRecorded{BaseEvent: events.BaseEvent{ID: tabId}, Method: "card", Amount: shared.Cents(500), Payer: "Alice"}}))
+
+	// Then
+	invoice, err = suite.openTabQueries.InvoiceForTable(4)
+	assert.NoError(t, err)
+	assert.Equal(t, []queries.TabPayment{{Method: "card", Amount: shared.Cents(500), Payer: "Alice"}}, invoice.Payments)
+	assert.Equal(t, 2.2, invoice.BalanceDue)
+
+	// When
+	assert.NoError(t, suite.openTabQueries.HandleRecordedEvent(suite.ctx, events.RecordedEvent{Position: 10, SequenceNumber: 10, Event: events.TabClosed{BaseEvent: events.BaseEvent{ID: tabId}}}))
+
+	// Then
+	assert.Empty(t, suite.openTabQueries.ActiveTableNumbers())
+	_, err = suite.openTabQueries.TabIdForTable(4)
+	assert.Equal(t, "couldn't find a tab for table: 4", err.Error())
+}
+
+func TestPostgresOpenTabsTestSuite(t *testing.T) {
+	suite.Run(t, new(PostgresOpenTabsTestSuite))
+}
@@ -0,0 +1,67 @@
+package queries
+
+import (
+	"context"
+	"cqrseventsourcingbar/events"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+//go:generate mockery --name CheckpointedProjection
+type CheckpointedProjection interface {
+	LastPosition(ctx context.Context) (int64, error)
+	HandleRecordedEvent(ctx context.Context, recordedEvent events.RecordedEvent) error
+}
+
+// Projector feeds a CheckpointedProjection from the global event log, starting after the position it last stored.
+type Projector struct {
+	eventStore events.EventStore
+	projection CheckpointedProjection
+	batchSize  int
+	lock       sync.Mutex
+}
+
+func CreateProjector(eventStore events.EventStore, projection CheckpointedProjection, batchSize int) *Projector {
+	return &Projector{
+		eventStore: eventStore,
+		projection: projection,
+		batchSize:  batchSize,
+	}
+}
+
+func (p *Projector) CatchUp(ctx context.Context) error {
+	defer p.lock.Unlock()
+	p.lock.Lock()
+
+	position, err := p.projection.LastPosition(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = events.ForEachEventFrom(ctx, p.eventStore, position, p.batchSize, func(recordedEvent events.RecordedEvent) error {
+		return p.projection.HandleRecordedEvent(ctx, recordedEvent)
+	})
+	return err
+}
+
+// HandleEvent treats a live event as a signal that the log has grown; the event itself is read back from the store with its position.
+func (p *Projector) HandleEvent(_ events.Event) error {
+	return p.CatchUp(context.Background())
+}
+
+// Run also catches up periodically, so a missed live event only delays the projection.
+func (p *Projector) Run(ctx context.Context, pollInterval time.Duration) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.CatchUp(ctx); err != nil {
+				slog.Error("error catching up projection", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
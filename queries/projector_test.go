@@ -0,0 +1,77 @@
+package queries_test
+
+import (
+	"context"
+	"cqrseventsourcingbar/events"
+	mock_events "cqrseventsourcingbar/events/mocks"
+	"cqrseventsourcingbar/queries"
+	mock_queries "cqrseventsourcingbar/queries/mocks"
+	"errors"
+	"testing"
+
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ProjectorTestSuite struct {
+	suite.Suite
+	eventStore *mock_events.EventStore
+	projection *mock_queries.CheckpointedProjection
+	projector  *queries.Projector
+	ctx        context.Context
+}
+
+func (suite *ProjectorTestSuite) SetupTest() {
+	suite.eventStore = mock_events.NewEventStore(suite.T())
+	suite.projection = mock_queries.NewCheckpointedProjection(suite.T())
+	suite.projector = queries.CreateProjector(suite.eventStore, suite.projection, 10)
+	suite.ctx = context.Background()
+}
+
+func (suite *ProjectorTestSuite) TestCatchUpStartsAfterTheCheckpoint() {
+	// Given
+	tabId := ksuid.New()
+	recordedEvent := events.RecordedEvent{Position: 8, SequenceNumber: 1, Event: events.TabOpened{BaseEvent: events.BaseEvent{ID: tabId}, TableNumber: 1, Waiter: "w1"}}
+	suite.projection.On("LastPosition", suite.ctx).Return(int64(7), nil)
+	suite.eventStore.On("LoadEventsFrom", suite.ctx, int64(7), 10).Return([]events.RecordedEvent{recordedEvent}, nil)
+	suite.projection.On("HandleRecordedEvent", suite.ctx, recordedEvent).Return(nil)
+
+	// When
+	err := suite.projector.CatchUp(suite.ctx)
+
+	// Then
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *ProjectorTestSuite) TestCatchUpReturnsProjectionErrors() {
+	// Given
+	suite.projection.On("LastPosition", suite.ctx).Return(int64(0), nil)
+	suite.eventStore.On("LoadEventsFrom", suite.ctx, int64(0), 10).Return([]events.RecordedEvent{{Position: 1, SequenceNumber: 1, Event: events.BaseEvent{ID: ksuid.New()}}}, nil)
+	suite.projection.On("HandleRecordedEvent", suite.ctx, mock.Anything).Return(errors.New("all broken"))
+
+	// When
+	err := suite.projector.CatchUp(suite.ctx)
+
+	// Then
+	if assert.Error(suite.T(), err) {
+		assert.Equal(suite.T(), "all broken", err.Error())
+	}
+}
+
+func (suite *ProjectorTestSuite) TestLiveEventTriggersCatchUp() {
+	// Given
+	suite.projection.On("LastPosition", mock.Anything).Return(int64(3), nil)
+	suite.eventStore.On("LoadEventsFrom", mock.Anything, int64(3), 10).Return(nil, nil)
+
+	// When
+	err := suite.projector.HandleEvent(events.BaseEvent{ID: ksuid.New()})
+
+	// Then
+	assert.NoError(suite.T(), err)
+}
+
+func TestProjectorTestSuite(t *testing.T) {
+	suite.Run(t, new(ProjectorTestSuite))
+}
@@ -2,6 +2,7 @@ package queries
 
 import (
 	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/shared"
 	"errors"
 	"fmt"
 	"slices"
@@ -22,19 +23,19 @@ type OpenTabQueries interface {
 }
 
 func (o *openTabs) handleTabOpened(e events.TabOpened) error {
-	defer o.lock.Unlock()
-	o.lock.Lock()
 	o.todoByTab[e.ID] = &Tab{
-		TableNumber: e.TableNumber,
-		Waiter:      e.Waiter,
-		ToServe:     []TabItem{},
-		Served:      []TabItem{},
+		TableNumber:   e.TableNumber,
+		Waiter:        e.Waiter,
+		ToServe:       []TabItem{},
+		InPreparation: []TabItem{},
+		Served:        []TabItem{},
+		Comps:         []TabAdjustment{},
+		Discounts:     []TabAdjustment{},
+		Payments:      []TabPayment{},
 	}
 	return nil
 }
 func (o *openTabs) handleDrinksOrdered(e events.DrinksOrdered) error {
-	defer o.lock.Unlock()
-	o.lock.Lock()
 	tab := o.todoByTab[e.ID]
 	addToServe := []TabItem{}
 	for _, orderedItem := range e.Items {
@@ -42,6 +43,7 @@ func (o *openTabs) handleDrinksOrdered(e events.DrinksOrdered) error {
 			MenuNumber:  orderedItem.ID,
 			Description: orderedItem.Description,
 			Price:       orderedItem.Price,
+			IsDrink:     orderedItem.IsDrink,
 		}
 		addToServe = append(addToServe, tabItem)
 	}
@@ -49,8 +51,6 @@ func (o *openTabs) handleDrinksOrdered(e events.DrinksOrdered) error {
 	return nil
 }
 func (o *openTabs) handleDrinksServed(e events.DrinksServed) error {
-	defer o.lock.Unlock()
-	o.lock.Lock()
 	tab := o.todoByTab[e.ID]
 	for _, menuNumber := range e.MenuNumbers {
 		foundElemWithMenuNumber := funk.Find(tab.ToServe, func(tabItem TabItem) bool {
@@ -70,16 +70,93 @@ func (o *openTabs) handleDrinksServed(e events.DrinksServed) error {
 	}
 	return nil
 }
+func (o *openTabs) handleFoodOrdered(e events.FoodOrdered) error {
+	tab := o.todoByTab[e.ID]
+	for _, orderedItem := range e.Items {
+		tab.InPreparation = append(tab.InPreparation, TabItem{
+			MenuNumber:  orderedItem.ID,
+			Description: orderedItem.Description,
+			Price:       orderedItem.Price,
+			IsDrink:     orderedItem.IsDrink,
+		})
+	}
+	return nil
+}
+
+func (o *openTabs) handleFoodPrepared(e events.FoodPrepared) error {
+	tab := o.todoByTab[e.ID]
+	for _, menuNumber := range e.MenuNumbers {
+		tab.InPreparation, tab.ToServe = moveFirstMatch(tab.InPreparation, tab.ToServe, menuNumber)
+	}
+	return nil
+}
+
+func (o *openTabs) handleFoodServed(e events.FoodServed) error {
+	tab := o.todoByTab[e.ID]
+	for _, menuNumber := range e.MenuNumbers {
+		tab.ToServe, tab.Served = moveFirstMatch(tab.ToServe, tab.Served, menuNumber)
+	}
+	return nil
+}
+
+func (o *openTabs) handleItemsCancelled(e events.ItemsCancelled) error {
+	tab := o.todoByTab[e.ID]
+	for _, menuNumber := range e.MenuNumbers {
+		index := slices.IndexFunc(tab.ToServe, func(tabItem TabItem) bool { return tabItem.MenuNumber == menuNumber && tabItem.IsDrink })
+		if index >= 0 {
+			tab.ToServe = slices.Delete(tab.ToServe, index, index+1)
+		}
+	}
+	return nil
+}
+
+func (o *openTabs) handleItemComped(e events.ItemComped) error {
+	tab := o.todoByTab[e.ID]
+	tab.Comps = append(tab.Comps, TabAdjustment{
+		MenuNumber:   e.MenuNumber,
+		Description:  e.Description,
+		Amount:       e.Amount,
+		Reason:       e.Reason,
+		AuthorisedBy: e.AuthorisedBy,
+	})
+	return nil
+}
+
+func (o *openTabs) handleDiscountApplied(e events.DiscountApplied) error {
+	tab := o.todoByTab[e.ID]
+	tab.Discounts = append(tab.Discounts, TabAdjustment{
+		Percentage:   e.Percentage,
+		Amount:       e.Amount,
+		Reason:       e.Reason,
+		AuthorisedBy: e.AuthorisedBy,
+	})
+	return nil
+}
+
+func (o *openTabs) handlePaymentRecorded(e events.PaymentRecorded) error {
+	tab := o.todoByTab[e.ID]
+	tab.Payments = append(tab.Payments, TabPayment{Method: e.Method, Amount: e.Amount, Payer: e.Payer})
+	return nil
+}
+
+func moveFirstMatch(from []TabItem, to []TabItem, menuNumber int) ([]TabItem, []TabItem) {
+	index := slices.IndexFunc(from, func(tabItem TabItem) bool { return tabItem.MenuNumber == menuNumber })
+	if index < 0 {
+		return from, to
+	}
+	moved := from[index]
+	return slices.Delete(from, index, index+1), append(to, moved)
+}
+
 func (o *openTabs) handleTabClosed(e events.TabClosed) error {
-	defer o.lock.Unlock()
-	o.lock.Lock()
 	delete(o.todoByTab, e.ID)
 	return nil
 }
 
 type openTabs struct {
-	todoByTab map[ksuid.KSUID]*Tab
-	lock      sync.RWMutex
+	todoByTab               map[ksuid.KSUID]*Tab
+	lastSequenceNumberByTab sequenceTracker
+	lock                    sync.RWMutex
 }
 
 func (o *openTabs) ActiveTableNumbers() []int {
@@ -105,18 +182,11 @@ func (o *openTabs) InvoiceForTable(table int) (TabInvoice, error) {
 
 	tab := o.todoByTab[tabId]
 
-	mapped := funk.Map(tab.Served, func(t TabItem) float64 {
-		return t.Price
-	})
-	total := funk.SumFloat64(mapped.([]float64))
-
-	return TabInvoice{
-		TabID:            tabId.String(),
-		TableNumber:      table,
-		Items:            slices.Clone(tab.Served),
-		Total:            total,
-		HasUnservedItems: len(tab.ToServe) > 0,
-	}, nil
+	invoice := buildInvoice(slices.Clone(tab.Served), slices.Clone(tab.Comps), slices.Clone(tab.Discounts), slices.Clone(tab.Payments))
+	invoice.TabID = tabId.String()
+	invoice.TableNumber = table
+	invoice.HasUnservedItems = len(tab.ToServe) > 0 || len(tab.InPreparation) > 0
+	return invoice, nil
 
 }
 
@@ -133,10 +203,11 @@ func (o *openTabs) TabForTable(table int) (TabStatus, error) {
 	tab := o.todoByTab[tabId]
 
 	return TabStatus{
-		TabID:       tabId.String(),
-		TableNumber: table,
-		ToServe:     slices.Clone(tab.ToServe),
-		Served:      slices.Clone(tab.Served),
+		TabID:         tabId.String(),
+		TableNumber:   table,
+		ToServe:       slices.Clone(tab.ToServe),
+		InPreparation: slices.Clone(tab.InPreparation),
+		Served:        slices.Clone(tab.Served),
 	}, nil
 
 }
@@ -157,6 +228,8 @@ func (o *openTabs) TabIdForTable(table int) (ksuid.KSUID, error) {
 }
 
 func (o *openTabs) TodoListForWaiter(waiter string) map[int][]TabItem {
+	defer o.lock.RUnlock()
+	o.lock.RLock()
 	todoListForWaiter := make(map[int][]TabItem)
 
 	for _, v := range o.todoByTab {
@@ -171,7 +244,26 @@ func (o *openTabs) TodoListForWaiter(waiter string) map[int][]TabItem {
 	return todoListForWaiter
 }
 
+// HandleEvent skips events already applied and refuses to apply an event past a gap in the tab's sequence numbers.
+// Events without a sequence number are applied as they come.
 func (o *openTabs) HandleEvent(e events.Event) error {
+	defer o.lock.Unlock()
+	o.lock.Lock()
+
+	apply, err := o.lastSequenceNumberByTab.shouldApply(e)
+	if !apply {
+		return err
+	}
+
+	err = o.applyEvent(e)
+	if err != nil {
+		return err
+	}
+	o.lastSequenceNumberByTab.applied(e)
+	return nil
+}
+
+func (o *openTabs) applyEvent(e events.Event) error {
 	switch event := e.(type) {
 	case events.TabOpened:
 		return o.handleTabOpened(event)
@@ -179,8 +271,29 @@ func (o *openTabs) HandleEvent(e events.Event) error {
 		return o.handleDrinksOrdered(event)
 	case events.DrinksServed:
 		return o.handleDrinksServed(event)
+	case events.FoodOrdered:
+		return o.handleFoodOrdered(event)
+	case events.FoodPrepared:
+		return o.handleFoodPrepared(event)
+	case events.ItemsCancelled:
+		return o.handleItemsCancelled(event)
+	case events.ItemComped:
+		return o.handleItemComped(event)
+	case events.DiscountApplied:
+		return o.handleDiscountApplied(event)
+	case events.PaymentRecorded:
+		return o.handlePaymentRecorded(event)
+	case events.FoodServed:
+		return o.handleFoodServed(event)
 	case events.TabClosed:
 		return o.handleTabClosed(event)
+	case events.TableClaimed, events.TableReleased,
+		events.MenuItemAdded, events.MenuItemPriceChanged, events.MenuItemRenamed, events.MenuItemCategorised, events.MenuItemRecipeSet, events.MenuItemRetired,
+		events.StockRestocked, events.StockCounted, events.ReorderLevelSet, events.StockReserved, events.StockReleased, events.StockConsumed, events.StockRanLow,
+		events.IngredientRestocked, events.IngredientCounted, events.IngredientConsumed,
+		events.ShiftOpened, events.ShiftClosed,
+		events.UnknownEvent:
+		return nil
 	default:
 		return fmt.Errorf("unexpected events.Event: %#v", e)
 	}
@@ -188,35 +301,64 @@ func (o *openTabs) HandleEvent(e events.Event) error {
 
 func CreateOpenTabs() OpenTabQueries {
 	return &openTabs{
-		todoByTab: make(map[ksuid.KSUID]*Tab),
-		lock:      sync.RWMutex{},
+		todoByTab:               make(map[ksuid.KSUID]*Tab),
+		lastSequenceNumberByTab: make(sequenceTracker),
+		lock:                    sync.RWMutex{},
 	}
 }
 
 type TabInvoice struct {
-	TabID            string    `json:"tab_id"`
-	TableNumber      int       `json:"table_number"`
-	Items            []TabItem `json:"items"`
-	Total            float64   `json:"total"`
-	HasUnservedItems bool      `json:"has_unserved_items"`
+	TabID            string          `json:"tab_id"`
+	TableNumber      int             `json:"table_number"`
+	Items            []TabItem       `json:"items"`
+	Subtotal         shared.Money    `json:"subtotal"`
+	LineAdjustments  []TabAdjustment `json:"line_adjustments"`
+	TabAdjustments   []TabAdjustment `json:"tab_adjustments"`
+	Total            shared.Money    `json:"total"`
+	Payments         []TabPayment    `json:"payments"`
+	BalanceDue       shared.Money    `json:"balance_due"`
+	HasUnservedItems bool            `json:"has_unserved_items"`
+}
+
+type TabPayment struct {
+	Method string       `json:"method"`
+	Amount shared.Money `json:"amount"`
+	Payer  string       `json:"payer"`
+}
+
+// TabAdjustment is either a comped item, on the invoice lines, or a discount on the whole tab.
+// The Amount of a percentage discount is what the percentage takes off the tab.
+type TabAdjustment struct {
+	MenuNumber   int          `json:"menu_number,omitempty"`
+	Description  string       `json:"description,omitempty"`
+	Percentage   float64      `json:"percentage,omitempty"`
+	Amount       shared.Money `json:"amount"`
+	Reason       string       `json:"reason"`
+	AuthorisedBy string       `json:"authorised_by"`
 }
 
 type TabStatus struct {
-	TabID       string    `json:"tab_id"`
-	TableNumber int       `json:"table_number"`
-	ToServe     []TabItem `json:"to_serve"`
-	Served      []TabItem `json:"served"`
+	TabID         string    `json:"tab_id"`
+	TableNumber   int       `json:"table_number"`
+	ToServe       []TabItem `json:"to_serve"`
+	InPreparation []TabItem `json:"in_preparation"`
+	Served        []TabItem `json:"served"`
 }
 
 type TabItem struct {
-	MenuNumber  int     `json:"menu_number"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
+	MenuNumber  int          `json:"menu_number"`
+	Description string       `json:"description"`
+	Price       shared.Money `json:"price"`
+	IsDrink     bool         `json:"is_drink"`
 }
 
 type Tab struct {
-	TableNumber int       `json:"table_number"`
-	Waiter      string    `json:"waiter"`
-	ToServe     []TabItem `json:"to_serve"`
-	Served      []TabItem `json:"served"`
+	TableNumber   int             `json:"table_number"`
+	Waiter        string          `json:"waiter"`
+	ToServe       []TabItem       `json:"to_serve"`
+	InPreparation []TabItem       `json:"in_preparation"`
+	Served        []TabItem       `json:"served"`
+	Comps         []TabAdjustment `json:"comps"`
+	Discounts     []TabAdjustment `json:"discounts"`
+	Payments      []TabPayment    `json:"payments"`
 }
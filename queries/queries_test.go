@@ -57,7 +57,7 @@ func (suite *QueriesTestSuite) TestAnOpenTab() {
 	assert.Equal(suite.T(), false, invoice.HasUnservedItems)
 	assert.Equal(suite.T(), tabId.String(), invoice.TabID)
 	assert.Equal(suite.T(), 1, invoice.TableNumber)
-	assert.Equal(suite.T(), 0.0, invoice.Total)
+	assert.Equal(suite.T(), shared.Cents(0), invoice.Total)
 	assert.Empty(suite.T(), invoice.Items)
 
 	tabForTable, err := suite.openTabQueries.TabForTable(1)
@@ -89,7 +89,7 @@ func (suite *QueriesTestSuite) TestAnOpenTabWithOneOrder() {
 		Items: []shared.MenuItem{{
 			ID:          10,
 			Description: "Water",
-			Price:       1,
+			Price:       shared.Cents(100),
 		}},
 	})
 
@@ -103,21 +103,21 @@ func (suite *QueriesTestSuite) TestAnOpenTabWithOneOrder() {
 	assert.Equal(suite.T(), true, invoice.HasUnservedItems)
 	assert.Equal(suite.T(), tabId.String(), invoice.TabID)
 	assert.Equal(suite.T(), 1, invoice.TableNumber)
-	assert.Equal(suite.T(), 0.0, invoice.Total)
+	assert.Equal(suite.T(), shared.Cents(0), invoice.Total)
 	assert.Empty(suite.T(), invoice.Items)
 
 	tabForTable, err := suite.openTabQueries.TabForTable(1)
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), tabId.String(), tabForTable.TabID)
 	assert.Equal(suite.T(), 1, tabForTable.TableNumber)
-	assert.Equal(suite.T(), []queries.TabItem{{MenuNumber: 10, Description: "Water", Price: 1}}, tabForTable.ToServe)
+	assert.Equal(suite.T(), []queries.TabItem{{MenuNumber: 10, Description: "Water", Price: shared.Cents(100)}}, tabForTable.ToServe)
 
 	tabIdForTable1, err := suite.openTabQueries.TabIdForTable(1)
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), tabId, tabIdForTable1)
 
 	todoListForWaiter := suite.openTabQueries.TodoListForWaiter("Charles")
-	assert.Equal(suite.T(), map[int][]queries.TabItem{1: {{MenuNumber: 10, Description: "Water", Price: 1}}}, todoListForWaiter)
+	assert.Equal(suite.T(), map[int][]queries.TabItem{1: {{MenuNumber: 10, Description: "Water", Price: shared.Cents(100)}}}, todoListForWaiter)
 }
 
 func (suite *QueriesTestSuite) TestAnOpenTabWithTwoOrdersOnlyOneServed() {
@@ -135,7 +135,7 @@ func (suite *QueriesTestSuite) TestAnOpenTabWithTwoOrdersOnlyOneServed() {
 		Items: []shared.MenuItem{{
 			ID:          10,
 			Description: "Water",
-			Price:       1,
+			Price:       shared.Cents(100),
 		}},
 	})
 
@@ -146,7 +146,7 @@ func (suite *QueriesTestSuite) TestAnOpenTabWithTwoOrdersOnlyOneServed() {
 		Items: []shared.MenuItem{{
 			ID:          11,
 			Description: "Beer",
-			Price:       2,
+			Price:       shared.Cents(200),
 		}},
 	})
 
@@ -167,21 +167,21 @@ func (suite *QueriesTestSuite) TestAnOpenTabWithTwoOrdersOnlyOneServed() {
 	assert.Equal(suite.T(), true, invoice.HasUnservedItems)
 	assert.Equal(suite.T(), tabId.String(), invoice.TabID)
 	assert.Equal(suite.T(), 1, invoice.TableNumber)
-	assert.Equal(suite.T(), 1.0, invoice.Total)
-	assert.Equal(suite.T(), []queries.TabItem{{MenuNumber: 10, Description: "Water", Price: 1}}, invoice.Items)
+	assert.Equal(suite.T(), shared.Cents(100), invoice.Total)
+	assert.Equal(suite.T(), []queries.TabItem{{MenuNumber: 10, Description: "Water", Price: shared.Cents(100)}}, invoice.Items)
 
 	tabForTable, err := suite.openTabQueries.TabForTable(1)
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), tabId.String(), tabForTable.TabID)
 	assert.Equal(suite.T(), 1, tabForTable.TableNumber)
-	assert.Equal(suite.T(), []queries.TabItem{{MenuNumber: 11, Description: "Beer", Price: 2}}, tabForTable.ToServe)
+	assert.Equal(suite.T(), []queries.TabItem{{MenuNumber: 11, Description: "Beer", Price: shared.Cents(200)}}, tabForTable.ToServe)
 
 	tabIdForTable1, err := suite.openTabQueries.TabIdForTable(1)
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), tabId, tabIdForTable1)
 
 	todoListForWaiter := suite.openTabQueries.TodoListForWaiter("Charles")
-	assert.Equal(suite.T(), map[int][]queries.TabItem{1: {{MenuNumber: 11, Description: "Beer", Price: 2}}}, todoListForWaiter)
+	assert.Equal(suite.T(), map[int][]queries.TabItem{1: {{MenuNumber: 11, Description: "Beer", Price: shared.Cents(200)}}}, todoListForWaiter)
 }
 
 func (suite *QueriesTestSuite) TestAnOpenTabWithTwoOrdersBothServed() {
@@ -198,7 +198,7 @@ func (suite *QueriesTestSuite) TestAnOpenTabWithTwoOrdersBothServed() {
 		Items: []shared.MenuItem{{
 			ID:          10,
 			Description: "Water",
-			Price:       1,
+			Price:       shared.Cents(100),
 		}},
 	})
 	assert.NoError(suite.T(), err)
@@ -208,7 +208,7 @@ func (suite *QueriesTestSuite) TestAnOpenTabWithTwoOrdersBothServed() {
 		Items: []shared.MenuItem{{
 			ID:          11,
 			Description: "Beer",
-			Price:       2,
+			Price:       shared.Cents(200),
 		}},
 	})
 	assert.NoError(suite.T(), err)
@@ -232,11 +232,11 @@ func (suite *QueriesTestSuite) TestAnOpenTabWithTwoOrdersBothServed() {
 	assert.Equal(suite.T(), false, invoice.HasUnservedItems)
 	assert.Equal(suite.T(), tabId.String(), invoice.TabID)
 	assert.Equal(suite.T(), 1, invoice.TableNumber)
-	assert.Equal(suite.T(), 3.0, invoice.Total)
-	assert.Equal(suite.T(), []queries.TabItem{{MenuNumber: 10, Description: "Water", Price: 1}, {
+	assert.Equal(suite.T(), shared.Cents(300), invoice.Total)
+	assert.Equal(suite.T(), []queries.TabItem{{MenuNumber: 10, Description: "Water", Price: shared.Cents(100)}, {
 		MenuNumber:  11,
 		Description: "Beer",
-		Price:       2,
+		Price:       shared.Cents(200),
 	}}, invoice.Items)
 
 	tabForTable, err := suite.openTabQueries.TabForTable(1)
@@ -264,9 +264,9 @@ func (suite *QueriesTestSuite) TestAfterCloseThereIsNoData() {
 
 	err = suite.openTabQueries.HandleEvent(events.TabClosed{
 		BaseEvent:   events.BaseEvent{ID: tabId},
-		AmountPaid:  0.0,
-		OrderAmount: 0.0,
-		Tip:         0.0,
+		AmountPaid:  shared.Cents(0),
+		OrderAmount: shared.Cents(0),
+		Tip:         shared.Cents(0),
 	})
 	assert.NoError(suite.T(), err)
 
@@ -289,6 +289,200 @@ func (suite *QueriesTestSuite) TestAfterCloseThereIsNoData() {
 	assert.Empty(suite.T(), todoListForWaiter)
 }
 
+func (suite *QueriesTestSuite) TestRedeliveredEventsAreAppliedOnce() {
+	// Given
+	tabId := ksuid.New()
+	tabOpened := events.TabOpened{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 1}, TableNumber: 1, Waiter: "Charles"}
+	drinksOrdered := events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 2}, Items: []shared.MenuItem{
+		{ID: 10, Description: "Water", Price: shared.Cents(100)},
+		{ID: 10, Description: "Water", Price: shared.Cents(100)},
+	}}
+	drinksServed := events.DrinksServed{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 3}, MenuNumbers: []int{10}}
+
+	// When
+	for _, event := range []events.Event{tabOpened, drinksOrdered, drinksOrdered, drinksServed, drinksServed, tabOpened} {
+		err := suite.openTabQueries.HandleEvent(event)
+		assert.NoError(suite.T(), err)
+	}
+
+	// Then
+	tabForTable, err := suite.openTabQueries.TabForTable(1)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []queries.TabItem{{MenuNumber: 10, Description: "Water", Price: shared.Cents(100)}}, tabForTable.ToServe)
+	assert.Equal(suite.T(), []queries.TabItem{{MenuNumber: 10, Description: "Water", Price: shared.Cents(100)}}, tabForTable.Served)
+}
+
+func (suite *QueriesTestSuite) TestRedeliveredEventsForAClosedTabAreSkipped() {
+	// Given
+	tabId := ksuid.New()
+	drinksOrdered := events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 2}, Items: []shared.MenuItem{{ID: 10, Description: "Water", Price: shared.Cents(100)}}}
+	err := suite.openTabQueries.HandleEvent(events.TabOpened{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 1}, TableNumber: 1, Waiter: "Charles"})
+	assert.NoError(suite.T(), err)
+	err = suite.openTabQueries.HandleEvent(drinksOrdered)
+	assert.NoError(suite.T(), err)
+	err = suite.openTabQueries.HandleEvent(events.TabClosed{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 3}})
+	assert.NoError(suite.T(), err)
+
+	// When
+	err = suite.openTabQueries.HandleEvent(drinksOrdered)
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), suite.openTabQueries.ActiveTableNumbers())
+}
+
+func (suite *QueriesTestSuite) TestAGapInSequenceNumbersIsReported() {
+	// Given
+	tabId := ksuid.New()
+	err := suite.openTabQueries.HandleEvent(events.TabOpened{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 1}, TableNumber: 1, Waiter: "Charles"})
+	assert.NoError(suite.T(), err)
+
+	// When
+	err = suite.openTabQueries.HandleEvent(events.DrinksServed{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 3}, MenuNumbers: []int{10}})
+
+	// Then
+	var gapError *queries.SequenceGapError
+	assert.ErrorAs(suite.T(), err, &gapError)
+	assert.Equal(suite.T(), queries.SequenceGapError{TabID: tabId, Expected: 2, Received: 3}, *gapError)
+
+	tabForTable, err := suite.openTabQueries.TabForTable(1)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []queries.TabItem{}, tabForTable.Served)
+}
+
+func (suite *QueriesTestSuite) TestCancelledDrinksAreNoLongerToServe() {
+	// Given
+	tabId := ksuid.New()
+	for _, event := range []events.Event{
+		events.TabOpened{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 1}, TableNumber: 1, Waiter: "Charles"},
+		events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 2}, Items: []shared.MenuItem{
+			{ID: 10, Description: "Water", Price: shared.Cents(100), IsDrink: true},
+			{ID: 11, Description: "Beer", Price: shared.Cents(200), IsDrink: true},
+		}},
+	} {
+		assert.NoError(suite.T(), suite.openTabQueries.HandleEvent(event))
+	}
+
+	// When
+	err := suite.openTabQueries.HandleEvent(events.ItemsCancelled{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 3}, MenuNumbers: []int{11}, Reason: "ordered by mistake"})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	tabForTable, err := suite.openTabQueries.TabForTable(1)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []queries.TabItem{{MenuNumber: 10, Description: "Water", Price: shared.Cents(100), IsDrink: true}}, tabForTable.ToServe)
+	invoice, err := suite.openTabQueries.InvoiceForTable(1)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), shared.Cents(0), invoice.Total)
+}
+
+func (suite *QueriesTestSuite) TestInvoiceShowsCompsAndDiscounts() {
+	// Given
+	tabId := ksuid.New()
+	for _, event := range []events.Event{
+		events.TabOpened{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 1}, TableNumber: 1, Waiter: "Charles"},
+		events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 2}, Items: []shared.MenuItem{
+			{ID: 10, Description: "Water", Price: shared.Cents(200), IsDrink: true},
+			{ID: 11, Description: "Wine", Price: shared.Cents(1200), IsDrink: true},
+		}},
+		events.DrinksServed{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 3}, MenuNumbers: []int{10, 11}},
+	} {
+		assert.NoError(suite.T(), suite.openTabQueries.HandleEvent(event))
+	}
+
+	// When
+	assert.NoError(suite.T(), suite.openTabQueries.HandleEvent(events.ItemComped{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 4}, MenuNumber: 10, Description: "Water", Amount: shared.Cents(200), Reason: "spilled", AuthorisedBy: "manager"}))
+	assert.NoError(suite.T(), suite.openTabQueries.HandleEvent(events.DiscountApplied{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 5}, Percentage: 25, Reason: "regular", AuthorisedBy: "manager"}))
+	assert.NoError(suite.T(), suite.openTabQueries.HandleEvent(events.DiscountApplied{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 6}, Amount: shared.Cents(100), Reason: "birthday", AuthorisedBy: "manager"}))
+
+	// Then
+	invoice, err := suite.openTabQueries.InvoiceForTable(1)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), shared.Cents(1400), invoice.Subtotal)
+	assert.Equal(suite.T(), []queries.TabAdjustment{{MenuNumber: 10, Description: "Water", Amount: shared.Cents(200), Reason: "spilled", AuthorisedBy: "manager"}}, invoice.LineAdjustments)
+	assert.Equal(suite.T(), []queries.TabAdjustment{
+		{Percentage: 25, Amount: shared.Cents(300), Reason: "regular", AuthorisedBy: "manager"},
+		{Amount: shared.Cents(100), Reason: "birthday", AuthorisedBy: "manager"},
+	}, invoice.TabAdjustments)
+	assert.Equal(suite.T(), shared.Cents(800), invoice.Total)
+}
+
+func (suite *QueriesTestSuite) TestInvoiceShowsPaymentsAndTheBalanceDue() {
+	// Given
+	tabId := ksuid.New()
+	for _, event := range []events.Event{
+		events.TabOpened{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 1}, TableNumber: 1, Waiter: "Charles"},
+		events.DrinksOrdered{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 2}, Items: []shared.MenuItem{
+			{ID: 10, Description: "Water", Price: shared.Cents(200), IsDrink: true},
+			{ID: 11, Description: "Wine", Price: shared.Cents(1200), IsDrink: true},
+		}},
+		events.DrinksServed{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 3}, MenuNumbers: []int{10, 11}},
+	} {
+		assert.NoError(suite.T(), suite.openTabQueries.HandleEvent(event))
+	}
+
+	// When
+	assert.NoError(suite.T(), suite.openTabQueries.HandleEvent(events.PaymentRecorded{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 4}, Method: "card", Amount: shared.Cents(700), Payer: "Alice"}))
+	assert.NoError(suite.T(), suite.openTabQueries.HandleEvent(events.PaymentRecorded{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 5}, Method: "cash", Amount: shared.Cents(500), Payer: "Bob"}))
+
+	// Then
+	invoice, err := suite.openTabQueries.InvoiceForTable(1)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), shared.Cents(1400), invoice.Total)
+	assert.Equal(suite.T(), []queries.TabPayment{{Method: "card", Amount: shared.Cents(700), Payer: "Alice"}, {Method: "cash", Amount: shared.Cents(500), Payer: "Bob"}}, invoice.Payments)
+	assert.Equal(suite.T(), shared.Cents(200), invoice.BalanceDue)
+}
+
+func (suite *QueriesTestSuite) TestTableEventsAreIgnored() {
+	// Given
+	tableId := ksuid.New()
+
+	// When
+	err := suite.openTabQueries.HandleEvent(events.TableClaimed{BaseEvent: events.BaseEvent{ID: tableId, SequenceNumber: 1}, TableNumber: 1, TabID: ksuid.New()})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), suite.openTabQueries.ActiveTableNumbers())
+}
+
+func (suite *QueriesTestSuite) TestMenuEventsAreIgnored() {
+	// Given
+	menuItemId := ksuid.New()
+
+	// When
+	err := suite.openTabQueries.HandleEvent(events.MenuItemAdded{BaseEvent: events.BaseEvent{ID: menuItemId, SequenceNumber: 1}, MenuNumber: 6, Description: "pie", Price: shared.Cents(450)})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), suite.openTabQueries.ActiveTableNumbers())
+}
+
+func (suite *QueriesTestSuite) TestStockEventsAreIgnored() {
+	// Given
+	stockId := ksuid.New()
+
+	// When
+	err := suite.openTabQueries.HandleEvent(events.StockRestocked{BaseEvent: events.BaseEvent{ID: stockId, SequenceNumber: 1}, MenuNumber: 1, Quantity: 12})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), suite.openTabQueries.ActiveTableNumbers())
+}
+
+func (suite *QueriesTestSuite) TestUnknownEventsAreSkippedWithoutLeavingAGap() {
+	// Given
+	tabId := ksuid.New()
+	assert.NoError(suite.T(), suite.openTabQueries.HandleEvent(events.TabOpened{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 1}, TableNumber: 1, Waiter: "w1"}))
+
+	// When
+	err := suite.openTabQueries.HandleEvent(events.UnknownEvent{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 2}, EventType: "TipPooled", SchemaVersion: 1})
+
+	// Then
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.openTabQueries.HandleEvent(events.DrinksServed{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 3}, MenuNumbers: []int{}}))
+	assert.Equal(suite.T(), []int{1}, suite.openTabQueries.ActiveTableNumbers())
+}
+
 func TestSuite(t *testing.T) {
 	suite.Run(t, new(QueriesTestSuite))
 }
@@ -0,0 +1,48 @@
+package queries
+
+import (
+	"cqrseventsourcingbar/events"
+	"fmt"
+	"log/slog"
+
+	"github.com/segmentio/ksuid"
+)
+
+type SequenceGapError struct {
+	TabID    ksuid.KSUID
+	Expected int
+	Received int
+}
+
+func (e *SequenceGapError) Error() string {
+	return fmt.Sprintf("gap in events for tab: %s, expected sequence number %d but received %d", e.TabID, e.Expected, e.Received)
+}
+
+// sequenceTracker keeps the last sequence number applied per aggregate, so in-memory projections can skip
+// redelivered events and spot gaps. Events without a sequence number are always applied.
+type sequenceTracker map[ksuid.KSUID]int
+
+func (t sequenceTracker) shouldApply(e events.Event) (bool, error) {
+	sequenceNumber := e.GetSequenceNumber()
+	if sequenceNumber == 0 {
+		return true, nil
+	}
+
+	lastSequenceNumber := t[e.GetID()]
+	if sequenceNumber <= lastSequenceNumber {
+		slog.Debug("skipping event already applied", slog.String("aggregate", e.GetID().String()), slog.Int("sequence_number", sequenceNumber))
+		return false, nil
+	}
+	if sequenceNumber > lastSequenceNumber+1 {
+		gapError := &SequenceGapError{TabID: e.GetID(), Expected: lastSequenceNumber + 1, Received: sequenceNumber}
+		slog.Warn("gap detected in tab events", slog.String("error", gapError.Error()))
+		return false, gapError
+	}
+	return true, nil
+}
+
+func (t sequenceTracker) applied(e events.Event) {
+	if e.GetSequenceNumber() > 0 {
+		t[e.GetID()] = e.GetSequenceNumber()
+	}
+}
@@ -0,0 +1,235 @@
+package queries
+
+import (
+	"cmp"
+	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/shared"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/segmentio/ksuid"
+)
+
+const cashPaymentMethod = "cash"
+
+//go:generate mockery --name ShiftQueries
+type ShiftQueries interface {
+	Reconciliations() []ShiftReconciliation
+	events.EventListener
+}
+
+// ShiftReconciliation compares the cash a shift should have taken with the cash counted once it was closed.
+type ShiftReconciliation struct {
+	Station      string                 `json:"station"`
+	Shift        int                    `json:"shift"`
+	Open         bool                   `json:"open"`
+	OpenedAt     time.Time              `json:"opened_at"`
+	ClosedAt     *time.Time             `json:"closed_at,omitempty"`
+	OpeningFloat shared.Money           `json:"opening_float"`
+	Takings      shared.Money           `json:"takings"`
+	Tips         shared.Money           `json:"tips"`
+	ExpectedCash shared.Money           `json:"expected_cash"`
+	CountedCash  *shared.Money          `json:"counted_cash,omitempty"`
+	Discrepancy  *shared.Money          `json:"discrepancy,omitempty"`
+	Waiters      []WaiterReconciliation `json:"waiters"`
+}
+
+// WaiterReconciliation is the part of a shift taken on the tabs of one waiter.
+type WaiterReconciliation struct {
+	Waiter       string        `json:"waiter"`
+	Tabs         int           `json:"tabs"`
+	Takings      shared.Money  `json:"takings"`
+	Tips         shared.Money  `json:"tips"`
+	ExpectedCash shared.Money  `json:"expected_cash"`
+	CountedCash  *shared.Money `json:"counted_cash,omitempty"`
+	Discrepancy  *shared.Money `json:"discrepancy,omitempty"`
+}
+
+type shiftKey struct {
+	station string
+	shift   int
+}
+
+type shiftRecord struct {
+	reconciliation ShiftReconciliation
+	cashTakings    shared.Money
+	waiters        map[string]*WaiterReconciliation
+	waiterCounts   map[string]shared.Money
+}
+
+type tabPayments struct {
+	waiter     string
+	attributed shared.Money
+	nonCash    shared.Money
+}
+
+type shiftReconciliations struct {
+	shifts                        map[shiftKey]*shiftRecord
+	tabs                          map[ksuid.KSUID]*tabPayments
+	lastSequenceNumberByAggregate sequenceTracker
+	lock                          sync.RWMutex
+}
+
+func (s *shiftReconciliations) Reconciliations() []ShiftReconciliation {
+	defer s.lock.RUnlock()
+	s.lock.RLock()
+
+	reconciliations := make([]ShiftReconciliation, 0, len(s.shifts))
+	for _, record := range s.shifts {
+		reconciliations = append(reconciliations, record.reconcile())
+	}
+	slices.SortFunc(reconciliations, func(a, b ShiftReconciliation) int {
+		return cmp.Or(strings.Compare(a.Station, b.Station), cmp.Compare(a.Shift, b.Shift))
+	})
+	return reconciliations
+}
+
+func (r *shiftRecord) reconcile() ShiftReconciliation {
+	reconciliation := r.reconciliation
+	reconciliation.ExpectedCash = reconciliation.OpeningFloat.Add(r.cashTakings)
+	if reconciliation.CountedCash != nil {
+		reconciliation.Discrepancy = discrepancy(*reconciliation.CountedCash, reconciliation.ExpectedCash)
+	}
+
+	reconciliation.Waiters = make([]WaiterReconciliation, 0, len(r.waiters))
+	for _, name := range slices.Sorted(maps.Keys(r.waiters)) {
+		waiter := *r.waiters[name]
+		if len(r.waiterCounts) > 0 {
+			// A waiter missing from the counts handed in nothing.
+			counted := shared.Cents(0).Add(r.waiterCounts[name])
+			waiter.CountedCash = &counted
+			waiter.Discrepancy = discrepancy(counted, waiter.ExpectedCash)
+		}
+		reconciliation.Waiters = append(reconciliation.Waiters, waiter)
+	}
+	return reconciliation
+}
+
+func discrepancy(counted shared.Money, expected shared.Money) *shared.Money {
+	difference := counted.Sub(expected)
+	return &difference
+}
+
+func (s *shiftReconciliations) HandleEvent(e events.Event) error {
+	defer s.lock.Unlock()
+	s.lock.Lock()
+
+	apply, err := s.lastSequenceNumberByAggregate.shouldApply(e)
+	if !apply {
+		return err
+	}
+
+	switch event := e.(type) {
+	case events.TabOpened:
+		s.tabs[event.ID] = &tabPayments{waiter: event.Waiter, attributed: shared.Cents(0), nonCash: shared.Cents(0)}
+	case events.PaymentRecorded:
+		s.handlePaymentRecorded(event)
+	case events.TabClosed:
+		s.handleTabClosed(event)
+	case events.ShiftOpened:
+		record := s.shift(event.Station, event.Shift)
+		record.reconciliation.Open = true
+		record.reconciliation.OpenedAt = event.Metadata.OccurredAt
+		record.reconciliation.OpeningFloat = event.OpeningFloat
+	case events.ShiftClosed:
+		record := s.shift(event.Station, event.Shift)
+		closedAt := event.Metadata.OccurredAt
+		counted := event.CountedCash
+		record.reconciliation.Open = false
+		record.reconciliation.ClosedAt = &closedAt
+		record.reconciliation.CountedCash = &counted
+		record.waiterCounts = event.WaiterCounts
+		for waiter := range event.WaiterCounts {
+			record.waiter(waiter)
+		}
+	}
+	s.lastSequenceNumberByAggregate.applied(e)
+	return nil
+}
+
+func (s *shiftReconciliations) handlePaymentRecorded(event events.PaymentRecorded) {
+	tab := s.tab(event.ID)
+	if event.Shift == 0 {
+		if event.Method != cashPaymentMethod {
+			tab.nonCash = tab.nonCash.Add(event.Amount)
+		}
+		return
+	}
+
+	cash := shared.Cents(0)
+	if event.Method == cashPaymentMethod {
+		cash = event.Amount
+	}
+	tab.attributed = tab.attributed.Add(event.Amount)
+	s.shift(event.Station, event.Shift).take(tab.waiter, event.Amount, cash)
+}
+
+// handleTabClosed attributes the rest of the tab's takings, tip included, to the shift it was closed in.
+func (s *shiftReconciliations) handleTabClosed(event events.TabClosed) {
+	tab := s.tab(event.ID)
+	delete(s.tabs, event.ID)
+	if event.Shift == 0 {
+		return
+	}
+
+	takings := event.AmountPaid.Sub(tab.attributed)
+	record := s.shift(event.Station, event.Shift)
+	record.take(tab.waiter, takings, takings.Sub(tab.nonCash))
+	record.reconciliation.Tips = record.reconciliation.Tips.Add(event.Tip)
+
+	waiter := record.waiter(tab.waiter)
+	waiter.Tabs++
+	waiter.Tips = waiter.Tips.Add(event.Tip)
+}
+
+func (s *shiftReconciliations) tab(id ksuid.KSUID) *tabPayments {
+	tab, ok := s.tabs[id]
+	if !ok {
+		tab = &tabPayments{attributed: shared.Cents(0), nonCash: shared.Cents(0)}
+		s.tabs[id] = tab
+	}
+	return tab
+}
+
+func (s *shiftReconciliations) shift(station string, shift int) *shiftRecord {
+	key := shiftKey{station: station, shift: shift}
+	record, ok := s.shifts[key]
+	if !ok {
+		record = &shiftRecord{
+			reconciliation: ShiftReconciliation{Station: station, Shift: shift, OpeningFloat: shared.Cents(0), Takings: shared.Cents(0), Tips: shared.Cents(0)},
+			cashTakings:    shared.Cents(0),
+			waiters:        make(map[string]*WaiterReconciliation),
+		}
+		s.shifts[key] = record
+	}
+	return record
+}
+
+func (r *shiftRecord) take(waiterName string, takings shared.Money, cash shared.Money) {
+	r.reconciliation.Takings = r.reconciliation.Takings.Add(takings)
+	r.cashTakings = r.cashTakings.Add(cash)
+
+	waiter := r.waiter(waiterName)
+	waiter.Takings = waiter.Takings.Add(takings)
+	waiter.ExpectedCash = waiter.ExpectedCash.Add(cash)
+}
+
+func (r *shiftRecord) waiter(name string) *WaiterReconciliation {
+	waiter, ok := r.waiters[name]
+	if !ok {
+		waiter = &WaiterReconciliation{Waiter: name, Takings: shared.Cents(0), Tips: shared.Cents(0), ExpectedCash: shared.Cents(0)}
+		r.waiters[name] = waiter
+	}
+	return waiter
+}
+
+func CreateShiftReconciliations() ShiftQueries {
+	return &shiftReconciliations{
+		shifts:                        make(map[shiftKey]*shiftRecord),
+		tabs:                          make(map[ksuid.KSUID]*tabPayments),
+		lastSequenceNumberByAggregate: make(sequenceTracker),
+	}
+}
@@ -0,0 +1,162 @@
+package queries_test
+
+import (
+	"cqrseventsourcingbar/commands"
+	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/queries"
+	"cqrseventsourcingbar/shared"
+	"testing"
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ShiftReconciliationsTestSuite struct {
+	suite.Suite
+	reconciliations queries.ShiftQueries
+	terraceID       ksuid.KSUID
+	openedAt        time.Time
+}
+
+func (suite *ShiftReconciliationsTestSuite) SetupTest() {
+	suite.reconciliations = queries.CreateShiftReconciliations()
+	suite.terraceID = commands.StationID("terrace")
+	suite.openedAt = time.Date(2024, 3, 1, 17, 0, 0, 0, time.UTC)
+}
+
+func (suite *ShiftReconciliationsTestSuite) handleEvents(shiftEvents ...events.Event) {
+	for _, event := range shiftEvents {
+		assert.NoError(suite.T(), suite.reconciliations.HandleEvent(event))
+	}
+}
+
+func (suite *ShiftReconciliationsTestSuite) openShift() {
+	suite.handleEvents(events.ShiftOpened{BaseEvent: events.BaseEvent{ID: suite.terraceID, SequenceNumber: 1, Metadata: events.Metadata{OccurredAt: suite.openedAt}}, Station: "terrace", Shift: 1, OpeningFloat: shared.Cents(10000)})
+}
+
+func money(amount int64) *shared.Money {
+	cents := shared.Cents(amount)
+	return &cents
+}
+
+func (suite *ShiftReconciliationsTestSuite) TestCountedCashIsComparedWithTheCashTakenPerWaiter() {
+	// Given
+	suite.openShift()
+	charlesTab := ksuid.New()
+	jenkinsTab := ksuid.New()
+	unattributedTab := ksuid.New()
+	closedAt := time.Date(2024, 3, 1, 23, 0, 0, 0, time.UTC)
+
+	// When
+	suite.handleEvents(
+		events.TabOpened{BaseEvent: events.BaseEvent{ID: charlesTab, SequenceNumber: 1}, TableNumber: 1, Waiter: "Charles"},
+		events.TabOpened{BaseEvent: events.BaseEvent{ID: jenkinsTab, SequenceNumber: 1}, TableNumber: 2, Waiter: "Jenkins"},
+		events.TabOpened{BaseEvent: events.BaseEvent{ID: unattributedTab, SequenceNumber: 1}, TableNumber: 3, Waiter: "Jenkins"},
+		events.PaymentRecorded{BaseEvent: events.BaseEvent{ID: charlesTab, SequenceNumber: 2}, Method: "card", Amount: shared.Cents(2000), Payer: "Ann"},
+		events.PaymentRecorded{BaseEvent: events.BaseEvent{ID: charlesTab, SequenceNumber: 3}, Method: "cash", Amount: shared.Cents(1000), Payer: "Bob"},
+		events.TabClosed{BaseEvent: events.BaseEvent{ID: charlesTab, SequenceNumber: 4}, AmountPaid: shared.Cents(5000), OrderAmount: shared.Cents(4500), Tip: shared.Cents(500), Station: "terrace", Shift: 1},
+		events.TabClosed{BaseEvent: events.BaseEvent{ID: jenkinsTab, SequenceNumber: 2}, AmountPaid: shared.Cents(3000), OrderAmount: shared.Cents(3000), Tip: shared.Cents(0), Station: "terrace", Shift: 1},
+		events.TabClosed{BaseEvent: events.BaseEvent{ID: unattributedTab, SequenceNumber: 2}, AmountPaid: shared.Cents(800), OrderAmount: shared.Cents(800), Tip: shared.Cents(0)},
+		events.ShiftClosed{BaseEvent: events.BaseEvent{ID: suite.terraceID, SequenceNumber: 2, Metadata: events.Metadata{OccurredAt: closedAt}}, Station: "terrace", Shift: 1, CountedCash: shared.Cents(15500),
+			WaiterCounts: map[string]shared.Money{"Charles": shared.Cents(3000), "Jenkins": shared.Cents(2500)}},
+	)
+
+	// Then
+	assert.Equal(suite.T(), []queries.ShiftReconciliation{{
+		Station:      "terrace",
+		Shift:        1,
+		OpenedAt:     suite.openedAt,
+		ClosedAt:     &closedAt,
+		OpeningFloat: shared.Cents(10000),
+		Takings:      shared.Cents(8000),
+		Tips:         shared.Cents(500),
+		ExpectedCash: shared.Cents(16000),
+		CountedCash:  money(15500),
+		Discrepancy:  money(-500),
+		Waiters: []queries.WaiterReconciliation{
+			{Waiter: "Charles", Tabs: 1, Takings: shared.Cents(5000), Tips: shared.Cents(500), ExpectedCash: shared.Cents(3000), CountedCash: money(3000), Discrepancy: money(0)},
+			{Waiter: "Jenkins", Tabs: 1, Takings: shared.Cents(3000), Tips: shared.Cents(0), ExpectedCash: shared.Cents(3000), CountedCash: money(2500), Discrepancy: money(-500)},
+		},
+	}}, suite.reconciliations.Reconciliations())
+}
+
+func (suite *ShiftReconciliationsTestSuite) TestOpenShiftsHaveNoDiscrepancyYet() {
+	// Given
+	suite.openShift()
+	tabId := ksuid.New()
+	tabClosed := events.TabClosed{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 2}, AmountPaid: shared.Cents(1200), OrderAmount: shared.Cents(1000), Tip: shared.Cents(200), Station: "terrace", Shift: 1}
+
+	// When
+	suite.handleEvents(
+		events.TabOpened{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 1}, TableNumber: 1, Waiter: "Charles"},
+		tabClosed,
+		tabClosed,
+	)
+
+	// Then
+	assert.Equal(suite.T(), []queries.ShiftReconciliation{{
+		Station:      "terrace",
+		Shift:        1,
+		Open:         true,
+		OpenedAt:     suite.openedAt,
+		OpeningFloat: shared.Cents(10000),
+		Takings:      shared.Cents(1200),
+		Tips:         shared.Cents(200),
+		ExpectedCash: shared.Cents(11200),
+		Waiters: []queries.WaiterReconciliation{
+			{Waiter: "Charles", Tabs: 1, Takings: shared.Cents(1200), Tips: shared.Cents(200), ExpectedCash: shared.Cents(1200)},
+		},
+	}}, suite.reconciliations.Reconciliations())
+}
+
+func (suite *ShiftReconciliationsTestSuite) TestPaymentsGoToTheShiftTheyWereTakenIn() {
+	// Given
+	suite.openShift()
+	tabId := ksuid.New()
+	closedAt := time.Date(2024, 3, 1, 23, 0, 0, 0, time.UTC)
+	nextOpenedAt := time.Date(2024, 3, 1, 23, 5, 0, 0, time.UTC)
+
+	// When
+	suite.handleEvents(
+		events.TabOpened{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 1}, TableNumber: 1, Waiter: "Charles"},
+		events.PaymentRecorded{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 2}, Method: "cash", Amount: shared.Cents(3000), Payer: "Ann", Station: "terrace", Shift: 1},
+		events.ShiftClosed{BaseEvent: events.BaseEvent{ID: suite.terraceID, SequenceNumber: 2, Metadata: events.Metadata{OccurredAt: closedAt}}, Station: "terrace", Shift: 1, CountedCash: shared.Cents(13000)},
+		events.ShiftOpened{BaseEvent: events.BaseEvent{ID: suite.terraceID, SequenceNumber: 3, Metadata: events.Metadata{OccurredAt: nextOpenedAt}}, Station: "terrace", Shift: 2, OpeningFloat: shared.Cents(10000)},
+		events.TabClosed{BaseEvent: events.BaseEvent{ID: tabId, SequenceNumber: 3}, AmountPaid: shared.Cents(3500), OrderAmount: shared.Cents(3000), Tip: shared.Cents(500), Station: "terrace", Shift: 2},
+	)
+
+	// Then
+	assert.Equal(suite.T(), []queries.ShiftReconciliation{{
+		Station:      "terrace",
+		Shift:        1,
+		OpenedAt:     suite.openedAt,
+		ClosedAt:     &closedAt,
+		OpeningFloat: shared.Cents(10000),
+		Takings:      shared.Cents(3000),
+		Tips:         shared.Cents(0),
+		ExpectedCash: shared.Cents(13000),
+		CountedCash:  money(13000),
+		Discrepancy:  money(0),
+		Waiters: []queries.WaiterReconciliation{
+			{Waiter: "Charles", Tabs: 0, Takings: shared.Cents(3000), Tips: shared.Cents(0), ExpectedCash: shared.Cents(3000)},
+		},
+	}, {
+		Station:      "terrace",
+		Shift:        2,
+		Open:         true,
+		OpenedAt:     nextOpenedAt,
+		OpeningFloat: shared.Cents(10000),
+		Takings:      shared.Cents(500),
+		Tips:         shared.Cents(500),
+		ExpectedCash: shared.Cents(10500),
+		Waiters: []queries.WaiterReconciliation{
+			{Waiter: "Charles", Tabs: 1, Takings: shared.Cents(500), Tips: shared.Cents(500), ExpectedCash: shared.Cents(500)},
+		},
+	}}, suite.reconciliations.Reconciliations())
+}
+
+func TestShiftReconciliationsTestSuite(t *testing.T) {
+	suite.Run(t, new(ShiftReconciliationsTestSuite))
+}
@@ -0,0 +1,183 @@
+package queries
+
+import (
+	"cmp"
+	"cqrseventsourcingbar/events"
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+//go:generate mockery --name StockQueries
+type StockQueries interface {
+	StockLevels() []StockLevel
+	LowStock() []StockLevel
+	StockAlerts() []StockAlert
+	IngredientLevels() []IngredientLevel
+	events.EventListener
+}
+
+type StockLevel struct {
+	MenuNumber   int    `json:"menu_number"`
+	Description  string `json:"description"`
+	OnHand       int    `json:"on_hand"`
+	Reserved     int    `json:"reserved"`
+	Available    int    `json:"available"`
+	ReorderLevel int    `json:"reorder_level"`
+}
+
+type StockAlert struct {
+	MenuNumber   int       `json:"menu_number"`
+	Description  string    `json:"description"`
+	Available    int       `json:"available"`
+	ReorderLevel int       `json:"reorder_level"`
+	RaisedAt     time.Time `json:"raised_at"`
+}
+
+// IngredientLevel tells the stock of an ingredient in the unit its recipes use and in bottles or kegs,
+// when the size of those is known.
+type IngredientLevel struct {
+	Ingredient    string  `json:"ingredient"`
+	Unit          string  `json:"unit"`
+	OnHand        int     `json:"on_hand"`
+	ContainerSize int     `json:"container_size,omitempty"`
+	Containers    float64 `json:"containers,omitempty"`
+}
+
+// stockLevels keeps the stock of the tracked menu items and ingredients, and every time a menu item ran low,
+// oldest alert first.
+type stockLevels struct {
+	levels                   map[int]*StockLevel
+	descriptions             map[int]string
+	alerts                   []StockAlert
+	ingredients              map[string]*IngredientLevel
+	lastSequenceNumberByItem sequenceTracker
+	lock                     sync.RWMutex
+}
+
+func (s *stockLevels) StockLevels() []StockLevel {
+	defer s.lock.RUnlock()
+	s.lock.RLock()
+
+	return s.levelsWhere(func(StockLevel) bool { return true })
+}
+
+func (s *stockLevels) LowStock() []StockLevel {
+	defer s.lock.RUnlock()
+	s.lock.RLock()
+
+	return s.levelsWhere(func(level StockLevel) bool { return level.Available <= level.ReorderLevel })
+}
+
+func (s *stockLevels) levelsWhere(include func(StockLevel) bool) []StockLevel {
+	levels := []StockLevel{}
+	for menuNumber, level := range s.levels {
+		stockLevel := *level
+		stockLevel.Description = s.descriptions[menuNumber]
+		stockLevel.Available = stockLevel.OnHand - stockLevel.Reserved
+		if include(stockLevel) {
+			levels = append(levels, stockLevel)
+		}
+	}
+	slices.SortFunc(levels, func(a, b StockLevel) int { return cmp.Compare(a.MenuNumber, b.MenuNumber) })
+	return levels
+}
+
+func (s *stockLevels) StockAlerts() []StockAlert {
+	defer s.lock.RUnlock()
+	s.lock.RLock()
+
+	alerts := slices.Clone(s.alerts)
+	for i := range alerts {
+		alerts[i].Description = s.descriptions[alerts[i].MenuNumber]
+	}
+	return alerts
+}
+
+func (s *stockLevels) IngredientLevels() []IngredientLevel {
+	defer s.lock.RUnlock()
+	s.lock.RLock()
+
+	levels := make([]IngredientLevel, 0, len(s.ingredients))
+	for _, ingredient := range s.ingredients {
+		level := *ingredient
+		if level.ContainerSize > 0 {
+			level.Containers = math.Round(float64(level.OnHand)/float64(level.ContainerSize)*100) / 100
+		}
+		levels = append(levels, level)
+	}
+	slices.SortFunc(levels, func(a, b IngredientLevel) int { return cmp.Compare(a.Ingredient, b.Ingredient) })
+	return levels
+}
+
+func (s *stockLevels) HandleEvent(e events.Event) error {
+	defer s.lock.Unlock()
+	s.lock.Lock()
+
+	apply, err := s.lastSequenceNumberByItem.shouldApply(e)
+	if !apply {
+		return err
+	}
+
+	switch event := e.(type) {
+	case events.MenuItemAdded:
+		s.descriptions[event.MenuNumber] = event.Description
+	case events.MenuItemRenamed:
+		s.descriptions[event.MenuNumber] = event.Description
+	case events.StockRestocked:
+		s.level(event.MenuNumber).OnHand += event.Quantity
+	case events.StockCounted:
+		s.level(event.MenuNumber).OnHand = event.Quantity
+	case events.ReorderLevelSet:
+		s.level(event.MenuNumber).ReorderLevel = event.Level
+	case events.StockReserved:
+		s.level(event.MenuNumber).Reserved += event.Quantity
+	case events.StockReleased:
+		s.level(event.MenuNumber).Reserved -= event.Quantity
+	case events.StockConsumed:
+		level := s.level(event.MenuNumber)
+		level.OnHand -= event.Quantity
+		level.Reserved -= min(event.Quantity, level.Reserved)
+	case events.StockRanLow:
+		s.alerts = append(s.alerts, StockAlert{MenuNumber: event.MenuNumber, Available: event.Available, ReorderLevel: event.ReorderLevel, RaisedAt: event.Metadata.OccurredAt})
+	case events.IngredientRestocked:
+		ingredient := s.ingredient(event.Ingredient)
+		ingredient.OnHand += event.Quantity
+		ingredient.Unit = event.Unit
+		ingredient.ContainerSize = event.ContainerSize
+	case events.IngredientCounted:
+		s.ingredient(event.Ingredient).OnHand = event.Quantity
+	case events.IngredientConsumed:
+		s.ingredient(event.Ingredient).OnHand -= event.Quantity
+	}
+	s.lastSequenceNumberByItem.applied(e)
+	return nil
+}
+
+func (s *stockLevels) level(menuNumber int) *StockLevel {
+	level, ok := s.levels[menuNumber]
+	if !ok {
+		level = &StockLevel{MenuNumber: menuNumber}
+		s.levels[menuNumber] = level
+	}
+	return level
+}
+
+func (s *stockLevels) ingredient(name string) *IngredientLevel {
+	ingredient, ok := s.ingredients[name]
+	if !ok {
+		ingredient = &IngredientLevel{Ingredient: name}
+		s.ingredients[name] = ingredient
+	}
+	return ingredient
+}
+
+func CreateStockLevels() StockQueries {
+	return &stockLevels{
+		levels:                   make(map[int]*StockLevel),
+		descriptions:             make(map[int]string),
+		ingredients:              make(map[string]*IngredientLevel),
+		lastSequenceNumberByItem: make(sequenceTracker),
+	}
+}
@@ -0,0 +1,97 @@
+package queries_test
+
+import (
+	"cqrseventsourcingbar/commands"
+	"cqrseventsourcingbar/events"
+	"cqrseventsourcingbar/queries"
+	"cqrseventsourcingbar/shared"
+	"testing"
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type StockLevelsTestSuite struct {
+	suite.Suite
+	stockLevels queries.StockQueries
+}
+
+func (suite *StockLevelsTestSuite) SetupTest() {
+	suite.stockLevels = queries.CreateStockLevels()
+}
+
+func (suite *StockLevelsTestSuite) handleEvents(stockEvents ...events.Event) {
+	for _, event := range stockEvents {
+		assert.NoError(suite.T(), suite.stockLevels.HandleEvent(event))
+	}
+}
+
+func (suite *StockLevelsTestSuite) TestStockLevelsFollowTheStockEvents() {
+	// Given
+	waterId := commands.StockID(1)
+	beerId := commands.StockID(7)
+	tabId := ksuid.New()
+
+	// When
+	suite.handleEvents(
+		events.MenuItemAdded{BaseEvent: events.BaseEvent{ID: commands.MenuItemID(1), SequenceNumber: 1}, MenuNumber: 1, Description: "water", Price: shared.Cents(150), IsDrink: true},
+		events.StockRestocked{BaseEvent: events.BaseEvent{ID: waterId, SequenceNumber: 1}, MenuNumber: 1, Quantity: 12},
+		events.StockReserved{BaseEvent: events.BaseEvent{ID: waterId, SequenceNumber: 2}, MenuNumber: 1, Quantity: 3, TabID: tabId, TabSequenceNumber: 2},
+		events.StockConsumed{BaseEvent: events.BaseEvent{ID: waterId, SequenceNumber: 3}, MenuNumber: 1, Quantity: 2, TabID: tabId, TabSequenceNumber: 3},
+		events.StockCounted{BaseEvent: events.BaseEvent{ID: beerId, SequenceNumber: 1}, MenuNumber: 7, Quantity: 4, Difference: 4},
+		events.ReorderLevelSet{BaseEvent: events.BaseEvent{ID: beerId, SequenceNumber: 2}, MenuNumber: 7, Level: 6},
+	)
+
+	// Then
+	assert.Equal(suite.T(), []queries.StockLevel{
+		{MenuNumber: 1, Description: "water", OnHand: 10, Reserved: 1, Available: 9},
+		{MenuNumber: 7, OnHand: 4, Available: 4, ReorderLevel: 6},
+	}, suite.stockLevels.StockLevels())
+	assert.Equal(suite.T(), []queries.StockLevel{{MenuNumber: 7, OnHand: 4, Available: 4, ReorderLevel: 6}}, suite.stockLevels.LowStock())
+}
+
+func (suite *StockLevelsTestSuite) TestAlertsAreKeptWithTheTimeTheyWereRaised() {
+	// Given
+	beerId := commands.StockID(7)
+	raised := events.Metadata{OccurredAt: time.Date(2024, 3, 1, 22, 0, 0, 0, time.UTC)}
+	suite.handleEvents(
+		events.MenuItemAdded{BaseEvent: events.BaseEvent{ID: commands.MenuItemID(7), SequenceNumber: 1}, MenuNumber: 7, Description: "beer", Price: shared.Cents(300), IsDrink: true},
+		events.StockRestocked{BaseEvent: events.BaseEvent{ID: beerId, SequenceNumber: 1}, MenuNumber: 7, Quantity: 2},
+	)
+	ranLow := events.StockRanLow{BaseEvent: events.BaseEvent{ID: beerId, SequenceNumber: 3, Metadata: raised}, MenuNumber: 7, Available: 0, ReorderLevel: 0}
+
+	// When
+	suite.handleEvents(
+		events.StockReserved{BaseEvent: events.BaseEvent{ID: beerId, SequenceNumber: 2}, MenuNumber: 7, Quantity: 2, TabID: ksuid.New(), TabSequenceNumber: 2},
+		ranLow,
+		ranLow,
+	)
+
+	// Then
+	assert.Equal(suite.T(), []queries.StockAlert{{MenuNumber: 7, Description: "beer", Available: 0, ReorderLevel: 0, RaisedAt: raised.OccurredAt}}, suite.stockLevels.StockAlerts())
+}
+
+func (suite *StockLevelsTestSuite) TestIngredientsAreToldInBottles() {
+	// Given
+	ginId := commands.IngredientID("gin")
+	limeId := commands.IngredientID("lime")
+
+	// When
+	suite.handleEvents(
+		events.IngredientRestocked{BaseEvent: events.BaseEvent{ID: ginId, SequenceNumber: 1}, Ingredient: "gin", Quantity: 1400, Unit: "ml", ContainerSize: 700},
+		events.IngredientConsumed{BaseEvent: events.BaseEvent{ID: ginId, SequenceNumber: 2}, Ingredient: "gin", Quantity: 130, MenuNumbers: []int{6, 7}, TabID: ksuid.New(), TabSequenceNumber: 3},
+		events.IngredientCounted{BaseEvent: events.BaseEvent{ID: limeId, SequenceNumber: 1}, Ingredient: "lime", Quantity: 12, Difference: 12},
+	)
+
+	// Then
+	assert.Equal(suite.T(), []queries.IngredientLevel{
+		{Ingredient: "gin", Unit: "ml", OnHand: 1270, ContainerSize: 700, Containers: 1.81},
+		{Ingredient: "lime", OnHand: 12},
+	}, suite.stockLevels.IngredientLevels())
+}
+
+func TestStockLevelsTestSuite(t *testing.T) {
+	suite.Run(t, new(StockLevelsTestSuite))
+}
@@ -21,4 +21,16 @@ type InvoiceForTableResponse QueryResponse[queries.TabInvoice]
 
 type TodoListForWaiterResponse QueryResponse[map[int][]queries.TabItem]
 
+type ChefTodoListResponse QueryResponse[[]queries.ChefTodoGroup]
+
 type AllMenuItemsResponse QueryResponse[[]shared.MenuItem]
+
+type MenuItemPriceHistoryResponse QueryResponse[[]queries.PriceChange]
+
+type StockLevelsResponse QueryResponse[[]queries.StockLevel]
+
+type StockAlertsResponse QueryResponse[[]queries.StockAlert]
+
+type IngredientLevelsResponse QueryResponse[[]queries.IngredientLevel]
+
+type ShiftReconciliationsResponse QueryResponse[[]queries.ShiftReconciliation]
@@ -16,10 +16,16 @@ type ReadService struct {
 	httpServer         *http.Server
 	serveMux           *http.ServeMux
 	openTabQueries     queries.OpenTabQueries
+	chefTodoList       queries.ChefTodoListQueries
 	menuItemRepository shared.MenuItemRepository
+	menuCatalogue      queries.MenuCatalogueQueries
+	stockQueries       queries.StockQueries
+	shiftQueries       queries.ShiftQueries
 }
 
-func CreateReadService(port int, openTabQueries queries.OpenTabQueries, menuItemRepository shared.MenuItemRepository) *ReadService {
+// CreateReadService lists the menu from menuItemRepository, which should price the items the way the write service
+// charges them, so clients show the prices orders are checked against. The price history comes from menuCatalogue.
+func CreateReadService(port int, openTabQueries queries.OpenTabQueries, chefTodoList queries.ChefTodoListQueries, menuItemRepository shared.MenuItemRepository, menuCatalogue queries.MenuCatalogueQueries, stockQueries queries.StockQueries, shiftQueries queries.ShiftQueries) *ReadService {
 	srv := &ReadService{}
 
 	srv.serveMux = http.NewServeMux()
@@ -28,7 +34,14 @@ func CreateReadService(port int, openTabQueries queries.OpenTabQueries, menuItem
 	srv.serveMux.HandleFunc("/tabForTable", srv.tabForTableNumberHandler)
 	srv.serveMux.HandleFunc("/invoiceForTable", srv.invoiceForTableNumberHandler)
 	srv.serveMux.HandleFunc("/todoListForWaiter", srv.todoListForWaiterHandler)
+	srv.serveMux.HandleFunc("/chefTodoList", srv.chefTodoListHandler)
 	srv.serveMux.HandleFunc("/allMenuItems", srv.allMenuItemsHandler)
+	srv.serveMux.HandleFunc("/menuItemPriceHistory", srv.menuItemPriceHistoryHandler)
+	srv.serveMux.HandleFunc("/stockLevels", srv.stockLevelsHandler)
+	srv.serveMux.HandleFunc("/lowStock", srv.lowStockHandler)
+	srv.serveMux.HandleFunc("/stockAlerts", srv.stockAlertsHandler)
+	srv.serveMux.HandleFunc("/ingredientLevels", srv.ingredientLevelsHandler)
+	srv.serveMux.HandleFunc("/shiftReconciliations", srv.shiftReconciliationsHandler)
 
 	srv.httpServer = &http.Server{
 		Addr: fmt.Sprintf(":%d", port),
@@ -36,7 +49,11 @@ func CreateReadService(port int, openTabQueries queries.OpenTabQueries, menuItem
 
 	srv.httpServer.Handler = srv.serveMux
 	srv.openTabQueries = openTabQueries
+	srv.chefTodoList = chefTodoList
 	srv.menuItemRepository = menuItemRepository
+	srv.menuCatalogue = menuCatalogue
+	srv.stockQueries = stockQueries
+	srv.shiftQueries = shiftQueries
 
 	return srv
 }
@@ -159,6 +176,22 @@ func (rs *ReadService) todoListForWaiterHandler(w http.ResponseWriter, r *http.R
 	returnJsonOk(w, todoListForWaiterResponse)
 }
 
+func (rs *ReadService) chefTodoListHandler(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodGet {
+		returnJsonError(w, "Method Not Allowed", http.StatusMethodNotAllowed, &model.QueryResponse[any]{})
+		return
+	}
+
+	chefTodoListResponse := model.ChefTodoListResponse{
+		Data:  rs.chefTodoList.TodoList(),
+		OK:    true,
+		Error: "",
+	}
+
+	returnJsonOk(w, chefTodoListResponse)
+}
+
 func (rs *ReadService) allMenuItemsHandler(w http.ResponseWriter, r *http.Request) {
 
 	if r.Method != http.MethodGet {
@@ -182,6 +215,120 @@ func (rs *ReadService) allMenuItemsHandler(w http.ResponseWriter, r *http.Reques
 	returnJsonOk(w, allMenuItemsResponse)
 }
 
+func (rs *ReadService) menuItemPriceHistoryHandler(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodGet {
+		returnJsonError(w, "Method Not Allowed", http.StatusMethodNotAllowed, &model.QueryResponse[any]{})
+		return
+	}
+
+	menuNumberStr := r.URL.Query().Get("menu_number")
+	if menuNumberStr == "" {
+		returnJsonError(w, "menu_number is required", http.StatusBadRequest, &model.QueryResponse[any]{})
+		return
+	}
+
+	menuNumber, err := strconv.ParseInt(menuNumberStr, 10, 64)
+	if err != nil {
+		returnJsonError(w, fmt.Sprintf("Error reading menu_number: %v", err), http.StatusBadRequest, &model.QueryResponse[any]{})
+		return
+	}
+
+	priceHistory, err := rs.menuCatalogue.PriceHistory(int(menuNumber))
+	if err != nil {
+		returnJsonError(w, fmt.Sprintf("Error processing menuItemPriceHistory request: %v", err), http.StatusNotFound, &model.QueryResponse[any]{})
+		return
+	}
+
+	menuItemPriceHistoryResponse := model.MenuItemPriceHistoryResponse{
+		Data:  priceHistory,
+		OK:    true,
+		Error: "",
+	}
+
+	returnJsonOk(w, menuItemPriceHistoryResponse)
+}
+
+func (rs *ReadService) stockLevelsHandler(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodGet {
+		returnJsonError(w, "Method Not Allowed", http.StatusMethodNotAllowed, &model.QueryResponse[any]{})
+		return
+	}
+
+	stockLevelsResponse := model.StockLevelsResponse{
+		Data:  rs.stockQueries.StockLevels(),
+		OK:    true,
+		Error: "",
+	}
+
+	returnJsonOk(w, stockLevelsResponse)
+}
+
+func (rs *ReadService) lowStockHandler(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodGet {
+		returnJsonError(w, "Method Not Allowed", http.StatusMethodNotAllowed, &model.QueryResponse[any]{})
+		return
+	}
+
+	lowStockResponse := model.StockLevelsResponse{
+		Data:  rs.stockQueries.LowStock(),
+		OK:    true,
+		Error: "",
+	}
+
+	returnJsonOk(w, lowStockResponse)
+}
+
+func (rs *ReadService) stockAlertsHandler(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodGet {
+		returnJsonError(w, "Method Not Allowed", http.StatusMethodNotAllowed, &model.QueryResponse[any]{})
+		return
+	}
+
+	stockAlertsResponse := model.StockAlertsResponse{
+		Data:  rs.stockQueries.StockAlerts(),
+		OK:    true,
+		Error: "",
+	}
+
+	returnJsonOk(w, stockAlertsResponse)
+}
+
+func (rs *ReadService) ingredientLevelsHandler(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodGet {
+		returnJsonError(w, "Method Not Allowed", http.StatusMethodNotAllowed, &model.QueryResponse[any]{})
+		return
+	}
+
+	ingredientLevelsResponse := model.IngredientLevelsResponse{
+		Data:  rs.stockQueries.IngredientLevels(),
+		OK:    true,
+		Error: "",
+	}
+
+	returnJsonOk(w, ingredientLevelsResponse)
+}
+
+func (rs *ReadService) shiftReconciliationsHandler(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodGet {
+		returnJsonError(w, "Method Not Allowed", http.StatusMethodNotAllowed, &model.QueryResponse[any]{})
+		return
+	}
+
+	shiftReconciliationsResponse := model.ShiftReconciliationsResponse{
+		Data:  rs.shiftQueries.Reconciliations(),
+		OK:    true,
+		Error: "",
+	}
+
+	returnJsonOk(w, shiftReconciliationsResponse)
+}
+
 func readTableNumber(q url.Values, w http.ResponseWriter) (int, bool) {
 	tableNumberStr := q.Get("table_number")
 
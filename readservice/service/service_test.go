@@ -3,20 +3,27 @@ package service
 import (
 	"cqrseventsourcingbar/queries"
 	queries_mocks "cqrseventsourcingbar/queries/mocks"
+	"cqrseventsourcingbar/shared"
 	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/segmentio/ksuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
 
 type ReadServiceTestSuite struct {
 	suite.Suite
 	openTabQueries queries_mocks.OpenTabQueries
+	chefTodoList   queries_mocks.ChefTodoListQueries
+	menuCatalogue  queries_mocks.MenuCatalogueQueries
+	stockQueries   queries_mocks.StockQueries
+	shiftQueries   queries_mocks.ShiftQueries
 	readService    *ReadService
 }
 
@@ -207,10 +214,11 @@ func (suite *ReadServiceTestSuite) TestTabForTableNumber() {
 	request, err := http.NewRequest(http.MethodGet, "?table_number=19", nil)
 	assert.NoError(suite.T(), err)
 	suite.openTabQueries.On("TabForTable", 19).Return(queries.TabStatus{
-		TabID:       "2qPTBJCN6ib7iJ6WaIVvoSmySSV",
-		TableNumber: 19,
-		ToServe:     []queries.TabItem{},
-		Served:      []queries.TabItem{},
+		TabID:         "2qPTBJCN6ib7iJ6WaIVvoSmySSV",
+		TableNumber:   19,
+		ToServe:       []queries.TabItem{},
+		InPreparation: []queries.TabItem{},
+		Served:        []queries.TabItem{},
 	}, nil)
 
 	// When
@@ -220,7 +228,7 @@ func (suite *ReadServiceTestSuite) TestTabForTableNumber() {
 	assert.Equal(suite.T(), string("200 OK"), rr.Result().Status)
 	bytes, err := io.ReadAll(rr.Result().Body)
 	assert.NoError(suite.T(), err)
-	assert.Equal(suite.T(), "{\"ok\":true,\"error\":\"\",\"data\":{\"tab_id\":\"2qPTBJCN6ib7iJ6WaIVvoSmySSV\",\"table_number\":19,\"to_serve\":[],\"served\":[]}}", string(bytes))
+	assert.Equal(suite.T(), "{\"ok\":true,\"error\":\"\",\"data\":{\"tab_id\":\"2qPTBJCN6ib7iJ6WaIVvoSmySSV\",\"table_number\":19,\"to_serve\":[],\"in_preparation\":[],\"served\":[]}}", string(bytes))
 }
 
 func (suite *ReadServiceTestSuite) TestInvoiceForTableReturnsErrorIfNotGet() {
@@ -296,8 +304,13 @@ func (suite *ReadServiceTestSuite) TestInvoiceForTable() {
 	suite.openTabQueries.On("InvoiceForTable", 19).Return(queries.TabInvoice{
 		TabID:            "2qPTBJCN6ib7iJ6WaIVvoSmySSV",
 		TableNumber:      19,
-		Items:            []queries.TabItem{},
-		Total:            0,
+		Items:            []queries.TabItem{{MenuNumber: 1, Description: "Blue Water", Price: shared.Cents(200), IsDrink: true}},
+		Subtotal:         shared.Cents(200),
+		LineAdjustments:  []queries.TabAdjustment{},
+		TabAdjustments:   []queries.TabAdjustment{{Percentage: 50, Amount: shared.Cents(100), Reason: "regular", AuthorisedBy: "manager"}},
+		Total:            shared.Cents(100),
+		Payments:         []queries.TabPayment{{Method: "card", Amount: shared.Cents(50), Payer: "Alice"}},
+		BalanceDue:       shared.Cents(50),
 		HasUnservedItems: false,
 	}, nil)
 
@@ -308,7 +321,7 @@ func (suite *ReadServiceTestSuite) TestInvoiceForTable() {
 	assert.Equal(suite.T(), string("200 OK"), rr.Result().Status)
 	bytes, err := io.ReadAll(rr.Result().Body)
 	assert.NoError(suite.T(), err)
-	assert.Equal(suite.T(), "{\"ok\":true,\"error\":\"\",\"data\":{\"tab_id\":\"2qPTBJCN6ib7iJ6WaIVvoSmySSV\",\"table_number\":19,\"items\":[],\"total\":0,\"has_unserved_items\":false}}", string(bytes))
+	assert.Equal(suite.T(), "{\"ok\":true,\"error\":\"\",\"data\":{\"tab_id\":\"2qPTBJCN6ib7iJ6WaIVvoSmySSV\",\"table_number\":19,\"items\":[{\"menu_number\":1,\"description\":\"Blue Water\",\"price\":{\"amount\":200,\"currency\":\"EUR\"},\"is_drink\":true}],\"subtotal\":{\"amount\":200,\"currency\":\"EUR\"},\"line_adjustments\":[],\"tab_adjustments\":[{\"percentage\":50,\"amount\":{\"amount\":100,\"currency\":\"EUR\"},\"reason\":\"regular\",\"authorised_by\":\"manager\"}],\"total\":{\"amount\":100,\"currency\":\"EUR\"},\"payments\":[{\"method\":\"card\",\"amount\":{\"amount\":50,\"currency\":\"EUR\"},\"payer\":\"Alice\"}],\"balance_due\":{\"amount\":50,\"currency\":\"EUR\"},\"has_unserved_items\":false}}", string(bytes))
 }
 
 func (suite *ReadServiceTestSuite) TestTodoListForWaiterReturnsErrorIfNotGet() {
@@ -336,7 +349,8 @@ func (suite *ReadServiceTestSuite) TestTodoListForWaiter() {
 	tabItems[19] = []queries.TabItem{{
 		MenuNumber:  1,
 		Description: "Blue Water",
-		Price:       1.0,
+		Price:       shared.Cents(100),
+		IsDrink:     true,
 	}}
 	suite.openTabQueries.On("TodoListForWaiter", "w1").Return(tabItems, nil)
 
@@ -347,12 +361,216 @@ func (suite *ReadServiceTestSuite) TestTodoListForWaiter() {
 	assert.Equal(suite.T(), string("200 OK"), rr.Result().Status)
 	bytes, err := io.ReadAll(rr.Result().Body)
 	assert.NoError(suite.T(), err)
-	assert.Equal(suite.T(), "{\"ok\":true,\"error\":\"\",\"data\":{\"19\":[{\"menu_number\":1,\"description\":\"Blue Water\",\"price\":1}]}}", string(bytes))
+	assert.Equal(suite.T(), "{\"ok\":true,\"error\":\"\",\"data\":{\"19\":[{\"menu_number\":1,\"description\":\"Blue Water\",\"price\":{\"amount\":100,\"currency\":\"EUR\"},\"is_drink\":true}]}}", string(bytes))
+}
+
+func (suite *ReadServiceTestSuite) TestChefTodoListReturnsErrorIfNotGet() {
+	// Given
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", nil)
+	assert.NoError(suite.T(), err)
+
+	// When
+	suite.readService.chefTodoListHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), string("405 Method Not Allowed"), rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"Method Not Allowed\",\"data\":null}", string(bytes))
+}
+
+func (suite *ReadServiceTestSuite) TestChefTodoList() {
+	// Given
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "", nil)
+	assert.NoError(suite.T(), err)
+	suite.chefTodoList.On("TodoList").Return([]queries.ChefTodoGroup{{
+		TabID:       "2Y1dSUdkBqi5JuVmp8jNzSOhVkF",
+		TableNumber: 19,
+		Items:       []queries.ChefTodoItem{{MenuNumber: 4, Description: "Burger"}},
+	}})
+
+	// When
+	suite.readService.chefTodoListHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), string("200 OK"), rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":true,\"error\":\"\",\"data\":[{\"tab_id\":\"2Y1dSUdkBqi5JuVmp8jNzSOhVkF\",\"table_number\":19,\"items\":[{\"menu_number\":4,\"description\":\"Burger\"}]}]}", string(bytes))
+}
+
+func (suite *ReadServiceTestSuite) TestAllMenuItems() {
+	// Given
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "", nil)
+	assert.NoError(suite.T(), err)
+	suite.menuCatalogue.On("ReadAllItems", mock.Anything).Return([]shared.MenuItem{{ID: 6, Description: "pie", Price: shared.Cents(450), Category: "mains"}}, nil)
+
+	// When
+	suite.readService.allMenuItemsHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), string("200 OK"), rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":true,\"error\":\"\",\"data\":[{\"id\":6,\"description\":\"pie\",\"price\":{\"amount\":450,\"currency\":\"EUR\"},\"is_drink\":false,\"category\":\"mains\"}]}", string(bytes))
+}
+
+func (suite *ReadServiceTestSuite) TestMenuItemPriceHistoryReturnsErrorIfNoMenuNumber() {
+	// Given
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "", nil)
+	assert.NoError(suite.T(), err)
+
+	// When
+	suite.readService.menuItemPriceHistoryHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), string("400 Bad Request"), rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"menu_number is required\",\"data\":null}", string(bytes))
+}
+
+func (suite *ReadServiceTestSuite) TestMenuItemPriceHistoryReturnsNotFoundForUnknownItems() {
+	// Given
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "?menu_number=42", nil)
+	assert.NoError(suite.T(), err)
+	suite.menuCatalogue.On("PriceHistory", 42).Return(nil, errors.New("couldn't find menu item: 42"))
+
+	// When
+	suite.readService.menuItemPriceHistoryHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), string("404 Not Found"), rr.Result().Status)
+}
+
+func (suite *ReadServiceTestSuite) TestMenuItemPriceHistory() {
+	// Given
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "?menu_number=6", nil)
+	assert.NoError(suite.T(), err)
+	suite.menuCatalogue.On("PriceHistory", 6).Return([]queries.PriceChange{
+		{Price: shared.Cents(450), ChangedAt: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC), ChangedBy: "manager"},
+		{Price: shared.Cents(500), ChangedAt: time.Date(2024, 3, 8, 9, 0, 0, 0, time.UTC)},
+	}, nil)
+
+	// When
+	suite.readService.menuItemPriceHistoryHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), string("200 OK"), rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":true,\"error\":\"\",\"data\":[{\"price\":{\"amount\":450,\"currency\":\"EUR\"},\"changed_at\":\"2024-03-01T09:00:00Z\",\"changed_by\":\"manager\"},{\"price\":{\"amount\":500,\"currency\":\"EUR\"},\"changed_at\":\"2024-03-08T09:00:00Z\"}]}", string(bytes))
+}
+
+func (suite *ReadServiceTestSuite) TestLowStock() {
+	// Given
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "", nil)
+	assert.NoError(suite.T(), err)
+	suite.stockQueries.On("LowStock").Return([]queries.StockLevel{{MenuNumber: 3, Description: "green water", OnHand: 4, Reserved: 2, Available: 2, ReorderLevel: 6}})
+
+	// When
+	suite.readService.lowStockHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), string("200 OK"), rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":true,\"error\":\"\",\"data\":[{\"menu_number\":3,\"description\":\"green water\",\"on_hand\":4,\"reserved\":2,\"available\":2,\"reorder_level\":6}]}", string(bytes))
+}
+
+func (suite *ReadServiceTestSuite) TestStockAlerts() {
+	// Given
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "", nil)
+	assert.NoError(suite.T(), err)
+	raisedAt := time.Date(2024, 3, 1, 22, 0, 0, 0, time.UTC)
+	suite.stockQueries.On("StockAlerts").Return([]queries.StockAlert{{MenuNumber: 3, Description: "green water", Available: 0, ReorderLevel: 0, RaisedAt: raisedAt}})
+
+	// When
+	suite.readService.stockAlertsHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), string("200 OK"), rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":true,\"error\":\"\",\"data\":[{\"menu_number\":3,\"description\":\"green water\",\"available\":0,\"reorder_level\":0,\"raised_at\":\"2024-03-01T22:00:00Z\"}]}", string(bytes))
+}
+
+func (suite *ReadServiceTestSuite) TestIngredientLevels() {
+	// Given
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "", nil)
+	assert.NoError(suite.T(), err)
+	suite.stockQueries.On("IngredientLevels").Return([]queries.IngredientLevel{{Ingredient: "gin", Unit: "ml", OnHand: 1050, ContainerSize: 700, Containers: 1.5}, {Ingredient: "lime", OnHand: 12}})
+
+	// When
+	suite.readService.ingredientLevelsHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), string("200 OK"), rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":true,\"error\":\"\",\"data\":[{\"ingredient\":\"gin\",\"unit\":\"ml\",\"on_hand\":1050,\"container_size\":700,\"containers\":1.5},{\"ingredient\":\"lime\",\"unit\":\"\",\"on_hand\":12}]}", string(bytes))
+}
+
+func (suite *ReadServiceTestSuite) TestStockHandlersOnlyAcceptGet() {
+	for _, handler := range []http.HandlerFunc{suite.readService.stockLevelsHandler, suite.readService.lowStockHandler, suite.readService.stockAlertsHandler, suite.readService.ingredientLevelsHandler} {
+		rr := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodPost, "", nil)
+		assert.NoError(suite.T(), err)
+
+		handler(rr, request)
+
+		assert.Equal(suite.T(), "405 Method Not Allowed", rr.Result().Status)
+	}
+}
+
+func (suite *ReadServiceTestSuite) TestShiftReconciliations() {
+	// Given
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "", nil)
+	assert.NoError(suite.T(), err)
+	counted := shared.Cents(2500)
+	discrepancy := shared.Cents(-500)
+	suite.shiftQueries.On("Reconciliations").Return([]queries.ShiftReconciliation{{
+		Station:      "terrace",
+		Shift:        1,
+		Open:         true,
+		OpenedAt:     time.Date(2024, 3, 1, 17, 0, 0, 0, time.UTC),
+		OpeningFloat: shared.Cents(10000),
+		Takings:      shared.Cents(3000),
+		Tips:         shared.Cents(0),
+		ExpectedCash: shared.Cents(13000),
+		Waiters:      []queries.WaiterReconciliation{{Waiter: "Jenkins", Tabs: 1, Takings: shared.Cents(3000), Tips: shared.Cents(0), ExpectedCash: shared.Cents(3000), CountedCash: &counted, Discrepancy: &discrepancy}},
+	}})
+
+	// When
+	suite.readService.shiftReconciliationsHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), string("200 OK"), rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":true,\"error\":\"\",\"data\":[{\"station\":\"terrace\",\"shift\":1,\"open\":true,\"opened_at\":\"2024-03-01T17:00:00Z\","+
+		"\"opening_float\":{\"amount\":10000,\"currency\":\"EUR\"},\"takings\":{\"amount\":3000,\"currency\":\"EUR\"},\"tips\":{\"amount\":0,\"currency\":\"EUR\"},\"expected_cash\":{\"amount\":13000,\"currency\":\"EUR\"},"+
+		"\"waiters\":[{\"waiter\":\"Jenkins\",\"tabs\":1,\"takings\":{\"amount\":3000,\"currency\":\"EUR\"},\"tips\":{\"amount\":0,\"currency\":\"EUR\"},\"expected_cash\":{\"amount\":3000,\"currency\":\"EUR\"},"+
+		"\"counted_cash\":{\"amount\":2500,\"currency\":\"EUR\"},\"discrepancy\":{\"amount\":-500,\"currency\":\"EUR\"}}]}]}", string(bytes))
 }
 
 func (suite *ReadServiceTestSuite) SetupTest() {
 	suite.openTabQueries = *queries_mocks.NewOpenTabQueries(suite.T())
-	suite.readService = CreateReadService(1235, &suite.openTabQueries, nil)
+	suite.chefTodoList = *queries_mocks.NewChefTodoListQueries(suite.T())
+	suite.menuCatalogue = *queries_mocks.NewMenuCatalogueQueries(suite.T())
+	suite.stockQueries = *queries_mocks.NewStockQueries(suite.T())
+	suite.shiftQueries = *queries_mocks.NewShiftQueries(suite.T())
+	suite.readService = CreateReadService(1235, &suite.openTabQueries, &suite.chefTodoList, &suite.menuCatalogue, &suite.menuCatalogue, &suite.stockQueries, &suite.shiftQueries)
 }
 
 func TestReadServiceTestSuite(t *testing.T) {
@@ -0,0 +1,65 @@
+package shared
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExpectedPrice is what a client showed for a menu item when the order was taken, the price, the menu version or both.
+type ExpectedPrice struct {
+	MenuNumber int    `json:"menu_number"`
+	Price      *Money `json:"price,omitempty"`
+	Version    int    `json:"version,omitempty"`
+}
+
+// PriceMismatch tells a client what a menu item costs now, next to what it expected.
+type PriceMismatch struct {
+	MenuNumber      int    `json:"menu_number"`
+	Description     string `json:"description"`
+	ExpectedPrice   *Money `json:"expected_price,omitempty"`
+	ExpectedVersion int    `json:"expected_version,omitempty"`
+	CurrentPrice    Money  `json:"current_price"`
+	CurrentVersion  int    `json:"current_version"`
+}
+
+type PriceMismatchError struct {
+	Mismatches []PriceMismatch
+}
+
+func (e *PriceMismatchError) Error() string {
+	menuNumbers := make([]string, 0, len(e.Mismatches))
+	for _, mismatch := range e.Mismatches {
+		menuNumbers = append(menuNumbers, fmt.Sprint(mismatch.MenuNumber))
+	}
+	return fmt.Sprintf("prices changed for menu items: %s", strings.Join(menuNumbers, ", "))
+}
+
+// CheckExpectedPrices returns a PriceMismatchError if any of the items no longer has the price or version expected for it.
+// Items without an expectation are not checked.
+func CheckExpectedPrices(items []MenuItem, expectedPrices []ExpectedPrice) error {
+	mismatches := []PriceMismatch{}
+	for _, expected := range expectedPrices {
+		for _, item := range items {
+			if item.ID != expected.MenuNumber {
+				continue
+			}
+			priceChanged := expected.Price != nil && *expected.Price != item.Price
+			versionChanged := expected.Version != 0 && expected.Version != item.Version
+			if priceChanged || versionChanged {
+				mismatches = append(mismatches, PriceMismatch{
+					MenuNumber:      item.ID,
+					Description:     item.Description,
+					ExpectedPrice:   expected.Price,
+					ExpectedVersion: expected.Version,
+					CurrentPrice:    item.Price,
+					CurrentVersion:  item.Version,
+				})
+			}
+			break
+		}
+	}
+	if len(mismatches) > 0 {
+		return &PriceMismatchError{Mismatches: mismatches}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package shared_test
+
+import (
+	"cqrseventsourcingbar/shared"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItemsWithoutExpectationsAreNotChecked(t *testing.T) {
+	items := []shared.MenuItem{{ID: 1, Description: "blue water", Price: shared.Cents(120), Version: 2}}
+
+	assert.NoError(t, shared.CheckExpectedPrices(items, nil))
+	assert.NoError(t, shared.CheckExpectedPrices(items, []shared.ExpectedPrice{{MenuNumber: 2, Version: 1}}))
+}
+
+func TestExpectedPricesAndVersionsMustMatch(t *testing.T) {
+	// Given
+	shownPrice := shared.Cents(100)
+	friesPrice := shared.Cents(300)
+	items := []shared.MenuItem{
+		{ID: 1, Description: "blue water", Price: shared.Cents(120), Version: 2},
+		{ID: 1, Description: "blue water", Price: shared.Cents(120), Version: 2},
+		{ID: 4, Description: "burger", Price: shared.Cents(800), Version: 3},
+		{ID: 5, Description: "fries", Price: shared.Cents(300), Version: 1},
+	}
+
+	// When
+	err := shared.CheckExpectedPrices(items, []shared.ExpectedPrice{
+		{MenuNumber: 1, Price: &shownPrice},
+		{MenuNumber: 4, Version: 2},
+		{MenuNumber: 5, Price: &friesPrice, Version: 1},
+	})
+
+	// Then
+	assert.EqualError(t, err, "prices changed for menu items: 1, 4")
+	assert.Equal(t, &shared.PriceMismatchError{Mismatches: []shared.PriceMismatch{
+		{MenuNumber: 1, Description: "blue water", ExpectedPrice: &shownPrice, CurrentPrice: shared.Cents(120), CurrentVersion: 2},
+		{MenuNumber: 4, Description: "burger", ExpectedVersion: 2, CurrentPrice: shared.Cents(800), CurrentVersion: 3},
+	}}, err)
+}
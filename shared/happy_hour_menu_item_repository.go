@@ -0,0 +1,80 @@
+package shared
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// HappyHourRule takes a percentage off the listed menu items while the time of day is between From and Until.
+// A window whose Until is before its From runs past midnight.
+type HappyHourRule struct {
+	From        time.Duration
+	Until       time.Duration
+	MenuNumbers []int
+	Percentage  float64
+}
+
+func (r HappyHourRule) appliesTo(item MenuItem, now time.Time) bool {
+	if !slices.Contains(r.MenuNumbers, item.ID) {
+		return false
+	}
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	timeOfDay := now.Sub(midnight)
+	if r.From <= r.Until {
+		return timeOfDay >= r.From && timeOfDay < r.Until
+	}
+	return timeOfDay >= r.From || timeOfDay < r.Until
+}
+
+// DefaultHappyHourRules make the drinks half price from 5pm to 7pm.
+var DefaultHappyHourRules = []HappyHourRule{
+	{From: 17 * time.Hour, Until: 19 * time.Hour, MenuNumbers: []int{1, 2, 3}, Percentage: 50},
+}
+
+// happyHourMenuItemRepository prices the items read from the menu with the happy hour rules in force when they are read,
+// so an order placed during happy hour records the reduced prices in its events.
+type happyHourMenuItemRepository struct {
+	menuItemRepository MenuItemRepository
+	rules              []HappyHourRule
+	now                func() time.Time
+}
+
+func (h *happyHourMenuItemRepository) ReadItems(ctx context.Context, menuItems []int) ([]MenuItem, error) {
+	items, err := h.menuItemRepository.ReadItems(ctx, menuItems)
+	if err != nil {
+		return nil, err
+	}
+	return h.applyRules(items), nil
+}
+
+func (h *happyHourMenuItemRepository) ReadAllItems(ctx context.Context) ([]MenuItem, error) {
+	items, err := h.menuItemRepository.ReadAllItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return h.applyRules(items), nil
+}
+
+func (h *happyHourMenuItemRepository) applyRules(items []MenuItem) []MenuItem {
+	now := h.now()
+	pricedItems := make([]MenuItem, 0, len(items))
+	for _, item := range items {
+		for _, rule := range h.rules {
+			if rule.appliesTo(item, now) {
+				item.Price = ApplyDiscounts(item.Price, []Discount{{Percentage: rule.Percentage}})
+				break
+			}
+		}
+		pricedItems = append(pricedItems, item)
+	}
+	return pricedItems
+}
+
+func CreateHappyHourMenuItemRepository(menuItemRepository MenuItemRepository, rules []HappyHourRule, now func() time.Time) MenuItemRepository {
+	return &happyHourMenuItemRepository{
+		menuItemRepository: menuItemRepository,
+		rules:              rules,
+		now:                now,
+	}
+}
@@ -0,0 +1,65 @@
+package shared_test
+
+import (
+	"context"
+	"cqrseventsourcingbar/shared"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var happyHourRules = []shared.HappyHourRule{
+	{From: 17 * time.Hour, Until: 19 * time.Hour, MenuNumbers: []int{1}, Percentage: 50},
+	{From: 23 * time.Hour, Until: 1 * time.Hour, MenuNumbers: []int{2}, Percentage: 25},
+}
+
+func clockAt(hour int, minute int) func() time.Time {
+	return func() time.Time {
+		return time.Date(2024, time.March, 1, hour, minute, 0, 0, time.UTC)
+	}
+}
+
+func TestHappyHourMenuItemRepositoryDiscountsItemsDuringTheWindow(t *testing.T) {
+	// Given
+	menuItemRepository := shared.CreateHappyHourMenuItemRepository(shared.CreateInMemoryMenuItemRepository(inMemoryMenu), happyHourRules, clockAt(17, 30))
+
+	// When
+	items, err := menuItemRepository.ReadItems(context.TODO(), []int{1, 2})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []shared.MenuItem{
+		{ID: 1, Description: "blue water", Price: shared.Cents(50)},
+		{ID: 2, Description: "red water", Price: shared.Cents(200)},
+	}, items)
+}
+
+func TestHappyHourMenuItemRepositoryKeepsRegularPricesOutsideTheWindow(t *testing.T) {
+	// Given
+	menuItemRepository := shared.CreateHappyHourMenuItemRepository(shared.CreateInMemoryMenuItemRepository(inMemoryMenu), happyHourRules, clockAt(19, 0))
+
+	// When
+	items, err := menuItemRepository.ReadAllItems(context.TODO())
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, inMemoryMenu, items)
+}
+
+func TestHappyHourMenuItemRepositorySupportsWindowsPastMidnight(t *testing.T) {
+	// Given
+	menuItemRepository := shared.CreateHappyHourMenuItemRepository(shared.CreateInMemoryMenuItemRepository(inMemoryMenu), happyHourRules, clockAt(0, 15))
+
+	// When
+	items, err := menuItemRepository.ReadItems(context.TODO(), []int{2})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []shared.MenuItem{{ID: 2, Description: "red water", Price: shared.Cents(150)}}, items)
+}
+
+func TestApplyDiscountsTakesPercentagesBeforeFixedAmounts(t *testing.T) {
+	assert.Equal(t, shared.Cents(700), shared.ApplyDiscounts(shared.Cents(1000), []shared.Discount{{Amount: shared.Cents(200)}, {Percentage: 10}}))
+	assert.Equal(t, shared.Cents(0), shared.ApplyDiscounts(shared.Cents(1000), []shared.Discount{{Amount: shared.Cents(2000)}}))
+}
@@ -0,0 +1,31 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+type inMemoryMenuItemRepository struct {
+	items []MenuItem
+}
+
+func (r *inMemoryMenuItemRepository) ReadAllItems(_ context.Context) ([]MenuItem, error) {
+	return slices.Clone(r.items), nil
+}
+
+func (r *inMemoryMenuItemRepository) ReadItems(_ context.Context, menuItems []int) ([]MenuItem, error) {
+	orderedItems := []MenuItem{}
+	for _, id := range slices.Sorted(slices.Values(menuItems)) {
+		index := slices.IndexFunc(r.items, func(item MenuItem) bool { return item.ID == id })
+		if index < 0 {
+			return nil, fmt.Errorf("couldn't find menu item: %d", id)
+		}
+		orderedItems = append(orderedItems, r.items[index])
+	}
+	return orderedItems, nil
+}
+
+func CreateInMemoryMenuItemRepository(items []MenuItem) MenuItemRepository {
+	return &inMemoryMenuItemRepository{items: slices.Clone(items)}
+}
@@ -0,0 +1,52 @@
+package shared_test
+
+import (
+	"context"
+	"cqrseventsourcingbar/shared"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var inMemoryMenu = []shared.MenuItem{
+	{ID: 1, Description: "blue water", Price: shared.Cents(100)},
+	{ID: 2, Description: "red water", Price: shared.Cents(200)},
+}
+
+func TestInMemoryMenuItemRepositoryReadItemsShouldReturnRepeated(t *testing.T) {
+	// Given
+	menuItemRepository := shared.CreateInMemoryMenuItemRepository(inMemoryMenu)
+
+	// When
+	items, err := menuItemRepository.ReadItems(context.TODO(), []int{2, 1, 2})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []shared.MenuItem{inMemoryMenu[0], inMemoryMenu[1], inMemoryMenu[1]}, items)
+}
+
+func TestInMemoryMenuItemRepositoryReadItemsShouldFailForUnknownItem(t *testing.T) {
+	// Given
+	menuItemRepository := shared.CreateInMemoryMenuItemRepository(inMemoryMenu)
+
+	// When
+	items, err := menuItemRepository.ReadItems(context.TODO(), []int{1, 4})
+
+	// Then
+	assert.Empty(t, items)
+	if assert.Error(t, err) {
+		assert.Equal(t, "couldn't find menu item: 4", err.Error())
+	}
+}
+
+func TestInMemoryMenuItemRepositoryReadAllItems(t *testing.T) {
+	// Given
+	menuItemRepository := shared.CreateInMemoryMenuItemRepository(inMemoryMenu)
+
+	// When
+	items, err := menuItemRepository.ReadAllItems(context.TODO())
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, inMemoryMenu, items)
+}
@@ -9,7 +9,17 @@ type MenuItemRepository interface {
 }
 
 type MenuItem struct {
-	ID          int     `json:"id"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
+	ID          int                `json:"id"`
+	Description string             `json:"description"`
+	Price       Money              `json:"price"`
+	IsDrink     bool               `json:"is_drink"`
+	Category    string             `json:"category,omitempty"`
+	Version     int                `json:"version,omitempty"` // changes made to the item on the event sourced menu, zero elsewhere
+	Recipe      []RecipeIngredient `json:"recipe,omitempty"`
+}
+
+// RecipeIngredient is how much of an ingredient one serving of a menu item uses, in the unit the ingredient is stocked in.
+type RecipeIngredient struct {
+	Ingredient string `json:"ingredient"`
+	Quantity   int    `json:"quantity"`
 }
@@ -0,0 +1,47 @@
+// Code generated by mockery v2.49.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	shared "cqrseventsourcingbar/shared"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// StockChecker is an autogenerated mock type for the StockChecker type
+type StockChecker struct {
+	mock.Mock
+}
+
+// CheckStock provides a mock function with given fields: ctx, items
+func (_m *StockChecker) CheckStock(ctx context.Context, items []shared.MenuItem) error {
+	ret := _m.Called(ctx, items)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckStock")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []shared.MenuItem) error); ok {
+		r0 = rf(ctx, items)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewStockChecker creates a new instance of StockChecker. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewStockChecker(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *StockChecker {
+	mock := &StockChecker{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
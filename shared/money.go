@@ -0,0 +1,168 @@
+package shared
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const DefaultCurrency = "EUR"
+
+// Money is an exact amount in the minor units of its currency, cents for euros.
+// The zero Money takes the currency of whatever it is added to, so it can be used to start a sum.
+// Add, Sub and LessThan panic with a CurrencyMismatchError when the amounts are in different currencies, callers
+// taking amounts from outside check them with CheckCurrency first.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Cents is an amount in minor units of the default currency.
+func Cents(amount int64) Money {
+	return NewMoney(amount, DefaultCurrency)
+}
+
+// MoneyFromFloat converts an amount in major units of the default currency, rounded to the nearest cent.
+func MoneyFromFloat(amount float64) Money {
+	return Cents(int64(math.Round(amount * 100)))
+}
+
+// ParseMoney reads an amount in major units of the default currency, like "12", "12.5" or "12.50", without going through a float.
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "-")
+	whole, fraction, _ := strings.Cut(strings.TrimPrefix(s, "-"), ".")
+	if whole == "" || len(fraction) > 2 || strings.HasPrefix(fraction, "+") || strings.HasPrefix(fraction, "-") {
+		return Money{}, fmt.Errorf("could not read money from: %s", s)
+	}
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("could not read money from: %s", s)
+	}
+	cents := int64(0)
+	if fraction != "" {
+		cents, err = strconv.ParseInt(fraction+strings.Repeat("0", 2-len(fraction)), 10, 64)
+		if err != nil {
+			return Money{}, fmt.Errorf("could not read money from: %s", s)
+		}
+	}
+	amount := units*100 + cents
+	if negative {
+		amount = -amount
+	}
+	return Cents(amount), nil
+}
+
+type CurrencyMismatchError struct {
+	Currency      string
+	OtherCurrency string
+}
+
+func (e *CurrencyMismatchError) Error() string {
+	return fmt.Sprintf("cannot combine amounts in %s and %s", e.Currency, e.OtherCurrency)
+}
+
+// CheckCurrency returns a CurrencyMismatchError if the other amount is in a different currency, an amount without a
+// currency goes with any other.
+func (m Money) CheckCurrency(other Money) error {
+	if m.Currency != "" && other.Currency != "" && m.Currency != other.Currency {
+		return &CurrencyMismatchError{Currency: m.Currency, OtherCurrency: other.Currency}
+	}
+	return nil
+}
+
+func (m Money) currencyWith(other Money) string {
+	if err := m.CheckCurrency(other); err != nil {
+		panic(err)
+	}
+	if m.Currency == "" {
+		return other.Currency
+	}
+	return m.Currency
+}
+
+func (m Money) Add(other Money) Money {
+	return NewMoney(m.Amount+other.Amount, m.currencyWith(other))
+}
+
+func (m Money) Sub(other Money) Money {
+	return NewMoney(m.Amount-other.Amount, m.currencyWith(other))
+}
+
+func (m Money) Times(n int) Money {
+	return NewMoney(m.Amount*int64(n), m.Currency)
+}
+
+// Percent returns the percentage of the amount, rounded half away from zero to the minor unit.
+func (m Money) Percent(percentage float64) Money {
+	return NewMoney(int64(math.Round(float64(m.Amount)*percentage/100)), m.Currency)
+}
+
+// Split divides the amount in n shares that add up to it, the first shares take the cents left over.
+func (m Money) Split(n int) []Money {
+	shares := make([]Money, 0, n)
+	share := m.Amount / int64(n)
+	remainder := m.Amount % int64(n)
+	for i := 0; i < n; i++ {
+		amount := share
+		if int64(i) < remainder {
+			amount++
+		}
+		shares = append(shares, NewMoney(amount, m.Currency))
+	}
+	return shares
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+func (m Money) LessThan(other Money) bool {
+	m.currencyWith(other)
+	return m.Amount < other.Amount
+}
+
+// Decimal formats the amount in major units, like "12.50".
+func (m Money) Decimal() string {
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
+
+func (m Money) String() string {
+	return strings.TrimSpace(fmt.Sprintf("%s %s", m.Decimal(), m.Currency))
+}
+
+// UnmarshalJSON also reads the plain numbers of major units that snapshots and requests held before
+// money was kept in minor units, so those are read in the default currency.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] != '{' && string(data) != "null" {
+		var legacy float64
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return fmt.Errorf("could not read money from: %s", data)
+		}
+		*m = MoneyFromFloat(legacy)
+		return nil
+	}
+	type plainMoney Money
+	var money plainMoney
+	if err := json.Unmarshal(data, &money); err != nil {
+		return err
+	}
+	*m = Money(money)
+	return nil
+}
@@ -0,0 +1,90 @@
+package shared_test
+
+import (
+	"cqrseventsourcingbar/shared"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoneyAddsWithoutRoundingErrors(t *testing.T) {
+	// Given
+	total := shared.Money{}
+
+	// When
+	for i := 0; i < 10; i++ {
+		total = total.Add(shared.Cents(10))
+	}
+
+	// Then
+	assert.Equal(t, shared.Cents(100), total)
+	assert.Equal(t, "1.00 EUR", total.String())
+}
+
+func TestMoneyIsReadFromLegacyFloatsAndFromObjects(t *testing.T) {
+	// Given
+	var item struct {
+		Legacy  shared.Money `json:"legacy"`
+		Current shared.Money `json:"current"`
+	}
+
+	// When
+	err := json.Unmarshal([]byte(`{"legacy":0.3,"current":{"amount":250,"currency":"GBP"}}`), &item)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, shared.Cents(30), item.Legacy)
+	assert.Equal(t, shared.NewMoney(250, "GBP"), item.Current)
+}
+
+func TestMoneyIsWrittenInMinorUnits(t *testing.T) {
+	// When
+	bytes, err := json.Marshal(shared.Cents(150))
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, `{"amount":150,"currency":"EUR"}`, string(bytes))
+}
+
+func TestParseMoney(t *testing.T) {
+	for input, expected := range map[string]shared.Money{
+		"12":    shared.Cents(1200),
+		"12.5":  shared.Cents(1250),
+		"0.05":  shared.Cents(5),
+		"-1.20": shared.Cents(-120),
+	} {
+		money, err := shared.ParseMoney(input)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, money, input)
+	}
+
+	_, err := shared.ParseMoney("1.005")
+	assert.EqualError(t, err, "could not read money from: 1.005")
+}
+
+func TestMoneySplitAddsUpToTheAmount(t *testing.T) {
+	assert.Equal(t, []shared.Money{shared.Cents(334), shared.Cents(333), shared.Cents(333)}, shared.Cents(1000).Split(3))
+}
+
+func TestMoneyPercentRoundsToTheMinorUnit(t *testing.T) {
+	assert.Equal(t, shared.Cents(13), shared.Cents(125).Percent(10))
+}
+
+func TestMoneyRefusesToCombineCurrencies(t *testing.T) {
+	// Given
+	euros := shared.Cents(100)
+	pounds := shared.NewMoney(100, "GBP")
+
+	// When
+	err := euros.CheckCurrency(pounds)
+
+	// Then
+	assert.Equal(t, &shared.CurrencyMismatchError{Currency: "EUR", OtherCurrency: "GBP"}, err)
+	assert.EqualError(t, err, "cannot combine amounts in EUR and GBP")
+	assert.NoError(t, shared.Money{}.CheckCurrency(pounds))
+	assert.PanicsWithError(t, "cannot combine amounts in EUR and GBP", func() { euros.Add(pounds) })
+	assert.PanicsWithError(t, "cannot combine amounts in GBP and EUR", func() { pounds.Sub(euros) })
+	assert.PanicsWithError(t, "cannot combine amounts in EUR and GBP", func() { euros.LessThan(pounds) })
+	assert.Equal(t, pounds, shared.Money{}.Add(pounds))
+}
@@ -14,7 +14,7 @@ type postgresMenuItemRepository struct {
 }
 
 func (p *postgresMenuItemRepository) ReadAllItems(ctx context.Context) ([]MenuItem, error) {
-	rows, err := p.conn.Query(ctx, "SELECT id, description, price FROM menu_item ORDER by id")
+	rows, err := p.conn.Query(ctx, "SELECT id, description, price, currency, is_drink FROM menu_item ORDER by id")
 
 	if err != nil {
 		return nil, err
@@ -25,14 +25,17 @@ func (p *postgresMenuItemRepository) ReadAllItems(ctx context.Context) ([]MenuIt
 	for rows.Next() {
 		var id int
 		var description string
-		var price float64
-		if err := rows.Scan(&id, &description, &price); err != nil {
+		var price int64
+		var currency string
+		var isDrink bool
+		if err := rows.Scan(&id, &description, &price, &currency, &isDrink); err != nil {
 			return nil, err
 		}
 		allItems = append(allItems, MenuItem{
 			ID:          id,
 			Description: description,
-			Price:       price,
+			Price:       NewMoney(price, currency),
+			IsDrink:     isDrink,
 		})
 	}
 
@@ -43,7 +46,7 @@ func (p *postgresMenuItemRepository) ReadItems(ctx context.Context, menuItems []
 	slices.Sort(menuItems)
 	originalItems := slices.Clone(menuItems)
 	uniqueItems := slices.Compact(menuItems)
-	rows, err := p.conn.Query(ctx, "SELECT id, description, price FROM menu_item WHERE id = any($1)", uniqueItems)
+	rows, err := p.conn.Query(ctx, "SELECT id, description, price, currency, is_drink FROM menu_item WHERE id = any($1)", uniqueItems)
 
 	if err != nil {
 		return nil, err
@@ -54,14 +57,17 @@ func (p *postgresMenuItemRepository) ReadItems(ctx context.Context, menuItems []
 	for rows.Next() {
 		var id int
 		var description string
-		var price float64
-		if err := rows.Scan(&id, &description, &price); err != nil {
+		var price int64
+		var currency string
+		var isDrink bool
+		if err := rows.Scan(&id, &description, &price, &currency, &isDrink); err != nil {
 			return nil, err
 		}
 		retrievedItems[id] = MenuItem{
 			ID:          id,
 			Description: description,
-			Price:       price,
+			Price:       NewMoney(price, currency),
+			IsDrink:     isDrink,
 		}
 	}
 
@@ -40,17 +40,20 @@ func (suite *PostgresMenuItemRepositoryTestSuite) TestReadItems() {
 		{
 			ID:          1,
 			Description: "blue water",
-			Price:       1.0,
+			Price:       shared.Cents(100),
+			IsDrink:     true,
 		},
 		{
 			ID:          2,
 			Description: "red water",
-			Price:       2.0,
+			Price:       shared.Cents(200),
+			IsDrink:     true,
 		},
 		{
 			ID:          3,
 			Description: "green water",
-			Price:       3.0,
+			Price:       shared.Cents(300),
+			IsDrink:     true,
 		},
 	}, items)
 }
@@ -66,22 +69,26 @@ func (suite *PostgresMenuItemRepositoryTestSuite) TestReadItemsShouldReturnRepea
 		{
 			ID:          1,
 			Description: "blue water",
-			Price:       1.0,
+			Price:       shared.Cents(100),
+			IsDrink:     true,
 		},
 		{
 			ID:          1,
 			Description: "blue water",
-			Price:       1.0,
+			Price:       shared.Cents(100),
+			IsDrink:     true,
 		},
 		{
 			ID:          2,
 			Description: "red water",
-			Price:       2.0,
+			Price:       shared.Cents(200),
+			IsDrink:     true,
 		},
 		{
 			ID:          3,
 			Description: "green water",
-			Price:       3.0,
+			Price:       shared.Cents(300),
+			IsDrink:     true,
 		},
 	}, items)
 }
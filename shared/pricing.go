@@ -0,0 +1,22 @@
+package shared
+
+// Discount takes either a percentage or a fixed amount off a bill.
+type Discount struct {
+	Percentage float64 `json:"percentage"`
+	Amount     Money   `json:"amount"`
+}
+
+// ApplyDiscounts takes the percentage discounts off the amount first and then the fixed ones, never going below zero.
+func ApplyDiscounts(amount Money, discounts []Discount) Money {
+	percentage := 0.0
+	fixed := Money{}
+	for _, discount := range discounts {
+		percentage += discount.Percentage
+		fixed = fixed.Add(discount.Amount)
+	}
+	discounted := amount.Sub(amount.Percent(percentage)).Sub(fixed)
+	if discounted.Amount < 0 {
+		return NewMoney(0, amount.Currency)
+	}
+	return discounted
+}
@@ -0,0 +1,32 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+//go:generate mockery --name StockChecker
+type StockChecker interface {
+	CheckStock(ctx context.Context, items []MenuItem) error
+}
+
+type StockShortage struct {
+	MenuNumber  int    `json:"menu_number"`
+	Description string `json:"description"`
+	Requested   int    `json:"requested"`
+	Available   int    `json:"available"`
+}
+
+// InsufficientStockError lists the items of an order there is not enough stock available for.
+type InsufficientStockError struct {
+	Shortages []StockShortage
+}
+
+func (e *InsufficientStockError) Error() string {
+	menuNumbers := make([]string, 0, len(e.Shortages))
+	for _, shortage := range e.Shortages {
+		menuNumbers = append(menuNumbers, fmt.Sprint(shortage.MenuNumber))
+	}
+	return fmt.Sprintf("not enough stock for menu items: %s", strings.Join(menuNumbers, ", "))
+}
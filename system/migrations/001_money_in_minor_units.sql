@@ -0,0 +1,49 @@
+-- Converts the menu and the open tabs read model of a database created before money was kept in minor units:
+-- prices and amounts go from major units in double precision to BIGINT cents, in the default currency.
+-- Only columns still in double precision are converted, so running the script again changes nothing, and the open
+-- tabs tables are left alone in a database that does not have them yet.
+BEGIN;
+
+DO $$
+BEGIN
+    IF (SELECT data_type FROM information_schema.columns WHERE table_name = 'menu_item' AND column_name = 'price') = 'double precision' THEN
+        ALTER TABLE menu_item
+            ALTER COLUMN price TYPE BIGINT USING ROUND(price * 100)::BIGINT,
+            ALTER COLUMN price SET NOT NULL;
+    END IF;
+    IF (SELECT data_type FROM information_schema.columns WHERE table_name = 'open_tab_to_serve' AND column_name = 'price') = 'double precision' THEN
+        ALTER TABLE open_tab_to_serve
+            ALTER COLUMN price TYPE BIGINT USING ROUND(price * 100)::BIGINT,
+            ALTER COLUMN price SET NOT NULL;
+    END IF;
+    IF (SELECT data_type FROM information_schema.columns WHERE table_name = 'open_tab_in_preparation' AND column_name = 'price') = 'double precision' THEN
+        ALTER TABLE open_tab_in_preparation
+            ALTER COLUMN price TYPE BIGINT USING ROUND(price * 100)::BIGINT,
+            ALTER COLUMN price SET NOT NULL;
+    END IF;
+    IF (SELECT data_type FROM information_schema.columns WHERE table_name = 'open_tab_served' AND column_name = 'price') = 'double precision' THEN
+        ALTER TABLE open_tab_served
+            ALTER COLUMN price TYPE BIGINT USING ROUND(price * 100)::BIGINT,
+            ALTER COLUMN price SET NOT NULL;
+    END IF;
+    IF (SELECT data_type FROM information_schema.columns WHERE table_name = 'open_tab_adjustment' AND column_name = 'amount') = 'double precision' THEN
+        ALTER TABLE open_tab_adjustment
+            ALTER COLUMN amount DROP DEFAULT,
+            ALTER COLUMN amount TYPE BIGINT USING ROUND(amount * 100)::BIGINT,
+            ALTER COLUMN amount SET DEFAULT 0;
+    END IF;
+    IF (SELECT data_type FROM information_schema.columns WHERE table_name = 'open_tab_payment' AND column_name = 'amount') = 'double precision' THEN
+        ALTER TABLE open_tab_payment
+            ALTER COLUMN amount TYPE BIGINT USING ROUND(amount * 100)::BIGINT;
+    END IF;
+END
+$$;
+
+ALTER TABLE menu_item ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'EUR';
+ALTER TABLE IF EXISTS open_tab_to_serve ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'EUR';
+ALTER TABLE IF EXISTS open_tab_in_preparation ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'EUR';
+ALTER TABLE IF EXISTS open_tab_served ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'EUR';
+ALTER TABLE IF EXISTS open_tab_adjustment ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'EUR';
+ALTER TABLE IF EXISTS open_tab_payment ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'EUR';
+
+COMMIT;
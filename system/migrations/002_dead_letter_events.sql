@@ -0,0 +1,20 @@
+-- Adds the dead letter table to a database created before events of unknown types could be dead lettered, and keeps
+-- a single row per event in one that already has it, as the same event is dead lettered every time it is read.
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS dead_letter_events (
+    id BIGSERIAL PRIMARY KEY,
+    aggregate_id VARCHAR(28) NOT NULL,
+    sequence_number INT NOT NULL,
+    event_type VARCHAR(512) NOT NULL,
+    schema_version INT NOT NULL,
+    payload JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+DELETE FROM dead_letter_events duplicate USING dead_letter_events kept
+    WHERE duplicate.aggregate_id = kept.aggregate_id AND duplicate.sequence_number = kept.sequence_number AND duplicate.id > kept.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS dead_letter_events_aggregate_id_sequence_number_key ON dead_letter_events (aggregate_id, sequence_number);
+
+COMMIT;
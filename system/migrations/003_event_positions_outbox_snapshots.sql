@@ -0,0 +1,48 @@
+-- Adds the global event position, the snapshots, the outbox and the projection checkpoints to a database created
+-- before them. The events already stored are numbered in the order they were saved in.
+BEGIN;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'events' AND column_name = 'position') THEN
+        ALTER TABLE events ADD COLUMN position BIGINT;
+        UPDATE events SET position = numbered.position
+            FROM (SELECT aggregate_id, sequence_number, ROW_NUMBER() OVER (ORDER BY timestamp, aggregate_id, sequence_number) AS position FROM events) numbered
+            WHERE events.aggregate_id = numbered.aggregate_id AND events.sequence_number = numbered.sequence_number;
+        CREATE SEQUENCE events_position_seq OWNED BY events.position;
+        PERFORM setval('events_position_seq', COALESCE((SELECT MAX(position) FROM events), 0) + 1, false);
+        ALTER TABLE events
+            ALTER COLUMN position SET DEFAULT nextval('events_position_seq'),
+            ALTER COLUMN position SET NOT NULL,
+            ADD UNIQUE (position);
+    END IF;
+END
+$$;
+
+CREATE TABLE IF NOT EXISTS snapshots (
+    aggregate_id VARCHAR(28),
+    version INT NOT NULL,
+    timestamp TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    state JSONB,
+    PRIMARY KEY (aggregate_id)
+);
+
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGSERIAL PRIMARY KEY,
+    aggregate_id VARCHAR(28) NOT NULL,
+    sequence_number INT NOT NULL,
+    event_type VARCHAR(512) NOT NULL,
+    payload JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    sent_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending ON outbox (id) WHERE sent_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS projection_checkpoint (
+    name VARCHAR(128),
+    position BIGINT NOT NULL,
+    PRIMARY KEY (name)
+);
+
+COMMIT;
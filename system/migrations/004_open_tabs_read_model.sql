@@ -0,0 +1,67 @@
+-- Rebuilds the Postgres open tabs read model at its current definition: the tables are dropped and created again and
+-- its checkpoint is removed, so the read service replays the event log into it on its next start.
+BEGIN;
+
+DROP TABLE IF EXISTS open_tab_to_serve, open_tab_in_preparation, open_tab_served, open_tab_adjustment, open_tab_payment, open_tab;
+
+CREATE TABLE open_tab (
+    tab_id VARCHAR(28),
+    table_number INT NOT NULL,
+    waiter VARCHAR(512) NOT NULL,
+    PRIMARY KEY (tab_id)
+);
+
+CREATE TABLE open_tab_to_serve (
+    id BIGSERIAL PRIMARY KEY,
+    tab_id VARCHAR(28) NOT NULL REFERENCES open_tab (tab_id) ON DELETE CASCADE,
+    menu_number INT NOT NULL,
+    description VARCHAR(512) NOT NULL,
+    price BIGINT NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT 'EUR',
+    is_drink BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE TABLE open_tab_in_preparation (
+    id BIGSERIAL PRIMARY KEY,
+    tab_id VARCHAR(28) NOT NULL REFERENCES open_tab (tab_id) ON DELETE CASCADE,
+    menu_number INT NOT NULL,
+    description VARCHAR(512) NOT NULL,
+    price BIGINT NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT 'EUR',
+    is_drink BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE open_tab_served (
+    id BIGSERIAL PRIMARY KEY,
+    tab_id VARCHAR(28) NOT NULL REFERENCES open_tab (tab_id) ON DELETE CASCADE,
+    menu_number INT NOT NULL,
+    description VARCHAR(512) NOT NULL,
+    price BIGINT NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT 'EUR',
+    is_drink BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE TABLE open_tab_adjustment (
+    id BIGSERIAL PRIMARY KEY,
+    tab_id VARCHAR(28) NOT NULL REFERENCES open_tab (tab_id) ON DELETE CASCADE,
+    menu_number INT,
+    description VARCHAR(512) NOT NULL DEFAULT '',
+    percentage double precision NOT NULL DEFAULT 0,
+    amount BIGINT NOT NULL DEFAULT 0,
+    currency VARCHAR(3) NOT NULL DEFAULT 'EUR',
+    reason VARCHAR(512) NOT NULL,
+    authorised_by VARCHAR(512) NOT NULL
+);
+
+CREATE TABLE open_tab_payment (
+    id BIGSERIAL PRIMARY KEY,
+    tab_id VARCHAR(28) NOT NULL REFERENCES open_tab (tab_id) ON DELETE CASCADE,
+    method VARCHAR(64) NOT NULL,
+    amount BIGINT NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT 'EUR',
+    payer VARCHAR(512) NOT NULL DEFAULT ''
+);
+
+DELETE FROM projection_checkpoint WHERE name = 'open_tabs';
+
+COMMIT;
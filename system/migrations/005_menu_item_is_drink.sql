@@ -0,0 +1,3 @@
+-- Adds the drink or food flag to the menu of a database created before food could be ordered, the items already on
+-- the menu were all drinks.
+ALTER TABLE menu_item ADD COLUMN IF NOT EXISTS is_drink BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,8 @@
+-- Adds the schema version to the events and the outbox of a database created before event schemas were versioned,
+-- the payloads already stored are the first version of their schema.
+BEGIN;
+
+ALTER TABLE events ADD COLUMN IF NOT EXISTS schema_version INT NOT NULL DEFAULT 1;
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS schema_version INT NOT NULL DEFAULT 1;
+
+COMMIT;
@@ -0,0 +1,20 @@
+-- Adds the metadata columns to the events and the outbox of a database created before events carried causation,
+-- correlation and actor metadata, and the indexes the events are looked up by metadata with.
+BEGIN;
+
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS causation_id VARCHAR(28) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS correlation_id VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS actor VARCHAR(255) NOT NULL DEFAULT '';
+
+ALTER TABLE outbox
+    ADD COLUMN IF NOT EXISTS timestamp TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    ADD COLUMN IF NOT EXISTS causation_id VARCHAR(28) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS correlation_id VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS actor VARCHAR(255) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS events_correlation_id ON events (correlation_id);
+CREATE INDEX IF NOT EXISTS events_actor ON events (actor);
+CREATE INDEX IF NOT EXISTS events_timestamp ON events (timestamp);
+
+COMMIT;
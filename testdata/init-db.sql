@@ -3,10 +3,19 @@ CREATE TABLE events (
     sequence_number INT NOT NULL,
     timestamp TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
     event_type VARCHAR(512) NOT NULL,
+    schema_version INT NOT NULL DEFAULT 1,
     payload JSONB,
+    causation_id VARCHAR(28) NOT NULL DEFAULT '',
+    correlation_id VARCHAR(255) NOT NULL DEFAULT '',
+    actor VARCHAR(255) NOT NULL DEFAULT '',
+    position BIGSERIAL UNIQUE,
     PRIMARY KEY (aggregate_id, sequence_number)
 );
 
+CREATE INDEX events_correlation_id ON events (correlation_id);
+CREATE INDEX events_actor ON events (actor);
+CREATE INDEX events_timestamp ON events (timestamp);
+
 INSERT INTO events(aggregate_id, sequence_number, event_type, payload) VALUES ('2qPTBJCN6ib7iJ6WaIVvoSmySSV', 1, 'TabOpened', '{"id":"2qPTBJCN6ib7iJ6WaIVvoSmySSV","table_number":1,"waiter":"w1"}');
 INSERT INTO events(aggregate_id, sequence_number, event_type, payload) VALUES ('2qPTBJCN6ib7iJ6WaIVvoSmySSV', 2, 'DrinksOrdered', '{"id":"2qPTBJCN6ib7iJ6WaIVvoSmySSV","items":[{"id":1,"description":"water","price":1.5}]}');
 INSERT INTO events(aggregate_id, sequence_number, event_type, payload) VALUES ('1qPTBJCN6ib7iJ6WaIVvoSmySSV', 1, 'TabOpened', '{"id":"1qPTBJCN6ib7iJ6WaIVvoSmySSV","table_number":2,"waiter":"w2"}');
@@ -14,10 +23,112 @@ INSERT INTO events(aggregate_id, sequence_number, event_type, payload) VALUES ('
 CREATE TABLE menu_item (
     id INT NOT NULL,
     description VARCHAR(512) NOT NULL,
-    price double precision,
+    price BIGINT NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT 'EUR',
+    is_drink BOOLEAN NOT NULL DEFAULT TRUE,
     PRIMARY KEY (id)
 );
 
-INSERT INTO menu_item(id, description, price) VALUES (1, 'blue water', 1.0);
-INSERT INTO menu_item(id, description, price) VALUES (2, 'red water', 2.0);
-INSERT INTO menu_item(id, description, price) VALUES (3, 'green water', 3.0);
\ No newline at end of file
+INSERT INTO menu_item(id, description, price) VALUES (1, 'blue water', 100);
+INSERT INTO menu_item(id, description, price) VALUES (2, 'red water', 200);
+INSERT INTO menu_item(id, description, price) VALUES (3, 'green water', 300);
+
+CREATE TABLE snapshots (
+    aggregate_id VARCHAR(28),
+    version INT NOT NULL,
+    timestamp TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    state JSONB,
+    PRIMARY KEY (aggregate_id)
+);
+
+CREATE TABLE outbox (
+    id BIGSERIAL PRIMARY KEY,
+    aggregate_id VARCHAR(28) NOT NULL,
+    sequence_number INT NOT NULL,
+    event_type VARCHAR(512) NOT NULL,
+    schema_version INT NOT NULL DEFAULT 1,
+    payload JSONB,
+    timestamp TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    causation_id VARCHAR(28) NOT NULL DEFAULT '',
+    correlation_id VARCHAR(255) NOT NULL DEFAULT '',
+    actor VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    sent_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX outbox_pending ON outbox (id) WHERE sent_at IS NULL;
+
+CREATE TABLE dead_letter_events (
+    id BIGSERIAL PRIMARY KEY,
+    aggregate_id VARCHAR(28) NOT NULL,
+    sequence_number INT NOT NULL,
+    event_type VARCHAR(512) NOT NULL,
+    schema_version INT NOT NULL,
+    payload JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (aggregate_id, sequence_number)
+);
+
+CREATE TABLE open_tab (
+    tab_id VARCHAR(28),
+    table_number INT NOT NULL,
+    waiter VARCHAR(512) NOT NULL,
+    PRIMARY KEY (tab_id)
+);
+
+CREATE TABLE open_tab_to_serve (
+    id BIGSERIAL PRIMARY KEY,
+    tab_id VARCHAR(28) NOT NULL REFERENCES open_tab (tab_id) ON DELETE CASCADE,
+    menu_number INT NOT NULL,
+    description VARCHAR(512) NOT NULL,
+    price BIGINT NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT 'EUR',
+    is_drink BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE TABLE open_tab_in_preparation (
+    id BIGSERIAL PRIMARY KEY,
+    tab_id VARCHAR(28) NOT NULL REFERENCES open_tab (tab_id) ON DELETE CASCADE,
+    menu_number INT NOT NULL,
+    description VARCHAR(512) NOT NULL,
+    price BIGINT NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT 'EUR',
+    is_drink BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE open_tab_served (
+    id BIGSERIAL PRIMARY KEY,
+    tab_id VARCHAR(28) NOT NULL REFERENCES open_tab (tab_id) ON DELETE CASCADE,
+    menu_number INT NOT NULL,
+    description VARCHAR(512) NOT NULL,
+    price BIGINT NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT 'EUR',
+    is_drink BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE TABLE open_tab_adjustment (
+    id BIGSERIAL PRIMARY KEY,
+    tab_id VARCHAR(28) NOT NULL REFERENCES open_tab (tab_id) ON DELETE CASCADE,
+    menu_number INT,
+    description VARCHAR(512) NOT NULL DEFAULT '',
+    percentage double precision NOT NULL DEFAULT 0,
+    amount BIGINT NOT NULL DEFAULT 0,
+    currency VARCHAR(3) NOT NULL DEFAULT 'EUR',
+    reason VARCHAR(512) NOT NULL,
+    authorised_by VARCHAR(512) NOT NULL
+);
+
+CREATE TABLE open_tab_payment (
+    id BIGSERIAL PRIMARY KEY,
+    tab_id VARCHAR(28) NOT NULL REFERENCES open_tab (tab_id) ON DELETE CASCADE,
+    method VARCHAR(64) NOT NULL,
+    amount BIGINT NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT 'EUR',
+    payer VARCHAR(512) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE projection_checkpoint (
+    name VARCHAR(128),
+    position BIGINT NOT NULL,
+    PRIMARY KEY (name)
+);
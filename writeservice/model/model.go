@@ -1,13 +1,22 @@
 package model
 
+import "cqrseventsourcingbar/shared"
+
+// The headers naming the request a command is part of and who is acting, the events it causes keep them as metadata.
+const (
+	CorrelationIDHeader = "X-Correlation-ID"
+	ActorHeader         = "X-Actor"
+)
+
 type OpenTabRequest struct {
 	TableNumber int    `json:"table_number"`
 	Waiter      string `json:"waiter"`
 }
 
 type PlaceOrderRequest struct {
-	TabId     string `json:"tab_id"`
-	MenuItems []int  `json:"menu_items"`
+	TabId          string                 `json:"tab_id"`
+	MenuItems      []int                  `json:"menu_items"`
+	ExpectedPrices []shared.ExpectedPrice `json:"expected_prices,omitempty"`
 }
 
 type MarkDrinksServedRequest struct {
@@ -15,12 +24,124 @@ type MarkDrinksServedRequest struct {
 	MenuNumbers []int  `json:"menu_numbers"`
 }
 
+type MarkFoodPreparedRequest struct {
+	TabId       string `json:"tab_id"`
+	MenuNumbers []int  `json:"menu_numbers"`
+}
+
+type MarkFoodServedRequest struct {
+	TabId       string `json:"tab_id"`
+	MenuNumbers []int  `json:"menu_numbers"`
+}
+
+type CancelItemsRequest struct {
+	TabId       string `json:"tab_id"`
+	MenuNumbers []int  `json:"menu_numbers"`
+	Reason      string `json:"reason"`
+}
+
+type ApplyDiscountRequest struct {
+	TabId        string       `json:"tab_id"`
+	Percentage   float64      `json:"percentage"`
+	Amount       shared.Money `json:"amount"`
+	Reason       string       `json:"reason"`
+	AuthorisedBy string       `json:"authorised_by"`
+}
+
+type CompItemRequest struct {
+	TabId        string `json:"tab_id"`
+	MenuNumber   int    `json:"menu_number"`
+	Reason       string `json:"reason"`
+	AuthorisedBy string `json:"authorised_by"`
+}
+
+type RecordPaymentRequest struct {
+	TabId   string       `json:"tab_id"`
+	Method  string       `json:"method"`
+	Amount  shared.Money `json:"amount"`
+	Payer   string       `json:"payer"`
+	Station string       `json:"station,omitempty"`
+}
+
 type CloseTabRequest struct {
-	TabId      string  `json:"tab_id"`
-	AmountPaid float64 `json:"amount_paid"`
+	TabId      string       `json:"tab_id"`
+	AmountPaid shared.Money `json:"amount_paid"`
+	Station    string       `json:"station,omitempty"`
+}
+
+type OpenShiftRequest struct {
+	Station      string       `json:"station"`
+	OpeningFloat shared.Money `json:"opening_float"`
+}
+
+type CloseShiftRequest struct {
+	Station      string                  `json:"station"`
+	CountedCash  shared.Money            `json:"counted_cash"`
+	WaiterCounts map[string]shared.Money `json:"waiter_counts,omitempty"`
+}
+
+type AddMenuItemRequest struct {
+	MenuNumber  int          `json:"menu_number"`
+	Description string       `json:"description"`
+	Price       shared.Money `json:"price"`
+	IsDrink     bool         `json:"is_drink"`
+	Category    string       `json:"category"`
+}
+
+type ChangeMenuItemPriceRequest struct {
+	MenuNumber int          `json:"menu_number"`
+	Price      shared.Money `json:"price"`
+}
+
+type RenameMenuItemRequest struct {
+	MenuNumber  int    `json:"menu_number"`
+	Description string `json:"description"`
+}
+
+type CategoriseMenuItemRequest struct {
+	MenuNumber int    `json:"menu_number"`
+	Category   string `json:"category"`
+}
+
+type SetMenuItemRecipeRequest struct {
+	MenuNumber int                       `json:"menu_number"`
+	Recipe     []shared.RecipeIngredient `json:"recipe"`
+}
+
+type RetireMenuItemRequest struct {
+	MenuNumber int `json:"menu_number"`
+}
+
+type RestockRequest struct {
+	MenuNumber int `json:"menu_number"`
+	Quantity   int `json:"quantity"`
+}
+
+type CountStockRequest struct {
+	MenuNumber int `json:"menu_number"`
+	Quantity   int `json:"quantity"`
+}
+
+type SetReorderLevelRequest struct {
+	MenuNumber int `json:"menu_number"`
+	Level      int `json:"level"`
+}
+
+type RestockIngredientRequest struct {
+	Ingredient    string `json:"ingredient"`
+	Quantity      int    `json:"quantity"`
+	Unit          string `json:"unit"`
+	ContainerSize int    `json:"container_size"`
+}
+
+type CountIngredientRequest struct {
+	Ingredient string `json:"ingredient"`
+	Quantity   int    `json:"quantity"`
 }
 
 type CommandReponse struct {
-	OK    bool   `json:"ok"`
-	Error string `json:"error"`
+	OK              bool                   `json:"ok"`
+	Error           string                 `json:"error"`
+	PriceMismatches []shared.PriceMismatch `json:"price_mismatches,omitempty"`
+	StockShortages  []shared.StockShortage `json:"stock_shortages,omitempty"`
 }
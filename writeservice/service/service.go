@@ -2,9 +2,11 @@ package service
 
 import (
 	"cqrseventsourcingbar/commands"
+	"cqrseventsourcingbar/events"
 	"cqrseventsourcingbar/shared"
 	"cqrseventsourcingbar/writeservice/model"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -18,29 +20,68 @@ type WriteService struct {
 	serveMux           *http.ServeMux
 	menuItemRepository shared.MenuItemRepository
 	commandDispatcher  commands.CommandDispatcher
+	menuDispatcher     commands.CommandDispatcher
+	stockDispatcher    commands.CommandDispatcher
+	stockChecker       shared.StockChecker
 }
 
-func CreateWriteService(port int, menuItemRepository shared.MenuItemRepository, commandDispatcher commands.CommandDispatcher) *WriteService {
+func CreateWriteService(port int, menuItemRepository shared.MenuItemRepository, commandDispatcher commands.CommandDispatcher, menuDispatcher commands.CommandDispatcher, stockDispatcher commands.CommandDispatcher, stockChecker shared.StockChecker) *WriteService {
 	srv := &WriteService{
 		menuItemRepository: menuItemRepository,
 		commandDispatcher:  commandDispatcher,
+		menuDispatcher:     menuDispatcher,
+		stockDispatcher:    stockDispatcher,
+		stockChecker:       stockChecker,
 	}
 
 	srv.serveMux = http.NewServeMux()
 	srv.serveMux.HandleFunc("/openTab", srv.openTabHandler)
 	srv.serveMux.HandleFunc("/placeOrder", srv.placeOrderHandler)
 	srv.serveMux.HandleFunc("/markDrinksServed", srv.markDrinksServedHandler)
+	srv.serveMux.HandleFunc("/markFoodPrepared", srv.markFoodPreparedHandler)
+	srv.serveMux.HandleFunc("/markFoodServed", srv.markFoodServedHandler)
+	srv.serveMux.HandleFunc("/cancelItems", srv.cancelItemsHandler)
+	srv.serveMux.HandleFunc("/applyDiscount", srv.applyDiscountHandler)
+	srv.serveMux.HandleFunc("/compItem", srv.compItemHandler)
+	srv.serveMux.HandleFunc("/recordPayment", srv.recordPaymentHandler)
 	srv.serveMux.HandleFunc("/closeTab", srv.closeTabHandler)
+	srv.serveMux.HandleFunc("/openShift", srv.openShiftHandler)
+	srv.serveMux.HandleFunc("/closeShift", srv.closeShiftHandler)
+	srv.serveMux.HandleFunc("/addMenuItem", srv.addMenuItemHandler)
+	srv.serveMux.HandleFunc("/changeMenuItemPrice", srv.changeMenuItemPriceHandler)
+	srv.serveMux.HandleFunc("/renameMenuItem", srv.renameMenuItemHandler)
+	srv.serveMux.HandleFunc("/categoriseMenuItem", srv.categoriseMenuItemHandler)
+	srv.serveMux.HandleFunc("/setMenuItemRecipe", srv.setMenuItemRecipeHandler)
+	srv.serveMux.HandleFunc("/retireMenuItem", srv.retireMenuItemHandler)
+	srv.serveMux.HandleFunc("/restock", srv.restockHandler)
+	srv.serveMux.HandleFunc("/countStock", srv.countStockHandler)
+	srv.serveMux.HandleFunc("/setReorderLevel", srv.setReorderLevelHandler)
+	srv.serveMux.HandleFunc("/restockIngredient", srv.restockIngredientHandler)
+	srv.serveMux.HandleFunc("/countIngredient", srv.countIngredientHandler)
 
 	srv.httpServer = &http.Server{
 		Addr: fmt.Sprintf(":%d", port),
 	}
 
-	srv.httpServer.Handler = srv.serveMux
+	srv.httpServer.Handler = withRequestMetadata(srv.serveMux)
 
 	return srv
 }
 
+// withRequestMetadata passes the correlation ID and the actor of a request on to the events it causes.
+// A request without a correlation ID gets a new one, which is sent back with the response.
+func withRequestMetadata(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		correlationID := r.Header.Get(model.CorrelationIDHeader)
+		if correlationID == "" {
+			correlationID = ksuid.New().String()
+		}
+		w.Header().Set(model.CorrelationIDHeader, correlationID)
+		ctx := events.ContextWithMetadata(r.Context(), events.Metadata{CorrelationID: correlationID, Actor: r.Header.Get(model.ActorHeader)})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 func (ws *WriteService) Start() error {
 	slog.Info(fmt.Sprintf("Write server listening on%s", ws.httpServer.Addr))
 
@@ -61,7 +102,7 @@ func (ws *WriteService) openTabHandler(w http.ResponseWriter, r *http.Request) {
 	})
 
 	if err != nil {
-		returnJsonError(w, fmt.Sprintf("Error processing openTab request: %v", err), http.StatusInternalServerError)
+		returnJsonError(w, fmt.Sprintf("Error processing openTab request: %v", err), statusForDispatchError(err))
 		return
 	}
 
@@ -89,13 +130,39 @@ func (ws *WriteService) placeOrderHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// A client that showed other prices than the ones the order would be recorded with has to confirm them first.
+	var priceMismatch *shared.PriceMismatchError
+	if errors.As(shared.CheckExpectedPrices(orderedItems, request.ExpectedPrices), &priceMismatch) {
+		returnJsonErrorResponse(w, http.StatusConflict, model.CommandReponse{
+			OK:              false,
+			Error:           priceMismatch.Error(),
+			PriceMismatches: priceMismatch.Mismatches,
+		})
+		return
+	}
+
+	var insufficientStock *shared.InsufficientStockError
+	err = ws.stockChecker.CheckStock(r.Context(), orderedItems)
+	if errors.As(err, &insufficientStock) {
+		returnJsonErrorResponse(w, http.StatusConflict, model.CommandReponse{
+			OK:             false,
+			Error:          insufficientStock.Error(),
+			StockShortages: insufficientStock.Shortages,
+		})
+		return
+	}
+	if err != nil {
+		returnJsonError(w, fmt.Sprintf("could not check stock: %v", err), http.StatusInternalServerError)
+		return
+	}
+
 	err = ws.commandDispatcher.DispatchCommand(r.Context(), commands.PlaceOrder{
 		BaseCommand: commands.BaseCommand{ID: id},
 		Items:       orderedItems,
 	})
 
 	if err != nil {
-		returnJsonError(w, fmt.Sprintf("Error processing placeOrder request: %v", err), http.StatusInternalServerError)
+		returnJsonError(w, fmt.Sprintf("Error processing placeOrder request: %v", err), statusForDispatchError(err))
 		return
 	}
 
@@ -122,7 +189,178 @@ func (ws *WriteService) markDrinksServedHandler(w http.ResponseWriter, r *http.R
 	})
 
 	if err != nil {
-		returnJsonError(w, fmt.Sprintf("Error processing markDrinksServed request: %v", err), http.StatusInternalServerError)
+		returnJsonError(w, fmt.Sprintf("Error processing markDrinksServed request: %v", err), statusForDispatchError(err))
+		return
+	}
+
+	returnJsonOk(w)
+}
+
+func (ws *WriteService) markFoodPreparedHandler(w http.ResponseWriter, r *http.Request) {
+	var request model.MarkFoodPreparedRequest
+	shouldReturn := readRequest(w, r, &request)
+	if shouldReturn {
+		return
+	}
+
+	id, err := ksuid.Parse(request.TabId)
+
+	if err != nil {
+		returnJsonError(w, "could not parse id", http.StatusBadRequest)
+		return
+	}
+
+	err = ws.commandDispatcher.DispatchCommand(r.Context(), commands.MarkFoodPrepared{
+		BaseCommand: commands.BaseCommand{ID: id},
+		MenuNumbers: request.MenuNumbers,
+	})
+
+	if err != nil {
+		returnJsonError(w, fmt.Sprintf("Error processing markFoodPrepared request: %v", err), statusForDispatchError(err))
+		return
+	}
+
+	returnJsonOk(w)
+}
+
+func (ws *WriteService) markFoodServedHandler(w http.ResponseWriter, r *http.Request) {
+	var request model.MarkFoodServedRequest
+	shouldReturn := readRequest(w, r, &request)
+	if shouldReturn {
+		return
+	}
+
+	id, err := ksuid.Parse(request.TabId)
+
+	if err != nil {
+		returnJsonError(w, "could not parse id", http.StatusBadRequest)
+		return
+	}
+
+	err = ws.commandDispatcher.DispatchCommand(r.Context(), commands.MarkFoodServed{
+		BaseCommand: commands.BaseCommand{ID: id},
+		MenuNumbers: request.MenuNumbers,
+	})
+
+	if err != nil {
+		returnJsonError(w, fmt.Sprintf("Error processing markFoodServed request: %v", err), statusForDispatchError(err))
+		return
+	}
+
+	returnJsonOk(w)
+}
+
+func (ws *WriteService) cancelItemsHandler(w http.ResponseWriter, r *http.Request) {
+	var request model.CancelItemsRequest
+	shouldReturn := readRequest(w, r, &request)
+	if shouldReturn {
+		return
+	}
+
+	id, err := ksuid.Parse(request.TabId)
+
+	if err != nil {
+		returnJsonError(w, "could not parse id", http.StatusBadRequest)
+		return
+	}
+
+	err = ws.commandDispatcher.DispatchCommand(r.Context(), commands.CancelItems{
+		BaseCommand: commands.BaseCommand{ID: id},
+		MenuNumbers: request.MenuNumbers,
+		Reason:      request.Reason,
+	})
+
+	if err != nil {
+		returnJsonError(w, fmt.Sprintf("Error processing cancelItems request: %v", err), statusForDispatchError(err))
+		return
+	}
+
+	returnJsonOk(w)
+}
+
+func (ws *WriteService) applyDiscountHandler(w http.ResponseWriter, r *http.Request) {
+	var request model.ApplyDiscountRequest
+	shouldReturn := readRequest(w, r, &request)
+	if shouldReturn {
+		return
+	}
+
+	id, err := ksuid.Parse(request.TabId)
+
+	if err != nil {
+		returnJsonError(w, "could not parse id", http.StatusBadRequest)
+		return
+	}
+
+	err = ws.commandDispatcher.DispatchCommand(r.Context(), commands.ApplyDiscount{
+		BaseCommand:  commands.BaseCommand{ID: id},
+		Percentage:   request.Percentage,
+		Amount:       request.Amount,
+		Reason:       request.Reason,
+		AuthorisedBy: request.AuthorisedBy,
+	})
+
+	if err != nil {
+		returnJsonError(w, fmt.Sprintf("Error processing applyDiscount request: %v", err), statusForDispatchError(err))
+		return
+	}
+
+	returnJsonOk(w)
+}
+
+func (ws *WriteService) compItemHandler(w http.ResponseWriter, r *http.Request) {
+	var request model.CompItemRequest
+	shouldReturn := readRequest(w, r, &request)
+	if shouldReturn {
+		return
+	}
+
+	id, err := ksuid.Parse(request.TabId)
+
+	if err != nil {
+		returnJsonError(w, "could not parse id", http.StatusBadRequest)
+		return
+	}
+
+	err = ws.commandDispatcher.DispatchCommand(r.Context(), commands.CompItem{
+		BaseCommand:  commands.BaseCommand{ID: id},
+		MenuNumber:   request.MenuNumber,
+		Reason:       request.Reason,
+		AuthorisedBy: request.AuthorisedBy,
+	})
+
+	if err != nil {
+		returnJsonError(w, fmt.Sprintf("Error processing compItem request: %v", err), statusForDispatchError(err))
+		return
+	}
+
+	returnJsonOk(w)
+}
+
+func (ws *WriteService) recordPaymentHandler(w http.ResponseWriter, r *http.Request) {
+	var request model.RecordPaymentRequest
+	shouldReturn := readRequest(w, r, &request)
+	if shouldReturn {
+		return
+	}
+
+	id, err := ksuid.Parse(request.TabId)
+
+	if err != nil {
+		returnJsonError(w, "could not parse id", http.StatusBadRequest)
+		return
+	}
+
+	err = ws.commandDispatcher.DispatchCommand(r.Context(), commands.RecordPayment{
+		BaseCommand: commands.BaseCommand{ID: id},
+		Method:      request.Method,
+		Amount:      request.Amount,
+		Payer:       request.Payer,
+		Station:     request.Station,
+	})
+
+	if err != nil {
+		returnJsonError(w, fmt.Sprintf("Error processing recordPayment request: %v", err), statusForDispatchError(err))
 		return
 	}
 
@@ -146,16 +384,316 @@ func (ws *WriteService) closeTabHandler(w http.ResponseWriter, r *http.Request)
 	err = ws.commandDispatcher.DispatchCommand(r.Context(), commands.CloseTab{
 		BaseCommand: commands.BaseCommand{ID: id},
 		AmountPaid:  request.AmountPaid,
+		Station:     request.Station,
 	})
 
 	if err != nil {
-		returnJsonError(w, fmt.Sprintf("Error processing closeTab request: %v", err), http.StatusInternalServerError)
+		returnJsonError(w, fmt.Sprintf("Error processing closeTab request: %v", err), statusForDispatchError(err))
 		return
 	}
 
 	returnJsonOk(w)
 }
 
+func (ws *WriteService) openShiftHandler(w http.ResponseWriter, r *http.Request) {
+	var request model.OpenShiftRequest
+	shouldReturn := readRequest(w, r, &request)
+	if shouldReturn {
+		return
+	}
+
+	err := ws.commandDispatcher.DispatchCommand(r.Context(), commands.OpenShift{
+		BaseCommand:  commands.BaseCommand{ID: commands.StationID(request.Station)},
+		Station:      request.Station,
+		OpeningFloat: request.OpeningFloat,
+	})
+
+	if err != nil {
+		returnJsonError(w, fmt.Sprintf("Error processing openShift request: %v", err), statusForDispatchError(err))
+		return
+	}
+
+	returnJsonOk(w)
+}
+
+func (ws *WriteService) closeShiftHandler(w http.ResponseWriter, r *http.Request) {
+	var request model.CloseShiftRequest
+	shouldReturn := readRequest(w, r, &request)
+	if shouldReturn {
+		return
+	}
+
+	err := ws.commandDispatcher.DispatchCommand(r.Context(), commands.CloseShift{
+		BaseCommand:  commands.BaseCommand{ID: commands.StationID(request.Station)},
+		Station:      request.Station,
+		CountedCash:  request.CountedCash,
+		WaiterCounts: request.WaiterCounts,
+	})
+
+	if err != nil {
+		returnJsonError(w, fmt.Sprintf("Error processing closeShift request: %v", err), statusForDispatchError(err))
+		return
+	}
+
+	returnJsonOk(w)
+}
+
+func (ws *WriteService) addMenuItemHandler(w http.ResponseWriter, r *http.Request) {
+	var request model.AddMenuItemRequest
+	shouldReturn := readRequest(w, r, &request)
+	if shouldReturn {
+		return
+	}
+
+	err := ws.menuDispatcher.DispatchCommand(r.Context(), commands.AddMenuItem{
+		BaseCommand: commands.BaseCommand{ID: commands.MenuItemID(request.MenuNumber)},
+		MenuNumber:  request.MenuNumber,
+		Description: request.Description,
+		Price:       request.Price,
+		IsDrink:     request.IsDrink,
+		Category:    request.Category,
+	})
+
+	if err != nil {
+		returnJsonError(w, fmt.Sprintf("Error processing addMenuItem request: %v", err), statusForDispatchError(err))
+		return
+	}
+
+	returnJsonOk(w)
+}
+
+func (ws *WriteService) changeMenuItemPriceHandler(w http.ResponseWriter, r *http.Request) {
+	var request model.ChangeMenuItemPriceRequest
+	shouldReturn := readRequest(w, r, &request)
+	if shouldReturn {
+		return
+	}
+
+	err := ws.menuDispatcher.DispatchCommand(r.Context(), commands.ChangeMenuItemPrice{
+		BaseCommand: commands.BaseCommand{ID: commands.MenuItemID(request.MenuNumber)},
+		MenuNumber:  request.MenuNumber,
+		Price:       request.Price,
+	})
+
+	if err != nil {
+		returnJsonError(w, fmt.Sprintf("Error processing changeMenuItemPrice request: %v", err), statusForDispatchError(err))
+		return
+	}
+
+	returnJsonOk(w)
+}
+
+func (ws *WriteService) renameMenuItemHandler(w http.ResponseWriter, r *http.Request) {
+	var request model.RenameMenuItemRequest
+	shouldReturn := readRequest(w, r, &request)
+	if shouldReturn {
+		return
+	}
+
+	err := ws.menuDispatcher.DispatchCommand(r.Context(), commands.RenameMenuItem{
+		BaseCommand: commands.BaseCommand{ID: commands.MenuItemID(request.MenuNumber)},
+		MenuNumber:  request.MenuNumber,
+		Description: request.Description,
+	})
+
+	if err != nil {
+		returnJsonError(w, fmt.Sprintf("Error processing renameMenuItem request: %v", err), statusForDispatchError(err))
+		return
+	}
+
+	returnJsonOk(w)
+}
+
+func (ws *WriteService) categoriseMenuItemHandler(w http.ResponseWriter, r *http.Request) {
+	var request model.CategoriseMenuItemRequest
+	shouldReturn := readRequest(w, r, &request)
+	if shouldReturn {
+		return
+	}
+
+	err := ws.menuDispatcher.DispatchCommand(r.Context(), commands.CategoriseMenuItem{
+		BaseCommand: commands.BaseCommand{ID: commands.MenuItemID(request.MenuNumber)},
+		MenuNumber:  request.MenuNumber,
+		Category:    request.Category,
+	})
+
+	if err != nil {
+		returnJsonError(w, fmt.Sprintf("Error processing categoriseMenuItem request: %v", err), statusForDispatchError(err))
+		return
+	}
+
+	returnJsonOk(w)
+}
+
+func (ws *WriteService) setMenuItemRecipeHandler(w http.ResponseWriter, r *http.Request) {
+	var request model.SetMenuItemRecipeRequest
+	shouldReturn := readRequest(w, r, &request)
+	if shouldReturn {
+		return
+	}
+
+	err := ws.menuDispatcher.DispatchCommand(r.Context(), commands.SetMenuItemRecipe{
+		BaseCommand: commands.BaseCommand{ID: commands.MenuItemID(request.MenuNumber)},
+		MenuNumber:  request.MenuNumber,
+		Recipe:      request.Recipe,
+	})
+
+	if err != nil {
+		returnJsonError(w, fmt.Sprintf("Error processing setMenuItemRecipe request: %v", err), statusForDispatchError(err))
+		return
+	}
+
+	returnJsonOk(w)
+}
+
+func (ws *WriteService) retireMenuItemHandler(w http.ResponseWriter, r *http.Request) {
+	var request model.RetireMenuItemRequest
+	shouldReturn := readRequest(w, r, &request)
+	if shouldReturn {
+		return
+	}
+
+	err := ws.menuDispatcher.DispatchCommand(r.Context(), commands.RetireMenuItem{
+		BaseCommand: commands.BaseCommand{ID: commands.MenuItemID(request.MenuNumber)},
+		MenuNumber:  request.MenuNumber,
+	})
+
+	if err != nil {
+		returnJsonError(w, fmt.Sprintf("Error processing retireMenuItem request: %v", err), statusForDispatchError(err))
+		return
+	}
+
+	returnJsonOk(w)
+}
+
+func (ws *WriteService) restockHandler(w http.ResponseWriter, r *http.Request) {
+	var request model.RestockRequest
+	shouldReturn := readRequest(w, r, &request)
+	if shouldReturn {
+		return
+	}
+
+	err := ws.stockDispatcher.DispatchCommand(r.Context(), commands.Restock{
+		BaseCommand: commands.BaseCommand{ID: commands.StockID(request.MenuNumber)},
+		MenuNumber:  request.MenuNumber,
+		Quantity:    request.Quantity,
+	})
+
+	if err != nil {
+		returnJsonError(w, fmt.Sprintf("Error processing restock request: %v", err), statusForDispatchError(err))
+		return
+	}
+
+	returnJsonOk(w)
+}
+
+func (ws *WriteService) countStockHandler(w http.ResponseWriter, r *http.Request) {
+	var request model.CountStockRequest
+	shouldReturn := readRequest(w, r, &request)
+	if shouldReturn {
+		return
+	}
+
+	err := ws.stockDispatcher.DispatchCommand(r.Context(), commands.CountStock{
+		BaseCommand: commands.BaseCommand{ID: commands.StockID(request.MenuNumber)},
+		MenuNumber:  request.MenuNumber,
+		Quantity:    request.Quantity,
+	})
+
+	if err != nil {
+		returnJsonError(w, fmt.Sprintf("Error processing countStock request: %v", err), statusForDispatchError(err))
+		return
+	}
+
+	returnJsonOk(w)
+}
+
+func (ws *WriteService) setReorderLevelHandler(w http.ResponseWriter, r *http.Request) {
+	var request model.SetReorderLevelRequest
+	shouldReturn := readRequest(w, r, &request)
+	if shouldReturn {
+		return
+	}
+
+	err := ws.stockDispatcher.DispatchCommand(r.Context(), commands.SetReorderLevel{
+		BaseCommand: commands.BaseCommand{ID: commands.StockID(request.MenuNumber)},
+		MenuNumber:  request.MenuNumber,
+		Level:       request.Level,
+	})
+
+	if err != nil {
+		returnJsonError(w, fmt.Sprintf("Error processing setReorderLevel request: %v", err), statusForDispatchError(err))
+		return
+	}
+
+	returnJsonOk(w)
+}
+
+func (ws *WriteService) restockIngredientHandler(w http.ResponseWriter, r *http.Request) {
+	var request model.RestockIngredientRequest
+	shouldReturn := readRequest(w, r, &request)
+	if shouldReturn {
+		return
+	}
+
+	err := ws.stockDispatcher.DispatchCommand(r.Context(), commands.RestockIngredient{
+		BaseCommand:   commands.BaseCommand{ID: commands.IngredientID(request.Ingredient)},
+		Ingredient:    request.Ingredient,
+		Quantity:      request.Quantity,
+		Unit:          request.Unit,
+		ContainerSize: request.ContainerSize,
+	})
+
+	if err != nil {
+		returnJsonError(w, fmt.Sprintf("Error processing restockIngredient request: %v", err), statusForDispatchError(err))
+		return
+	}
+
+	returnJsonOk(w)
+}
+
+func (ws *WriteService) countIngredientHandler(w http.ResponseWriter, r *http.Request) {
+	var request model.CountIngredientRequest
+	shouldReturn := readRequest(w, r, &request)
+	if shouldReturn {
+		return
+	}
+
+	err := ws.stockDispatcher.DispatchCommand(r.Context(), commands.CountIngredient{
+		BaseCommand: commands.BaseCommand{ID: commands.IngredientID(request.Ingredient)},
+		Ingredient:  request.Ingredient,
+		Quantity:    request.Quantity,
+	})
+
+	if err != nil {
+		returnJsonError(w, fmt.Sprintf("Error processing countIngredient request: %v", err), statusForDispatchError(err))
+		return
+	}
+
+	returnJsonOk(w)
+}
+
+func statusForDispatchError(err error) int {
+	var tableOccupied *commands.TableOccupiedError
+	if errors.Is(err, events.ErrConcurrencyConflict) || errors.As(err, &tableOccupied) {
+		return http.StatusConflict
+	}
+	if errors.Is(err, commands.ErrMenuItemAlreadyExists) {
+		return http.StatusConflict
+	}
+	if errors.Is(err, commands.ErrShiftAlreadyOpen) || errors.Is(err, commands.ErrNoShiftOpen) {
+		return http.StatusConflict
+	}
+	if errors.Is(err, commands.ErrMenuItemNotFound) {
+		return http.StatusNotFound
+	}
+	var currencyMismatch *shared.CurrencyMismatchError
+	if errors.As(err, &currencyMismatch) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
 func readRequest[T any](w http.ResponseWriter, r *http.Request, data *T) (errored bool) {
 	if r.Method != http.MethodPost {
 		returnJsonError(w, "Method Not Allowed", http.StatusMethodNotAllowed)
@@ -187,6 +725,13 @@ func readRequest[T any](w http.ResponseWriter, r *http.Request, data *T) (errore
 }
 
 func returnJsonError(w http.ResponseWriter, error string, code int) {
+	returnJsonErrorResponse(w, code, model.CommandReponse{
+		OK:    false,
+		Error: error,
+	})
+}
+
+func returnJsonErrorResponse(w http.ResponseWriter, code int, response model.CommandReponse) {
 	h := w.Header()
 
 	h.Del("Content-Length")
@@ -195,19 +740,15 @@ func returnJsonError(w http.ResponseWriter, error string, code int) {
 	h.Set("X-Content-Type-Options", "nosniff")
 	w.WriteHeader(code)
 
-	response := model.CommandReponse{
-		OK:    false,
-		Error: error,
-	}
 	jsonResponse, err := json.Marshal(response)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("error encoding json, original error: %s", error), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("error encoding json, original error: %s", response.Error), http.StatusInternalServerError)
 		return
 	}
 
 	_, err = w.Write(jsonResponse)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("error writing json response, original error: %s", error), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("error writing json response, original error: %s", response.Error), http.StatusInternalServerError)
 	}
 }
 
@@ -5,17 +5,20 @@ import (
 	"context"
 	"cqrseventsourcingbar/commands"
 	commands_mocks "cqrseventsourcingbar/commands/mocks"
+	"cqrseventsourcingbar/events"
 	"cqrseventsourcingbar/shared"
 	shared_mocks "cqrseventsourcingbar/shared/mocks"
 	"cqrseventsourcingbar/writeservice/model"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/segmentio/ksuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
@@ -25,6 +28,9 @@ type WriteServiceTestSuite struct {
 	suite.Suite
 	menuItemRepository *shared_mocks.MenuItemRepository
 	commandDispatcher  *commands_mocks.CommandDispatcher
+	menuDispatcher     *commands_mocks.CommandDispatcher
+	stockDispatcher    *commands_mocks.CommandDispatcher
+	stockChecker       *shared_mocks.StockChecker
 	writeService       *WriteService
 	ctx                context.Context
 }
@@ -102,6 +108,32 @@ func (suite *WriteServiceTestSuite) TestOpenTabHandlerReturnsErrorIfDispatcherRe
 	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"Error processing openTab request: error dispatching command\"}", string(bytes))
 }
 
+func (suite *WriteServiceTestSuite) TestOpenTabHandlerReturnsConflictIfTableAlreadyHasATab() {
+
+	// Given
+	openTabRequest := model.OpenTabRequest{
+		TableNumber: 3,
+		Waiter:      "w1",
+	}
+	json, err := json.Marshal(openTabRequest)
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	holderID, _ := ksuid.Parse("2qPTBJCN6ib7iJ6WaIVvoSmySSV")
+	suite.commandDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(fmt.Errorf("error handling command [ClaimTable], reason: %w", &commands.TableOccupiedError{TableNumber: 3, TabID: holderID}))
+
+	// When
+	suite.writeService.openTabHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "409 Conflict", rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"Error processing openTab request: error handling command [ClaimTable], reason: table 3 already has an open tab: 2qPTBJCN6ib7iJ6WaIVvoSmySSV\"}", string(bytes))
+}
+
 func (suite *WriteServiceTestSuite) TestOpenTabHandlerReturnsOkIfNoError() {
 
 	// Given
@@ -245,8 +277,9 @@ func (suite *WriteServiceTestSuite) TestPlaceOrderHandlerReturnsErrorIfDispatche
 	suite.menuItemRepository.On("ReadItems", suite.ctx, []int{1}).Return([]shared.MenuItem{{
 		ID:          1,
 		Description: "Blue water",
-		Price:       1.0,
+		Price:       shared.Cents(100),
 	}}, nil)
+	suite.stockChecker.On("CheckStock", suite.ctx, mock.Anything).Return(nil)
 	suite.commandDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(errors.New("error dispatching command"))
 
 	// When
@@ -259,6 +292,97 @@ func (suite *WriteServiceTestSuite) TestPlaceOrderHandlerReturnsErrorIfDispatche
 	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"Error processing placeOrder request: error dispatching command\"}", string(bytes))
 }
 
+func (suite *WriteServiceTestSuite) TestPlaceOrderHandlerRejectsStalePricesWithTheCurrentOnes() {
+
+	// Given
+	shownPrice := shared.Cents(100)
+	placeOrderRequest := model.PlaceOrderRequest{
+		TabId:          "2qPTBJCN6ib7iJ6WaIVvoSmySSV",
+		MenuItems:      []int{1, 2},
+		ExpectedPrices: []shared.ExpectedPrice{{MenuNumber: 1, Price: &shownPrice}, {MenuNumber: 2, Version: 1}},
+	}
+	json, err := json.Marshal(placeOrderRequest)
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	suite.menuItemRepository.On("ReadItems", suite.ctx, []int{1, 2}).Return([]shared.MenuItem{
+		{ID: 1, Description: "Blue water", Price: shared.Cents(120), IsDrink: true, Version: 2},
+		{ID: 2, Description: "Red water", Price: shared.Cents(200), IsDrink: true, Version: 1},
+	}, nil)
+
+	// When
+	suite.writeService.placeOrderHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "409 Conflict", rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"prices changed for menu items: 1\",\"price_mismatches\":[{\"menu_number\":1,\"description\":\"Blue water\",\"expected_price\":{\"amount\":100,\"currency\":\"EUR\"},\"current_price\":{\"amount\":120,\"currency\":\"EUR\"},\"current_version\":2}]}", string(bytes))
+	suite.commandDispatcher.AssertNotCalled(suite.T(), "DispatchCommand", mock.Anything, mock.Anything)
+}
+
+func (suite *WriteServiceTestSuite) TestPlaceOrderHandlerRecordsTheMenuVersionOfTheItems() {
+
+	// Given
+	placeOrderRequest := model.PlaceOrderRequest{
+		TabId:          "2qPTBJCN6ib7iJ6WaIVvoSmySSV",
+		MenuItems:      []int{1},
+		ExpectedPrices: []shared.ExpectedPrice{{MenuNumber: 1, Version: 2}},
+	}
+	json, err := json.Marshal(placeOrderRequest)
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	blueWater := shared.MenuItem{ID: 1, Description: "Blue water", Price: shared.Cents(120), IsDrink: true, Version: 2}
+	suite.menuItemRepository.On("ReadItems", suite.ctx, []int{1}).Return([]shared.MenuItem{blueWater}, nil)
+	suite.stockChecker.On("CheckStock", suite.ctx, []shared.MenuItem{blueWater}).Return(nil)
+	var capturedCommand commands.PlaceOrder
+	suite.commandDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		capturedCommand = args.Get(1).(commands.PlaceOrder)
+	})
+
+	// When
+	suite.writeService.placeOrderHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "200 OK", rr.Result().Status)
+	assert.Equal(suite.T(), []shared.MenuItem{blueWater}, capturedCommand.Items)
+}
+
+func (suite *WriteServiceTestSuite) TestPlaceOrderHandlerRejectsOrdersThereIsNoStockFor() {
+
+	// Given
+	placeOrderRequest := model.PlaceOrderRequest{
+		TabId:     "2qPTBJCN6ib7iJ6WaIVvoSmySSV",
+		MenuItems: []int{1, 1},
+	}
+	json, err := json.Marshal(placeOrderRequest)
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	blueWater := shared.MenuItem{ID: 1, Description: "Blue water", Price: shared.Cents(120), IsDrink: true}
+	suite.menuItemRepository.On("ReadItems", suite.ctx, []int{1, 1}).Return([]shared.MenuItem{blueWater, blueWater}, nil)
+	suite.stockChecker.On("CheckStock", suite.ctx, []shared.MenuItem{blueWater, blueWater}).Return(&shared.InsufficientStockError{Shortages: []shared.StockShortage{
+		{MenuNumber: 1, Description: "Blue water", Requested: 2, Available: 1},
+	}})
+
+	// When
+	suite.writeService.placeOrderHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "409 Conflict", rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"not enough stock for menu items: 1\",\"stock_shortages\":[{\"menu_number\":1,\"description\":\"Blue water\",\"requested\":2,\"available\":1}]}", string(bytes))
+	suite.commandDispatcher.AssertNotCalled(suite.T(), "DispatchCommand", mock.Anything, mock.Anything)
+}
+
 func (suite *WriteServiceTestSuite) TestMarkDrinksServedHandlerReturnsErrorIfNotPost() {
 	// Given
 	rr := httptest.NewRecorder()
@@ -355,6 +479,31 @@ func (suite *WriteServiceTestSuite) TestMarkDrinksServedHandlerReturnsErrorIfDis
 	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"Error processing markDrinksServed request: error dispatching command\"}", string(bytes))
 }
 
+func (suite *WriteServiceTestSuite) TestMarkDrinksServedHandlerReturnsConflictIfDispatcherReturnsConcurrencyConflict() {
+
+	// Given
+	markDrinksServedRequest := model.MarkDrinksServedRequest{
+		TabId:       "2qPTBJCN6ib7iJ6WaIVvoSmySSV",
+		MenuNumbers: []int{1},
+	}
+	json, err := json.Marshal(markDrinksServedRequest)
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	suite.commandDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(fmt.Errorf("error when saving events: %w", events.ErrConcurrencyConflict))
+
+	// When
+	suite.writeService.markDrinksServedHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "409 Conflict", rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"Error processing markDrinksServed request: error when saving events: concurrency conflict, the aggregate was changed by another writer\"}", string(bytes))
+}
+
 func (suite *WriteServiceTestSuite) TestMarkDrinksServedHandlerReturnsOkIfNoError() {
 
 	// Given
@@ -385,69 +534,178 @@ func (suite *WriteServiceTestSuite) TestMarkDrinksServedHandlerReturnsOkIfNoErro
 	assert.Equal(suite.T(), "2qPTBJCN6ib7iJ6WaIVvoSmySSV", capturedCommand.ID.String())
 }
 
-func (suite *WriteServiceTestSuite) TestCloseTabHandlerReturnsErrorIfNotPost() {
+func (suite *WriteServiceTestSuite) TestMarkFoodPreparedHandlerReturnsErrorIfCannotParseId() {
+
 	// Given
+	markFoodPreparedRequest := model.MarkFoodPreparedRequest{
+		TabId:       "?",
+		MenuNumbers: []int{4},
+	}
+	json, err := json.Marshal(markFoodPreparedRequest)
+	assert.NoError(suite.T(), err)
 	rr := httptest.NewRecorder()
-	request, err := http.NewRequest(http.MethodGet, "", nil)
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
 	assert.NoError(suite.T(), err)
 
 	// When
-	suite.writeService.closeTabHandler(rr, request)
+	suite.writeService.markFoodPreparedHandler(rr, request)
 
 	// Then
-	assert.Equal(suite.T(), "405 Method Not Allowed", rr.Result().Status)
+	assert.Equal(suite.T(), "400 Bad Request", rr.Result().Status)
 	bytes, err := io.ReadAll(rr.Result().Body)
 	assert.NoError(suite.T(), err)
-	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"Method Not Allowed\"}", string(bytes))
+	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"could not parse id\"}", string(bytes))
 }
-func (suite *WriteServiceTestSuite) TestCloseTabHandlerReturnsErrorIfEmptyBody() {
+
+func (suite *WriteServiceTestSuite) TestMarkFoodPreparedHandlerReturnsErrorIfDispatcherReturnsError() {
+
 	// Given
+	markFoodPreparedRequest := model.MarkFoodPreparedRequest{
+		TabId:       "2qPTBJCN6ib7iJ6WaIVvoSmySSV",
+		MenuNumbers: []int{4},
+	}
+	json, err := json.Marshal(markFoodPreparedRequest)
+	assert.NoError(suite.T(), err)
 	rr := httptest.NewRecorder()
-	request, err := http.NewRequest(http.MethodPost, "", nil)
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
 	assert.NoError(suite.T(), err)
 
+	suite.commandDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(errors.New("error dispatching command"))
+
 	// When
-	suite.writeService.closeTabHandler(rr, request)
+	suite.writeService.markFoodPreparedHandler(rr, request)
 
 	// Then
-	assert.Equal(suite.T(), "400 Bad Request", rr.Result().Status)
+	assert.Equal(suite.T(), "500 Internal Server Error", rr.Result().Status)
 	bytes, err := io.ReadAll(rr.Result().Body)
 	assert.NoError(suite.T(), err)
-	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"Empty body\"}", string(bytes))
+	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"Error processing markFoodPrepared request: error dispatching command\"}", string(bytes))
 }
 
-func (suite *WriteServiceTestSuite) TestCloseTabHandlerReturnsErrorIfInvalidJson() {
+func (suite *WriteServiceTestSuite) TestMarkFoodPreparedHandlerReturnsOkIfNoError() {
+
+	// Given
+	markFoodPreparedRequest := model.MarkFoodPreparedRequest{
+		TabId:       "2qPTBJCN6ib7iJ6WaIVvoSmySSV",
+		MenuNumbers: []int{4},
+	}
+	json, err := json.Marshal(markFoodPreparedRequest)
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	var capturedCommand commands.MarkFoodPrepared
+	suite.commandDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		capturedCommand = args.Get(1).(commands.MarkFoodPrepared)
+	})
+
+	// When
+	suite.writeService.markFoodPreparedHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "200 OK", rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":true,\"error\":\"\"}", string(bytes))
+	assert.Equal(suite.T(), []int{4}, capturedCommand.MenuNumbers)
+	assert.Equal(suite.T(), "2qPTBJCN6ib7iJ6WaIVvoSmySSV", capturedCommand.ID.String())
+}
+
+func (suite *WriteServiceTestSuite) TestMarkFoodServedHandlerReturnsErrorIfCannotParseId() {
 
 	// Given
+	markFoodServedRequest := model.MarkFoodServedRequest{
+		TabId:       "?",
+		MenuNumbers: []int{4},
+	}
+	json, err := json.Marshal(markFoodServedRequest)
+	assert.NoError(suite.T(), err)
 	rr := httptest.NewRecorder()
-	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader([]byte{1, 2, 3}))
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
 	assert.NoError(suite.T(), err)
 
 	// When
-	suite.writeService.closeTabHandler(rr, request)
+	suite.writeService.markFoodServedHandler(rr, request)
 
 	// Then
 	assert.Equal(suite.T(), "400 Bad Request", rr.Result().Status)
 	bytes, err := io.ReadAll(rr.Result().Body)
 	assert.NoError(suite.T(), err)
-	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"Invalid JSON request\"}", string(bytes))
+	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"could not parse id\"}", string(bytes))
 }
 
-func (suite *WriteServiceTestSuite) TestCloseTabHandlerReturnsErrorIfCannotParseId() {
+func (suite *WriteServiceTestSuite) TestMarkFoodServedHandlerReturnsErrorIfDispatcherReturnsError() {
 
 	// Given
-	closeTabRequest := model.CloseTabRequest{
-		TabId:      "?",
-		AmountPaid: 0.0,
+	markFoodServedRequest := model.MarkFoodServedRequest{
+		TabId:       "2qPTBJCN6ib7iJ6WaIVvoSmySSV",
+		MenuNumbers: []int{4},
 	}
-	json, err := json.Marshal(closeTabRequest)
+	json, err := json.Marshal(markFoodServedRequest)
 	assert.NoError(suite.T(), err)
 	rr := httptest.NewRecorder()
 	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
 	assert.NoError(suite.T(), err)
 
+	suite.commandDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(errors.New("error dispatching command"))
+
 	// When
-	suite.writeService.closeTabHandler(rr, request)
+	suite.writeService.markFoodServedHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "500 Internal Server Error", rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"Error processing markFoodServed request: error dispatching command\"}", string(bytes))
+}
+
+func (suite *WriteServiceTestSuite) TestMarkFoodServedHandlerReturnsOkIfNoError() {
+
+	// Given
+	markFoodServedRequest := model.MarkFoodServedRequest{
+		TabId:       "2qPTBJCN6ib7iJ6WaIVvoSmySSV",
+		MenuNumbers: []int{4},
+	}
+	json, err := json.Marshal(markFoodServedRequest)
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	var capturedCommand commands.MarkFoodServed
+	suite.commandDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		capturedCommand = args.Get(1).(commands.MarkFoodServed)
+	})
+
+	// When
+	suite.writeService.markFoodServedHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "200 OK", rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":true,\"error\":\"\"}", string(bytes))
+	assert.Equal(suite.T(), []int{4}, capturedCommand.MenuNumbers)
+	assert.Equal(suite.T(), "2qPTBJCN6ib7iJ6WaIVvoSmySSV", capturedCommand.ID.String())
+}
+
+func (suite *WriteServiceTestSuite) TestCancelItemsHandlerReturnsErrorIfCannotParseId() {
+
+	// Given
+	cancelItemsRequest := model.CancelItemsRequest{
+		TabId:       "?",
+		MenuNumbers: []int{1},
+		Reason:      "ordered by mistake",
+	}
+	json, err := json.Marshal(cancelItemsRequest)
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	// When
+	suite.writeService.cancelItemsHandler(rr, request)
 
 	// Then
 	assert.Equal(suite.T(), "400 Bad Request", rr.Result().Status)
@@ -456,66 +714,687 @@ func (suite *WriteServiceTestSuite) TestCloseTabHandlerReturnsErrorIfCannotParse
 	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"could not parse id\"}", string(bytes))
 }
 
-func (suite *WriteServiceTestSuite) TestCloseTabHandlerReturnsErrorIfDispatcherReturnsError() {
+func (suite *WriteServiceTestSuite) TestCancelItemsHandlerReturnsErrorIfDispatcherReturnsError() {
 
 	// Given
-	closeTabRequest := model.CloseTabRequest{
-		TabId:      "2qPTBJCN6ib7iJ6WaIVvoSmySSV",
-		AmountPaid: 1.0,
+	cancelItemsRequest := model.CancelItemsRequest{
+		TabId:       "2qPTBJCN6ib7iJ6WaIVvoSmySSV",
+		MenuNumbers: []int{1},
 	}
-	json, err := json.Marshal(closeTabRequest)
+	json, err := json.Marshal(cancelItemsRequest)
 	assert.NoError(suite.T(), err)
 	rr := httptest.NewRecorder()
 	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
 	assert.NoError(suite.T(), err)
 
-	suite.commandDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(errors.New("error dispatching command"))
+	suite.commandDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(errors.New("a reason is required to cancel items"))
 
 	// When
-	suite.writeService.closeTabHandler(rr, request)
+	suite.writeService.cancelItemsHandler(rr, request)
 
 	// Then
 	assert.Equal(suite.T(), "500 Internal Server Error", rr.Result().Status)
 	bytes, err := io.ReadAll(rr.Result().Body)
 	assert.NoError(suite.T(), err)
-	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"Error processing closeTab request: error dispatching command\"}", string(bytes))
+	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"Error processing cancelItems request: a reason is required to cancel items\"}", string(bytes))
 }
 
-func (suite *WriteServiceTestSuite) TestCloseTabHandlerReturnsOkIfNoError() {
+func (suite *WriteServiceTestSuite) TestCancelItemsHandlerReturnsOkIfNoError() {
 
 	// Given
-	closeTabRequest := model.CloseTabRequest{
-		TabId:      "2qPTBJCN6ib7iJ6WaIVvoSmySSV",
-		AmountPaid: 1.0,
+	cancelItemsRequest := model.CancelItemsRequest{
+		TabId:       "2qPTBJCN6ib7iJ6WaIVvoSmySSV",
+		MenuNumbers: []int{1},
+		Reason:      "ordered by mistake",
 	}
-	json, err := json.Marshal(closeTabRequest)
+	json, err := json.Marshal(cancelItemsRequest)
 	assert.NoError(suite.T(), err)
 	rr := httptest.NewRecorder()
 	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
 	assert.NoError(suite.T(), err)
 
-	var capturedCommand commands.CloseTab
+	var capturedCommand commands.CancelItems
 	suite.commandDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
-		capturedCommand = args.Get(1).(commands.CloseTab)
+		capturedCommand = args.Get(1).(commands.CancelItems)
 	})
 
 	// When
-	suite.writeService.closeTabHandler(rr, request)
+	suite.writeService.cancelItemsHandler(rr, request)
 
 	// Then
 	assert.Equal(suite.T(), "200 OK", rr.Result().Status)
 	bytes, err := io.ReadAll(rr.Result().Body)
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), "{\"ok\":true,\"error\":\"\"}", string(bytes))
-	assert.Equal(suite.T(), 1.0, capturedCommand.AmountPaid)
+	assert.Equal(suite.T(), []int{1}, capturedCommand.MenuNumbers)
+	assert.Equal(suite.T(), "ordered by mistake", capturedCommand.Reason)
 	assert.Equal(suite.T(), "2qPTBJCN6ib7iJ6WaIVvoSmySSV", capturedCommand.ID.String())
 }
 
+func (suite *WriteServiceTestSuite) TestApplyDiscountHandlerReturnsErrorIfCannotParseId() {
+
+	// Given
+	applyDiscountRequest := model.ApplyDiscountRequest{
+		TabId:      "?",
+		Percentage: 10,
+	}
+	json, err := json.Marshal(applyDiscountRequest)
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	// When
+	suite.writeService.applyDiscountHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "400 Bad Request", rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"could not parse id\"}", string(bytes))
+}
+
+func (suite *WriteServiceTestSuite) TestApplyDiscountHandlerReturnsOkIfNoError() {
+
+	// Given
+	applyDiscountRequest := model.ApplyDiscountRequest{
+		TabId:        "2qPTBJCN6ib7iJ6WaIVvoSmySSV",
+		Percentage:   10,
+		Reason:       "regular customer",
+		AuthorisedBy: "manager",
+	}
+	json, err := json.Marshal(applyDiscountRequest)
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	var capturedCommand commands.ApplyDiscount
+	suite.commandDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		capturedCommand = args.Get(1).(commands.ApplyDiscount)
+	})
+
+	// When
+	suite.writeService.applyDiscountHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "200 OK", rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":true,\"error\":\"\"}", string(bytes))
+	assert.Equal(suite.T(), commands.ApplyDiscount{
+		BaseCommand:  commands.BaseCommand{ID: capturedCommand.ID},
+		Percentage:   10,
+		Reason:       "regular customer",
+		AuthorisedBy: "manager",
+	}, capturedCommand)
+	assert.Equal(suite.T(), "2qPTBJCN6ib7iJ6WaIVvoSmySSV", capturedCommand.ID.String())
+}
+
+func (suite *WriteServiceTestSuite) TestCompItemHandlerReturnsErrorIfDispatcherReturnsError() {
+
+	// Given
+	compItemRequest := model.CompItemRequest{
+		TabId:        "2qPTBJCN6ib7iJ6WaIVvoSmySSV",
+		MenuNumber:   1,
+		Reason:       "spilled",
+		AuthorisedBy: "manager",
+	}
+	json, err := json.Marshal(compItemRequest)
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	suite.commandDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(errors.New("cannot comp an item that was not served: 1"))
+
+	// When
+	suite.writeService.compItemHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "500 Internal Server Error", rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"Error processing compItem request: cannot comp an item that was not served: 1\"}", string(bytes))
+}
+
+func (suite *WriteServiceTestSuite) TestCompItemHandlerReturnsOkIfNoError() {
+
+	// Given
+	compItemRequest := model.CompItemRequest{
+		TabId:        "2qPTBJCN6ib7iJ6WaIVvoSmySSV",
+		MenuNumber:   1,
+		Reason:       "spilled",
+		AuthorisedBy: "manager",
+	}
+	json, err := json.Marshal(compItemRequest)
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	var capturedCommand commands.CompItem
+	suite.commandDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		capturedCommand = args.Get(1).(commands.CompItem)
+	})
+
+	// When
+	suite.writeService.compItemHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "200 OK", rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":true,\"error\":\"\"}", string(bytes))
+	assert.Equal(suite.T(), 1, capturedCommand.MenuNumber)
+	assert.Equal(suite.T(), "spilled", capturedCommand.Reason)
+	assert.Equal(suite.T(), "manager", capturedCommand.AuthorisedBy)
+	assert.Equal(suite.T(), "2qPTBJCN6ib7iJ6WaIVvoSmySSV", capturedCommand.ID.String())
+}
+
+func (suite *WriteServiceTestSuite) TestRecordPaymentHandlerReturnsErrorIfCannotParseId() {
+
+	// Given
+	recordPaymentRequest := model.RecordPaymentRequest{
+		TabId:  "?",
+		Method: "card",
+		Amount: shared.Cents(150),
+	}
+	json, err := json.Marshal(recordPaymentRequest)
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	// When
+	suite.writeService.recordPaymentHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "400 Bad Request", rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"could not parse id\"}", string(bytes))
+}
+
+func (suite *WriteServiceTestSuite) TestRecordPaymentHandlerReturnsOkIfNoError() {
+
+	// Given
+	recordPaymentRequest := model.RecordPaymentRequest{
+		TabId:   "2qPTBJCN6ib7iJ6WaIVvoSmySSV",
+		Method:  "card",
+		Amount:  shared.Cents(150),
+		Payer:   "Alice",
+		Station: "bar",
+	}
+	json, err := json.Marshal(recordPaymentRequest)
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	var capturedCommand commands.RecordPayment
+	suite.commandDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		capturedCommand = args.Get(1).(commands.RecordPayment)
+	})
+
+	// When
+	suite.writeService.recordPaymentHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "200 OK", rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":true,\"error\":\"\"}", string(bytes))
+	assert.Equal(suite.T(), "card", capturedCommand.Method)
+	assert.Equal(suite.T(), shared.Cents(150), capturedCommand.Amount)
+	assert.Equal(suite.T(), "Alice", capturedCommand.Payer)
+	assert.Equal(suite.T(), "bar", capturedCommand.Station)
+	assert.Equal(suite.T(), "2qPTBJCN6ib7iJ6WaIVvoSmySSV", capturedCommand.ID.String())
+}
+
+func (suite *WriteServiceTestSuite) TestCloseTabHandlerReturnsErrorIfNotPost() {
+	// Given
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "", nil)
+	assert.NoError(suite.T(), err)
+
+	// When
+	suite.writeService.closeTabHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "405 Method Not Allowed", rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"Method Not Allowed\"}", string(bytes))
+}
+func (suite *WriteServiceTestSuite) TestCloseTabHandlerReturnsErrorIfEmptyBody() {
+	// Given
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", nil)
+	assert.NoError(suite.T(), err)
+
+	// When
+	suite.writeService.closeTabHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "400 Bad Request", rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"Empty body\"}", string(bytes))
+}
+
+func (suite *WriteServiceTestSuite) TestCloseTabHandlerReturnsErrorIfInvalidJson() {
+
+	// Given
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader([]byte{1, 2, 3}))
+	assert.NoError(suite.T(), err)
+
+	// When
+	suite.writeService.closeTabHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "400 Bad Request", rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"Invalid JSON request\"}", string(bytes))
+}
+
+func (suite *WriteServiceTestSuite) TestCloseTabHandlerReturnsErrorIfCannotParseId() {
+
+	// Given
+	closeTabRequest := model.CloseTabRequest{
+		TabId:      "?",
+		AmountPaid: shared.Cents(0),
+	}
+	json, err := json.Marshal(closeTabRequest)
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	// When
+	suite.writeService.closeTabHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "400 Bad Request", rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"could not parse id\"}", string(bytes))
+}
+
+func (suite *WriteServiceTestSuite) TestCloseTabHandlerReturnsErrorIfDispatcherReturnsError() {
+
+	// Given
+	closeTabRequest := model.CloseTabRequest{
+		TabId:      "2qPTBJCN6ib7iJ6WaIVvoSmySSV",
+		AmountPaid: shared.Cents(100),
+	}
+	json, err := json.Marshal(closeTabRequest)
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	suite.commandDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(errors.New("error dispatching command"))
+
+	// When
+	suite.writeService.closeTabHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "500 Internal Server Error", rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"Error processing closeTab request: error dispatching command\"}", string(bytes))
+}
+
+func (suite *WriteServiceTestSuite) TestCloseTabHandlerReturnsOkIfNoError() {
+
+	// Given
+	closeTabRequest := model.CloseTabRequest{
+		TabId:      "2qPTBJCN6ib7iJ6WaIVvoSmySSV",
+		AmountPaid: shared.Cents(100),
+	}
+	json, err := json.Marshal(closeTabRequest)
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	var capturedCommand commands.CloseTab
+	suite.commandDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		capturedCommand = args.Get(1).(commands.CloseTab)
+	})
+
+	// When
+	suite.writeService.closeTabHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "200 OK", rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":true,\"error\":\"\"}", string(bytes))
+	assert.Equal(suite.T(), shared.Cents(100), capturedCommand.AmountPaid)
+	assert.Equal(suite.T(), "2qPTBJCN6ib7iJ6WaIVvoSmySSV", capturedCommand.ID.String())
+}
+
+func (suite *WriteServiceTestSuite) TestCloseTabHandlerPassesTheStation() {
+	// Given
+	json, err := json.Marshal(model.CloseTabRequest{TabId: "2qPTBJCN6ib7iJ6WaIVvoSmySSV", AmountPaid: shared.Cents(100), Station: "terrace"})
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	var capturedCommand commands.CloseTab
+	suite.commandDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		capturedCommand = args.Get(1).(commands.CloseTab)
+	})
+
+	// When
+	suite.writeService.closeTabHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "200 OK", rr.Result().Status)
+	assert.Equal(suite.T(), "terrace", capturedCommand.Station)
+}
+
+func (suite *WriteServiceTestSuite) TestOpenShiftHandlerReturnsOkIfNoError() {
+	// Given
+	json, err := json.Marshal(model.OpenShiftRequest{Station: "terrace", OpeningFloat: shared.Cents(10000)})
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	var capturedCommand commands.OpenShift
+	suite.commandDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		capturedCommand = args.Get(1).(commands.OpenShift)
+	})
+
+	// When
+	suite.writeService.openShiftHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "200 OK", rr.Result().Status)
+	assert.Equal(suite.T(), commands.OpenShift{BaseCommand: commands.BaseCommand{ID: commands.StationID("terrace")}, Station: "terrace", OpeningFloat: shared.Cents(10000)}, capturedCommand)
+}
+
+func (suite *WriteServiceTestSuite) TestCloseShiftHandlerReturnsConflictIfNoShiftIsOpen() {
+	// Given
+	json, err := json.Marshal(model.CloseShiftRequest{Station: "terrace", CountedCash: shared.Cents(15500), WaiterCounts: map[string]shared.Money{"Charles": shared.Cents(5500)}})
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	var capturedCommand commands.CloseShift
+	suite.commandDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(fmt.Errorf("%w at station terrace", commands.ErrNoShiftOpen)).Run(func(args mock.Arguments) {
+		capturedCommand = args.Get(1).(commands.CloseShift)
+	})
+
+	// When
+	suite.writeService.closeShiftHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "409 Conflict", rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"Error processing closeShift request: no shift is open at station terrace\"}", string(bytes))
+	assert.Equal(suite.T(), map[string]shared.Money{"Charles": shared.Cents(5500)}, capturedCommand.WaiterCounts)
+}
+
+func (suite *WriteServiceTestSuite) TestShiftHandlersOnlyAcceptPost() {
+	for _, handler := range []http.HandlerFunc{suite.writeService.openShiftHandler, suite.writeService.closeShiftHandler} {
+		rr := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "", nil)
+		assert.NoError(suite.T(), err)
+
+		handler(rr, request)
+
+		assert.Equal(suite.T(), "405 Method Not Allowed", rr.Result().Status)
+	}
+}
+
+func (suite *WriteServiceTestSuite) TestAddMenuItemHandlerReturnsOkIfNoError() {
+	// Given
+	addMenuItemRequest := model.AddMenuItemRequest{
+		MenuNumber:  6,
+		Description: "pie",
+		Price:       shared.Cents(450),
+		Category:    "mains",
+	}
+	json, err := json.Marshal(addMenuItemRequest)
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	var capturedCommand commands.AddMenuItem
+	suite.menuDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		capturedCommand = args.Get(1).(commands.AddMenuItem)
+	})
+
+	// When
+	suite.writeService.addMenuItemHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "200 OK", rr.Result().Status)
+	assert.Equal(suite.T(), commands.AddMenuItem{BaseCommand: commands.BaseCommand{ID: commands.MenuItemID(6)}, MenuNumber: 6, Description: "pie", Price: shared.Cents(450), Category: "mains"}, capturedCommand)
+}
+
+func (suite *WriteServiceTestSuite) TestAddMenuItemHandlerReturnsConflictIfTheItemExists() {
+	// Given
+	json, err := json.Marshal(model.AddMenuItemRequest{MenuNumber: 6, Description: "pie", Price: shared.Cents(450)})
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	suite.menuDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(fmt.Errorf("error handling command [AddMenuItem], reason: %w: 6", commands.ErrMenuItemAlreadyExists))
+
+	// When
+	suite.writeService.addMenuItemHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "409 Conflict", rr.Result().Status)
+}
+
+func (suite *WriteServiceTestSuite) TestChangeMenuItemPriceHandlerReturnsOkIfNoError() {
+	// Given
+	json, err := json.Marshal(model.ChangeMenuItemPriceRequest{MenuNumber: 6, Price: shared.Cents(500)})
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	var capturedCommand commands.ChangeMenuItemPrice
+	suite.menuDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		capturedCommand = args.Get(1).(commands.ChangeMenuItemPrice)
+	})
+
+	// When
+	suite.writeService.changeMenuItemPriceHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "200 OK", rr.Result().Status)
+	assert.Equal(suite.T(), commands.ChangeMenuItemPrice{BaseCommand: commands.BaseCommand{ID: commands.MenuItemID(6)}, MenuNumber: 6, Price: shared.Cents(500)}, capturedCommand)
+}
+
+func (suite *WriteServiceTestSuite) TestRetireMenuItemHandlerReturnsNotFoundForUnknownItems() {
+	// Given
+	json, err := json.Marshal(model.RetireMenuItemRequest{MenuNumber: 42})
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	suite.menuDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(fmt.Errorf("error handling command [RetireMenuItem], reason: %w: 42", commands.ErrMenuItemNotFound))
+
+	// When
+	suite.writeService.retireMenuItemHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "404 Not Found", rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"Error processing retireMenuItem request: error handling command [RetireMenuItem], reason: menu item not found: 42\"}", string(bytes))
+}
+
+func (suite *WriteServiceTestSuite) TestMenuHandlersOnlyAcceptPost() {
+	for _, handler := range []http.HandlerFunc{suite.writeService.addMenuItemHandler, suite.writeService.changeMenuItemPriceHandler, suite.writeService.renameMenuItemHandler, suite.writeService.categoriseMenuItemHandler, suite.writeService.setMenuItemRecipeHandler, suite.writeService.retireMenuItemHandler} {
+		rr := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "", nil)
+		assert.NoError(suite.T(), err)
+
+		handler(rr, request)
+
+		assert.Equal(suite.T(), "405 Method Not Allowed", rr.Result().Status)
+	}
+}
+
+func (suite *WriteServiceTestSuite) TestRestockHandlerReturnsOkIfNoError() {
+	// Given
+	json, err := json.Marshal(model.RestockRequest{MenuNumber: 1, Quantity: 24})
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	var capturedCommand commands.Restock
+	suite.stockDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		capturedCommand = args.Get(1).(commands.Restock)
+	})
+
+	// When
+	suite.writeService.restockHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "200 OK", rr.Result().Status)
+	assert.Equal(suite.T(), commands.Restock{BaseCommand: commands.BaseCommand{ID: commands.StockID(1)}, MenuNumber: 1, Quantity: 24}, capturedCommand)
+}
+
+func (suite *WriteServiceTestSuite) TestCountStockHandlerReturnsErrorIfDispatcherReturnsError() {
+	// Given
+	json, err := json.Marshal(model.CountStockRequest{MenuNumber: 1, Quantity: -1})
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	suite.stockDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(errors.New("the quantity counted can't be negative, got: -1"))
+
+	// When
+	suite.writeService.countStockHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "500 Internal Server Error", rr.Result().Status)
+	bytes, err := io.ReadAll(rr.Result().Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{\"ok\":false,\"error\":\"Error processing countStock request: the quantity counted can't be negative, got: -1\"}", string(bytes))
+}
+
+func (suite *WriteServiceTestSuite) TestSetMenuItemRecipeHandlerReturnsOkIfNoError() {
+	// Given
+	recipe := []shared.RecipeIngredient{{Ingredient: "gin", Quantity: 50}, {Ingredient: "tonic", Quantity: 150}}
+	json, err := json.Marshal(model.SetMenuItemRecipeRequest{MenuNumber: 6, Recipe: recipe})
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	var capturedCommand commands.SetMenuItemRecipe
+	suite.menuDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		capturedCommand = args.Get(1).(commands.SetMenuItemRecipe)
+	})
+
+	// When
+	suite.writeService.setMenuItemRecipeHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "200 OK", rr.Result().Status)
+	assert.Equal(suite.T(), commands.SetMenuItemRecipe{BaseCommand: commands.BaseCommand{ID: commands.MenuItemID(6)}, MenuNumber: 6, Recipe: recipe}, capturedCommand)
+}
+
+func (suite *WriteServiceTestSuite) TestRestockIngredientHandlerReturnsOkIfNoError() {
+	// Given
+	json, err := json.Marshal(model.RestockIngredientRequest{Ingredient: "gin", Quantity: 2100, Unit: "ml", ContainerSize: 700})
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+
+	var capturedCommand commands.RestockIngredient
+	suite.stockDispatcher.On("DispatchCommand", suite.ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		capturedCommand = args.Get(1).(commands.RestockIngredient)
+	})
+
+	// When
+	suite.writeService.restockIngredientHandler(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "200 OK", rr.Result().Status)
+	assert.Equal(suite.T(), commands.RestockIngredient{BaseCommand: commands.BaseCommand{ID: commands.IngredientID("gin")}, Ingredient: "gin", Quantity: 2100, Unit: "ml", ContainerSize: 700}, capturedCommand)
+}
+
+func (suite *WriteServiceTestSuite) TestStockHandlersOnlyAcceptPost() {
+	for _, handler := range []http.HandlerFunc{suite.writeService.restockHandler, suite.writeService.countStockHandler, suite.writeService.setReorderLevelHandler, suite.writeService.restockIngredientHandler, suite.writeService.countIngredientHandler} {
+		rr := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "", nil)
+		assert.NoError(suite.T(), err)
+
+		handler(rr, request)
+
+		assert.Equal(suite.T(), "405 Method Not Allowed", rr.Result().Status)
+	}
+}
+
+func (suite *WriteServiceTestSuite) TestCommandsCarryTheCorrelationIDAndActorOfTheRequest() {
+	// Given
+	json, err := json.Marshal(model.OpenTabRequest{TableNumber: 1, Waiter: "Charles"})
+	assert.NoError(suite.T(), err)
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/openTab", bytes.NewReader(json))
+	assert.NoError(suite.T(), err)
+	request.Header.Set("X-Correlation-ID", "request-1")
+	request.Header.Set("X-Actor", "Charles")
+
+	var capturedMetadata events.Metadata
+	suite.commandDispatcher.On("DispatchCommand", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		capturedMetadata = events.MetadataFromContext(args.Get(0).(context.Context))
+	})
+
+	// When
+	suite.writeService.httpServer.Handler.ServeHTTP(rr, request)
+
+	// Then
+	assert.Equal(suite.T(), "200 OK", rr.Result().Status)
+	assert.Equal(suite.T(), "request-1", rr.Result().Header.Get("X-Correlation-ID"))
+	assert.Equal(suite.T(), events.Metadata{CorrelationID: "request-1", Actor: "Charles"}, capturedMetadata)
+}
+
+func (suite *WriteServiceTestSuite) TestRequestsWithoutACorrelationIDGetOne() {
+	// Given
+	rr := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/openTab", nil)
+	assert.NoError(suite.T(), err)
+
+	// When
+	suite.writeService.httpServer.Handler.ServeHTTP(rr, request)
+
+	// Then
+	_, err = ksuid.Parse(rr.Result().Header.Get("X-Correlation-ID"))
+	assert.NoError(suite.T(), err)
+}
+
 func (suite *WriteServiceTestSuite) SetupTest() {
 	suite.ctx = context.Background()
 	suite.menuItemRepository = shared_mocks.NewMenuItemRepository(suite.T())
 	suite.commandDispatcher = commands_mocks.NewCommandDispatcher(suite.T())
-	suite.writeService = CreateWriteService(1234, suite.menuItemRepository, suite.commandDispatcher)
+	suite.menuDispatcher = commands_mocks.NewCommandDispatcher(suite.T())
+	suite.stockDispatcher = commands_mocks.NewCommandDispatcher(suite.T())
+	suite.stockChecker = shared_mocks.NewStockChecker(suite.T())
+	suite.writeService = CreateWriteService(1234, suite.menuItemRepository, suite.commandDispatcher, suite.menuDispatcher, suite.stockDispatcher, suite.stockChecker)
 }
 
 func TestWriteServiceTestSuite(t *testing.T) {